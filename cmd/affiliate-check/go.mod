@@ -0,0 +1,7 @@
+module main
+
+go 1.24.3
+
+replace thiroyoshi.com/video-converter => ../../src/video-converter
+
+require thiroyoshi.com/video-converter v0.0.0-00010101000000-000000000000
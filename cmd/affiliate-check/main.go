@@ -0,0 +1,55 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"net/http"
+	"os"
+	"time"
+
+	"thiroyoshi.com/video-converter/affiliate"
+)
+
+// Main function for checking the affiliate catalog.
+// It reports duplicate and malformed entries, then requests every link to find dead links and links to the same product.
+func main() {
+	catalogPath := flag.String("catalog", "", "affiliate catalog to check, the bundled one when empty")
+	workers := flag.Int("workers", 4, "number of links checked at the same time")
+	timeout := flag.Duration("timeout", 15*time.Second, "timeout of each request")
+	offline := flag.Bool("offline", false, "only validate the catalog without requesting the links")
+	flag.Parse()
+
+	problems, err := check(*catalogPath, *workers, *timeout, *offline)
+	if err != nil {
+		fmt.Printf("Error: %v\n", err)
+		os.Exit(1)
+	}
+	for _, p := range problems {
+		fmt.Println(p)
+	}
+	if len(problems) > 0 {
+		fmt.Printf("%d problems found.\n", len(problems))
+		os.Exit(1)
+	}
+	fmt.Println("No problems found.")
+}
+
+func check(path string, workers int, timeout time.Duration, offline bool) ([]affiliate.Problem, error) {
+	data, err := affiliate.Read(path)
+	if err != nil {
+		return nil, err
+	}
+	problems := data.Validate()
+	if offline {
+		return problems, nil
+	}
+
+	client := &http.Client{Timeout: timeout}
+	results, linkProblems := affiliate.CheckLinks(client, data.Products, workers)
+	for _, r := range results {
+		if r.Err == nil {
+			fmt.Printf("%d %s -> %s\n", r.StatusCode, r.Product.ID, r.FinalURL)
+		}
+	}
+	return append(problems, linkProblems...), nil
+}
@@ -0,0 +1,118 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"time"
+
+	"thiroyoshi.com/video-converter/notifier"
+	"thiroyoshi.com/video-converter/stats"
+	"thiroyoshi.com/video-converter/x"
+	"thiroyoshi.com/video-converter/youtube"
+)
+
+// Number of days shown in the chart
+const chartDays = 30
+
+// Main function for follower statistics.
+// "collect" records today's snapshot and "report" sends the weekly report to the digest notification route.
+func main() {
+	storePath := flag.String("store", "stats.jsonl", "time-series store of daily snapshots (JSON Lines)")
+	chartPath := flag.String("chart", "", "also write the chart PNG to this file (report only)")
+	flag.Usage = func() {
+		fmt.Fprintln(flag.CommandLine.Output(), "Usage: stats [flags] collect|report")
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+
+	jst, err := time.LoadLocation("Asia/Tokyo")
+	if err != nil {
+		fmt.Printf("Error loading timezone: %v\n", err)
+		os.Exit(1)
+	}
+	now := time.Now().In(jst)
+	store := stats.NewStore(*storePath)
+
+	switch flag.Arg(0) {
+	case "collect":
+		err = collect(store, now)
+	case "report":
+		err = report(store, now, *chartPath)
+	default:
+		flag.Usage()
+		os.Exit(2)
+	}
+	if err != nil {
+		fmt.Printf("Error: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+func collect(store *stats.Store, now time.Time) error {
+	var ytClient stats.ChannelStatistician
+	if client, err := youtube.NewClientFromEnv(); err == nil {
+		ytClient = client
+	} else {
+		fmt.Printf("Skipping YouTube: %v\n", err)
+	}
+
+	var xClient stats.XProfiler
+	if client, err := x.NewClient(); err == nil {
+		xClient = client
+	} else {
+		fmt.Printf("Skipping X: %v\n", err)
+	}
+
+	snapshot, collectErr := stats.Collect(now, xClient, ytClient)
+	if snapshot.X == nil && snapshot.YouTube == nil {
+		return fmt.Errorf("no statistics collected: %w", collectErr)
+	}
+	if err := store.Put(snapshot); err != nil {
+		return err
+	}
+
+	fmt.Printf("Snapshot for %s saved.\n", snapshot.Date)
+	return collectErr
+}
+
+func report(store *stats.Store, now time.Time, chartPath string) error {
+	snapshots, err := store.Load()
+	if err != nil {
+		return err
+	}
+	weekly := stats.Weekly(snapshots, now)
+	if weekly == nil {
+		return errors.New("no snapshots recorded yet")
+	}
+	text := weekly.Text()
+	fmt.Print(text)
+
+	msg := notifier.Message{Event: notifier.EventDigest, Title: stats.ReportTitle, Text: text}
+	if err := notifier.FromEnv().Notify(msg); err != nil {
+		return fmt.Errorf("failed to send report: %w", err)
+	}
+
+	chart, err := stats.Chart(snapshots, chartDays, now)
+	if errors.Is(err, stats.ErrNotEnoughData) {
+		fmt.Println("Not enough snapshots for a chart yet.")
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if chartPath != "" {
+		if err := os.WriteFile(chartPath, chart, 0o644); err != nil {
+			return err
+		}
+	}
+
+	uploader, ok := notifier.SlackFileUploaderFromEnv()
+	if !ok {
+		fmt.Println("SLACK_BOT_TOKEN or SLACK_CHANNEL_ID is not set, chart is not sent to Slack.")
+		return nil
+	}
+	comment := fmt.Sprintf("直近%d日の推移\n%s", chartDays, stats.ChartLegend)
+	return uploader.Upload("stats-"+now.Format("20060102")+".png", stats.ReportTitle, comment, chart)
+}
@@ -0,0 +1,108 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"thiroyoshi.com/video-converter/notifier"
+	"thiroyoshi.com/video-converter/videostats"
+	"thiroyoshi.com/video-converter/youtube"
+)
+
+// Playlists the video converter adds processed videos to
+const defaultPlaylists = "PLTSYDCu3sM9JLlRtt7LU6mfM8N8zQSYGq,PLTSYDCu3sM9LEQ27HYpSlCMrxHyquc-_O"
+
+// Main function for per-video statistics.
+// "collect" records the current numbers of recent videos and "report" ranks videos by early views.
+func main() {
+	storePath := flag.String("store", "video-stats.jsonl", "time-series store of video statistics (JSON Lines)")
+	playlists := flag.String("playlists", "", "comma separated playlist IDs of processed videos (default YOUTUBE_PLAYLIST_IDS or the converter's playlists)")
+	maxAge := flag.Duration("max-age", 30*24*time.Hour, "only collect videos published within this duration (0 for all)")
+	window := flag.Duration("window", 24*time.Hour, "time after publishing used to compare videos (report only)")
+	top := flag.Int("top", 10, "number of videos in the ranking (report only)")
+	notify := flag.Bool("notify", false, "send the report to the digest notification route (report only)")
+	flag.Usage = func() {
+		fmt.Fprintln(flag.CommandLine.Output(), "Usage: video-stats [flags] collect|report")
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+
+	jst, err := time.LoadLocation("Asia/Tokyo")
+	if err != nil {
+		fmt.Printf("Error loading timezone: %v\n", err)
+		os.Exit(1)
+	}
+	store := videostats.NewStore(*storePath)
+
+	switch flag.Arg(0) {
+	case "collect":
+		err = collect(store, playlistIDs(*playlists), *maxAge, time.Now().In(jst))
+	case "report":
+		err = report(store, *window, *top, *notify, jst)
+	default:
+		flag.Usage()
+		os.Exit(2)
+	}
+	if err != nil {
+		fmt.Printf("Error: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+func playlistIDs(flagValue string) []string {
+	value := flagValue
+	if value == "" {
+		value = os.Getenv("YOUTUBE_PLAYLIST_IDS")
+	}
+	if value == "" {
+		value = defaultPlaylists
+	}
+	var ids []string
+	for _, id := range strings.Split(value, ",") {
+		if id = strings.TrimSpace(id); id != "" {
+			ids = append(ids, id)
+		}
+	}
+	return ids
+}
+
+func collect(store *videostats.Store, playlists []string, maxAge time.Duration, now time.Time) error {
+	client, err := youtube.NewClientFromEnv()
+	if err != nil {
+		return err
+	}
+	records, err := videostats.Collect(client, playlists, maxAge, now)
+	if err != nil {
+		return err
+	}
+	if err := store.Append(records); err != nil {
+		return err
+	}
+	fmt.Printf("%d videos recorded.\n", len(records))
+	return nil
+}
+
+func report(store *videostats.Store, window time.Duration, top int, notify bool, loc *time.Location) error {
+	records, err := store.Load()
+	if err != nil {
+		return err
+	}
+	if len(records) == 0 {
+		return errors.New("no records in the store yet, run collect first")
+	}
+	text := videostats.BuildReport(records, window, loc).Text(top)
+	fmt.Print(text)
+
+	if !notify {
+		return nil
+	}
+	msg := notifier.Message{Event: notifier.EventDigest, Title: videostats.ReportTitle, Text: text}
+	if err := notifier.FromEnv().Notify(msg); err != nil {
+		return fmt.Errorf("failed to send report: %w", err)
+	}
+	return nil
+}
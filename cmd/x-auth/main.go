@@ -0,0 +1,107 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"time"
+
+	"thiroyoshi.com/video-converter/secret"
+	"thiroyoshi.com/video-converter/x"
+)
+
+// Time to wait for the user to approve the app in the browser
+const authorizeTimeout = 5 * time.Minute
+
+// Main function for the one-time X OAuth 2.0 authorization
+func main() {
+	config, ok := x.OAuth2ConfigFromEnv()
+	if !ok {
+		fmt.Println("X_OAUTH2_CLIENT_ID is not set.")
+		os.Exit(1)
+	}
+
+	token, err := authorize(config)
+	if err != nil {
+		fmt.Printf("Error authorizing with X: %v\n", err)
+		os.Exit(1)
+	}
+
+	// GOOGLE_CLOUD_PROJECT が設定されていればSecret Managerに、なければ SECRETS_DIR に保存する
+	if err := x.NewTokenStore(secret.FromEnv()).Save(token); err != nil {
+		fmt.Printf("Error saving X OAuth2 token: %v\n", err)
+		os.Exit(1)
+	}
+
+	fmt.Printf("X OAuth2 token saved. scope=%s expiry=%s\n", token.Scope, token.Expiry.Format(time.RFC3339))
+}
+
+// authorize はローカルでコールバックを待ち受け、認可コードをトークンに交換します。
+func authorize(config *x.OAuth2Config) (*x.Token, error) {
+	redirect, err := url.Parse(config.RedirectURL)
+	if err != nil {
+		return nil, fmt.Errorf("invalid redirect URL: %w", err)
+	}
+
+	verifier, challenge, err := x.NewPKCE()
+	if err != nil {
+		return nil, err
+	}
+	state, err := x.NewState()
+	if err != nil {
+		return nil, err
+	}
+
+	listener, err := net.Listen("tcp", redirect.Host)
+	if err != nil {
+		return nil, fmt.Errorf("failed to listen on %s: %w", redirect.Host, err)
+	}
+
+	type result struct {
+		code string
+		err  error
+	}
+	results := make(chan result, 1)
+
+	mux := http.NewServeMux()
+	mux.HandleFunc(redirect.Path, func(w http.ResponseWriter, r *http.Request) {
+		q := r.URL.Query()
+		switch {
+		case q.Get("state") != state:
+			http.Error(w, "state mismatch", http.StatusBadRequest)
+			results <- result{err: errors.New("state mismatch")}
+		case q.Get("error") != "":
+			http.Error(w, "authorization denied", http.StatusBadRequest)
+			results <- result{err: fmt.Errorf("authorization denied: %s", q.Get("error"))}
+		default:
+			_, _ = fmt.Fprintln(w, "Authorization completed. You can close this window.")
+			results <- result{code: q.Get("code")}
+		}
+	})
+	server := &http.Server{Handler: mux, ReadHeaderTimeout: 10 * time.Second}
+	go func() {
+		if err := server.Serve(listener); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			results <- result{err: err}
+		}
+	}()
+	defer func() {
+		_ = server.Shutdown(context.Background())
+	}()
+
+	fmt.Println("Open the following URL in your browser and authorize the app:")
+	fmt.Println(config.AuthCodeURL(state, challenge))
+
+	select {
+	case res := <-results:
+		if res.err != nil {
+			return nil, res.err
+		}
+		return config.Exchange(res.code, verifier)
+	case <-time.After(authorizeTimeout):
+		return nil, errors.New("timed out waiting for authorization")
+	}
+}
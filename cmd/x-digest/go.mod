@@ -0,0 +1,31 @@
+module main
+
+go 1.24.3
+
+replace (
+	thiroyoshi.com/blog-post => ../../src/blog-post
+	thiroyoshi.com/video-converter => ../../src/video-converter
+)
+
+require thiroyoshi.com/blog-post v0.0.0-00010101000000-000000000000
+
+require (
+	github.com/GoogleCloudPlatform/functions-framework-go v1.9.2 // indirect
+	github.com/cloudevents/sdk-go/v2 v2.15.2 // indirect
+	github.com/dghubble/oauth1 v0.7.3 // indirect
+	github.com/google/uuid v1.6.0 // indirect
+	github.com/json-iterator/go v1.1.10 // indirect
+	github.com/modern-go/concurrent v0.0.0-20180228061459-e0a39a4cb421 // indirect
+	github.com/modern-go/reflect2 v0.0.0-20180701023420-4b7aa43c6742 // indirect
+	github.com/openai/openai-go v0.1.0-beta.10 // indirect
+	github.com/tidwall/gjson v1.18.0 // indirect
+	github.com/tidwall/match v1.1.1 // indirect
+	github.com/tidwall/pretty v1.2.1 // indirect
+	github.com/tidwall/sjson v1.2.5 // indirect
+	go.uber.org/atomic v1.4.0 // indirect
+	go.uber.org/multierr v1.1.0 // indirect
+	go.uber.org/zap v1.10.0 // indirect
+	golang.org/x/net v0.50.0 // indirect
+	golang.org/x/text v0.34.0 // indirect
+	thiroyoshi.com/video-converter v0.0.0-00010101000000-000000000000 // indirect
+)
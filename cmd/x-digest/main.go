@@ -0,0 +1,20 @@
+package main
+
+import (
+	"fmt"
+	"os"
+
+	blogpost "thiroyoshi.com/blog-post"
+)
+
+// Main function for sending the weekly X digest
+func main() {
+	fmt.Println("Collecting this week's X activity...")
+
+	if err := blogpost.RunXDigest(); err != nil {
+		fmt.Printf("Error sending X digest: %v\n", err)
+		os.Exit(1)
+	}
+
+	fmt.Println("X digest sent successfully.")
+}
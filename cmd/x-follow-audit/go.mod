@@ -0,0 +1,15 @@
+module main
+
+go 1.24.3
+
+replace (
+	thiroyoshi.com/blog-post => ../../src/blog-post
+	thiroyoshi.com/video-converter => ../../src/video-converter
+)
+
+require (
+	thiroyoshi.com/blog-post v0.0.0-00010101000000-000000000000
+	thiroyoshi.com/video-converter v0.0.0-00010101000000-000000000000
+)
+
+require github.com/dghubble/oauth1 v0.7.3 // indirect
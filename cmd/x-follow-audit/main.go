@@ -0,0 +1,73 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"time"
+
+	"thiroyoshi.com/blog-post/followaudit"
+	"thiroyoshi.com/video-converter/x"
+)
+
+// Main function for auditing X follows. It only prints the plan unless -apply is given.
+func main() {
+	rulesPath := flag.String("rules", "", "JSON file of audit rules (default rules are used when empty)")
+	apply := flag.Bool("apply", false, "follow and unfollow accounts instead of a dry run")
+	logPath := flag.String("log", "follow-audit.log", "audit log file (JSON Lines)")
+	flag.Parse()
+
+	if err := run(*rulesPath, *apply, *logPath); err != nil {
+		fmt.Printf("Error auditing X follows: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+func run(rulesPath string, apply bool, logPath string) error {
+	rules, err := followaudit.LoadRules(rulesPath)
+	if err != nil {
+		return err
+	}
+
+	client, err := x.NewClient()
+	if err != nil {
+		return err
+	}
+	me := os.Getenv("X_USER_ID")
+	if me == "" {
+		if me, err = client.Me(); err != nil {
+			return err
+		}
+	}
+
+	following, err := client.Following(me)
+	if err != nil {
+		return err
+	}
+	followers, err := client.Followers(me)
+	if err != nil {
+		return err
+	}
+
+	report := followaudit.Plan(following, followers, rules, time.Now())
+	fmt.Print(report.Text())
+
+	if !apply {
+		fmt.Println("Dry run. Run again with -apply to follow and unfollow the accounts above.")
+		return nil
+	}
+
+	log, err := followaudit.OpenAuditLog(logPath)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err := log.Close(); err != nil {
+			fmt.Printf("Error closing audit log: %v\n", err)
+		}
+	}()
+
+	done, err := followaudit.Apply(client, me, report.Actions, rules, log)
+	fmt.Printf("%d actions applied. See %s for details.\n", done, logPath)
+	return err
+}
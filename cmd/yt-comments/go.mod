@@ -0,0 +1,15 @@
+module main
+
+go 1.24.3
+
+replace thiroyoshi.com/video-converter => ../../src/video-converter
+
+require thiroyoshi.com/video-converter v0.0.0-00010101000000-000000000000
+
+require (
+	github.com/openai/openai-go v0.1.0-beta.10 // indirect
+	github.com/tidwall/gjson v1.18.0 // indirect
+	github.com/tidwall/match v1.1.1 // indirect
+	github.com/tidwall/pretty v1.2.1 // indirect
+	github.com/tidwall/sjson v1.2.5 // indirect
+)
@@ -0,0 +1,123 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"thiroyoshi.com/video-converter/comments"
+	"thiroyoshi.com/video-converter/notifier"
+	"thiroyoshi.com/video-converter/youtube"
+)
+
+// Main function for watching YouTube comments.
+// "watch" moderates spam and notifies new comments, "pending" lists reply drafts,
+// and "approve" / "discard" post or drop a draft by comment ID.
+func main() {
+	rulesPath := flag.String("rules", "", "JSON file of moderation and reply rules (default rules are used when empty)")
+	statePath := flag.String("state", "comments.json", "state file of last checked time and pending replies")
+	reply := flag.String("reply", "", "reply text to post instead of the draft (approve only)")
+	flag.Usage = func() {
+		fmt.Fprintln(flag.CommandLine.Output(), "Usage: yt-comments [flags] watch|pending|approve <comment-id>|discard <comment-id>")
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+
+	if err := run(flag.Args(), *rulesPath, *statePath, *reply); err != nil {
+		fmt.Printf("Error: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+func run(args []string, rulesPath, statePath, reply string) error {
+	if len(args) == 0 {
+		flag.Usage()
+		os.Exit(2)
+	}
+	store := comments.NewStateStore(statePath)
+
+	if args[0] == "pending" {
+		return pending(store)
+	}
+
+	client, err := youtube.NewClientFromEnv()
+	if err != nil {
+		return err
+	}
+	watcher := &comments.Watcher{API: client, Store: store}
+
+	switch {
+	case args[0] == "watch":
+		return watch(watcher, client, rulesPath)
+	case args[0] == "approve" && len(args) == 2:
+		id, err := watcher.Approve(args[1], reply)
+		if err != nil {
+			return err
+		}
+		fmt.Printf("Reply %s posted.\n", id)
+		return nil
+	case args[0] == "discard" && len(args) == 2:
+		if err := watcher.Discard(args[1]); err != nil {
+			return err
+		}
+		fmt.Println("Draft discarded.")
+		return nil
+	default:
+		flag.Usage()
+		os.Exit(2)
+		return nil
+	}
+}
+
+func watch(watcher *comments.Watcher, client *youtube.Client, rulesPath string) error {
+	rules, err := comments.LoadRules(rulesPath)
+	if err != nil {
+		return err
+	}
+	drafter, err := comments.NewDrafter(rules.Reply)
+	if err != nil {
+		return err
+	}
+
+	channelID := os.Getenv("YOUTUBE_CHANNEL_ID")
+	if channelID == "" {
+		channel, err := client.MyChannelStatistics()
+		if err != nil {
+			return err
+		}
+		channelID = channel.ChannelID
+	}
+
+	watcher.ChannelID = channelID
+	watcher.Rules = rules
+	watcher.Drafter = drafter
+	watcher.Notify = notifier.FromEnv().Notify
+
+	result, err := watcher.Watch(time.Now())
+	if result != nil {
+		fmt.Printf("%d new comments, %d spam, %d reply drafts.\n", len(result.Genuine), len(result.Spam), len(result.Drafts))
+		for _, spam := range result.Spam {
+			fmt.Printf("  spam %s by %s: %s\n", spam.Comment.ID, spam.Comment.AuthorName, spam.Reason)
+		}
+	}
+	return err
+}
+
+func pending(store *comments.StateStore) error {
+	state, err := store.Load()
+	if err != nil {
+		return err
+	}
+	if len(state.Pending) == 0 {
+		fmt.Println("No pending replies.")
+		return nil
+	}
+	for _, draft := range state.Pending {
+		fmt.Printf("%s (%s, https://www.youtube.com/watch?v=%s)\n", draft.CommentID, draft.Author, draft.VideoID)
+		fmt.Printf("  comment: %s\n", strings.ReplaceAll(draft.Comment, "\n", " "))
+		fmt.Printf("  reply:   %s\n", strings.ReplaceAll(draft.Reply, "\n", " "))
+	}
+	return nil
+}
@@ -0,0 +1,147 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"regexp"
+	"time"
+
+	"thiroyoshi.com/video-converter/affiliate"
+	"thiroyoshi.com/video-converter/disclosure"
+	"thiroyoshi.com/video-converter/metasync"
+	"thiroyoshi.com/video-converter/videometa"
+	"thiroyoshi.com/video-converter/youtube"
+)
+
+// Main function for bringing the description, tags and category of past videos up to date with videometa.
+// "plan" shows the diff and saves the plan, "apply" writes the saved plan and can be run again to resume.
+func main() {
+	planPath := flag.String("plan", "metadata-plan.json", "plan file written by plan and read by apply")
+	playlist := flag.String("playlist", "", "only videos in this playlist (all uploads when empty, plan only)")
+	from := flag.String("from", "", "only videos published on or after this date, YYYY-MM-DD in JST (plan only)")
+	to := flag.String("to", "", "only videos published on or before this date, YYYY-MM-DD in JST (plan only)")
+	title := flag.String("title", "", "only videos whose title matches this regular expression (plan only)")
+	quota := flag.Int("quota", 9000, "quota units this apply may use; each update costs 50 (apply only)")
+	interval := flag.Duration("interval", 2*time.Second, "wait between updates (apply only)")
+	catalogPath := flag.String("catalog", os.Getenv("AFFILIATE_CATALOG"), "affiliate catalog for the gear list, the bundled one when empty (plan only)")
+	disclosurePath := flag.String("disclosure", os.Getenv("DISCLOSURE_RULES"), "advertising disclosure rules, the default ones when empty (plan only)")
+	flag.Usage = func() {
+		fmt.Fprintln(flag.CommandLine.Output(), "Usage: yt-metadata [flags] plan|apply")
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+
+	client, err := youtube.NewClientFromEnv()
+	if err != nil {
+		fmt.Printf("Error: %v\n", err)
+		os.Exit(1)
+	}
+
+	switch flag.Arg(0) {
+	case "plan":
+		var render metasync.Renderer
+		if render, err = newRenderer(*catalogPath, *disclosurePath); err != nil {
+			break
+		}
+		var selector metasync.Selector
+		if selector, err = newSelector(*playlist, *from, *to, *title); err != nil {
+			break
+		}
+		err = plan(client, selector, render, *planPath)
+	case "apply":
+		err = apply(client, *planPath, *quota, *interval)
+	default:
+		flag.Usage()
+		os.Exit(2)
+	}
+	if err != nil {
+		fmt.Printf("Error: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+func newSelector(playlist, from, to, title string) (metasync.Selector, error) {
+	selector := metasync.Selector{PlaylistID: playlist}
+	jst, err := time.LoadLocation("Asia/Tokyo")
+	if err != nil {
+		return selector, err
+	}
+	if from != "" {
+		if selector.From, err = time.ParseInLocation(time.DateOnly, from, jst); err != nil {
+			return selector, fmt.Errorf("invalid -from: %w", err)
+		}
+	}
+	if to != "" {
+		day, err := time.ParseInLocation(time.DateOnly, to, jst)
+		if err != nil {
+			return selector, fmt.Errorf("invalid -to: %w", err)
+		}
+		selector.To = day.AddDate(0, 0, 1)
+	}
+	if title != "" {
+		if selector.TitlePattern, err = regexp.Compile(title); err != nil {
+			return selector, fmt.Errorf("invalid -title: %w", err)
+		}
+	}
+	return selector, nil
+}
+
+// newRenderer は videometa の現在の説明・タグ・カテゴリを返す Renderer を作ります。説明には広告の表示を入れます。
+func newRenderer(catalogPath, disclosurePath string) (metasync.Renderer, error) {
+	catalog, err := affiliate.Load(catalogPath)
+	if err != nil {
+		return nil, err
+	}
+	rules, err := disclosure.LoadRules(disclosurePath)
+	if err != nil {
+		return nil, err
+	}
+	// The description does not depend on the title
+	description, err := rules.Disclose(disclosure.MediumYouTube, videometa.Description(catalog))
+	if err != nil {
+		return nil, err
+	}
+	return func(title string) metasync.Metadata {
+		snippet := videometa.NewSnippet(title, catalog)
+		return metasync.Metadata{Description: description, Tags: snippet.Tags, CategoryID: snippet.CategoryID}
+	}, nil
+}
+
+func plan(client *youtube.Client, selector metasync.Selector, render metasync.Renderer, planPath string) error {
+	p, err := metasync.BuildPlan(client, selector, render, time.Now())
+	if err != nil {
+		return err
+	}
+	fmt.Print(p.Text())
+	if len(p.Changes) == 0 {
+		fmt.Println("No changes. Videos are up to date.")
+		return nil
+	}
+	if err := metasync.SavePlan(planPath, p); err != nil {
+		return err
+	}
+	fmt.Printf("Plan saved to %s. Run apply to update the videos above.\n", planPath)
+	return nil
+}
+
+func apply(client *youtube.Client, planPath string, quota int, interval time.Duration) error {
+	p, err := metasync.LoadPlan(planPath)
+	if err != nil {
+		return err
+	}
+	result, err := metasync.Apply(client, p, metasync.ApplyOptions{
+		QuotaUnits: quota,
+		Interval:   interval,
+		Save:       func(p *metasync.Plan) error { return metasync.SavePlan(planPath, p) },
+	})
+	if result != nil {
+		fmt.Print(result.Text(p))
+	}
+	if errors.Is(err, metasync.ErrQuotaExhausted) {
+		fmt.Println(err)
+		return nil
+	}
+	return err
+}
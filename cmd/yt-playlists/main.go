@@ -0,0 +1,53 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+
+	"thiroyoshi.com/video-converter/playlistsync"
+	"thiroyoshi.com/video-converter/youtube"
+)
+
+// Main function for reconciling YouTube playlists. It only prints the plan unless -apply is given.
+func main() {
+	configPath := flag.String("config", "", "JSON file of managed playlists (the converter's playlists are used when empty)")
+	apply := flag.Bool("apply", false, "insert, delete and move playlist items instead of a dry run")
+	flag.Parse()
+
+	if err := run(*configPath, *apply); err != nil {
+		fmt.Printf("Error reconciling playlists: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+func run(configPath string, apply bool) error {
+	config, err := playlistsync.LoadConfig(configPath)
+	if err != nil {
+		return err
+	}
+	client, err := youtube.NewClientFromEnv()
+	if err != nil {
+		return err
+	}
+
+	uploads, items, err := playlistsync.Fetch(client, config)
+	if err != nil {
+		return err
+	}
+	plan := playlistsync.BuildPlan(config, uploads, items)
+	fmt.Print(plan.Text())
+
+	if len(plan.Actions) == 0 {
+		fmt.Println("Playlists are up to date.")
+		return nil
+	}
+	if !apply {
+		fmt.Println("Dry run. Run again with -apply to update the playlists above.")
+		return nil
+	}
+
+	done, err := playlistsync.Apply(client, plan, config.MaxActions)
+	fmt.Printf("%d of %d actions applied.\n", done, len(plan.Actions))
+	return err
+}
@@ -15,6 +15,8 @@ resource "google_cloudfunctions2_function" "blog_post" {
     service_account_email = google_service_account.function_sa.email
     environment_variables = {
       GOOGLE_CLOUD_PROJECT = var.project_id
+      X_OAUTH2_CLIENT_ID   = var.x_oauth2_client_id
+      HISTORY_BUCKET       = google_storage_bucket.state.name
     }
     secret_environment_variables {
       key        = "OPENAI_API_KEY"
@@ -10,3 +10,8 @@ output "function_url" {
 output "service_account_email" {
   value = google_service_account.function_sa.email
 }
+
+output "slack_webhook_url_secret_id" {
+  value       = google_secret_manager_secret.slack_webhook_url.secret_id
+  description = "Secret Manager secret ID of the Slack webhook URL shared with other functions."
+}
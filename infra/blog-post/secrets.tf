@@ -46,6 +46,21 @@ resource "google_secret_manager_secret_iam_member" "slack_webhook_url_access" {
   depends_on = [google_service_account.function_sa]
 }
 
+# X OAuth 2.0 token is read on every run, and when it is refreshed a new version is added and the older ones destroyed
+resource "google_secret_manager_secret_iam_member" "x_oauth2_token_access" {
+  secret_id  = var.x_oauth2_token_secret_id
+  role       = "roles/secretmanager.secretAccessor"
+  member     = "serviceAccount:${google_service_account.function_sa.email}"
+  depends_on = [google_service_account.function_sa]
+}
+
+resource "google_secret_manager_secret_iam_member" "x_oauth2_token_version_manager" {
+  secret_id  = var.x_oauth2_token_secret_id
+  role       = "roles/secretmanager.secretVersionManager"
+  member     = "serviceAccount:${google_service_account.function_sa.email}"
+  depends_on = [google_service_account.function_sa]
+}
+
 # Ensure the function service account has general secret manager access
 resource "google_project_iam_member" "function_sa_secret_manager_access" {
   project = var.project_id
@@ -0,0 +1,14 @@
+# Bucket for data rewritten on every run, such as the article history
+resource "google_storage_bucket" "state" {
+  name                        = "${var.project_id}-blog-post-state"
+  location                    = var.region
+  uniform_bucket_level_access = true
+  public_access_prevention    = "enforced"
+}
+
+# 記事の記録の読み込みと上書き
+resource "google_storage_bucket_iam_member" "state_object_user" {
+  bucket = google_storage_bucket.state.name
+  role   = "roles/storage.objectUser"
+  member = "serviceAccount:${google_service_account.function_sa.email}"
+}
@@ -22,3 +22,14 @@ variable "short_sha" {
   description = "Short SHA for artifact versioning"
   type        = string
 }
+
+variable "x_oauth2_client_id" {
+  description = "X OAuth 2.0 Client ID (空の場合はOAuth 1.0aを使用)"
+  type        = string
+  default     = ""
+}
+
+variable "x_oauth2_token_secret_id" {
+  description = "X OAuth 2.0トークンを保存するSecret ManagerのシークレットID"
+  type        = string
+}
@@ -9,9 +9,10 @@ resource "google_cloudbuild_trigger" "this" {
     }
   }
 
-  included_files = [
-    "${var.trigger_dir}/**"
-  ]
+  included_files = concat(
+    ["${var.trigger_dir}/**"],
+    [for dir in var.shared_dirs : "${dir}/**"],
+  )
 
   filename = "build/cloudbuild.yaml"
   substitutions = {
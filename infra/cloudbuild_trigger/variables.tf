@@ -17,3 +17,9 @@ variable "trigger_dir" {
   description = "Cloud Buildトリガーで監視するディレクトリ（例: src/convert-starter）"
   type        = string
 }
+
+variable "shared_dirs" {
+  description = "トリガーの対象に加える、関数が参照する他のディレクトリ（例: src/video-converter）"
+  type        = list(string)
+  default     = []
+}
@@ -47,6 +47,42 @@ resource "google_project_iam_member" "cloudbuild_sa_roles" {
   member   = "serviceAccount:${google_service_account.cloudbuild_sa.email}"
 }
 
+# X OAuth 2.0 token secret
+# video-converter and blog-post share one token, and each refresh adds a new version and destroys the older ones
+resource "google_secret_manager_secret" "x_oauth2_token" {
+  secret_id = "x-oauth2-token"
+  replication {
+    auto {}
+  }
+  depends_on = [google_project_service.secretmanager]
+}
+
+# X OAuth 1.0a credentials used when no OAuth 2.0 token is stored
+resource "google_secret_manager_secret" "x_oauth1_credentials" {
+  secret_id = "x-oauth1-credentials"
+  replication {
+    auto {}
+  }
+  depends_on = [google_project_service.secretmanager]
+}
+
+# YouTube OAuth 2.0 client secret and refresh token used by video-converter
+resource "google_secret_manager_secret" "youtube_client_secret" {
+  secret_id = "youtube-client-secret"
+  replication {
+    auto {}
+  }
+  depends_on = [google_project_service.secretmanager]
+}
+
+resource "google_secret_manager_secret" "youtube_refresh_token" {
+  secret_id = "youtube-refresh-token"
+  replication {
+    auto {}
+  }
+  depends_on = [google_project_service.secretmanager]
+}
+
 resource "time_sleep" "wait_for_scheduler_api" {
   depends_on      = [google_project_service.cloud_scheduler]
   create_duration = "30s"
@@ -73,6 +109,7 @@ module "blog-post_deploy_trigger" {
   trigger_name     = "blog-post-deploy-trigger"
   function_name    = "blog-post"
   trigger_dir      = "src/blog-post"
+  shared_dirs      = ["src/video-converter"]
   cloudbuild_sa_id = google_service_account.cloudbuild_sa.id
 }
 
@@ -93,6 +130,13 @@ module "video-converter" {
   source_bucket                         = var.source_bucket
   convert_starter_service_account_email = module.convert-starter.service_account_email
   short_sha                             = var.short_sha
+  x_oauth2_client_id                    = var.x_oauth2_client_id
+  x_oauth2_token_secret_id              = google_secret_manager_secret.x_oauth2_token.id
+  slack_webhook_url_secret_id           = module.blog-post.slack_webhook_url_secret_id
+  x_oauth1_credentials_secret_id        = google_secret_manager_secret.x_oauth1_credentials.id
+  youtube_client_id                     = var.youtube_client_id
+  youtube_client_secret_secret_id       = google_secret_manager_secret.youtube_client_secret.secret_id
+  youtube_refresh_token_secret_id       = google_secret_manager_secret.youtube_refresh_token.secret_id
 }
 
 module "blog-post" {
@@ -102,5 +146,8 @@ module "blog-post" {
   region         = var.region
   source_bucket  = var.source_bucket
   short_sha      = var.short_sha
-  depends_on     = [time_sleep.wait_for_scheduler_api, google_project_service.pubsub, google_project_service.secretmanager]
+
+  x_oauth2_client_id       = var.x_oauth2_client_id
+  x_oauth2_token_secret_id = google_secret_manager_secret.x_oauth2_token.id
+  depends_on               = [time_sleep.wait_for_scheduler_api, google_project_service.pubsub, google_project_service.secretmanager]
 }
\ No newline at end of file
@@ -28,3 +28,15 @@ variable "short_sha" {
   description = "Short SHA for artifact versioning"
   type        = string
 }
+
+variable "x_oauth2_client_id" {
+  description = "X OAuth 2.0 Client ID (空の場合はOAuth 1.0aを使用)"
+  type        = string
+  default     = ""
+}
+
+variable "youtube_client_id" {
+  description = "YouTube Data API の OAuth 2.0 Client ID"
+  type        = string
+  default     = "589350762095-2rpqdftrm5m5s0ibhg6m1kb0f46q058r.apps.googleusercontent.com"
+}
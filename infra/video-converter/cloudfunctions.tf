@@ -15,6 +15,26 @@ resource "google_cloudfunctions2_function" "video_converter" {
     service_account_email = google_service_account.video_converter_sa.email
     environment_variables = {
       GOOGLE_CLOUD_PROJECT = var.project_id
+      X_OAUTH2_CLIENT_ID   = var.x_oauth2_client_id
+      YOUTUBE_CLIENT_ID    = var.youtube_client_id
+    }
+    secret_environment_variables {
+      key        = "SLACK_WEBHOOK_URL"
+      project_id = var.project_id
+      secret     = var.slack_webhook_url_secret_id
+      version    = "latest"
+    }
+    secret_environment_variables {
+      key        = "YOUTUBE_CLIENT_SECRET"
+      project_id = var.project_id
+      secret     = var.youtube_client_secret_secret_id
+      version    = "latest"
+    }
+    secret_environment_variables {
+      key        = "YOUTUBE_REFRESH_TOKEN"
+      project_id = var.project_id
+      secret     = var.youtube_refresh_token_secret_id
+      version    = "latest"
     }
     min_instance_count = 0
     max_instance_count = 1
@@ -36,3 +56,46 @@ resource "google_cloudfunctions2_function_iam_member" "video_converter_invoker"
   role           = "roles/cloudfunctions.invoker"
   member         = "allUsers"
 }
+
+# X OAuth 2.0トークンの読み込みと、リフレッシュ後のトークンの保存・古いバージョンの破棄
+resource "google_secret_manager_secret_iam_member" "x_oauth2_token_accessor" {
+  secret_id = var.x_oauth2_token_secret_id
+  role      = "roles/secretmanager.secretAccessor"
+  member    = "serviceAccount:${google_service_account.video_converter_sa.email}"
+}
+
+resource "google_secret_manager_secret_iam_member" "x_oauth2_token_version_manager" {
+  secret_id = var.x_oauth2_token_secret_id
+  role      = "roles/secretmanager.secretVersionManager"
+  member    = "serviceAccount:${google_service_account.video_converter_sa.email}"
+}
+
+# X OAuth 1.0aの認証情報の読み込み
+resource "google_secret_manager_secret_iam_member" "x_oauth1_credentials_accessor" {
+  secret_id = var.x_oauth1_credentials_secret_id
+  role      = "roles/secretmanager.secretAccessor"
+  member    = "serviceAccount:${google_service_account.video_converter_sa.email}"
+}
+
+# Slack Webhook URLの読み込み
+resource "google_secret_manager_secret_iam_member" "slack_webhook_url_accessor" {
+  project   = var.project_id
+  secret_id = var.slack_webhook_url_secret_id
+  role      = "roles/secretmanager.secretAccessor"
+  member    = "serviceAccount:${google_service_account.video_converter_sa.email}"
+}
+
+# YouTube OAuth 2.0のクライアントシークレットとリフレッシュトークンの読み込み
+resource "google_secret_manager_secret_iam_member" "youtube_client_secret_accessor" {
+  project   = var.project_id
+  secret_id = var.youtube_client_secret_secret_id
+  role      = "roles/secretmanager.secretAccessor"
+  member    = "serviceAccount:${google_service_account.video_converter_sa.email}"
+}
+
+resource "google_secret_manager_secret_iam_member" "youtube_refresh_token_accessor" {
+  project   = var.project_id
+  secret_id = var.youtube_refresh_token_secret_id
+  role      = "roles/secretmanager.secretAccessor"
+  member    = "serviceAccount:${google_service_account.video_converter_sa.email}"
+}
@@ -21,4 +21,40 @@ variable "convert_starter_service_account_email" {
 variable "short_sha" {
   description = "Short SHA for artifact versioning"
   type        = string
-}
\ No newline at end of file
+}
+
+variable "x_oauth2_client_id" {
+  description = "X OAuth 2.0 Client ID (空の場合はOAuth 1.0aを使用)"
+  type        = string
+  default     = ""
+}
+
+variable "x_oauth2_token_secret_id" {
+  description = "X OAuth 2.0トークンを保存するSecret ManagerのシークレットID"
+  type        = string
+}
+
+variable "slack_webhook_url_secret_id" {
+  description = "Slack Webhook URLを保存するSecret ManagerのシークレットID"
+  type        = string
+}
+
+variable "x_oauth1_credentials_secret_id" {
+  description = "X OAuth 1.0aの認証情報を保存するSecret ManagerのシークレットID"
+  type        = string
+}
+
+variable "youtube_client_id" {
+  description = "YouTube Data API の OAuth 2.0 Client ID"
+  type        = string
+}
+
+variable "youtube_client_secret_secret_id" {
+  description = "YouTube OAuth 2.0 のクライアントシークレットを保存するSecret ManagerのシークレットID"
+  type        = string
+}
+
+variable "youtube_refresh_token_secret_id" {
+  description = "YouTube OAuth 2.0 のリフレッシュトークンを保存するSecret ManagerのシークレットID"
+  type        = string
+}
@@ -0,0 +1,82 @@
+package blogpost
+
+import (
+	"fmt"
+	"html"
+	"log/slog"
+	"math/rand/v2"
+	"strings"
+
+	"thiroyoshi.com/video-converter/affiliate"
+	"thiroyoshi.com/video-converter/disclosure"
+)
+
+// Maximum number of affiliate links in a post
+const maxAffiliateLinks = 2
+
+// loadAffiliateCatalog は設定のカタログを読み込みます。読み込めない場合は同梱のカタログを使います。
+func loadAffiliateCatalog(config *Config) *affiliate.Catalog {
+	catalog, err := affiliate.Load(config.AffiliateCatalog)
+	if err == nil {
+		return catalog
+	}
+	slog.Warn("Failed to load affiliate catalog, using the bundled one", "path", config.AffiliateCatalog, "error", err)
+	catalog, err = affiliate.Load("")
+	if err != nil {
+		slog.Error("Failed to load bundled affiliate catalog", "error", err)
+		return &affiliate.Catalog{}
+	}
+	return catalog
+}
+
+// loadDisclosureRules は設定の広告の表示のルールを読み込みます。読み込めない場合は disclosure.DefaultRules を使います。
+func loadDisclosureRules(config *Config) disclosure.Rules {
+	rules, err := disclosure.LoadRules(config.DisclosureRules)
+	if err != nil {
+		slog.Warn("Failed to load disclosure rules, using the default ones", "path", config.DisclosureRules, "error", err)
+		return disclosure.DefaultRules
+	}
+	return rules
+}
+
+// placeAffiliateLinks はトピックに合う商品のリンクをそのトピックの<section>の直後に入れます。
+// 合う商品のあるトピックがない場合は、カタログ全体から選んだリンクを本文の最後に付けます。
+func placeAffiliateLinks(content string, catalog *affiliate.Catalog, r *rand.Rand) string {
+	used := map[string]bool{}
+	sections, err := parseSections(content)
+	const closeTag = "</section>"
+	if err == nil && len(sections) == strings.Count(content, closeTag) {
+		var b strings.Builder
+		rest := content
+		for _, section := range sections {
+			end := strings.Index(rest, closeTag) + len(closeTag)
+			b.WriteString(rest[:end])
+			rest = rest[end:]
+			if len(used) >= maxAffiliateLinks {
+				continue
+			}
+			products := catalog.Match(section.Heading + "\n" + section.Body)
+			if product, ok := affiliate.Pick(r, products, used); ok {
+				used[product.ID] = true
+				b.WriteString(affiliateLinkHTML(product))
+				slog.Info("Affiliate link placed", "product", product.ID, "topic", section.Heading)
+			}
+		}
+		b.WriteString(rest)
+		content = b.String()
+	}
+	if len(used) > 0 {
+		return content
+	}
+
+	product, ok := affiliate.Pick(r, catalog.Linkable(), nil)
+	if !ok {
+		return content
+	}
+	slog.Info("Affiliate link placed", "product", product.ID)
+	return content + affiliateLinkHTML(product)
+}
+
+func affiliateLinkHTML(product affiliate.Product) string {
+	return fmt.Sprintf("<p><a href=\"%s\">%s</a></p>", html.EscapeString(product.URL), html.EscapeString(product.Title))
+}
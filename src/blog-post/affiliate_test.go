@@ -0,0 +1,64 @@
+package blogpost
+
+import (
+	"math/rand/v2"
+	"strings"
+	"testing"
+
+	"thiroyoshi.com/video-converter/affiliate"
+)
+
+func TestPlaceAffiliateLinks(t *testing.T) {
+	t.Parallel()
+
+	catalog := &affiliate.Catalog{
+		Categories: map[string][]string{"controller": {"コントローラー"}, "audio": {"足音"}, "pc": {"PC版"}},
+		Products: []affiliate.Product{
+			{ID: "pad", Title: "Pad <Fortnite>", URL: "https://example.com/pad", Category: "controller", Weight: 1},
+			{ID: "buds", Title: "Buds", URL: "https://example.com/buds", Category: "audio", Weight: 1},
+			{ID: "pc", Title: "PC", URL: "https://example.com/pc", Category: "pc", Weight: 1},
+		},
+	}
+	section := func(heading, body string) string {
+		return "<section><h2>" + heading + "</h2><p>" + body + "</p></section>"
+	}
+	padLink := `<p><a href="https://example.com/pad">Pad &lt;Fortnite&gt;</a></p>`
+	budsLink := `<p><a href="https://example.com/buds">Buds</a></p>`
+
+	tests := []struct {
+		name    string
+		content string
+		want    string
+	}{
+		{
+			name:    "正常系: トピックの直後に入れる",
+			content: section("新スキン", "登場") + section("コントローラーの新機能", "設定が追加"),
+			want:    section("新スキン", "登場") + section("コントローラーの新機能", "設定が追加") + padLink,
+		},
+		{
+			name:    "正常系: 最大2件",
+			content: section("足音の調整", "") + section("コントローラー", "") + section("PC版の推奨環境", ""),
+			want:    section("足音の調整", "") + budsLink + section("コントローラー", "") + padLink + section("PC版の推奨環境", ""),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			got := placeAffiliateLinks(tt.content, catalog, rand.New(rand.NewPCG(1, 2)))
+			if got != tt.want {
+				t.Errorf("placeAffiliateLinks() =\n%s\nwant\n%s", got, tt.want)
+			}
+		})
+	}
+
+	t.Run("正常系: 合うトピックがなければ最後に1件", func(t *testing.T) {
+		t.Parallel()
+
+		content := section("新スキン", "登場")
+		got := placeAffiliateLinks(content, catalog, rand.New(rand.NewPCG(1, 2)))
+		if !strings.HasPrefix(got, content+"<p><a href=") || strings.Count(got, "<a href=") != 1 {
+			t.Errorf("placeAffiliateLinks() = %s", got)
+		}
+	})
+}
@@ -0,0 +1,194 @@
+package blogpost
+
+import (
+	"fmt"
+	"log/slog"
+	"time"
+
+	"thiroyoshi.com/video-converter/bluesky"
+	"thiroyoshi.com/video-converter/disclosure"
+	"thiroyoshi.com/video-converter/fediverse"
+	"thiroyoshi.com/video-converter/x"
+)
+
+// defaultXTemplates は告知文の既定のテンプレート。
+var defaultXTemplates = []string{
+	"ブログを更新しました！\n{{.Hashtags}}\n\n{{.Title}}\n{{.URL}}",
+	"今日のフォートナイト情報をまとめました！\n\n{{.Title}}\n{{.URL}}\n\n{{.Hashtags}}",
+	"{{.Title}}\n\nフォートナイトの最新ニュースをブログにまとめています！\n{{.URL}}\n\n{{.Hashtags}}",
+}
+
+var defaultXHashtags = []string{"#Fortnite", "#フォートナイト", "#はてなブログ", "#GABA"}
+
+// blueskyDescriptionLength はBlueskyのリンクカードの説明の最大文字数。
+const blueskyDescriptionLength = 100
+
+// disclosureLabel はXの広告の表示を改行付きで返す。
+func disclosureLabel(config *Config) string {
+	if label := loadDisclosureRules(config).Labels[disclosure.MediumX]; label != "" {
+		return label + "\n"
+	}
+	return ""
+}
+
+// composeAnnouncement は投稿日で選んだテンプレートから告知文を作る。
+func composeAnnouncement(config *Config, title, url string, now time.Time) (string, error) {
+	templates, hashtags := defaultXTemplates, defaultXHashtags
+	if len(config.XTemplates) > 0 {
+		templates = config.XTemplates
+	}
+	if len(config.XHashtags) > 0 {
+		hashtags = config.XHashtags
+	}
+	composer, err := x.NewComposer(templates, hashtags)
+	if err != nil {
+		return "", fmt.Errorf("failed to create tweet composer: %v", err)
+	}
+	composer.RotateByDate(now)
+	composer.Reserve(disclosureLabel(config))
+	message, err := composer.Compose(title, url)
+	if err != nil {
+		return "", fmt.Errorf("failed to compose tweet: %v", err)
+	}
+	return message, nil
+}
+
+// announcement はSNSに告知する記事。
+type announcement struct {
+	Config  *Config
+	Title   string
+	URL     string
+	Content string
+	// Message は広告の表示を入れる前の告知文
+	Message string
+	// EyecatchURL はアイキャッチ画像のURL。取得できない場合は空
+	EyecatchURL string
+}
+
+// newAnnouncement は告知文を作り、記事のアイキャッチ画像を取得する。
+func newAnnouncement(title, url, content string, now time.Time) (announcement, error) {
+	a := announcement{Config: loadAnnounceConfig(), Title: title, URL: url, Content: content}
+	message, err := composeAnnouncement(a.Config, title, url, now)
+	if err != nil {
+		return a, err
+	}
+	a.Message = message
+	eyecatchURL, err := getEyecatchURL(url, nil)
+	if err != nil {
+		slog.Warn("Failed to get eyecatch image", "error", err)
+	}
+	a.EyecatchURL = eyecatchURL
+	return a, nil
+}
+
+func loadAnnounceConfig() *Config {
+	config, err := loadConfig()
+	if err != nil {
+		slog.Warn("Failed to load config, using default announcement settings", "error", err)
+		return &Config{}
+	}
+	return config
+}
+
+// announceToX はブログの更新をXに投稿する。
+// 設定でスレッド投稿が有効な場合は、記事のトピックごとの要約を返信としてつなげる。
+func announceToX(a announcement) error {
+	rules := loadDisclosureRules(a.Config)
+	if !a.Config.XThread {
+		message, err := rules.Disclose(disclosure.MediumX, a.Message)
+		if err != nil {
+			return err
+		}
+		_, err = x.PostToXWithImage(message, a.EyecatchURL)
+		return err
+	}
+
+	sections, err := parseSections(a.Content)
+	if err != nil {
+		slog.Warn("Failed to parse sections, posting without thread", "error", err)
+	}
+
+	messages := buildThreadMessages(a.Message, sections, disclosureLabel(a.Config))
+	for i := range messages {
+		if messages[i], err = rules.Disclose(disclosure.MediumX, messages[i]); err != nil {
+			return err
+		}
+	}
+	_, err = x.PostThread(messages, a.EyecatchURL)
+	return err
+}
+
+// announceToBluesky はブログの更新をBlueskyに投稿する。記事のリンクカードにはアイキャッチ画像と最初のトピックの要約を載せる。
+// Blueskyの認証情報が設定されていない場合は bluesky.ErrNotConfigured を返す。
+func announceToBluesky(a announcement) error {
+	client, err := bluesky.NewClientFromEnv()
+	if err != nil {
+		return err
+	}
+
+	message, err := loadDisclosureRules(a.Config).Disclose(disclosure.MediumBluesky, a.Message)
+	if err != nil {
+		return err
+	}
+
+	card := &bluesky.LinkCard{URL: a.URL, Title: a.Title, ImageURL: a.EyecatchURL}
+	if sections, err := parseSections(a.Content); err != nil {
+		slog.Warn("Failed to parse sections for link card", "error", err)
+	} else if len(sections) > 0 {
+		card.Description = truncateRunes(sections[0].Body, blueskyDescriptionLength)
+	}
+
+	_, err = client.Publish(message, card)
+	return err
+}
+
+// announceToFediverse はブログの更新を設定されたMastodonとMisskeyのアカウントに投稿する。アイキャッチ画像があれば添付する。
+// アカウントが設定されていない場合は fediverse.ErrNotConfigured を返す。
+func announceToFediverse(a announcement) error {
+	accounts, err := fediverse.AccountsFromEnv()
+	if err != nil {
+		return err
+	}
+	if len(accounts) == 0 {
+		return fediverse.ErrNotConfigured
+	}
+
+	message, err := loadDisclosureRules(a.Config).Disclose(disclosure.MediumFediverse, a.Message)
+	if err != nil {
+		return err
+	}
+
+	var media *fediverse.Media
+	if a.EyecatchURL != "" {
+		media = &fediverse.Media{URL: a.EyecatchURL, Description: a.Title}
+	}
+
+	return fediverse.PublishAll(accounts, message, media)
+}
+
+// truncateRunes は文字数がmaxを超える場合に切り詰めて「…」を付ける。
+func truncateRunes(s string, max int) string {
+	runes := []rune(s)
+	if len(runes) <= max {
+		return s
+	}
+	return string(runes[:max-1]) + "…"
+}
+
+// buildThreadMessages はスレッドの各ツイートの本文を作る。1件目は告知文で、以降は見出しのあるトピックを1件ずつ並べる。
+// 要約は reserved を付け足しても文字数の上限に収まるように切り詰める。
+func buildThreadMessages(announcement string, sections []Section, reserved string) []string {
+	var topics []Section
+	for _, section := range sections {
+		if section.Heading != "" {
+			topics = append(topics, section)
+		}
+	}
+
+	messages := []string{announcement}
+	for i, section := range topics {
+		header := fmt.Sprintf("%d/%d %s\n\n", i+1, len(topics), section.Heading)
+		messages = append(messages, header+x.Truncate(section.Body, x.MaxWeightedLength-x.WeightedLength(header)-x.WeightedLength(reserved)))
+	}
+	return messages
+}
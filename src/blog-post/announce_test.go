@@ -0,0 +1,61 @@
+package blogpost
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"thiroyoshi.com/video-converter/x"
+)
+
+func TestBuildThreadMessages(t *testing.T) {
+	t.Parallel()
+
+	sections := []Section{
+		{Heading: "新シーズン開幕！", Body: "新しいマップが追加されました！"},
+		{Heading: "", Body: "見出しのないトピックはスキップされる"},
+		{Heading: "コラボスキン登場", Body: generateLongJapaneseText(150)},
+	}
+
+	got := buildThreadMessages("ブログを更新しました！", sections, "#PR\n")
+
+	want := []string{
+		"ブログを更新しました！",
+		"1/2 新シーズン開幕！\n\n新しいマップが追加されました！",
+		"2/2 コラボスキン登場\n\n" + generateLongJapaneseText(126) + "…",
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("buildThreadMessages() = %q, want %q", got, want)
+	}
+	for _, message := range got {
+		if x.WeightedLength("#PR\n"+message) > x.MaxWeightedLength {
+			t.Errorf("message %q is too long: %d", message, x.WeightedLength(message))
+		}
+	}
+}
+
+func TestComposeAnnouncement(t *testing.T) {
+	t.Parallel()
+
+	config := &Config{XTemplates: []string{"A {{.Title}} {{.URL}}", "B {{.Title}} {{.URL}}", "C {{.Title}} {{.URL}}"}}
+	jst := time.FixedZone("JST", 9*60*60)
+	monday := time.Date(2025, 5, 5, 8, 0, 0, 0, jst)
+
+	// Posts on Monday, Wednesday and Friday use every variant, and a retry on the same day uses the same one
+	seen := map[string]bool{}
+	for _, days := range []int{0, 2, 4} {
+		got, err := composeAnnouncement(config, "タイトル", "https://example.com", monday.AddDate(0, 0, days))
+		if err != nil {
+			t.Fatalf("composeAnnouncement() error = %v", err)
+		}
+		seen[got[:1]] = true
+	}
+	if len(seen) != 3 {
+		t.Errorf("variants used on Mon/Wed/Fri = %v, want all 3", seen)
+	}
+	first, _ := composeAnnouncement(config, "タイトル", "https://example.com", monday)
+	retry, _ := composeAnnouncement(config, "タイトル", "https://example.com", monday.Add(10*time.Hour))
+	if first != retry {
+		t.Errorf("composeAnnouncement() on the same day = %q and %q", first, retry)
+	}
+}
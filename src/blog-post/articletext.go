@@ -0,0 +1,84 @@
+package blogpost
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"time"
+	"unicode/utf8"
+
+	"thiroyoshi.com/blog-post/fetcher"
+)
+
+const (
+	// minArticleTextLength is the shortest extracted text in runes summarised without web search
+	minArticleTextLength = 200
+	// maxArticleTextLength is the longest article text in runes put into the summary prompt
+	maxArticleTextLength = 4000
+)
+
+// articleFetcher is replaced in tests
+var articleFetcher = fetcher.New(&http.Client{Timeout: 20 * time.Second})
+
+// Prompt for summarising the extracted article text
+var textSummaryPrompt = `
+	後述する記事本文を使用して、
+	以下の条件に合わせてFortniteに関する情報を要約してください
+
+	[記事タイトル]
+	・%s
+
+	[記事本文]
+	%s
+
+	[条件]
+	・要約には、記事のタイトル、日付（%s）、リンク（%s）を含めること
+	・要約は記事本文の内容を全角400字程度でまとめたものとすること
+	・記事本文に書かれていない情報は追加しないこと
+	・情報は %s から %s の間に公開されたものを使用する
+
+
+	記事本文にFortniteに関する情報がない場合は、その記事の出力をスキップしてください。
+	`
+
+// ArticleText は配信元の記事ページから取り出した要約の元になる情報です。
+type ArticleText struct {
+	// URL は要約で引用する記事の正規のURLです
+	URL         string
+	Text        string
+	PublishedAt time.Time
+}
+
+// fetchArticleText は記事のリンクから配信元のページを取得して本文を返します。
+// 取得できない場合や本文が短すぎる場合は nil を返し、Web検索による要約に切り替えます。
+func fetchArticleText(article Article) *ArticleText {
+	page, err := articleFetcher.Fetch(article.Link)
+	if err != nil {
+		if errors.Is(err, fetcher.ErrDisallowed) {
+			slog.Info("Article page is disallowed by robots.txt", "link", article.Link, "error", err)
+		} else {
+			slog.Warn("Failed to fetch article page", "link", article.Link, "error", err)
+		}
+		return nil
+	}
+	if utf8.RuneCountInString(page.Text) < minArticleTextLength {
+		slog.Warn("Article text is too short to summarise", "url", page.URL, "length", utf8.RuneCountInString(page.Text))
+		return nil
+	}
+
+	text := &ArticleText{URL: page.CanonicalURL, Text: page.Text, PublishedAt: page.PublishedAt}
+	if text.PublishedAt.IsZero() {
+		text.PublishedAt = article.PubDate
+	}
+	if runes := []rune(text.Text); len(runes) > maxArticleTextLength {
+		text.Text = string(runes[:maxArticleTextLength])
+	}
+	slog.Info("Article text extracted", "title", article.Title, "url", text.URL, "length", utf8.RuneCountInString(text.Text))
+	return text
+}
+
+// textSummaryMessage は記事本文を要約するプロンプトを作成します。
+func textSummaryMessage(article Article, text *ArticleText, today, lastweek string) string {
+	return fmt.Sprintf(textSummaryPrompt, article.Title, text.Text, text.PublishedAt.Format("2006-01-02"), text.URL, today, lastweek)
+}
@@ -0,0 +1,72 @@
+package blogpost
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+	"unicode/utf8"
+
+	"thiroyoshi.com/blog-post/fetcher"
+)
+
+func TestFetchArticleText(t *testing.T) {
+	paragraph := "<p>" + strings.Repeat("フォートナイトの新シーズンが開幕し、新しい武器が追加されました。", 10) + "</p>"
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/long":
+			_, _ = fmt.Fprintf(w, `<html><head><link rel="canonical" href="/news/long"></head><body><article>%s</article></body></html>`, strings.Repeat(paragraph, 30))
+		case "/dated":
+			_, _ = fmt.Fprintf(w, `<html><head><meta property="article:published_time" content="2025-05-01T10:00:00Z"></head><body><article>%s</article></body></html>`, paragraph)
+		case "/short":
+			_, _ = fmt.Fprint(w, `<html><body><article><p>短い記事です。本文はこれだけで要約するには短すぎます。</p></article></body></html>`)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	original := articleFetcher
+	articleFetcher = fetcher.New(server.Client())
+	defer func() { articleFetcher = original }()
+
+	pubDate := time.Date(2025, 5, 2, 0, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name          string
+		path          string
+		wantNil       bool
+		wantURL       string
+		wantPublished time.Time
+		wantLength    int
+	}{
+		{name: "正常系: 長い本文は切り詰めて正規のURLを使う", path: "/long", wantURL: server.URL + "/news/long", wantPublished: pubDate, wantLength: maxArticleTextLength},
+		{name: "正常系: ページの公開日時を使う", path: "/dated", wantURL: server.URL + "/dated", wantPublished: time.Date(2025, 5, 1, 10, 0, 0, 0, time.UTC)},
+		{name: "異常系: 本文が短い", path: "/short", wantNil: true},
+		{name: "異常系: ページがない", path: "/missing", wantNil: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			text := fetchArticleText(Article{Title: "新シーズン", Link: server.URL + tt.path, PubDate: pubDate})
+			if tt.wantNil {
+				if text != nil {
+					t.Errorf("fetchArticleText() = %+v, want nil", text)
+				}
+				return
+			}
+			if text == nil {
+				t.Fatal("fetchArticleText() = nil")
+			}
+			if text.URL != tt.wantURL || !text.PublishedAt.Equal(tt.wantPublished) {
+				t.Errorf("fetchArticleText() URL = %q, PublishedAt = %v, want %q, %v", text.URL, text.PublishedAt, tt.wantURL, tt.wantPublished)
+			}
+			if tt.wantLength > 0 && utf8.RuneCountInString(text.Text) != tt.wantLength {
+				t.Errorf("text length = %d, want %d", utf8.RuneCountInString(text.Text), tt.wantLength)
+			}
+			if message := textSummaryMessage(Article{Title: "新シーズン"}, text, "2025年05月02日", "2025年04月29日"); !strings.Contains(message, text.URL) {
+				t.Errorf("textSummaryMessage() does not cite %q", text.URL)
+			}
+		})
+	}
+}
@@ -13,10 +13,28 @@ const (
 )
 
 type Config struct {
-	OpenAIAPIKey string `json:"openai_api_key"`
-	HatenaId     string `json:"hatena_id"`
-	HatenaBlogId string `json:"hatena_blog_id"`
-	HatenaApiKey string `json:"hatena_api_key"`
+	OpenAIAPIKey    string `json:"openai_api_key"`
+	AnthropicAPIKey string `json:"anthropic_api_key"`
+	// LocalLLMAPIKey is sent to the OpenAI compatible local server, if it requires one
+	LocalLLMAPIKey string `json:"local_llm_api_key"`
+	HatenaId       string `json:"hatena_id"`
+	HatenaBlogId   string `json:"hatena_blog_id"`
+	HatenaApiKey   string `json:"hatena_api_key"`
+	// XThread posts the announcement to X as a thread with one reply per topic
+	XThread bool `json:"x_thread"`
+	// XTemplates are text/template variants for the X announcement. Defaults are used when empty.
+	XTemplates []string `json:"x_templates"`
+	XHashtags  []string `json:"x_hashtags"`
+	// XDigestInPrompt adds the weekly X digest to the blog prompt as reference
+	XDigestInPrompt bool `json:"x_digest_in_prompt"`
+	// AffiliateCatalog is the path of the affiliate catalog. The catalog bundled with the affiliate package is used when empty.
+	AffiliateCatalog string `json:"affiliate_catalog"`
+	// DisclosureRules is the path of the advertising disclosure rules. disclosure.DefaultRules is used when empty.
+	DisclosureRules string `json:"disclosure_rules"`
+	// NewsSources is the path of the news search sources. DefaultSources is used when empty.
+	NewsSources string `json:"news_sources"`
+	// LLMStages is the path of the provider and model of each generation stage. DefaultStages is used when empty.
+	LLMStages string `json:"llm_stages"`
 }
 
 func loadFromEnv() *Config {
@@ -24,14 +42,23 @@ func loadFromEnv() *Config {
 	// If the environment variable value starts with "sm://", it is treated as an automatically retrieved value from Secret Manager
 	// HatenaId and HatenaBlogId are defined as fixed values
 	config := &Config{
-		OpenAIAPIKey: os.Getenv("OPENAI_API_KEY"),
-		HatenaId:     hatenaId,
-		HatenaBlogId: hatenaBlogId,
-		HatenaApiKey: os.Getenv("HATENA_API_KEY"),
+		OpenAIAPIKey:     os.Getenv("OPENAI_API_KEY"),
+		AnthropicAPIKey:  os.Getenv("ANTHROPIC_API_KEY"),
+		LocalLLMAPIKey:   os.Getenv("LOCAL_LLM_API_KEY"),
+		HatenaId:         hatenaId,
+		HatenaBlogId:     hatenaBlogId,
+		HatenaApiKey:     os.Getenv("HATENA_API_KEY"),
+		XThread:          os.Getenv("X_THREAD") == "true",
+		XDigestInPrompt:  os.Getenv("X_DIGEST_IN_PROMPT") == "true",
+		AffiliateCatalog: os.Getenv("AFFILIATE_CATALOG"),
+		DisclosureRules:  os.Getenv("DISCLOSURE_RULES"),
+		NewsSources:      os.Getenv("NEWS_SOURCES"),
+		LLMStages:        os.Getenv("LLM_STAGES"),
 	}
 
 	// Verify that required configuration values are specified
-	if config.OpenAIAPIKey != "" && config.HatenaApiKey != "" {
+	// Any provider is enough, the stages check their own key when the generator is created
+	if (config.OpenAIAPIKey != "" || config.AnthropicAPIKey != "" || config.LLMStages != "") && config.HatenaApiKey != "" {
 		return config
 	}
 	return nil
@@ -2,17 +2,17 @@ package blogpost
 
 import (
 	"context"
-	"crypto/rand"
-	"encoding/json"
+	"errors"
 	"fmt"
 	"log/slog"
+	"math/rand/v2"
 	"strings"
 	"time"
 	"unicode/utf8"
 
-	openai "github.com/openai/openai-go"
-	option "github.com/openai/openai-go/option"
-	param "github.com/openai/openai-go/packages/param"
+	"thiroyoshi.com/blog-post/llm"
+	"thiroyoshi.com/video-converter/affiliate"
+	"thiroyoshi.com/video-converter/disclosure"
 )
 
 // Prompt for generating initial blog post draft
@@ -102,15 +102,16 @@ type ContentJson struct {
 	Content string `json:"content"`
 }
 
-func getSummaries(articles []Article, limit int, now time.Time) (string, error) {
+// getSummaries は記事を要約し、要約と、記事本文で引用してよい情報源を返します。
+func getSummaries(articles []Article, limit int, now time.Time) (string, []groundingSource, error) {
 	config, err := loadConfig()
 	if err != nil {
 		slog.Error("Failed to load config", "error", err)
-		return "", fmt.Errorf("failed to load config: %v", err)
+		return "", nil, fmt.Errorf("failed to load config: %v", err)
 	}
 
 	today := now.Format("2006年01月02日")
-	lastweek := now.AddDate(0, 0, -3).Format("2006年01月02日")
+	lastweek := now.AddDate(0, 0, -postWindowDays).Format("2006年01月02日")
 
 	systemRole := `
 	あなたはFortnite専門のプロブロガーです。
@@ -134,49 +135,65 @@ func getSummaries(articles []Article, limit int, now time.Time) (string, error)
 	記事にデータがない場合は、その記事の出力をスキップしてください。
 	`
 
-	client := openai.NewClient(
-		option.WithAPIKey(config.OpenAIAPIKey),
-	)
+	searcher, err := newStageGenerator(config, stageSearch)
+	if err != nil {
+		return "", nil, err
+	}
+	summarizer, err := newStageGenerator(config, stageSummary)
+	if err != nil {
+		return "", nil, err
+	}
 
 	var summaries []string
+	var sources []groundingSource
 	for _, article := range articles {
 		if len(summaries) >= limit {
 			break
 		}
 
-		chatCompletion, err := client.Chat.Completions.New(context.TODO(), openai.ChatCompletionNewParams{
-			Messages: []openai.ChatCompletionMessageParamUnion{
-				openai.SystemMessage(fmt.Sprintf(systemRole, today, lastweek)),
-				openai.UserMessage(fmt.Sprintf(prompt1, article.Title, article.PubDate, article.Link, today, lastweek)),
-			},
-			Model: openai.ChatModelGPT4oSearchPreview2025_03_11,
-			WebSearchOptions: openai.ChatCompletionNewParamsWebSearchOptions{
-				SearchContextSize: "medium",
-				UserLocation: openai.ChatCompletionNewParamsWebSearchOptionsUserLocation{
-					Approximate: openai.ChatCompletionNewParamsWebSearchOptionsUserLocationApproximate{
-						Timezone: param.Opt[string]{Value: "Asia/Tokyo"},
-					},
-				},
-			},
-		})
+		// Summarise the publisher's article text when it can be fetched, otherwise let the model search the web
+		link := article.Link
+		pubDate := article.PubDate
+		generator := searcher
+		req := llm.Request{
+			System:    fmt.Sprintf(systemRole, today, lastweek),
+			Prompt:    fmt.Sprintf(prompt1, article.Title, article.PubDate, article.Link, today, lastweek),
+			WebSearch: true,
+		}
+		if text := fetchArticleText(article); text != nil {
+			link = text.URL
+			pubDate = text.PublishedAt
+			generator = summarizer
+			req = llm.Request{
+				System: fmt.Sprintf(systemRole, today, lastweek),
+				Prompt: textSummaryMessage(article, text, today, lastweek),
+			}
+		}
+
+		resp, err := generator.Generate(context.TODO(), req)
+		if errors.Is(err, llm.ErrWebSearchUnsupported) {
+			slog.Warn("Skipping article that needs web search, which the search stage provider does not support", "title", article.Title)
+			continue
+		}
 		if err != nil {
-			return "", fmt.Errorf("failed to generate article summary: %w", err)
+			return "", nil, fmt.Errorf("failed to generate article summary: %w", err)
 		}
 
-		resp := chatCompletion.Choices[0].Message.Content
 		slog.Info("Article summary generated",
 			"title", article.Title,
-			"link", article.Link,
+			"link", link,
 			"response", resp,
 			"response_length", len(resp))
 
-		summaries = append(summaries, fmt.Sprintf("%s: %s, %s", article.Title, article.Link, resp))
+		summaries = append(summaries, fmt.Sprintf("%s: %s, %s", article.Title, link, resp))
+		sources = append(sources, groundingSource{Title: article.Title, Links: []string{article.Link, link}, PubDate: pubDate})
 	}
 
-	return strings.Join(summaries, "\n"), nil
+	return strings.Join(summaries, "\n"), sources, nil
 }
 
-func generatePostByArticles(articles string, now time.Time) (string, string, error) {
+// generatePostByArticles は要約から記事を生成します。記事本文のリンクと公開日は sources と照合し、結果を record に残します。
+func generatePostByArticles(articles string, sources []groundingSource, now time.Time, record *runRecord) (string, string, error) {
 	config, err := loadConfig()
 	if err != nil {
 		slog.Error("Failed to load config", "error", err)
@@ -184,83 +201,76 @@ func generatePostByArticles(articles string, now time.Time) (string, string, err
 	}
 
 	// == first phase : initial creation ==
-	client := openai.NewClient(
-		option.WithAPIKey(config.OpenAIAPIKey),
-	)
+	drafter, err := newStageGenerator(config, stageDraft)
+	if err != nil {
+		return "", "", err
+	}
+	refiner, err := newStageGenerator(config, stageRefine)
+	if err != nil {
+		return "", "", err
+	}
 
 	var resultContent ContentJson
 	var title string
 
 	maxRetries := 5
 	minContentLength := 1000
-	threeDaysBefore := now.AddDate(0, 0, -3)
+	threeDaysBefore := now.AddDate(0, 0, -postWindowDays)
+	ground := newGrounding(sources, now)
+	regenerate := func(section string, problems []string) (string, error) {
+		return refiner.Generate(context.TODO(), llm.Request{Prompt: regroundMessage(section, problems, ground)})
+	}
 
 	// Generate blog post with retry logic for short content
 	for i := 0; i < maxRetries; i++ {
-		chatCompletion, err := client.Chat.Completions.New(context.TODO(), openai.ChatCompletionNewParams{
-			Messages: []openai.ChatCompletionMessageParamUnion{
-				openai.UserMessage(fmt.Sprintf(prompt2, now.Format("2006-01-02"), threeDaysBefore.Format("2006-01-02"), articles)),
-			},
-			Model: openai.ChatModelO3Mini,
-		})
-		if err != nil {
-			return "", "", fmt.Errorf("failed to request OpenAI API for initial version: %w", err)
+		record.Attempts = i + 1
+		initialContent, err := generateContent(drafter, llm.Request{
+			Prompt: fmt.Sprintf(prompt2, articles, threeDaysBefore.Format("2006-01-02"), now.Format("2006-01-02")),
+		}, stageDraft)
+		if retryable(err, i, maxRetries) {
+			continue
 		}
-
-		resp := chatCompletion.Choices[0].Message.Content
-		resp = strings.TrimPrefix(resp, "```json\n")
-		resp = strings.ReplaceAll(resp, "`", "")
-
-		var initialContent ContentJson
-		err = json.Unmarshal([]byte(resp), &initialContent)
 		if err != nil {
-			slog.Error("Failed to parse initial response JSON", "response", resp, "error", err)
-			return "", "", fmt.Errorf("failed to parse initial response JSON: %w", err)
+			return "", "", fmt.Errorf("failed to generate initial version: %w", err)
 		}
 
 		slog.Info("Initial content generated", "length", len(initialContent.Content))
 
 		// == second phase : revision of draft ==
-		chatCompletion, err = client.Chat.Completions.New(context.TODO(), openai.ChatCompletionNewParams{
-			Messages: []openai.ChatCompletionMessageParamUnion{
-				openai.UserMessage(fmt.Sprintf(prompt3, initialContent.Content)),
-			},
-			Model: openai.ChatModelO3Mini,
-		})
-		if err != nil {
-			return "", "", fmt.Errorf("failed to request OpenAI API for refined version: %w", err)
+		resultContent, err = generateContent(refiner, llm.Request{
+			Prompt: fmt.Sprintf(prompt3, initialContent.Content),
+		}, stageRefine)
+		if retryable(err, i, maxRetries) {
+			continue
 		}
-		resp = chatCompletion.Choices[0].Message.Content
-		slog.Info("Raw refined response", "raw_response", resp, "length", len(resp))
-
-		// More careful JSON extraction processing
-		respCleaned := resp
-		// Remove only if it starts with ```json and ends with ```
-		if strings.HasPrefix(respCleaned, "```json\n") {
-			respCleaned = strings.TrimPrefix(respCleaned, "```json\n")
-			if strings.HasSuffix(respCleaned, "\n```") {
-				respCleaned = strings.TrimSuffix(respCleaned, "\n```")
-			} else if strings.HasSuffix(respCleaned, "```") {
-				respCleaned = strings.TrimSuffix(respCleaned, "```")
-			}
+		if err != nil {
+			return "", "", fmt.Errorf("failed to generate refined version: %w", err)
 		}
 
-		// Find JSON start position
-		jsonStart := strings.Index(respCleaned, "{")
-		jsonEnd := strings.LastIndex(respCleaned, "}")
+		slog.Info("Refined content generated", "length", len(resultContent.Content))
 
-		if jsonStart != -1 && jsonEnd != -1 && jsonEnd > jsonStart {
-			respCleaned = respCleaned[jsonStart : jsonEnd+1]
+		// Enforce the allowed HTML and the section structure before the length check and posting
+		sanitized, report, err := sanitizeContent(resultContent.Content)
+		report.log()
+		if retryable(err, i, maxRetries) {
+			continue
 		}
-
-		slog.Info("Processed refined response", "processed_response", respCleaned, "length", len(respCleaned))
-		err = json.Unmarshal([]byte(respCleaned), &resultContent)
 		if err != nil {
-			slog.Error("Failed to parse refined response JSON", "response", resp, "error", err)
-			return "", "", fmt.Errorf("failed to parse result response JSON: %w", err)
+			return "", "", fmt.Errorf("failed to sanitize generated content: %w", err)
 		}
-
-		slog.Info("Refined content generated", "length", len(resultContent.Content))
+		resultContent.Content = sanitized
+
+		// Every link and date must come from the summarised articles
+		var grounded groundingReport
+		sanitized, err = groundContent(resultContent.Content, ground, regenerate, &grounded)
+		record.Grounding = grounded
+		if retryable(err, i, maxRetries) {
+			continue
+		}
+		if err != nil {
+			return "", "", fmt.Errorf("failed to ground generated content: %w", err)
+		}
+		resultContent.Content = sanitized
 
 		// Check if the content is long enough
 		if utf8.RuneCountInString(resultContent.Content) >= minContentLength {
@@ -286,48 +296,27 @@ func generatePostByArticles(articles string, now time.Time) (string, string, err
 	pubDate := now.Format("2006/01/02")
 	title = fmt.Sprintf("【%s】%s", pubDate, resultContent.Title)
 
-	return title, addContentFormat(resultContent.Content), nil
+	catalog := loadAffiliateCatalog(config)
+	r := rand.New(rand.NewPCG(uint64(now.UnixNano()), 0))
+	content, err := loadDisclosureRules(config).Disclose(disclosure.MediumHatena, addContentFormat(resultContent.Content, catalog, r))
+	if err != nil {
+		return "", "", err
+	}
+	return title, content, nil
 }
 
-func addContentFormat(content string) string {
+// addContentFormat は記事本文に挨拶・アフィリエイトリンク・注意書きを付けます。
+func addContentFormat(content string, catalog *affiliate.Catalog, r *rand.Rand) string {
 	hello := `
 		<p>どうも。GABAです！</p>
 		<p>今日もFortniteの情報をまとめてみます！</p>
 	`
 
-	// links from "https://www.amazon.co.jp/b/?encoding=UTF8&node=25009176051&ref=cct_cg_CIHAssoc_2c1&pf_rd_p=1c92d06f-632f-4dc2-8657-e9f22172f4e7&pf_rd_r=PRFGAWFG1RA6R3ZE5C9H"
-	links := []string{
-		"<p><a href=\"https://amzn.to/4251ZYM\">【純正品】DualSense ワイヤレスコントローラー \"フォートナイト\" リミテッドエディション（CFI-ZCT1JZ4）</a></p>",
-		"<p><a href=\"https://amzn.to/43IeMDf\"> ガレリア ゲーミングPC GALLERIA RM5R-R46 RTX 4060 Ryzen 5 4500 メモリ32GB SSD1TB Windows11</a></p>",
-		"<p><a href=\"https://amzn.to/44z6bmP\">【任天堂公式ライセンス商品】PowerA エンハンスド・ワイヤレスコントローラー for Nintendo Switch 【国内正規品2年保証】</a></p>",
-		"<p><a href=\"https://amzn.to/44Wiw4l\">【任天堂公式ライセンス商品】PowerA 有線イヤホン 1.3ｍ for Nintendo Switch - フォートナイト ピーリー【国内正規品２年保証】【購入特典】アイテム用コード「複雑なんだ」（エモート）付</a></p>",
-		"<p><a href=\"https://amzn.to/44z6bmP\">Elgato Stream Deck MK.2 【並行輸入品】</a></p>",
-		"<p><a href=\"https://amzn.to/44z6bmP\">長尾製作所 マウス/ゲーミングマウスを美しく飾れる専用ディスプレイ台 NB-MOUSE-DP03</a></p>",
-		"<p><a href=\"https://amzn.to/44z6bmP\">エレコム ウェットティッシュクリーナー 日本製 液晶用 80枚入り 液晶画面にやさしいノンアルコールタイプ WC-DP80N4</a></p>",
-		"<p><a href=\"https://amzn.to/44z6bmP\">Anker Magnetic Cable Holder マグネット式 ケーブルホルダー ライトニングケーブル USB-C Micro USB ケーブル 他対応 デスク周り 便利グッズ (ブルー)</a></p>",
-		"<p><a href=\"https://amzn.to/44z6bmP\">BenQ ScreenBar Halo モニターライト スクリーンバー ハロー USBライト デスクライト [無線リモコン 自動調光 間接照明モード 高演色性]</a></p>",
-		"<p><a href=\"https://amzn.to/44z6bmP\">FORTNITE ゲーミングマウス Razer レイザー DeathAdder V3 Pro Fortnite Edition ワイヤレス 63g</a></p>",
-		"<p><a href=\"https://amzn.to/44z6bmP\">FORTNITE ゲーミングキーボード Razer レイザー BlackWidow V4 X Fortnite Edition</a></p>",
-		"<p><a href=\"https://amzn.to/44z6bmP\">FORTNITE ゲーミングヘッドセット Razer レイザー Kraken V3 X Fortnite Edition 軽量で長時間プレイでも快適な有線ゲーミングヘッドセット USB 7.1サラウンドサウンド</a></p>",
-		"<p><a href=\"https://amzn.to/44z6bmP\">【Amazon.co.jp限定】 Logicool G PRO ゲーミングキーボード G-PKB-002LNd テンキーレス リニア 赤軸 静かなタイピング</a></p>",
-		"<p><a href=\"https://amzn.to/44z6bmP\">ソニー ゲーミングイヤホン INZONE Buds:WF-G700N Fnatic監修</a></p>",
-		"<p><a href=\"https://amzn.to/44z6bmP\">Anker Soundcore P40i (Bluetooth 5.3) 【完全ワイヤレスイヤホン/ウルトラノイズキャンセリング 2.0</a></p>",
-		"<p><a href=\"https://amzn.to/44z6bmP\">SteelSeries ワイヤレス ゲーミングヘッドセット ヘッドホン 軽量 ボイスチャット可能 ゲームとスマホを同時接続</a></p>",
-		"<p><a href=\"https://amzn.to/44z6bmP\">Pulsar Gaming Gears PCMK 2HE TKL ゲーミング キーボード JIS 日本語配列 91キー 磁気スイッチ 8K Polling Rate【国内正規品】</a></p>",
-	}
-
 	disclaimer := `
 	<p>※この記事は本日時点の最新情報に基づいて作成しています。過去に紹介した内容と重複していることがあります。</p>
 	<p>[blog:g:26006613551861511:banner] [blog:g:11696248318757265981:banner]</p>
 	<iframe src="https://blog.hatena.ne.jp/GABA_FORTNITE/gaba-fortnite.hatenablog.com/subscribe/iframe" allowtransparency="true" frameborder="0" scrolling="no" width="150" height="28"></iframe>
 	`
 
-	b := make([]byte, 1)
-	if _, err := rand.Read(b); err != nil {
-		// Use the first link if an error occurs
-		return hello + content + links[0] + disclaimer
-	}
-	index := int(b[0]) % len(links)
-
-	return hello + content + links[index] + disclaimer
+	return hello + placeAffiliateLinks(content, catalog, r) + disclaimer
 }
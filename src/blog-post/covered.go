@@ -0,0 +1,88 @@
+package blogpost
+
+import (
+	"log/slog"
+	"os"
+	"time"
+
+	"thiroyoshi.com/blog-post/history"
+	"thiroyoshi.com/blog-post/storage"
+)
+
+const (
+	// coveredThreshold is the similarity from which an article is treated as already covered and dropped
+	coveredThreshold = 0.6
+	// relatedThreshold is the similarity from which an article is moved after the new ones
+	relatedThreshold = 0.35
+	// historyRetention is how long covered topics are kept
+	historyRetention = 30 * 24 * time.Hour
+	// defaultHistoryDir is where the history is saved locally when HISTORY_BUCKET is not set
+	defaultHistoryDir = ".history"
+)
+
+// filterCovered は過去の記事で扱ったトピックと同じ記事を除き、似ている記事を新しい記事の後ろに回します。
+func filterCovered(articles []Article, h *history.History) []Article {
+	var fresh, related []Article
+	for _, article := range articles {
+		entry, score := h.Match(article.Title, article.Link)
+		switch {
+		case score >= coveredThreshold:
+			slog.Info("Skipping article already covered", "title", article.Title, "covered", entry.Title, "covered_at", entry.CoveredAt, "score", score)
+		case score >= relatedThreshold:
+			slog.Info("Down-ranking article similar to a covered topic", "title", article.Title, "covered", entry.Title, "score", score)
+			related = append(related, article)
+		default:
+			fresh = append(fresh, article)
+		}
+	}
+	return append(fresh, related...)
+}
+
+// recordCovered は投稿した記事のトピックを記録に加え、保存期間を過ぎたトピックを削除します。
+// 次の実行ではフィードのリンクで照合するため、トピックの情報源を sources から探してフィードのリンクも記録します。
+func recordCovered(h *history.History, content string, sources []groundingSource, now time.Time) {
+	sections, err := parseSections(content)
+	if err != nil {
+		slog.Warn("Failed to parse sections for article history", "error", err)
+		return
+	}
+	// feedLinks maps the canonical URL of every source link to the link from the feed
+	feedLinks := map[string]string{}
+	for _, source := range sources {
+		for _, link := range source.Links {
+			if link != "" {
+				feedLinks[history.CanonicalURL(link)] = source.Links[0]
+			}
+		}
+	}
+	for _, section := range sections {
+		if section.Heading == "" && section.SourceURL == "" {
+			continue
+		}
+		h.Add(section.SourceTitle, section.Heading, section.SourceURL, feedLinks[history.CanonicalURL(section.SourceURL)], now)
+	}
+	h.Prune(now.Add(-historyRetention))
+}
+
+// loadHistory は記録を読み込みます。読み込めない場合は空の記録で続けます。
+func loadHistory(store *history.Store) *history.History {
+	h, err := store.Load()
+	if err != nil {
+		slog.Warn("Failed to load article history, continuing without it", "error", err)
+		return &history.History{}
+	}
+	return h
+}
+
+// newHistoryStore は記録の保存先を作ります。HISTORY_BUCKET が設定されている場合はそのバケットに、
+// それ以外は HISTORY_DIR（既定は .history）のファイルに保存します。
+func newHistoryStore() *history.Store {
+	if bucket := os.Getenv("HISTORY_BUCKET"); bucket != "" {
+		return history.NewStore(storage.NewBucket(bucket))
+	}
+	dir := os.Getenv("HISTORY_DIR")
+	if dir == "" {
+		dir = defaultHistoryDir
+	}
+	return history.NewStore(storage.NewDir(dir))
+}
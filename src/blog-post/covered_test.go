@@ -0,0 +1,60 @@
+package blogpost
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"thiroyoshi.com/blog-post/history"
+)
+
+func TestFilterCovered(t *testing.T) {
+	t.Parallel()
+
+	now := time.Date(2025, 5, 2, 12, 0, 0, 0, time.UTC)
+	h := &history.History{}
+	recordCovered(h, `
+		<section>
+			<h2>新シーズン開幕！</h2>
+			<p>新しいマップが追加されました！</p>
+			<a href="https://media.example.com/news/1?utm_source=rss">フォートナイト新シーズン開幕、新武器とマップ変更まとめ - ゲームメディア</a>
+		</section>`, []groundingSource{
+		{Title: "フォートナイト新シーズン開幕、新武器とマップ変更まとめ - ゲームメディア", Links: []string{"https://news.google.com/rss/articles/abc", "https://media.example.com/news/1"}},
+	}, now.AddDate(0, 0, -2))
+
+	articles := []Article{
+		{Title: "フォートナイト新シーズン開幕、新武器とマップ変更まとめ - 別メディア", Link: "https://other.example.com/1"},
+		{Title: "新シーズン開幕で変わったマップの注目ポイント", Link: "https://other.example.com/2"},
+		{Title: "人気アニメとのコラボスキンが登場", Link: "https://other.example.com/3"},
+		{Title: "別のタイトル", Link: "https://media.example.com/news/1"},
+		{Title: "フィードの別のタイトル", Link: "https://news.google.com/rss/articles/abc"},
+	}
+	var links []string
+	for _, article := range filterCovered(articles, h) {
+		links = append(links, article.Link)
+	}
+	// 1, 4 and 5 are covered, 2 is similar and moved after 3
+	if got := strings.Join(links, ","); got != "https://other.example.com/3,https://other.example.com/2" {
+		t.Errorf("filterCovered() = %s", got)
+	}
+}
+
+func TestNewHistoryStore(t *testing.T) {
+	dir := t.TempDir()
+	t.Setenv("HISTORY_BUCKET", "")
+	t.Setenv("HISTORY_DIR", dir)
+	// Without a bucket the history must stay local even on Cloud Functions
+	t.Setenv("GOOGLE_CLOUD_PROJECT", "test-project")
+
+	store := newHistoryStore()
+	h := &history.History{}
+	h.Add("title", "heading", "https://example.com/1", "", time.Date(2025, 5, 2, 12, 0, 0, 0, time.UTC))
+	if err := store.Save(h); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, history.Name)); err != nil {
+		t.Errorf("history was not saved to HISTORY_DIR: %v", err)
+	}
+}
@@ -0,0 +1,72 @@
+package blogpost
+
+import (
+	"fmt"
+	"log/slog"
+	"time"
+
+	"thiroyoshi.com/video-converter/notifier"
+	"thiroyoshi.com/video-converter/x"
+)
+
+// Header of the X digest appended to the article summaries given to the blog prompt
+const xDigestPromptHeader = "【参考：今週のGABAのX（話題選びの参考にのみ使い、トピックや情報源にはしない）】"
+
+// collectXDigest は now までの1週間のツイートを集計します。
+func collectXDigest(now time.Time) (*x.Digest, error) {
+	start := now.AddDate(0, 0, -7)
+	tweets, err := x.CollectTweets(start, now)
+	if err != nil {
+		return nil, fmt.Errorf("failed to collect tweets: %w", err)
+	}
+	return x.NewDigest(tweets, start, now), nil
+}
+
+// digestMessage はダイジェストの通知メッセージを作ります。反応が多かったポストをリンクとして載せます。
+func digestMessage(digest *x.Digest) notifier.Message {
+	msg := notifier.Message{
+		Event: notifier.EventDigest,
+		Title: x.DigestTitle,
+		Text:  digest.Text(),
+	}
+	for i, tweet := range digest.Top {
+		msg.Links = append(msg.Links, notifier.Link{Label: fmt.Sprintf("%d位のポスト", i+1), URL: tweet.URL()})
+	}
+	return msg
+}
+
+// RunXDigest は直近1週間のXの活動をまとめて「今週のGABAのX」として通知します。
+func RunXDigest() error {
+	jst, err := time.LoadLocation("Asia/Tokyo")
+	if err != nil {
+		return fmt.Errorf("failed to load JST location: %v", err)
+	}
+
+	digest, err := collectXDigest(time.Now().In(jst))
+	if err != nil {
+		slog.Error("Failed to collect X digest", "error", err)
+		return err
+	}
+	slog.Info("X digest collected", "posts", digest.Posts, "likes", digest.Likes)
+
+	if err := notifier.FromEnv().Notify(digestMessage(digest)); err != nil {
+		return fmt.Errorf("failed to send X digest: %w", err)
+	}
+	return nil
+}
+
+// appendXDigest は設定で有効な場合に、記事の要約の後ろにXのダイジェストを付けます。
+// ダイジェストの取得に失敗しても記事の生成は続けます。
+func appendXDigest(summaries string, now time.Time) string {
+	config, err := loadConfig()
+	if err != nil || !config.XDigestInPrompt {
+		return summaries
+	}
+
+	digest, err := collectXDigest(now)
+	if err != nil {
+		slog.Warn("Failed to collect X digest for the prompt", "error", err)
+		return summaries
+	}
+	return summaries + "\n\n" + xDigestPromptHeader + "\n" + digest.Text()
+}
@@ -0,0 +1,36 @@
+package blogpost
+
+import (
+	"testing"
+	"time"
+
+	"thiroyoshi.com/video-converter/notifier"
+	"thiroyoshi.com/video-converter/x"
+)
+
+func TestDigestMessage(t *testing.T) {
+	t.Parallel()
+
+	start := time.Date(2025, 4, 14, 0, 0, 0, 0, time.UTC)
+	tweets := []x.TimelineTweet{
+		{ID: "1", Text: "動画をアップしました", PublicMetrics: x.PublicMetrics{LikeCount: 3}},
+		{ID: "2", Text: "ブログを更新しました", PublicMetrics: x.PublicMetrics{LikeCount: 10}},
+	}
+	msg := digestMessage(x.NewDigest(tweets, start, start.AddDate(0, 0, 7)))
+
+	if msg.Event != notifier.EventDigest || msg.Title != x.DigestTitle {
+		t.Errorf("Event = %v, Title = %q", msg.Event, msg.Title)
+	}
+	want := []notifier.Link{
+		{Label: "1位のポスト", URL: "https://x.com/i/web/status/2"},
+		{Label: "2位のポスト", URL: "https://x.com/i/web/status/1"},
+	}
+	if len(msg.Links) != len(want) {
+		t.Fatalf("Links = %+v, want %+v", msg.Links, want)
+	}
+	for i := range want {
+		if msg.Links[i] != want[i] {
+			t.Errorf("Links[%d] = %+v, want %+v", i, msg.Links[i], want[i])
+		}
+	}
+}
@@ -0,0 +1,291 @@
+package blogpost
+
+import (
+	"bytes"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"log/slog"
+	"strings"
+	"time"
+
+	"golang.org/x/net/html"
+	"golang.org/x/net/html/charset"
+)
+
+// dateLayouts are the date formats seen in feeds, tried in order
+var dateLayouts = []string{
+	time.RFC1123,
+	time.RFC1123Z,
+	time.RFC3339,
+	"Mon, 2 Jan 2006 15:04:05 MST",
+	"Mon, 2 Jan 2006 15:04:05 -0700",
+	"Mon, 2 Jan 2006 15:04 MST",
+	"Mon, 2 Jan 2006 15:04 -0700",
+	"2 Jan 2006 15:04:05 MST",
+	"2 Jan 2006 15:04:05 -0700",
+	"2006-01-02T15:04Z07:00",
+	"2006-01-02T15:04:05",
+	"2006-01-02 15:04:05",
+	"2006-01-02",
+}
+
+// parseDate はフィードの日付を解釈します。曜日や秒のない形式、RFC3339も受け付けます。
+func parseDate(s string) (time.Time, error) {
+	s = strings.TrimSpace(s)
+	for _, layout := range dateLayouts {
+		if t, err := time.Parse(layout, s); err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("unknown date format: %q", s)
+}
+
+// plainText はHTMLのタグを除いたテキストを返します。
+func plainText(s string) string {
+	if !strings.Contains(s, "<") {
+		return strings.TrimSpace(html.UnescapeString(s))
+	}
+	var b strings.Builder
+	z := html.NewTokenizer(strings.NewReader(s))
+	for {
+		switch z.Next() {
+		case html.ErrorToken:
+			return strings.Join(strings.Fields(b.String()), " ")
+		case html.TextToken:
+			b.Write(z.Text())
+			b.WriteByte(' ')
+		}
+	}
+}
+
+// feedItem は形式ごとの項目を Article にする前の共通の形です。
+type feedItem struct {
+	title, link, date, description, source string
+	categories                             []string
+}
+
+// RSS 2.0
+type rss2Feed struct {
+	Channel struct {
+		Title string     `xml:"title"`
+		Items []rss2Item `xml:"item"`
+	} `xml:"channel"`
+}
+
+type rss2Item struct {
+	Title       string   `xml:"title"`
+	Link        string   `xml:"link"`
+	PubDate     string   `xml:"pubDate"`
+	DCDate      string   `xml:"http://purl.org/dc/elements/1.1/ date"`
+	Description string   `xml:"description"`
+	Source      string   `xml:"source"`
+	Categories  []string `xml:"category"`
+}
+
+// RSS 1.0 (RDF)
+type rdfFeed struct {
+	Channel struct {
+		Title string `xml:"title"`
+	} `xml:"channel"`
+	Items []struct {
+		Title       string   `xml:"title"`
+		Link        string   `xml:"link"`
+		Date        string   `xml:"http://purl.org/dc/elements/1.1/ date"`
+		Description string   `xml:"description"`
+		Subjects    []string `xml:"http://purl.org/dc/elements/1.1/ subject"`
+	} `xml:"item"`
+}
+
+// Atom
+type atomFeed struct {
+	Title   string `xml:"title"`
+	Entries []struct {
+		Title string `xml:"title"`
+		Links []struct {
+			Href string `xml:"href,attr"`
+			Rel  string `xml:"rel,attr"`
+		} `xml:"link"`
+		Published  string `xml:"published"`
+		Updated    string `xml:"updated"`
+		Summary    string `xml:"summary"`
+		Content    string `xml:"content"`
+		Categories []struct {
+			Term  string `xml:"term,attr"`
+			Label string `xml:"label,attr"`
+		} `xml:"category"`
+		Source struct {
+			Title string `xml:"title"`
+		} `xml:"source"`
+	} `xml:"entry"`
+}
+
+// JSON Feed
+type jsonFeed struct {
+	Title string `json:"title"`
+	Items []struct {
+		ID            string   `json:"id"`
+		URL           string   `json:"url"`
+		Title         string   `json:"title"`
+		ContentText   string   `json:"content_text"`
+		ContentHTML   string   `json:"content_html"`
+		Summary       string   `json:"summary"`
+		DatePublished string   `json:"date_published"`
+		DateModified  string   `json:"date_modified"`
+		Tags          []string `json:"tags"`
+	} `json:"items"`
+}
+
+// parseFeed は RSS 2.0、RSS 1.0（RDF）、Atom、JSON Feed のフィードを解釈して記事を返します。
+// 日付が解釈できない項目は警告を出して除きます。
+func parseFeed(r io.Reader) ([]Article, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read feed: %v", err)
+	}
+
+	var items []feedItem
+	if trimmed := bytes.TrimSpace(data); len(trimmed) > 0 && trimmed[0] == '{' {
+		items, err = parseJSONFeed(trimmed)
+	} else {
+		items, err = parseXMLFeed(data)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	articles := make([]Article, 0, len(items))
+	for _, item := range items {
+		pubDate, err := parseDate(item.date)
+		if err != nil {
+			slog.Warn("Skipping feed item without a valid date", "title", item.title, "error", err)
+			continue
+		}
+		articles = append(articles, Article{
+			Title:       plainText(item.title),
+			Link:        strings.TrimSpace(item.link),
+			PubDate:     pubDate,
+			Description: plainText(item.description),
+			Source:      strings.TrimSpace(item.source),
+			Categories:  item.categories,
+		})
+	}
+	return articles, nil
+}
+
+func parseJSONFeed(data []byte) ([]feedItem, error) {
+	var feed jsonFeed
+	if err := json.Unmarshal(data, &feed); err != nil {
+		return nil, fmt.Errorf("failed to parse JSON feed: %v", err)
+	}
+	items := make([]feedItem, 0, len(feed.Items))
+	for _, it := range feed.Items {
+		link := it.URL
+		if link == "" {
+			link = it.ID
+		}
+		date := it.DatePublished
+		if date == "" {
+			date = it.DateModified
+		}
+		description := it.Summary
+		for _, content := range []string{it.ContentText, it.ContentHTML} {
+			if description == "" {
+				description = content
+			}
+		}
+		items = append(items, feedItem{title: it.Title, link: link, date: date, description: description, source: feed.Title, categories: it.Tags})
+	}
+	return items, nil
+}
+
+func parseXMLFeed(data []byte) ([]feedItem, error) {
+	root, err := rootElement(data)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse XML: %v", err)
+	}
+
+	var items []feedItem
+	switch root {
+	case "rss":
+		var feed rss2Feed
+		if err := newXMLDecoder(data).Decode(&feed); err != nil {
+			return nil, fmt.Errorf("failed to parse XML: %v", err)
+		}
+		for _, it := range feed.Channel.Items {
+			date := it.PubDate
+			if date == "" {
+				date = it.DCDate
+			}
+			source := it.Source
+			if source == "" {
+				source = feed.Channel.Title
+			}
+			items = append(items, feedItem{title: it.Title, link: it.Link, date: date, description: it.Description, source: source, categories: it.Categories})
+		}
+	case "RDF":
+		var feed rdfFeed
+		if err := newXMLDecoder(data).Decode(&feed); err != nil {
+			return nil, fmt.Errorf("failed to parse XML: %v", err)
+		}
+		for _, it := range feed.Items {
+			items = append(items, feedItem{title: it.Title, link: it.Link, date: it.Date, description: it.Description, source: feed.Channel.Title, categories: it.Subjects})
+		}
+	case "feed":
+		var feed atomFeed
+		if err := newXMLDecoder(data).Decode(&feed); err != nil {
+			return nil, fmt.Errorf("failed to parse XML: %v", err)
+		}
+		for _, entry := range feed.Entries {
+			item := feedItem{title: entry.Title, date: entry.Published, description: entry.Summary, source: entry.Source.Title}
+			if item.date == "" {
+				item.date = entry.Updated
+			}
+			if item.description == "" {
+				item.description = entry.Content
+			}
+			if item.source == "" {
+				item.source = feed.Title
+			}
+			for _, link := range entry.Links {
+				if link.Rel == "" || link.Rel == "alternate" {
+					item.link = link.Href
+					break
+				}
+			}
+			for _, category := range entry.Categories {
+				if category.Label != "" {
+					item.categories = append(item.categories, category.Label)
+				} else {
+					item.categories = append(item.categories, category.Term)
+				}
+			}
+			items = append(items, item)
+		}
+	default:
+		return nil, fmt.Errorf("unknown feed format: <%s>", root)
+	}
+	return items, nil
+}
+
+// newXMLDecoder はXML宣言のencodingに従ってUTF-8以外（Shift_JIS、EUC-JPなど）も読み込むデコーダーを作ります。
+func newXMLDecoder(data []byte) *xml.Decoder {
+	decoder := xml.NewDecoder(bytes.NewReader(data))
+	decoder.CharsetReader = charset.NewReaderLabel
+	return decoder
+}
+
+// rootElement はXMLのルート要素のローカル名を返します。
+func rootElement(data []byte) (string, error) {
+	decoder := newXMLDecoder(data)
+	for {
+		token, err := decoder.Token()
+		if err != nil {
+			return "", err
+		}
+		if start, ok := token.(xml.StartElement); ok {
+			return start.Name.Local, nil
+		}
+	}
+}
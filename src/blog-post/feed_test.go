@@ -0,0 +1,191 @@
+package blogpost
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"golang.org/x/text/encoding"
+	"golang.org/x/text/encoding/japanese"
+)
+
+func TestParseFeed(t *testing.T) {
+	t.Parallel()
+
+	date := time.Date(2024, 4, 1, 10, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name    string
+		feed    string
+		want    Article
+		wantErr bool
+	}{
+		{
+			name: "正常系: RSS 2.0",
+			feed: `<?xml version="1.0" encoding="UTF-8"?>
+				<rss version="2.0"><channel><title>Google News</title>
+					<item>
+						<title>新シーズン開幕 - ゲームメディア</title>
+						<link>https://example.com/1</link>
+						<pubDate>Mon, 01 Apr 2024 10:00:00 GMT</pubDate>
+						<description>&lt;a href="https://example.com/1"&gt;新シーズン開幕&lt;/a&gt;&amp;nbsp;ゲームメディア</description>
+						<source url="https://media.example.com">ゲームメディア</source>
+						<category>ニュース</category><category>Fortnite</category>
+					</item>
+				</channel></rss>`,
+			want: Article{
+				Title: "新シーズン開幕 - ゲームメディア", Link: "https://example.com/1", PubDate: date,
+				Description: "新シーズン開幕 ゲームメディア", Source: "ゲームメディア", Categories: []string{"ニュース", "Fortnite"},
+			},
+		},
+		{
+			name: "正常系: RSS 1.0",
+			feed: `<?xml version="1.0"?>
+				<rdf:RDF xmlns:rdf="http://www.w3.org/1999/02/22-rdf-syntax-ns#" xmlns="http://purl.org/rss/1.0/" xmlns:dc="http://purl.org/dc/elements/1.1/">
+					<channel rdf:about="https://news.example.jp/"><title>ゲームニュース</title></channel>
+					<item rdf:about="https://news.example.jp/2">
+						<title>コラボ決定</title>
+						<link>https://news.example.jp/2</link>
+						<description>人気作品とのコラボ</description>
+						<dc:date>2024-04-01T19:00:00+09:00</dc:date>
+						<dc:subject>コラボ</dc:subject>
+					</item>
+				</rdf:RDF>`,
+			want: Article{
+				Title: "コラボ決定", Link: "https://news.example.jp/2", PubDate: date,
+				Description: "人気作品とのコラボ", Source: "ゲームニュース", Categories: []string{"コラボ"},
+			},
+		},
+		{
+			name: "正常系: Atom",
+			feed: `<?xml version="1.0" encoding="utf-8"?>
+				<feed xmlns="http://www.w3.org/2005/Atom">
+					<title>Epic Games News</title>
+					<entry>
+						<title type="html">v29.10 &lt;b&gt;Patch Notes&lt;/b&gt;</title>
+						<link rel="alternate" href="https://www.fortnite.com/news/patch-notes"/>
+						<link rel="enclosure" href="https://cdn.example.com/image.jpg"/>
+						<updated>2024-04-01T10:00Z</updated>
+						<summary>Bug fixes and new items.</summary>
+						<category term="patch-notes" label="Patch Notes"/>
+					</entry>
+				</feed>`,
+			want: Article{
+				Title: "v29.10 Patch Notes", Link: "https://www.fortnite.com/news/patch-notes", PubDate: date,
+				Description: "Bug fixes and new items.", Source: "Epic Games News", Categories: []string{"Patch Notes"},
+			},
+		},
+		{
+			name: "正常系: JSON Feed",
+			feed: `{
+				"version": "https://jsonfeed.org/version/1.1",
+				"title": "Game Site",
+				"items": [{"id": "https://game.example.com/3", "title": "FNCS Finals", "content_html": "<p>Results</p>",
+					"date_published": "2024-04-01T10:00:00Z", "tags": ["FNCS"]}]
+			}`,
+			want: Article{
+				Title: "FNCS Finals", Link: "https://game.example.com/3", PubDate: date,
+				Description: "Results", Source: "Game Site", Categories: []string{"FNCS"},
+			},
+		},
+		{
+			name: "正常系: Shift_JISのRSS 2.0",
+			feed: encodeFeed(t, japanese.ShiftJIS, `<?xml version="1.0" encoding="Shift_JIS"?>
+				<rss version="2.0"><channel><title>ゲームニュース</title>
+					<item>
+						<title>新シーズン開幕</title>
+						<link>https://news.example.jp/1</link>
+						<pubDate>Mon, 01 Apr 2024 10:00:00 GMT</pubDate>
+						<description>新しいマップが追加</description>
+					</item>
+				</channel></rss>`),
+			want: Article{
+				Title: "新シーズン開幕", Link: "https://news.example.jp/1", PubDate: date,
+				Description: "新しいマップが追加", Source: "ゲームニュース",
+			},
+		},
+		{
+			name: "正常系: EUC-JPのRSS 1.0",
+			feed: encodeFeed(t, japanese.EUCJP, `<?xml version="1.0" encoding="EUC-JP"?>
+				<rdf:RDF xmlns:rdf="http://www.w3.org/1999/02/22-rdf-syntax-ns#" xmlns="http://purl.org/rss/1.0/" xmlns:dc="http://purl.org/dc/elements/1.1/">
+					<channel rdf:about="https://news.example.jp/"><title>ゲームニュース</title></channel>
+					<item rdf:about="https://news.example.jp/2">
+						<title>コラボ決定</title>
+						<link>https://news.example.jp/2</link>
+						<description>人気作品とのコラボ</description>
+						<dc:date>2024-04-01T19:00:00+09:00</dc:date>
+					</item>
+				</rdf:RDF>`),
+			want: Article{
+				Title: "コラボ決定", Link: "https://news.example.jp/2", PubDate: date,
+				Description: "人気作品とのコラボ", Source: "ゲームニュース",
+			},
+		},
+		{name: "異常系: 未知の形式", feed: `<html><body></body></html>`, wantErr: true},
+		{name: "異常系: 不正なJSON", feed: `{"items": [`, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			got, err := parseFeed(strings.NewReader(tt.feed))
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("parseFeed() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			if len(got) != 1 {
+				t.Fatalf("parseFeed() returned %d articles", len(got))
+			}
+			a := got[0]
+			if a.Title != tt.want.Title || a.Link != tt.want.Link || !a.PubDate.Equal(tt.want.PubDate) ||
+				a.Description != tt.want.Description || a.Source != tt.want.Source ||
+				strings.Join(a.Categories, ",") != strings.Join(tt.want.Categories, ",") {
+				t.Errorf("parseFeed() = %+v, want %+v", a, tt.want)
+			}
+		})
+	}
+}
+
+// encodeFeed はUTF-8で書いたフィードを指定した文字コードに変換します。
+func encodeFeed(t *testing.T, enc encoding.Encoding, feed string) string {
+	t.Helper()
+	encoded, err := enc.NewEncoder().String(feed)
+	if err != nil {
+		t.Fatalf("failed to encode feed: %v", err)
+	}
+	return encoded
+}
+
+func TestParseDate(t *testing.T) {
+	t.Parallel()
+
+	want := time.Date(2024, 4, 1, 10, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name    string
+		date    string
+		wantErr bool
+	}{
+		{name: "正常系: RFC1123", date: "Mon, 01 Apr 2024 10:00:00 GMT"},
+		{name: "正常系: RFC1123Z", date: "Mon, 01 Apr 2024 19:00:00 +0900"},
+		{name: "正常系: RFC3339", date: "2024-04-01T19:00:00+09:00"},
+		{name: "正常系: 秒なし", date: "Mon, 01 Apr 2024 10:00 +0000"},
+		{name: "正常系: 1桁の日", date: "Mon, 1 Apr 2024 10:00:00 +0000"},
+		{name: "正常系: 曜日なし", date: " 01 Apr 2024 10:00:00 +0000 "},
+		{name: "正常系: RFC3339の秒なし", date: "2024-04-01T10:00Z"},
+		{name: "異常系: 不正な日付", date: "Invalid Date", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			got, err := parseDate(tt.date)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("parseDate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && !got.Equal(want) {
+				t.Errorf("parseDate() = %v, want %v", got, want)
+			}
+		})
+	}
+}
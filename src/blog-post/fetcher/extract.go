@@ -0,0 +1,232 @@
+package fetcher
+
+import (
+	"io"
+	"net/url"
+	"regexp"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"golang.org/x/net/html"
+	"golang.org/x/net/html/atom"
+)
+
+// Minimum length in runes of a paragraph counted as article text
+const minParagraphLength = 25
+
+var (
+	// positivePattern and negativePattern score elements by their class and id, as readability does
+	positivePattern   = regexp.MustCompile(`(?i)article|body|content|entry|main|news|post|story|text|honbun|kiji`)
+	negativePattern   = regexp.MustCompile(`(?i)comment|footer|sidebar|side|nav|menu|share|sns|social|related|ranking|recommend|banner|ad-|ads|promo|widget|breadcrumb`)
+	datePublishedJSON = regexp.MustCompile(`"datePublished"\s*:\s*"([^"]+)"`)
+)
+
+// skipElements are never part of the article text
+var skipElements = map[atom.Atom]bool{
+	atom.Script: true, atom.Style: true, atom.Noscript: true, atom.Nav: true, atom.Header: true, atom.Footer: true,
+	atom.Aside: true, atom.Form: true, atom.Iframe: true, atom.Button: true, atom.Svg: true, atom.Figure: true,
+}
+
+// Page は記事ページから取り出した内容です。
+type Page struct {
+	// URL はリダイレクトを辿った後の記事のURLです
+	URL string
+	// CanonicalURL は <link rel="canonical"> または og:url のURLです。ない場合は URL と同じです
+	CanonicalURL string
+	Title        string
+	// Text は本文の段落を改行でつないだテキストです
+	Text        string
+	PublishedAt time.Time
+}
+
+// Extract は記事ページのHTMLから本文・タイトル・公開日時・正規のURLを取り出します。
+// 本文はreadabilityと同じように、段落の文字数とclass・idの名前から本文らしい要素を選んで取り出します。
+func Extract(r io.Reader, pageURL string) (*Page, error) {
+	doc, err := html.Parse(r)
+	if err != nil {
+		return nil, err
+	}
+	page := &Page{URL: pageURL, CanonicalURL: pageURL}
+	extractMeta(doc, page)
+	if page.CanonicalURL != pageURL {
+		page.CanonicalURL = resolveURL(pageURL, page.CanonicalURL)
+	}
+	page.Text = strings.Join(paragraphs(bestCandidate(doc)), "\n")
+	return page, nil
+}
+
+// extractMeta はタイトル・公開日時・正規のURLをmetaタグなどから取り出します。
+func extractMeta(doc *html.Node, page *Page) {
+	var title, ogTitle string
+	walk(doc, func(n *html.Node) bool {
+		switch n.DataAtom {
+		case atom.Title:
+			if title == "" {
+				title = textOf(n)
+			}
+		case atom.Link:
+			if strings.EqualFold(attr(n, "rel"), "canonical") && attr(n, "href") != "" {
+				page.CanonicalURL = attr(n, "href")
+			}
+		case atom.Meta:
+			key := strings.ToLower(attr(n, "property") + attr(n, "name") + attr(n, "itemprop"))
+			content := attr(n, "content")
+			switch key {
+			case "og:title":
+				ogTitle = content
+			case "og:url":
+				if page.CanonicalURL == page.URL && content != "" {
+					page.CanonicalURL = content
+				}
+			case "article:published_time", "og:published_time", "datepublished", "pubdate", "publishdate", "date", "dc.date.issued":
+				setDate(page, content)
+			}
+		case atom.Time:
+			setDate(page, attr(n, "datetime"))
+		case atom.Script:
+			if attr(n, "type") == "application/ld+json" {
+				if m := datePublishedJSON.FindStringSubmatch(textOf(n)); m != nil {
+					setDate(page, m[1])
+				}
+			}
+		}
+		return true
+	})
+	page.Title = strings.TrimSpace(ogTitle)
+	if page.Title == "" {
+		page.Title = strings.TrimSpace(title)
+	}
+}
+
+// dateLayouts are the formats of publish dates in meta tags
+var dateLayouts = []string{time.RFC3339, "2006-01-02T15:04:05Z0700", "2006-01-02T15:04:05", "2006-01-02T15:04Z07:00", "2006-01-02 15:04:05", "2006-01-02", "2006/01/02 15:04", "2006/01/02"}
+
+// setDate は最初に見つかった解釈できる公開日時を設定します。
+func setDate(page *Page, value string) {
+	if !page.PublishedAt.IsZero() || value == "" {
+		return
+	}
+	value = strings.TrimSpace(value)
+	for _, layout := range dateLayouts {
+		if t, err := time.Parse(layout, value); err == nil {
+			page.PublishedAt = t
+			return
+		}
+	}
+}
+
+// bestCandidate は本文を含む要素を選びます。段落の文字数と読点の数を親要素に、半分を祖父要素に加点し、
+// class・idの名前で加点・減点した点数の最も高い要素を返します。
+func bestCandidate(doc *html.Node) *html.Node {
+	scores := map[*html.Node]float64{}
+	walk(doc, func(n *html.Node) bool {
+		if skipElements[n.DataAtom] {
+			return false
+		}
+		if n.DataAtom != atom.P {
+			return true
+		}
+		text := textOf(n)
+		length := utf8.RuneCountInString(text)
+		if length < minParagraphLength {
+			return false
+		}
+		score := 1 + float64(strings.Count(text, "、")+strings.Count(text, "。")+strings.Count(text, ",")) + min(float64(length)/100, 3)
+		if parent := n.Parent; parent != nil {
+			scores[parent] += score
+			if grand := parent.Parent; grand != nil {
+				scores[grand] += score / 2
+			}
+		}
+		return false
+	})
+
+	var best *html.Node
+	bestScore := 0.0
+	for n, score := range scores {
+		names := attr(n, "class") + " " + attr(n, "id")
+		if positivePattern.MatchString(names) || n.DataAtom == atom.Article || n.DataAtom == atom.Main {
+			score *= 1.25
+		}
+		if negativePattern.MatchString(names) {
+			score *= 0.5
+		}
+		if score > bestScore {
+			best, bestScore = n, score
+		}
+	}
+	return best
+}
+
+// paragraphs は要素の中の段落と見出しのテキストを返します。
+func paragraphs(root *html.Node) []string {
+	if root == nil {
+		return nil
+	}
+	var texts []string
+	walk(root, func(n *html.Node) bool {
+		if skipElements[n.DataAtom] {
+			return false
+		}
+		switch n.DataAtom {
+		case atom.P, atom.H2, atom.H3, atom.Li, atom.Blockquote:
+			if text := textOf(n); text != "" && (n.DataAtom != atom.P || utf8.RuneCountInString(text) >= minParagraphLength/2) {
+				texts = append(texts, text)
+			}
+			return false
+		}
+		return true
+	})
+	return texts
+}
+
+// walk は要素を深さ優先で辿ります。fn が false を返した要素の子は辿りません。
+func walk(n *html.Node, fn func(*html.Node) bool) {
+	if n.Type == html.ElementNode && !fn(n) {
+		return
+	}
+	for c := n.FirstChild; c != nil; c = c.NextSibling {
+		walk(c, fn)
+	}
+}
+
+// textOf は要素の中のテキストを空白を詰めて返します。
+func textOf(n *html.Node) string {
+	var b strings.Builder
+	var collect func(*html.Node)
+	collect = func(n *html.Node) {
+		for c := n.FirstChild; c != nil; c = c.NextSibling {
+			switch {
+			case c.Type == html.TextNode:
+				b.WriteString(c.Data)
+				b.WriteByte(' ')
+			case c.Type == html.ElementNode && !skipElements[c.DataAtom]:
+				collect(c)
+			}
+		}
+	}
+	collect(n)
+	return strings.Join(strings.Fields(b.String()), " ")
+}
+
+func attr(n *html.Node, key string) string {
+	for _, a := range n.Attr {
+		if a.Key == key {
+			return a.Val
+		}
+	}
+	return ""
+}
+
+func resolveURL(base, ref string) string {
+	b, err := url.Parse(base)
+	if err != nil {
+		return ref
+	}
+	r, err := url.Parse(ref)
+	if err != nil {
+		return base
+	}
+	return b.ResolveReference(r).String()
+}
@@ -0,0 +1,115 @@
+// Package fetcher はニュース記事のページを取得して本文を取り出します。
+// Googleニュースのリンクは配信元の記事のURLに解決し、配信元の robots.txt で許可されている場合だけページを取得します。
+package fetcher
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+)
+
+const (
+	// DefaultUserAgent is sent with every request and matched against robots.txt groups
+	DefaultUserAgent = "gaba-fortnite-blog/1.0 (+https://gaba-fortnite.hatenablog.com/)"
+	// maxBodySize is the largest response read, to keep memory bounded on huge pages
+	maxBodySize = 5 << 20
+)
+
+// ErrDisallowed は robots.txt で記事ページの取得が許可されていない場合のエラーです。
+var ErrDisallowed = errors.New("disallowed by robots.txt")
+
+// Fetcher は記事ページを取得します。robots.txt はホストごとに保持します。
+type Fetcher struct {
+	Client    *http.Client
+	UserAgent string
+
+	googleNewsHost string
+	mu             sync.Mutex
+	robotsCache    map[string]*robotsRules
+}
+
+// New は Fetcher を作成します。client が nil の場合は http.DefaultClient を使います。
+func New(client *http.Client) *Fetcher {
+	if client == nil {
+		client = http.DefaultClient
+	}
+	return &Fetcher{
+		Client:         client,
+		UserAgent:      DefaultUserAgent,
+		googleNewsHost: "news.google.com",
+		robotsCache:    map[string]*robotsRules{},
+	}
+}
+
+// Fetch は記事のリンクからページを取得して本文を取り出します。
+// Googleニュースのリンクは配信元の記事のURLに解決してから取得します。
+func (f *Fetcher) Fetch(link string) (*Page, error) {
+	u, err := url.Parse(link)
+	if err != nil {
+		return nil, fmt.Errorf("invalid article link: %w", err)
+	}
+	if f.isGoogleNews(u) {
+		resolved, err := f.resolveGoogleNews(u)
+		if err != nil {
+			slog.Warn("Failed to resolve Google News link, following redirect instead", "link", link, "error", err)
+			if resolved, err = f.followGoogleNewsRedirect(u); err != nil {
+				return nil, err
+			}
+		}
+		if u, err = url.Parse(resolved); err != nil {
+			return nil, fmt.Errorf("invalid resolved article url %q: %w", resolved, err)
+		}
+	}
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return nil, fmt.Errorf("unsupported article url %q", u)
+	}
+
+	if !f.robots(u).allowed(u.RequestURI()) {
+		return nil, fmt.Errorf("%s: %w", u, ErrDisallowed)
+	}
+	req, err := f.newRequest(u.String())
+	if err != nil {
+		return nil, err
+	}
+	resp, err := f.Client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get article page: %w", err)
+	}
+	body, err := readBody(resp)
+	if err != nil {
+		return nil, err
+	}
+	// The page may have been redirected to a path or host that robots.txt disallows
+	if final := resp.Request.URL; !f.robots(final).allowed(final.RequestURI()) {
+		return nil, fmt.Errorf("%s: %w", final, ErrDisallowed)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("article page %s returned %d", u, resp.StatusCode)
+	}
+	if contentType := resp.Header.Get("Content-Type"); contentType != "" && !strings.Contains(contentType, "html") {
+		return nil, fmt.Errorf("article page %s is not html: %s", u, contentType)
+	}
+	return Extract(bytes.NewReader(body), resp.Request.URL.String())
+}
+
+func (f *Fetcher) newRequest(rawURL string) (*http.Request, error) {
+	req, err := http.NewRequest(http.MethodGet, rawURL, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("User-Agent", f.UserAgent)
+	req.Header.Set("Accept-Language", "ja,en;q=0.8")
+	return req, nil
+}
+
+// readBody は最大 maxBodySize バイトまで本文を読み込んで閉じます。
+func readBody(resp *http.Response) ([]byte, error) {
+	defer func() { _ = resp.Body.Close() }()
+	return io.ReadAll(io.LimitReader(resp.Body, maxBodySize))
+}
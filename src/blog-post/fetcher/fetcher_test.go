@@ -0,0 +1,261 @@
+package fetcher
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+const articleHTML = `<!DOCTYPE html>
+<html><head>
+	<title>新シーズン開幕 | ゲームメディア</title>
+	<meta property="og:title" content="フォートナイト新シーズン開幕">
+	<meta property="article:published_time" content="2025-05-01T10:00:00+09:00">
+	<link rel="canonical" href="/news/1">
+	<script>var ads = "広告のスクリプトは本文に含めない、含めない、含めない。";</script>
+</head><body>
+	<header><p>ゲームメディアのトップページへ戻るリンクとメニューの説明文です。</p></header>
+	<nav><p>ホーム、ニュース、レビュー、ランキング、お問い合わせ、ログイン。</p></nav>
+	<div class="sidebar"><p>人気の記事ランキング、今週のおすすめ、関連記事の一覧です。</p></div>
+	<article class="entry-content">
+		<h2>新シーズンの概要</h2>
+		<p>本日、フォートナイトの新シーズンが開幕しました。新しいマップと武器が追加され、バトルパスも一新されています。</p>
+		<p>今シーズンは、移動手段が大きく変わり、序盤の立ち回りが重要になりそうです。</p>
+		<p>詳しいアップデート内容は、公式のパッチノートで確認できます。</p>
+		<div class="share"><p>この記事をシェアする、ポストする、ブックマークする。</p></div>
+	</article>
+	<footer><p>Copyright ゲームメディア、無断転載を禁じます、すべての権利を保有します。</p></footer>
+</body></html>`
+
+func TestExtract(t *testing.T) {
+	t.Parallel()
+
+	page, err := Extract(strings.NewReader(articleHTML), "https://media.example.com/news/1?utm_source=rss")
+	if err != nil {
+		t.Fatalf("Extract() error = %v", err)
+	}
+	if page.Title != "フォートナイト新シーズン開幕" {
+		t.Errorf("Title = %q", page.Title)
+	}
+	if page.CanonicalURL != "https://media.example.com/news/1" {
+		t.Errorf("CanonicalURL = %q", page.CanonicalURL)
+	}
+	if want := time.Date(2025, 5, 1, 1, 0, 0, 0, time.UTC); !page.PublishedAt.Equal(want) {
+		t.Errorf("PublishedAt = %v, want %v", page.PublishedAt, want)
+	}
+	for _, want := range []string{"新シーズンの概要", "新シーズンが開幕しました", "公式のパッチノート"} {
+		if !strings.Contains(page.Text, want) {
+			t.Errorf("Text does not contain %q: %q", want, page.Text)
+		}
+	}
+	for _, unwanted := range []string{"メニュー", "ログイン", "ランキング", "Copyright", "広告"} {
+		if strings.Contains(page.Text, unwanted) {
+			t.Errorf("Text contains %q: %q", unwanted, page.Text)
+		}
+	}
+}
+
+func TestExtractDate(t *testing.T) {
+	t.Parallel()
+
+	want := time.Date(2025, 5, 1, 0, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name string
+		head string
+		body string
+	}{
+		{name: "正常系: itemprop", head: `<meta itemprop="datePublished" content="2025-05-01">`},
+		{name: "正常系: JSON-LD", head: `<script type="application/ld+json">{"@type":"NewsArticle","datePublished": "2025-05-01T00:00:00Z"}</script>`},
+		{name: "正常系: timeタグ", body: `<time datetime="2025-05-01T09:00:00+09:00">5月1日</time>`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			page, err := Extract(strings.NewReader("<html><head>"+tt.head+"</head><body>"+tt.body+"</body></html>"), "https://example.com/")
+			if err != nil {
+				t.Fatalf("Extract() error = %v", err)
+			}
+			if !page.PublishedAt.Equal(want) {
+				t.Errorf("PublishedAt = %v, want %v", page.PublishedAt, want)
+			}
+		})
+	}
+}
+
+func TestRobots(t *testing.T) {
+	t.Parallel()
+
+	data := []byte(`
+User-agent: *
+Disallow: /private/
+Allow: /private/news/
+Disallow: /*.pdf$
+Disallow: /*?print=
+
+User-agent: BadBot
+Disallow: /
+`)
+	tests := []struct {
+		name  string
+		agent string
+		path  string
+		want  bool
+	}{
+		{name: "正常系: ルールのないパス", agent: DefaultUserAgent, path: "/news/1", want: true},
+		{name: "正常系: Disallow", agent: DefaultUserAgent, path: "/private/1", want: false},
+		{name: "正常系: より長い Allow が優先", agent: DefaultUserAgent, path: "/private/news/1", want: true},
+		{name: "正常系: ワイルドカードと末尾", agent: DefaultUserAgent, path: "/files/a.pdf", want: false},
+		{name: "正常系: 末尾が一致しない", agent: DefaultUserAgent, path: "/files/a.pdf?x=1", want: true},
+		{name: "正常系: クエリのルール", agent: DefaultUserAgent, path: "/news/1?print=1", want: false},
+		{name: "正常系: エージェント名のグループ", agent: "BadBot/2.0", path: "/news/1", want: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			if got := parseRobots(data, tt.agent).allowed(tt.path); got != tt.want {
+				t.Errorf("allowed(%q) = %v, want %v", tt.path, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDecodeArticleID(t *testing.T) {
+	t.Parallel()
+
+	old := base64.RawURLEncoding.EncodeToString([]byte("\x08\x13\x22\x1ehttps://media.example.com/news/1\xd2\x01\x00"))
+	if got := decodeArticleID(old); got != "https://media.example.com/news/1" {
+		t.Errorf("decodeArticleID(old) = %q", got)
+	}
+	token := base64.RawURLEncoding.EncodeToString([]byte("\x08\x13\x22\x10AU_yqLNxyz"))
+	if got := decodeArticleID(token); got != "" {
+		t.Errorf("decodeArticleID(token) = %q, want empty", got)
+	}
+}
+
+// newTestServers はGoogleニュースと配信元のサイトのテスト用のサーバーを作成します。
+func newTestServers(t *testing.T, robots string) (news, publisher *httptest.Server, f *Fetcher) {
+	t.Helper()
+
+	publisher = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.URL.Path == "/robots.txt":
+			if robots == "" {
+				http.NotFound(w, r)
+				return
+			}
+			_, _ = fmt.Fprint(w, robots)
+		case strings.HasPrefix(r.URL.Path, "/news/"), strings.HasPrefix(r.URL.Path, "/private/"):
+			if r.UserAgent() != DefaultUserAgent {
+				http.Error(w, "forbidden", http.StatusForbidden)
+				return
+			}
+			w.Header().Set("Content-Type", "text/html; charset=utf-8")
+			_, _ = fmt.Fprint(w, articleHTML)
+		case r.URL.Path == "/moved/1":
+			http.Redirect(w, r, "/private/1", http.StatusMovedPermanently)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	t.Cleanup(publisher.Close)
+
+	news = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/rss/articles/CBMiTOKEN":
+			_, _ = fmt.Fprint(w, `<html><body><c-wiz><div jscontroller="aLI87" data-n-a-sg="SIG" data-n-a-ts="1714521600"></div></c-wiz></body></html>`)
+		case "/rss/articles/CBMiREDIRECT":
+			http.Redirect(w, r, "/articles/CBMiREDIRECT2", http.StatusFound)
+		case "/articles/CBMiREDIRECT2":
+			http.Redirect(w, r, publisher.URL+"/news/1", http.StatusFound)
+		case batchExecutePath:
+			if r.Method != http.MethodPost || !strings.Contains(r.FormValue("f.req"), "SIG") {
+				http.Error(w, "bad request", http.StatusBadRequest)
+				return
+			}
+			result, _ := json.Marshal([]any{"garturlres", publisher.URL + "/news/1", 1})
+			envelope, _ := json.Marshal([][]any{{"wrb.fr", "Fbv4je", string(result), nil, nil, nil, "generic"}})
+			_, _ = fmt.Fprintf(w, ")]}'\n\n%s", envelope)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	t.Cleanup(news.Close)
+
+	f = New(publisher.Client())
+	f.googleNewsHost = strings.TrimPrefix(news.URL, "http://")
+	return news, publisher, f
+}
+
+func TestFetch(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name       string
+		googleNews bool
+		path       string
+		robots     string
+		wantErr    error
+	}{
+		{name: "正常系: batchexecute で解決", googleNews: true, path: "/rss/articles/CBMiTOKEN?oc=5"},
+		{name: "正常系: リダイレクトで解決", googleNews: true, path: "/rss/articles/CBMiREDIRECT"},
+		{name: "正常系: 配信元のリンク", path: "/news/1"},
+		{name: "正常系: robots.txt で許可", path: "/news/1", robots: "User-agent: *\nDisallow: /private/\n"},
+		{name: "異常系: robots.txt で禁止", path: "/private/1", robots: "User-agent: *\nDisallow: /private/\n", wantErr: ErrDisallowed},
+		{name: "異常系: robots.txt でクエリが禁止", path: "/news/1?print=1", robots: "User-agent: *\nDisallow: /*?print=\n", wantErr: ErrDisallowed},
+		{name: "異常系: リダイレクト先が robots.txt で禁止", path: "/moved/1", robots: "User-agent: *\nDisallow: /private/\n", wantErr: ErrDisallowed},
+		{name: "異常系: エージェント名のグループで禁止", googleNews: true, path: "/rss/articles/CBMiTOKEN", robots: "User-agent: gaba-fortnite-blog\nDisallow: /\n", wantErr: ErrDisallowed},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			news, publisher, f := newTestServers(t, tt.robots)
+			link := publisher.URL + tt.path
+			if tt.googleNews {
+				link = news.URL + tt.path
+			}
+			page, err := f.Fetch(link)
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("Fetch() error = %v, want %v", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Fetch() error = %v", err)
+			}
+			if page.URL != publisher.URL+"/news/1" || page.CanonicalURL != publisher.URL+"/news/1" {
+				t.Errorf("URL = %q, CanonicalURL = %q", page.URL, page.CanonicalURL)
+			}
+			if !strings.Contains(page.Text, "新シーズンが開幕しました") {
+				t.Errorf("Text = %q", page.Text)
+			}
+		})
+	}
+}
+
+func TestFetchRobotsServerError(t *testing.T) {
+	t.Parallel()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/robots.txt" {
+			http.Error(w, "unavailable", http.StatusServiceUnavailable)
+			return
+		}
+		t.Errorf("unexpected request to %s", r.URL)
+	}))
+	defer server.Close()
+
+	_, err := New(server.Client()).Fetch(server.URL + "/news/1")
+	if !errors.Is(err, ErrDisallowed) {
+		t.Errorf("Fetch() error = %v, want %v", err, ErrDisallowed)
+	}
+}
@@ -0,0 +1,182 @@
+package fetcher
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strings"
+)
+
+const (
+	// batchExecutePath is the endpoint the Google News article page uses to get the publisher URL
+	batchExecutePath = "/_/DotsSplashUi/data/batchexecute"
+)
+
+var (
+	signaturePattern = regexp.MustCompile(`data-n-a-sg="([^"]+)"`)
+	timestampPattern = regexp.MustCompile(`data-n-a-ts="([^"]+)"`)
+	embeddedURL      = regexp.MustCompile(`https?://[\x21-\x7e]+`)
+)
+
+// isGoogleNews はGoogleニュースの記事のリンクかを返します。
+func (f *Fetcher) isGoogleNews(u *url.URL) bool {
+	return u.Host == f.googleNewsHost && strings.Contains(u.Path, "/articles/")
+}
+
+// articleID はGoogleニュースの記事のリンクから記事IDを返します。
+func articleID(u *url.URL) string {
+	return u.Path[strings.LastIndex(u.Path, "/")+1:]
+}
+
+// resolveGoogleNews はGoogleニュースのリンクから配信元の記事のURLを返します。
+// 古い形式の記事IDはURLをそのまま含んでいるため取り出し、新しい形式は記事ページの署名を使って batchexecute に問い合わせます。
+func (f *Fetcher) resolveGoogleNews(u *url.URL) (string, error) {
+	id := articleID(u)
+	if decoded := decodeArticleID(id); decoded != "" {
+		return decoded, nil
+	}
+
+	page := *u
+	page.Path = "/rss/articles/" + id
+	page.RawQuery = ""
+	body, err := f.getGoogleNews(page.String())
+	if err != nil {
+		return "", fmt.Errorf("failed to get Google News article page: %w", err)
+	}
+	signature := signaturePattern.FindSubmatch(body)
+	timestamp := timestampPattern.FindSubmatch(body)
+	if signature == nil || timestamp == nil {
+		return "", fmt.Errorf("google news article page has no signature")
+	}
+
+	request := fmt.Sprintf(`["garturlreq",[["X","X",["X","X"],null,null,1,1,"US:en",null,1,null,null,null,null,null,0,1],"X","X",1,[1,1,1],1,1,null,0,0,null,0],%q,%s,%q]`,
+		id, timestamp[1], signature[1])
+	payload, err := json.Marshal([][][]any{{{"Fbv4je", request, nil, "generic"}}})
+	if err != nil {
+		return "", err
+	}
+	endpoint := url.URL{Scheme: u.Scheme, Host: u.Host, Path: batchExecutePath}
+	req, err := http.NewRequest(http.MethodPost, endpoint.String(), strings.NewReader(url.Values{"f.req": {string(payload)}}.Encode()))
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded;charset=UTF-8")
+	req.Header.Set("User-Agent", f.UserAgent)
+	resp, err := f.googleNewsClient().Do(req)
+	if err != nil {
+		return "", fmt.Errorf("failed to request Google News batchexecute: %w", err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+	data, err := io.ReadAll(io.LimitReader(resp.Body, maxBodySize))
+	if err != nil {
+		return "", err
+	}
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("google news batchexecute returned %d", resp.StatusCode)
+	}
+	return parseBatchExecute(data)
+}
+
+// googleNewsClient はGoogleニュースの外へのリダイレクトを辿らない http.Client を返します。
+// 配信元のページは robots.txt を確認してから取得するため、解決の途中ではアクセスしません。
+func (f *Fetcher) googleNewsClient() *http.Client {
+	client := *f.Client
+	client.CheckRedirect = func(req *http.Request, via []*http.Request) error {
+		if req.URL.Host != f.googleNewsHost || len(via) >= 10 {
+			return http.ErrUseLastResponse
+		}
+		return nil
+	}
+	return &client
+}
+
+// getGoogleNews はGoogleニュースのページを取得して本文を返します。
+func (f *Fetcher) getGoogleNews(rawURL string) ([]byte, error) {
+	req, err := f.newRequest(rawURL)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := f.googleNewsClient().Do(req)
+	if err != nil {
+		return nil, err
+	}
+	body, err := readBody(resp)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("%s returned %d", rawURL, resp.StatusCode)
+	}
+	return body, nil
+}
+
+// followGoogleNewsRedirect はGoogleニュースのリンクのリダイレクト先のうち、Googleニュースの外の最初のURLを返します。
+func (f *Fetcher) followGoogleNewsRedirect(u *url.URL) (string, error) {
+	req, err := f.newRequest(u.String())
+	if err != nil {
+		return "", err
+	}
+	resp, err := f.googleNewsClient().Do(req)
+	if err != nil {
+		return "", fmt.Errorf("failed to follow Google News redirect: %w", err)
+	}
+	_ = resp.Body.Close()
+	location, err := resp.Location()
+	if err != nil || location.Host == f.googleNewsHost {
+		return "", fmt.Errorf("google news link %s did not redirect to the article", u)
+	}
+	return location.String(), nil
+}
+
+// parseBatchExecute は batchexecute の応答から配信元のURLを取り出します。
+// 応答は )]}' の行の後に [["wrb.fr","Fbv4je","[\"garturlres\",\"<URL>\",1]",...]] のJSONが続きます。
+func parseBatchExecute(data []byte) (string, error) {
+	if i := bytes.IndexByte(data, '['); i >= 0 {
+		data = data[i:]
+	}
+	var envelopes [][]any
+	if err := json.NewDecoder(bytes.NewReader(data)).Decode(&envelopes); err != nil {
+		return "", fmt.Errorf("invalid Google News batchexecute response: %w", err)
+	}
+	for _, envelope := range envelopes {
+		if len(envelope) < 3 || envelope[1] != "Fbv4je" {
+			continue
+		}
+		inner, ok := envelope[2].(string)
+		if !ok {
+			continue
+		}
+		var result []any
+		if err := json.Unmarshal([]byte(inner), &result); err != nil {
+			return "", fmt.Errorf("invalid Google News batchexecute result: %w", err)
+		}
+		if len(result) >= 2 && result[0] == "garturlres" {
+			if resolved, ok := result[1].(string); ok {
+				return resolved, nil
+			}
+		}
+	}
+	return "", fmt.Errorf("google news batchexecute returned no url")
+}
+
+// decodeArticleID は古い形式の記事IDに含まれるURLを返します。新しい形式の場合は空文字を返します。
+func decodeArticleID(id string) string {
+	data, err := base64.RawURLEncoding.DecodeString(strings.TrimRight(id, "="))
+	if err != nil {
+		return ""
+	}
+	// The ID is a protobuf message whose first string field is the URL, or an opaque token starting with AU_yqL
+	if bytes.Contains(data, []byte("AU_yqL")) {
+		return ""
+	}
+	m := embeddedURL.Find(data)
+	if m == nil {
+		return ""
+	}
+	return string(m)
+}
@@ -0,0 +1,148 @@
+package fetcher
+
+import (
+	"bufio"
+	"bytes"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// robotsRules は robots.txt のうち、このクローラーに適用されるルールです。
+type robotsRules struct {
+	allow, disallow []string
+	// disallowAll is set when robots.txt could not be read because of a server error
+	disallowAll bool
+}
+
+// allowed はパスにアクセスしてよいかを返します。path はクエリを含めたパス（URL.RequestURI）です。
+// 最も長く一致したルールに従い、同じ長さの場合は Allow を優先します。
+func (r *robotsRules) allowed(path string) bool {
+	if r.disallowAll {
+		return false
+	}
+	longest := func(rules []string) int {
+		n := -1
+		for _, rule := range rules {
+			if robotsMatch(rule, path) && len(rule) > n {
+				n = len(rule)
+			}
+		}
+		return n
+	}
+	return longest(r.allow) >= longest(r.disallow)
+}
+
+// robotsMatch は robots.txt のパスのパターンに一致するかを返します。* は任意の文字列、末尾の $ はパスの終わりです。
+func robotsMatch(pattern, path string) bool {
+	anchored := strings.HasSuffix(pattern, "$")
+	pattern = strings.TrimSuffix(pattern, "$")
+	parts := strings.Split(pattern, "*")
+	if !strings.HasPrefix(path, parts[0]) {
+		return false
+	}
+	rest := path[len(parts[0]):]
+	for _, part := range parts[1:] {
+		i := strings.Index(rest, part)
+		if i < 0 {
+			return false
+		}
+		rest = rest[i+len(part):]
+	}
+	if anchored {
+		last := parts[len(parts)-1]
+		return rest == "" || (len(parts) > 1 && strings.HasSuffix(path, last))
+	}
+	return true
+}
+
+// parseRobots は robots.txt からユーザーエージェントに適用されるルールを取り出します。
+// エージェント名が一致するグループがあればそのグループを、なければ * のグループを使います。
+func parseRobots(data []byte, agent string) *robotsRules {
+	agent = strings.ToLower(agent)
+	var specific, wildcard *robotsRules
+	var current []*robotsRules
+	inAgents := false
+
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for scanner.Scan() {
+		line, _, _ := strings.Cut(scanner.Text(), "#")
+		key, value, ok := strings.Cut(line, ":")
+		if !ok {
+			continue
+		}
+		key, value = strings.ToLower(strings.TrimSpace(key)), strings.TrimSpace(value)
+		switch key {
+		case "user-agent":
+			if !inAgents {
+				current = nil
+			}
+			inAgents = true
+			name := strings.ToLower(value)
+			switch {
+			case name == "*":
+				if wildcard == nil {
+					wildcard = &robotsRules{}
+				}
+				current = append(current, wildcard)
+			case name != "" && strings.Contains(agent, name):
+				if specific == nil {
+					specific = &robotsRules{}
+				}
+				current = append(current, specific)
+			}
+		case "allow", "disallow":
+			inAgents = false
+			if value == "" {
+				continue
+			}
+			for _, rules := range current {
+				if key == "allow" {
+					rules.allow = append(rules.allow, value)
+				} else {
+					rules.disallow = append(rules.disallow, value)
+				}
+			}
+		default:
+			inAgents = false
+		}
+	}
+	if specific != nil {
+		return specific
+	}
+	if wildcard != nil {
+		return wildcard
+	}
+	return &robotsRules{}
+}
+
+// robots はホストの robots.txt のルールを返します。ホストごとに1回だけ取得します。
+// robots.txt がない場合（4xx）はすべて許可し、サーバーエラーや通信エラーの場合はすべて禁止とします。
+func (f *Fetcher) robots(u *url.URL) *robotsRules {
+	key := u.Scheme + "://" + u.Host
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if rules, ok := f.robotsCache[key]; ok {
+		return rules
+	}
+
+	rules := &robotsRules{}
+	req, err := f.newRequest(key + "/robots.txt")
+	if err == nil {
+		var resp *http.Response
+		if resp, err = f.Client.Do(req); err == nil {
+			data, readErr := readBody(resp)
+			switch {
+			case resp.StatusCode >= 500 || readErr != nil:
+				rules.disallowAll = true
+			case resp.StatusCode == http.StatusOK:
+				rules = parseRobots(data, f.UserAgent)
+			}
+		}
+	}
+	if err != nil {
+		rules.disallowAll = true
+	}
+	f.robotsCache[key] = rules
+	return rules
+}
@@ -0,0 +1,176 @@
+package followaudit
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"strings"
+	"time"
+
+	"thiroyoshi.com/video-converter/x"
+)
+
+// ActionType はフォロー操作の種類です。
+type ActionType string
+
+const (
+	ActionFollow   ActionType = "follow"
+	ActionUnfollow ActionType = "unfollow"
+)
+
+// Action はひとつのフォロー操作です。
+type Action struct {
+	Type   ActionType
+	User   x.User
+	Reason string
+}
+
+// Report は監査の結果です。
+type Report struct {
+	Following int
+	Followers int
+	Actions   []Action
+}
+
+// Plan はフォロー中とフォロワーの一覧をルールで評価し、行うべき操作を返します。フォロー解除を先に並べます。
+func Plan(following, followers []x.User, rules Rules, now time.Time) *Report {
+	followerIDs := make(map[string]bool, len(followers))
+	for _, user := range followers {
+		followerIDs[user.ID] = true
+	}
+	followingIDs := make(map[string]bool, len(following))
+	for _, user := range following {
+		followingIDs[user.ID] = true
+	}
+
+	report := &Report{Following: len(following), Followers: len(followers)}
+	for _, user := range following {
+		if reason := rules.Unfollow.unfollowReason(user, followerIDs[user.ID], now); reason != "" {
+			report.Actions = append(report.Actions, Action{Type: ActionUnfollow, User: user, Reason: reason})
+		}
+	}
+	for _, user := range followers {
+		if followingIDs[user.ID] {
+			continue
+		}
+		if reason := rules.Follow.followReason(user, now); reason != "" {
+			report.Actions = append(report.Actions, Action{Type: ActionFollow, User: user, Reason: reason})
+		}
+	}
+	return report
+}
+
+// Count は指定した種類の操作の件数を返します。
+func (r *Report) Count(t ActionType) int {
+	n := 0
+	for _, action := range r.Actions {
+		if action.Type == t {
+			n++
+		}
+	}
+	return n
+}
+
+// Text はドライランで表示するレポートです。
+func (r *Report) Text() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "フォロー中 %d / フォロワー %d\n", r.Following, r.Followers)
+	fmt.Fprintf(&b, "フォロー解除 %d件 / フォロー %d件\n", r.Count(ActionUnfollow), r.Count(ActionFollow))
+	for _, action := range r.Actions {
+		fmt.Fprintf(&b, "%-8s @%s（%s）\n", action.Type, action.User.Username, action.Reason)
+	}
+	return b.String()
+}
+
+// Actor はフォロー操作を行うクライアントです。
+type Actor interface {
+	Follow(sourceUserID, targetUserID string) error
+	Unfollow(sourceUserID, targetUserID string) error
+}
+
+// Entry は監査ログの1行です。
+type Entry struct {
+	Time     time.Time  `json:"time"`
+	Action   ActionType `json:"action"`
+	UserID   string     `json:"user_id"`
+	Username string     `json:"username"`
+	Reason   string     `json:"reason"`
+	Error    string     `json:"error,omitempty"`
+}
+
+// AuditLog は操作の結果をJSON Linesで追記するログです。
+type AuditLog struct {
+	encoder *json.Encoder
+	file    *os.File
+}
+
+// OpenAuditLog は監査ログのファイルを追記モードで開きます。
+func OpenAuditLog(path string) (*AuditLog, error) {
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o644)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open audit log: %w", err)
+	}
+	return &AuditLog{encoder: json.NewEncoder(file), file: file}, nil
+}
+
+// Write はログを1行書き込みます。
+func (l *AuditLog) Write(entry Entry) error {
+	return l.encoder.Encode(entry)
+}
+
+// Close はログのファイルを閉じます。
+func (l *AuditLog) Close() error {
+	return l.file.Close()
+}
+
+// sleep is replaced in tests
+var sleep = time.Sleep
+
+// Apply は操作を順に行い、結果を監査ログに書き込みます。
+// ルールの MaxActions で件数を制限し、操作の間は IntervalSeconds だけ待ちます。
+// レート制限に達した場合は残りの操作を行わずに終了します。
+func Apply(actor Actor, me string, actions []Action, rules Rules, log *AuditLog) (int, error) {
+	if rules.MaxActions > 0 && len(actions) > rules.MaxActions {
+		slog.Info("Limiting follow actions", "planned", len(actions), "max", rules.MaxActions)
+		actions = actions[:rules.MaxActions]
+	}
+
+	done := 0
+	for i, action := range actions {
+		if i > 0 && rules.IntervalSeconds > 0 {
+			sleep(time.Duration(rules.IntervalSeconds) * time.Second)
+		}
+
+		var err error
+		switch action.Type {
+		case ActionFollow:
+			err = actor.Follow(me, action.User.ID)
+		case ActionUnfollow:
+			err = actor.Unfollow(me, action.User.ID)
+		default:
+			err = fmt.Errorf("unknown action: %s", action.Type)
+		}
+
+		entry := Entry{Time: time.Now(), Action: action.Type, UserID: action.User.ID, Username: action.User.Username, Reason: action.Reason}
+		if err != nil {
+			entry.Error = err.Error()
+		}
+		if lerr := log.Write(entry); lerr != nil {
+			return done, fmt.Errorf("failed to write audit log: %w", lerr)
+		}
+
+		var rateErr *x.RateLimitError
+		if errors.As(err, &rateErr) {
+			return done, err
+		}
+		if err != nil {
+			slog.Error("Follow action failed", "action", action.Type, "username", action.User.Username, "error", err)
+			continue
+		}
+		slog.Info("Follow action done", "action", action.Type, "username", action.User.Username)
+		done++
+	}
+	return done, nil
+}
@@ -0,0 +1,192 @@
+package followaudit
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"strconv"
+	"testing"
+	"time"
+
+	"thiroyoshi.com/video-converter/x"
+)
+
+// tweetIDAt はdaysAgo日前に投稿されたツイートのIDを返します。
+func tweetIDAt(now time.Time, daysAgo int) string {
+	ms := now.AddDate(0, 0, -daysAgo).UnixMilli() - 1288834974657
+	return strconv.FormatInt(ms<<22, 10)
+}
+
+func TestPlan(t *testing.T) {
+	t.Parallel()
+
+	now := time.Date(2025, 4, 20, 0, 0, 0, 0, time.UTC)
+	following := []x.User{
+		{ID: "1", Username: "mutual"},
+		{ID: "2", Username: "nofollowback"},
+		{ID: "3", Username: "verified", VerifiedType: "blue"},
+		{ID: "4", Username: "FortniteJP"},
+		{ID: "5", Username: "sleeping", MostRecentTweetID: tweetIDAt(now, 200)},
+	}
+	followers := []x.User{
+		{ID: "1", Username: "mutual"},
+		{ID: "5", Username: "sleeping"},
+		{ID: "6", Username: "fan", VerifiedType: "blue", Description: "フォートナイト好き\nPS5でプレイ中"},
+		{ID: "7", Username: "oneline", VerifiedType: "blue", Description: "Fortnite player"},
+		{ID: "8", Username: "unverified", Description: "Fortnite\nplayer"},
+		{ID: "9", Username: "other", VerifiedType: "blue", Description: "料理\nキャンプ"},
+	}
+
+	rules := DefaultRules
+	rules.Unfollow.Keep = []string{"@FortniteJP"}
+	rules.Unfollow.InactiveDays = 180
+
+	report := Plan(following, followers, rules, now)
+
+	want := []struct {
+		action   ActionType
+		username string
+	}{
+		{ActionUnfollow, "nofollowback"},
+		{ActionUnfollow, "sleeping"},
+		{ActionFollow, "fan"},
+	}
+	if len(report.Actions) != len(want) {
+		t.Fatalf("Actions = %+v, want %d actions", report.Actions, len(want))
+	}
+	for i, w := range want {
+		if got := report.Actions[i]; got.Type != w.action || got.User.Username != w.username || got.Reason == "" {
+			t.Errorf("Actions[%d] = %s @%s (%s), want %s @%s", i, got.Type, got.User.Username, got.Reason, w.action, w.username)
+		}
+	}
+	if report.Count(ActionUnfollow) != 2 || report.Count(ActionFollow) != 1 {
+		t.Errorf("Count() = %d, %d", report.Count(ActionUnfollow), report.Count(ActionFollow))
+	}
+}
+
+type fakeActor struct {
+	calls   []string
+	failOn  string
+	limitOn string
+}
+
+func (f *fakeActor) Follow(source, target string) error {
+	return f.do("follow", source, target)
+}
+
+func (f *fakeActor) Unfollow(source, target string) error {
+	return f.do("unfollow", source, target)
+}
+
+func (f *fakeActor) do(action, source, target string) error {
+	f.calls = append(f.calls, action+" "+source+" "+target)
+	switch target {
+	case f.failOn:
+		return errors.New("forbidden")
+	case f.limitOn:
+		return &x.RateLimitError{Reset: time.Now().Add(time.Minute)}
+	}
+	return nil
+}
+
+func TestApply(t *testing.T) {
+	var slept []time.Duration
+	sleep = func(d time.Duration) { slept = append(slept, d) }
+	defer func() { sleep = time.Sleep }()
+
+	actions := []Action{
+		{Type: ActionUnfollow, User: x.User{ID: "1", Username: "a"}, Reason: "r"},
+		{Type: ActionUnfollow, User: x.User{ID: "2", Username: "b"}, Reason: "r"},
+		{Type: ActionFollow, User: x.User{ID: "3", Username: "c"}, Reason: "r"},
+		{Type: ActionFollow, User: x.User{ID: "4", Username: "d"}, Reason: "r"},
+		{Type: ActionFollow, User: x.User{ID: "5", Username: "e"}, Reason: "r"},
+	}
+
+	tests := []struct {
+		name      string
+		actor     *fakeActor
+		max       int
+		wantDone  int
+		wantCalls int
+		wantErr   bool
+	}{
+		{name: "正常系: 上限まで操作する", actor: &fakeActor{}, max: 3, wantDone: 3, wantCalls: 3},
+		{name: "正常系: 失敗した操作は記録して続ける", actor: &fakeActor{failOn: "2"}, max: 10, wantDone: 4, wantCalls: 5},
+		{name: "異常系: レート制限で中断する", actor: &fakeActor{limitOn: "3"}, max: 10, wantDone: 2, wantCalls: 3, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			slept = nil
+			path := filepath.Join(t.TempDir(), "audit.log")
+			log, err := OpenAuditLog(path)
+			if err != nil {
+				t.Fatalf("OpenAuditLog() error = %v", err)
+			}
+
+			rules := Rules{MaxActions: tt.max, IntervalSeconds: 5}
+			done, err := Apply(tt.actor, "me", actions, rules, log)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Apply() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err := log.Close(); err != nil {
+				t.Fatalf("Close() error = %v", err)
+			}
+
+			if done != tt.wantDone || len(tt.actor.calls) != tt.wantCalls {
+				t.Errorf("done = %d, calls = %v", done, tt.actor.calls)
+			}
+			if tt.actor.calls[0] != "unfollow me 1" {
+				t.Errorf("calls[0] = %q", tt.actor.calls[0])
+			}
+			if len(slept) != tt.wantCalls-1 {
+				t.Errorf("slept %d times, want %d", len(slept), tt.wantCalls-1)
+			}
+
+			// すべての操作が監査ログに残る
+			file, err := os.Open(path)
+			if err != nil {
+				t.Fatalf("Open() error = %v", err)
+			}
+			defer func() { _ = file.Close() }()
+			var entries []Entry
+			scanner := bufio.NewScanner(file)
+			for scanner.Scan() {
+				var entry Entry
+				if err := json.Unmarshal(scanner.Bytes(), &entry); err != nil {
+					t.Fatalf("invalid audit log line: %v", err)
+				}
+				entries = append(entries, entry)
+			}
+			if len(entries) != tt.wantCalls {
+				t.Errorf("audit log entries = %d, want %d", len(entries), tt.wantCalls)
+			}
+			if tt.actor.failOn != "" && entries[1].Error == "" {
+				t.Errorf("entries[1].Error is empty, want the failure")
+			}
+		})
+	}
+}
+
+func TestLoadRules(t *testing.T) {
+	t.Parallel()
+
+	path := filepath.Join(t.TempDir(), "rules.json")
+	if err := os.WriteFile(path, []byte(`{"unfollow": {"enabled": true, "inactive_days": 90}, "max_actions": 10}`), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	rules, err := LoadRules(path)
+	if err != nil {
+		t.Fatalf("LoadRules() error = %v", err)
+	}
+	if rules.Unfollow.InactiveDays != 90 || rules.MaxActions != 10 {
+		t.Errorf("rules = %+v", rules)
+	}
+	// 指定していない項目はデフォルトのまま
+	if !rules.Follow.Enabled || rules.IntervalSeconds != DefaultRules.IntervalSeconds {
+		t.Errorf("rules = %+v, want defaults for follow", rules)
+	}
+}
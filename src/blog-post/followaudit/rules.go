@@ -0,0 +1,137 @@
+// Package followaudit はXのフォロー・フォロワーを設定したルールで監査し、フォローとフォロー解除を行います。
+package followaudit
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"thiroyoshi.com/video-converter/x"
+)
+
+// Rules は監査のルールです。
+type Rules struct {
+	Unfollow UnfollowRules `json:"unfollow"`
+	Follow   FollowRules   `json:"follow"`
+	// MaxActions は1回の実行で行うフォロー・フォロー解除の上限です
+	MaxActions int `json:"max_actions"`
+	// IntervalSeconds はフォロー・フォロー解除の間隔です
+	IntervalSeconds int `json:"interval_seconds"`
+}
+
+// UnfollowRules はフォロー中のアカウントのうち、フォローを解除する条件です。
+type UnfollowRules struct {
+	Enabled bool `json:"enabled"`
+	// NotFollowingBack はフォローを返していないアカウントを対象にします
+	NotFollowingBack bool `json:"not_following_back"`
+	// InactiveDays は最新のツイートがこの日数より古いアカウントを対象にします。0の場合は判定しません
+	InactiveDays int `json:"inactive_days"`
+	// KeepVerified は認証済みアカウントを対象から外します
+	KeepVerified bool `json:"keep_verified"`
+	// Keep は対象から外すユーザー名です
+	Keep []string `json:"keep"`
+}
+
+// FollowRules はフォロワーのうち、フォローを返す条件です。
+type FollowRules struct {
+	Enabled bool `json:"enabled"`
+	// VerifiedOnly は認証済みアカウントだけを対象にします
+	VerifiedOnly bool `json:"verified_only"`
+	// Keywords はプロフィールの説明文にいずれかを含むアカウントを対象にします。空の場合は判定しません
+	Keywords []string `json:"keywords"`
+	// MinDescriptionLines は説明文の最低行数です
+	MinDescriptionLines int `json:"min_description_lines"`
+	// InactiveDays は最新のツイートがこの日数より古いアカウントを対象から外します。0の場合は判定しません
+	InactiveDays int `json:"inactive_days"`
+}
+
+// DefaultRules はこれまでブラウザ操作で行っていた監査と同じルールです。
+// 認証済みでなくフォローを返していないアカウントのフォローを解除し、
+// 説明文が2行以上でFortniteに触れている認証済みフォロワーをフォローします。
+var DefaultRules = Rules{
+	Unfollow: UnfollowRules{
+		Enabled:          true,
+		NotFollowingBack: true,
+		KeepVerified:     true,
+	},
+	Follow: FollowRules{
+		Enabled:             true,
+		VerifiedOnly:        true,
+		Keywords:            []string{"Fortnite", "フォートナイト", "ふぉーとないと", "フォトナ"},
+		MinDescriptionLines: 2,
+	},
+	MaxActions:      50,
+	IntervalSeconds: 20,
+}
+
+// LoadRules はJSONファイルからルールを読み込みます。ファイルにない項目は DefaultRules の値を使い、pathが空の場合は DefaultRules を返します。
+func LoadRules(path string) (Rules, error) {
+	if path == "" {
+		return DefaultRules, nil
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return Rules{}, fmt.Errorf("failed to read rules: %w", err)
+	}
+	rules := DefaultRules
+	if err := json.Unmarshal(data, &rules); err != nil {
+		return Rules{}, fmt.Errorf("failed to parse rules: %w", err)
+	}
+	return rules, nil
+}
+
+// unfollowReason はフォロー解除の理由を返します。対象でない場合は空文字を返します。
+func (r UnfollowRules) unfollowReason(user x.User, followsBack bool, now time.Time) string {
+	if !r.Enabled || containsFold(r.Keep, user.Username) || (r.KeepVerified && user.IsVerified()) {
+		return ""
+	}
+	if r.NotFollowingBack && !followsBack {
+		return "フォローを返していない"
+	}
+	if r.InactiveDays > 0 && inactive(user, r.InactiveDays, now) {
+		return fmt.Sprintf("%d日以上ツイートしていない", r.InactiveDays)
+	}
+	return ""
+}
+
+// followReason はフォローする理由を返します。対象でない場合は空文字を返します。
+func (r FollowRules) followReason(user x.User, now time.Time) string {
+	if !r.Enabled {
+		return ""
+	}
+	if r.VerifiedOnly && !user.IsVerified() {
+		return ""
+	}
+	if lines := len(strings.Split(strings.TrimSpace(user.Description), "\n")); user.Description == "" || lines < r.MinDescriptionLines {
+		return ""
+	}
+	if r.InactiveDays > 0 && inactive(user, r.InactiveDays, now) {
+		return ""
+	}
+	if len(r.Keywords) == 0 {
+		return "フォロワー"
+	}
+	for _, keyword := range r.Keywords {
+		if strings.Contains(strings.ToLower(user.Description), strings.ToLower(keyword)) {
+			return fmt.Sprintf("説明文に「%s」を含むフォロワー", keyword)
+		}
+	}
+	return ""
+}
+
+// inactive は最新のツイートがdays日より古いかを返します。最新のツイートが分からない場合は判定しません。
+func inactive(user x.User, days int, now time.Time) bool {
+	last := user.LastTweetAt()
+	return !last.IsZero() && now.Sub(last) > time.Duration(days)*24*time.Hour
+}
+
+func containsFold(list []string, s string) bool {
+	for _, v := range list {
+		if strings.EqualFold(strings.TrimPrefix(v, "@"), s) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,74 @@
+package blogpost
+
+import (
+	"encoding/json"
+	"fmt"
+	"maps"
+	"os"
+
+	"thiroyoshi.com/blog-post/llm"
+)
+
+// Pipeline stages whose provider and model are configurable
+const (
+	// stageSearch summarises an article by searching the web for its title
+	stageSearch = "search"
+	// stageSummary summarises the article text extracted from the publisher's page
+	stageSummary = "summary"
+	// stageDraft writes the first version of the post from the summaries
+	stageDraft = "draft"
+	// stageRefine rewrites the draft into the published post
+	stageRefine = "refine"
+)
+
+// DefaultStages は段階ごとの既定のプロバイダーとモデルです。
+var DefaultStages = map[string]llm.Stage{
+	stageSearch:  {Provider: llm.ProviderOpenAI, Model: "gpt-4o-search-preview-2025-03-11"},
+	stageSummary: {Provider: llm.ProviderOpenAI, Model: "gpt-4o"},
+	stageDraft:   {Provider: llm.ProviderOpenAI, Model: "o3-mini"},
+	stageRefine:  {Provider: llm.ProviderOpenAI, Model: "o3-mini"},
+}
+
+// loadStages はJSONファイルから段階ごとのプロバイダーとモデルを読み込み、DefaultStages に上書きします。
+// pathが空の場合は DefaultStages を使います。
+func loadStages(path string) (map[string]llm.Stage, error) {
+	stages := maps.Clone(DefaultStages)
+	if path == "" {
+		return stages, nil
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read llm stages: %w", err)
+	}
+	var overrides map[string]llm.Stage
+	if err := json.Unmarshal(data, &overrides); err != nil {
+		return nil, fmt.Errorf("failed to parse llm stages: %w", err)
+	}
+	for name, stage := range overrides {
+		if _, ok := DefaultStages[name]; !ok {
+			return nil, fmt.Errorf("unknown llm stage %q", name)
+		}
+		if err := stage.Validate(); err != nil {
+			return nil, fmt.Errorf("llm stage %q: %w", name, err)
+		}
+		stages[name] = stage
+	}
+	return stages, nil
+}
+
+// newStageGenerator は設定の段階のプロバイダーとモデルで Generator を作成します。テストでは差し替えます。
+var newStageGenerator = func(config *Config, stage string) (llm.Generator, error) {
+	stages, err := loadStages(config.LLMStages)
+	if err != nil {
+		return nil, err
+	}
+	generator, err := llm.New(stages[stage], llm.Credentials{
+		OpenAIAPIKey:    config.OpenAIAPIKey,
+		AnthropicAPIKey: config.AnthropicAPIKey,
+		LocalAPIKey:     config.LocalLLMAPIKey,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create generator for %s stage: %w", stage, err)
+	}
+	return generator, nil
+}
@@ -0,0 +1,128 @@
+package blogpost
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"thiroyoshi.com/blog-post/llm"
+)
+
+func TestLoadStages(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		content string
+		want    map[string]llm.Stage
+		wantErr bool
+	}{
+		{name: "正常系: 指定なし", want: DefaultStages},
+		{
+			name:    "正常系: 一部の段階を上書き",
+			content: `{"draft": {"provider": "anthropic", "model": "claude-sonnet-4-5"}, "search": {"provider": "local", "model": "llama3.1", "base_url": "http://localhost:8080/v1"}}`,
+			want: map[string]llm.Stage{
+				stageSearch:  {Provider: llm.ProviderLocal, Model: "llama3.1", BaseURL: "http://localhost:8080/v1"},
+				stageSummary: DefaultStages[stageSummary],
+				stageDraft:   {Provider: llm.ProviderAnthropic, Model: "claude-sonnet-4-5"},
+				stageRefine:  DefaultStages[stageRefine],
+			},
+		},
+		{name: "異常系: 不明な段階", content: `{"review": {"provider": "openai", "model": "gpt-4o"}}`, wantErr: true},
+		{name: "異常系: 不明なプロバイダー", content: `{"draft": {"provider": "gemini", "model": "x"}}`, wantErr: true},
+		{name: "異常系: JSONが不正", content: `{`, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			path := ""
+			if tt.content != "" {
+				path = filepath.Join(t.TempDir(), "stages.json")
+				if err := os.WriteFile(path, []byte(tt.content), 0o600); err != nil {
+					t.Fatal(err)
+				}
+			}
+			got, err := loadStages(path)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("loadStages() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			if len(got) != len(tt.want) {
+				t.Fatalf("loadStages() = %v, want %v", got, tt.want)
+			}
+			for name, stage := range tt.want {
+				if got[name] != stage {
+					t.Errorf("stage %s = %+v, want %+v", name, got[name], stage)
+				}
+			}
+		})
+	}
+}
+
+// useFakeGenerators は段階ごとのフェイクの Generator を使うように差し替えます。
+func useFakeGenerators(t *testing.T, fakes map[string]*llm.Fake) {
+	t.Helper()
+
+	t.Setenv("OPENAI_API_KEY", "test")
+	t.Setenv("HATENA_API_KEY", "test")
+	original := newStageGenerator
+	newStageGenerator = func(_ *Config, stage string) (llm.Generator, error) {
+		if fake, ok := fakes[stage]; ok {
+			return fake, nil
+		}
+		return &llm.Fake{}, nil
+	}
+	t.Cleanup(func() { newStageGenerator = original })
+}
+
+func TestGeneratePostByArticles(t *testing.T) {
+	now := time.Date(2025, 5, 2, 9, 0, 0, 0, time.UTC)
+	body := "<section><h2>新シーズン開幕</h2><p class='date'>公開日：2025-05-01</p><p>" + strings.Repeat("新しい武器が追加されました！", 80) + "</p><a href='https://example.com/1'>情報源</a></section>"
+	draft, _ := json.Marshal(ContentJson{Title: "下書き", Content: "<section>下書き</section>"})
+	refined, _ := json.Marshal(ContentJson{Title: "新シーズン開幕まとめ", Content: body})
+
+	drafter := &llm.Fake{Responses: []string{string(draft)}}
+	refiner := &llm.Fake{Responses: []string{"```json\n" + string(refined) + "\n```"}}
+	useFakeGenerators(t, map[string]*llm.Fake{stageDraft: drafter, stageRefine: refiner})
+
+	sources := []groundingSource{{Title: "新シーズン開幕", Links: []string{"https://example.com/1"}, PubDate: time.Date(2025, 5, 1, 3, 0, 0, 0, time.UTC)}}
+	record := &runRecord{}
+	title, content, err := generatePostByArticles("新シーズン開幕: https://example.com/1, 要約", sources, now, record)
+	if err != nil {
+		t.Fatalf("generatePostByArticles() error = %v", err)
+	}
+	if title != "【2025/05/02】新シーズン開幕まとめ" {
+		t.Errorf("title = %q", title)
+	}
+	if !strings.Contains(content, "新シーズン開幕") {
+		t.Errorf("content does not contain the refined post: %q", content)
+	}
+	if record.Attempts != 1 || record.Grounding.Checked != 1 || len(record.Grounding.Removed) != 0 {
+		t.Errorf("record = %+v", record)
+	}
+	if requests := drafter.Requests(); len(requests) != 1 || !strings.Contains(requests[0].Prompt, "https://example.com/1") {
+		t.Errorf("draft requests = %+v", requests)
+	}
+	if requests := refiner.Requests(); len(requests) != 1 || !strings.Contains(requests[0].Prompt, "<section>下書き</section>") {
+		t.Errorf("refine requests = %+v", requests)
+	}
+}
+
+func TestGetSummariesWebSearchUnsupported(t *testing.T) {
+	searcher := &llm.Fake{Err: llm.ErrWebSearchUnsupported}
+	useFakeGenerators(t, map[string]*llm.Fake{stageSearch: searcher})
+	// The invalid link fails before any request, so the article falls back to the search stage
+	summaries, sources, err := getSummaries([]Article{{Title: "新シーズン", Link: "://invalid"}}, 5, time.Now())
+	if err != nil || summaries != "" || len(sources) != 0 {
+		t.Errorf("getSummaries() = %q, %v, %v, want empty", summaries, sources, err)
+	}
+	if len(searcher.Requests()) != 1 || !searcher.Requests()[0].WebSearch {
+		t.Errorf("search requests = %+v", searcher.Requests())
+	}
+}
@@ -5,6 +5,9 @@ go 1.24.3
 require (
 	github.com/GoogleCloudPlatform/functions-framework-go v1.9.2
 	github.com/openai/openai-go v0.1.0-beta.10
+	golang.org/x/net v0.50.0
+	golang.org/x/text v0.34.0
+	thiroyoshi.com/video-converter v0.0.0-00010101000000-000000000000
 )
 
 require (
@@ -22,3 +25,6 @@ require (
 	go.uber.org/multierr v1.1.0 // indirect
 	go.uber.org/zap v1.10.0 // indirect
 )
+
+// Packages shared with the video converter live in its module
+replace thiroyoshi.com/video-converter => ../video-converter
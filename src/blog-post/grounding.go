@@ -0,0 +1,253 @@
+package blogpost
+
+import (
+	"fmt"
+	"log/slog"
+	"strconv"
+	"strings"
+	"time"
+
+	"golang.org/x/net/html"
+	"golang.org/x/net/html/atom"
+	"thiroyoshi.com/blog-post/history"
+)
+
+// postWindowDays is how many days before the post date a topic may have been published
+const postWindowDays = 3
+
+// Prompt for rewriting a topic whose links or date are not in the sources
+var regroundPrompt = `
+	以下のブログ記事のトピックには、情報源一覧にないリンクや、情報源と異なる公開日が含まれています。
+
+	【問題点】
+	%s
+
+	【トピック】
+	%s
+
+	【情報源一覧】
+	%s
+
+	情報源一覧のうち、このトピックの内容に合う情報源のリンクと公開日だけを使って、トピックを書き直してください。
+	・出力は<section>タグで囲んだHTMLのみとし、<h2>見出し</h2>、<p class='date'>公開日：YYYY-MM-DD</p>、<p>本文内容</p>、<a href>情報源タイトル</a> の順で記載する
+	・公開日は %s から %s の間とする
+	・合う情報源がない場合は何も出力しない
+	`
+
+// groundingSource は要約した記事の、引用してよいリンクと公開日です。
+type groundingSource struct {
+	Title string
+	// Links are the link from the feed and the URL cited in the summary, which differ when the article page was fetched
+	Links   []string
+	PubDate time.Time
+}
+
+// groundingReport は情報源との照合の結果です。
+type groundingReport struct {
+	// Checked is the number of sections checked
+	Checked int
+	// Regenerated are the sections rewritten with the sources, with the problems found
+	Regenerated []string
+	// Removed are the sections removed, with the problems found
+	Removed []string
+	// Unlinked are the links outside the sections that are not in the sources, whose href was removed
+	Unlinked []string
+}
+
+// grounding は記事本文のリンクと公開日を、要約した記事と照合します。
+type grounding struct {
+	sources []groundingSource
+	// links maps the canonical URL of every source link to its index in sources
+	links    map[string]int
+	from, to string
+	location *time.Location
+}
+
+func newGrounding(sources []groundingSource, now time.Time) *grounding {
+	g := &grounding{
+		sources:  sources,
+		links:    map[string]int{},
+		from:     now.AddDate(0, 0, -postWindowDays).Format("2006-01-02"),
+		to:       now.Format("2006-01-02"),
+		location: now.Location(),
+	}
+	for i, source := range sources {
+		for _, link := range source.Links {
+			if link != "" {
+				g.links[history.CanonicalURL(link)] = i
+			}
+		}
+	}
+	return g
+}
+
+// sourceList は書き直しのプロンプトに載せる情報源の一覧です。
+func (g *grounding) sourceList() string {
+	var lines []string
+	for _, source := range g.sources {
+		link := source.Links[len(source.Links)-1]
+		lines = append(lines, fmt.Sprintf("・%s（公開日：%s）: %s", source.Title, source.PubDate.In(g.location).Format("2006-01-02"), link))
+	}
+	return strings.Join(lines, "\n\t")
+}
+
+// checkSection は<section>のリンクと公開日を確認し、問題点を返します。
+// すべてのリンクが情報源のリンクと一致し、公開日が期間内で情報源の公開日と同じ日である必要があります。
+// 情報源の公開日はフィードの時刻のため、投稿の地域の日付とUTCの日付のどちらかと一致すればよいとします。
+func (g *grounding) checkSection(n *html.Node) []string {
+	var problems []string
+	source := -1
+	for _, link := range sectionLinks(n) {
+		i, ok := g.links[history.CanonicalURL(link)]
+		if !ok {
+			problems = append(problems, fmt.Sprintf("リンク %s が情報源にない", link))
+			continue
+		}
+		if source < 0 {
+			source = i
+		}
+	}
+
+	m := articleDatePattern.FindStringSubmatch(newSection(n).Date)
+	if m == nil {
+		return append(problems, "公開日がない")
+	}
+	month, _ := strconv.Atoi(m[2])
+	day, _ := strconv.Atoi(m[3])
+	date := fmt.Sprintf("%s-%02d-%02d", m[1], month, day)
+	if date < g.from || date > g.to {
+		problems = append(problems, fmt.Sprintf("公開日 %s が %s から %s の間にない", date, g.from, g.to))
+	}
+	if source >= 0 && !g.sources[source].PubDate.IsZero() {
+		pubDate := g.sources[source].PubDate
+		local, utc := pubDate.In(g.location).Format("2006-01-02"), pubDate.UTC().Format("2006-01-02")
+		if date != local && date != utc {
+			problems = append(problems, fmt.Sprintf("公開日 %s が情報源の公開日 %s と違う", date, local))
+		}
+	}
+	return problems
+}
+
+// sectionLinks は要素の中のリンクのURLを返します。
+func sectionLinks(n *html.Node) []string {
+	var links []string
+	var walk func(c *html.Node)
+	walk = func(c *html.Node) {
+		if c.Type == html.ElementNode && c.DataAtom == atom.A {
+			if href := attr(c, "href"); href != "" {
+				links = append(links, href)
+			}
+		}
+		for child := c.FirstChild; child != nil; child = child.NextSibling {
+			walk(child)
+		}
+	}
+	walk(n)
+	return links
+}
+
+// groundContent は記事本文の<section>を情報源と照合します。問題のある<section>は regenerate で書き直し、
+// 書き直しても問題が残る場合は取り除きます。regenerate は<section>と問題点を受け取り、書き直した<section>を返します。
+// <section>の外のリンクが情報源にない場合はリンクを外します。照合できる<section>が残らない場合は ContentError を返します。
+func groundContent(content string, g *grounding, regenerate func(section string, problems []string) (string, error), report *groundingReport) (string, error) {
+	body, err := parseFragment(content)
+	if err != nil {
+		return "", err
+	}
+
+	sections := sectionNodes(body)
+	valid := 0
+	for i, n := range sections {
+		report.Checked++
+		problems := g.checkSection(n)
+		if len(problems) == 0 {
+			valid++
+			continue
+		}
+		label := fmt.Sprintf("section %d (%s): %s", i+1, newSection(n).Heading, strings.Join(problems, "、"))
+
+		if replacement := regenerateSection(n, problems, g, regenerate); replacement != nil {
+			n.Parent.InsertBefore(replacement, n)
+			n.Parent.RemoveChild(n)
+			report.Regenerated = append(report.Regenerated, label)
+			valid++
+			continue
+		}
+		n.Parent.RemoveChild(n)
+		report.Removed = append(report.Removed, label)
+	}
+	if valid == 0 {
+		return "", &ContentError{Problems: append([]string{"情報源と一致する<section>がない"}, report.Removed...)}
+	}
+
+	// Links outside the sections, such as in the closing summary, cannot be regenerated
+	var unlink func(n *html.Node)
+	unlink = func(n *html.Node) {
+		if n.Type == html.ElementNode && n.DataAtom == atom.Section {
+			return
+		}
+		if n.Type == html.ElementNode && n.DataAtom == atom.A {
+			if href := attr(n, "href"); href != "" {
+				if _, ok := g.links[history.CanonicalURL(href)]; !ok {
+					n.Attr = removeAttr(n.Attr, "href")
+					report.Unlinked = append(report.Unlinked, href)
+				}
+			}
+		}
+		for c := n.FirstChild; c != nil; c = c.NextSibling {
+			unlink(c)
+		}
+	}
+	unlink(body)
+
+	return renderChildren(body)
+}
+
+// regenerateSection は<section>を書き直し、整えて照合し直します。問題が残る場合は nil を返します。
+func regenerateSection(n *html.Node, problems []string, g *grounding, regenerate func(string, []string) (string, error)) *html.Node {
+	if regenerate == nil {
+		return nil
+	}
+	var original strings.Builder
+	if err := html.Render(&original, n); err != nil {
+		return nil
+	}
+	resp, err := regenerate(original.String(), problems)
+	if err != nil {
+		slog.Warn("Failed to regenerate section", "error", err)
+		return nil
+	}
+	start, end := strings.Index(resp, "<section"), strings.LastIndex(resp, "</section>")
+	if start < 0 || end < start {
+		return nil
+	}
+	sanitized, _, err := sanitizeContent(resp[start : end+len("</section>")])
+	if err != nil {
+		return nil
+	}
+	body, err := parseFragment(sanitized)
+	if err != nil {
+		return nil
+	}
+	sections := sectionNodes(body)
+	if len(sections) != 1 || len(g.checkSection(sections[0])) > 0 {
+		return nil
+	}
+	sections[0].Parent.RemoveChild(sections[0])
+	return sections[0]
+}
+
+func removeAttr(attrs []html.Attribute, key string) []html.Attribute {
+	var kept []html.Attribute
+	for _, a := range attrs {
+		if a.Key != key {
+			kept = append(kept, a)
+		}
+	}
+	return kept
+}
+
+// regroundMessage は<section>を情報源で書き直すプロンプトを作成します。
+func regroundMessage(section string, problems []string, g *grounding) string {
+	return fmt.Sprintf(regroundPrompt, "・"+strings.Join(problems, "\n\t・"), section, g.sourceList(), g.from, g.to)
+}
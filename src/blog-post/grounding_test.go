@@ -0,0 +1,111 @@
+package blogpost
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestGroundContent(t *testing.T) {
+	t.Parallel()
+
+	now := time.Date(2025, 5, 2, 9, 0, 0, 0, time.UTC)
+	sources := []groundingSource{
+		{Title: "新シーズン開幕", Links: []string{"https://news.google.com/rss/articles/abc", "https://example.com/1"}, PubDate: time.Date(2025, 5, 1, 3, 0, 0, 0, time.UTC)},
+		{Title: "コラボ決定", Links: []string{"https://example.com/2"}, PubDate: time.Date(2025, 4, 30, 3, 0, 0, 0, time.UTC)},
+	}
+	collab := `<section><h2>コラボ決定</h2><p class="date">公開日：2025-04-30</p><p>コラボが決定しました！</p><a href="https://example.com/2">情報源</a></section>`
+	summary := `<h2>まとめ</h2><p>今週も盛りだくさんでした！</p>`
+
+	tests := []struct {
+		name            string
+		content         string
+		regenerated     string
+		want            string
+		wantRegenerated int
+		wantRemoved     int
+		wantUnlinked    int
+		wantErr         bool
+	}{
+		{
+			name:    "正常系: 情報源と一致する",
+			content: validSection + collab + summary,
+			want:    validSection + collab + summary,
+		},
+		{
+			name:    "正常系: 正規化すると一致するリンク",
+			content: strings.Replace(validSection, "https://example.com/1", "http://www.example.com/1?utm_source=x", 1),
+			want:    `<section><h2>新シーズン開幕</h2><p class="date">公開日：2025-05-01</p><p>新しい武器が追加されました！</p><a href="http://www.example.com/1?utm_source=x">情報源</a></section>`,
+		},
+		{
+			name:            "正常系: 情報源にないリンクを書き直す",
+			content:         strings.Replace(validSection, "https://example.com/1", "https://example.com/made-up", 1) + collab,
+			regenerated:     "書き直しました。\n" + validSection,
+			want:            validSection + collab,
+			wantRegenerated: 1,
+		},
+		{
+			name:        "正常系: 書き直しても直らない<section>を取り除く",
+			content:     strings.Replace(validSection, "2025-05-01", "2025-04-20", 1) + collab,
+			regenerated: strings.Replace(validSection, "2025-05-01", "2025-04-29", 1),
+			want:        collab,
+			wantRemoved: 1,
+		},
+		{
+			name:        "正常系: 情報源と公開日が違う<section>を取り除く",
+			content:     validSection + strings.Replace(collab, "2025-04-30", "2025-05-02", 1),
+			want:        validSection,
+			wantRemoved: 1,
+		},
+		{
+			name:         "正常系: まとめの情報源にないリンクを外す",
+			content:      validSection + `<p>詳しくは<a href="https://x.com/FortniteGame">公式</a>へ！</p>`,
+			want:         validSection + `<p>詳しくは<a>公式</a>へ！</p>`,
+			wantUnlinked: 1,
+		},
+		{
+			name:        "異常系: 情報源と一致する<section>がない",
+			content:     strings.Replace(validSection, "https://example.com/1", "https://example.com/made-up", 1) + summary,
+			wantRemoved: 1,
+			wantErr:     true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			g := newGrounding(sources, now)
+			var prompts []string
+			regenerate := func(section string, problems []string) (string, error) {
+				prompts = append(prompts, regroundMessage(section, problems, g))
+				if tt.regenerated == "" {
+					return "", errors.New("no response")
+				}
+				return tt.regenerated, nil
+			}
+
+			var report groundingReport
+			got, err := groundContent(tt.content, g, regenerate, &report)
+			if tt.wantErr {
+				var contentErr *ContentError
+				if !errors.As(err, &contentErr) {
+					t.Fatalf("groundContent() error = %v, want ContentError", err)
+				}
+			} else if err != nil {
+				t.Fatalf("groundContent() error = %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("groundContent() =\n%s\nwant\n%s", got, tt.want)
+			}
+			if len(report.Regenerated) != tt.wantRegenerated || len(report.Removed) != tt.wantRemoved || len(report.Unlinked) != tt.wantUnlinked {
+				t.Errorf("report = %+v", report)
+			}
+			for _, prompt := range prompts {
+				if !strings.Contains(prompt, "・新シーズン開幕（公開日：2025-05-01）: https://example.com/1") {
+					t.Errorf("prompt does not list the sources: %s", prompt)
+				}
+			}
+		})
+	}
+}
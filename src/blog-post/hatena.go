@@ -4,10 +4,14 @@ import (
 	"bytes"
 	"encoding/xml"
 	"fmt"
+	"html"
 	"io"
 	"log/slog"
 	"net/http"
+	"regexp"
 	"time"
+
+	"thiroyoshi.com/video-converter/disclosure"
 )
 
 // XML structure to send to AtomPub API
@@ -45,6 +49,11 @@ func post(title, content string) (string, error) {
 		return "", fmt.Errorf("failed to load config: %v", err)
 	}
 
+	// Never publish affiliate links without the advertising disclosure
+	if err := loadDisclosureRules(config).Check(disclosure.MediumHatena, content); err != nil {
+		return "", err
+	}
+
 	// Hatena Blog API endpoint
 	endpoint := fmt.Sprintf("https://blog.hatena.ne.jp/%s/%s/atom/entry", config.HatenaId, config.HatenaBlogId)
 
@@ -130,3 +139,38 @@ func post(title, content string) (string, error) {
 	slog.Info("Article published", "url", entryURL)
 	return entryURL, nil
 }
+
+var ogImagePattern = regexp.MustCompile(`<meta[^>]+property=["']og:image["'][^>]+content=["']([^"']+)["']`)
+
+// getEyecatchURL は公開された記事のページからアイキャッチ画像（og:image）のURLを取得します
+func getEyecatchURL(entryURL string, httpClient HTTPClient) (string, error) {
+	if httpClient == nil {
+		httpClient = defaultHTTPClient
+	}
+
+	resp, err := httpClient.Get(entryURL)
+	if err != nil {
+		return "", fmt.Errorf("failed to get article page: %v", err)
+	}
+	defer func() {
+		if cerr := resp.Body.Close(); cerr != nil {
+			slog.Error("Failed to close response body", "error", cerr)
+		}
+	}()
+
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("failed to get article page: status code %d", resp.StatusCode)
+	}
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return "", fmt.Errorf("failed to read article page: %v", err)
+	}
+
+	match := ogImagePattern.FindSubmatch(body)
+	if match == nil {
+		return "", fmt.Errorf("og:image not found in article page")
+	}
+
+	return html.UnescapeString(string(match[1])), nil
+}
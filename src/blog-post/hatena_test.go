@@ -0,0 +1,58 @@
+package blogpost
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestGetEyecatchURL(t *testing.T) {
+	t.Parallel()
+
+	cases := []struct {
+		name    string
+		status  int
+		html    string
+		want    string
+		wantErr bool
+	}{
+		{
+			name:   "正常系: og:imageがある場合",
+			status: http.StatusOK,
+			html:   `<html><head><meta property="og:image" content="https://cdn-ak.f.st-hatena.com/images/a.jpg?x=1&amp;y=2" /></head></html>`,
+			want:   "https://cdn-ak.f.st-hatena.com/images/a.jpg?x=1&y=2",
+		},
+		{
+			name:    "異常系: og:imageがない場合",
+			status:  http.StatusOK,
+			html:    `<html><head></head></html>`,
+			wantErr: true,
+		},
+		{
+			name:    "異常系: ページが取得できない場合",
+			status:  http.StatusNotFound,
+			wantErr: true,
+		},
+	}
+
+	for _, tc := range cases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(tc.status)
+				_, _ = w.Write([]byte(tc.html))
+			}))
+			defer server.Close()
+
+			got, err := getEyecatchURL(server.URL, server.Client())
+			if (err != nil) != tc.wantErr {
+				t.Fatalf("getEyecatchURL() error = %v, wantErr %v", err, tc.wantErr)
+			}
+			if got != tc.want {
+				t.Errorf("getEyecatchURL() = %q, want %q", got, tc.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,204 @@
+// Package history は過去のブログ記事で扱ったトピックを記録し、同じニュースを続けて記事にしないようにします。
+// 記事の投稿は月・水・金で、RSSの期間は7日のため、記録がないと続けて投稿する記事のトピックが重なります。
+package history
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"slices"
+	"strings"
+	"time"
+	"unicode"
+	"unicode/utf8"
+
+	"thiroyoshi.com/blog-post/storage"
+)
+
+// Name is the name the history is saved under
+const Name = "article-history"
+
+// Maximum length in runes of a " - 媒体名" suffix removed from titles
+const maxSiteNameLength = 30
+
+// Entry は記事で扱ったトピックです。
+type Entry struct {
+	// Title は情報源の記事のタイトルです
+	Title string `json:"title"`
+	// Heading はブログ記事のトピックの見出しです
+	Heading string `json:"heading,omitempty"`
+	// URL は情報源の正規化したURLです
+	URL string `json:"url"`
+	// FeedURL はフィードにあった情報源のリンクを正規化したURLです。記事のページを取得した場合は URL と異なります
+	FeedURL   string    `json:"feed_url,omitempty"`
+	CoveredAt time.Time `json:"covered_at"`
+}
+
+// History は記事で扱ったトピックの一覧です。
+type History struct {
+	Entries []Entry `json:"entries"`
+}
+
+// Add はトピックを記録します。URLとフィードのリンクは正規化して記録し、同じ場合はフィードのリンクを省きます。
+func (h *History) Add(title, heading, rawURL, feedURL string, at time.Time) {
+	entry := Entry{Title: title, Heading: heading, URL: CanonicalURL(rawURL), CoveredAt: at}
+	if feed := CanonicalURL(feedURL); feed != entry.URL {
+		entry.FeedURL = feed
+	}
+	h.Entries = append(h.Entries, entry)
+}
+
+// Prune は before より前に記録したトピックを削除します。
+func (h *History) Prune(before time.Time) {
+	h.Entries = slices.DeleteFunc(h.Entries, func(e Entry) bool { return e.CoveredAt.Before(before) })
+}
+
+// Match は記事に最も近い記録済みのトピックと、その近さ（0〜1）を返します。
+// 正規化したURLが記録したURLまたはフィードのリンクと同じ場合は1、それ以外はタイトルと、記録したタイトルまたは見出しの類似度です。
+func (h *History) Match(title, rawURL string) (Entry, float64) {
+	canonical := CanonicalURL(rawURL)
+	var best Entry
+	bestScore := 0.0
+	for _, e := range h.Entries {
+		score := max(Similarity(title, e.Title), Similarity(title, e.Heading))
+		if canonical != "" && (canonical == e.URL || canonical == e.FeedURL) {
+			score = 1
+		}
+		if score > bestScore {
+			best, bestScore = e, score
+		}
+	}
+	return best, bestScore
+}
+
+// Backend は記録を保存するオブジェクトの保存先です。storage.Bucket と storage.Dir が実装します。
+// Get はオブジェクトがない場合に storage.ErrNotFound を返します。
+type Backend interface {
+	Get(name string) ([]byte, error)
+	Put(name string, value []byte) error
+}
+
+// Store は記録を Backend に保存します。
+type Store struct {
+	backend Backend
+}
+
+// NewStore は記録の保存先を作ります。
+func NewStore(backend Backend) *Store {
+	return &Store{backend: backend}
+}
+
+// Load は記録を読み込みます。まだ記録がない場合は空の記録を返します。
+func (s *Store) Load() (*History, error) {
+	data, err := s.backend.Get(Name)
+	if errors.Is(err, storage.ErrNotFound) {
+		return &History{}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to load article history: %w", err)
+	}
+	var h History
+	if err := json.Unmarshal(data, &h); err != nil {
+		return nil, fmt.Errorf("failed to parse article history: %w", err)
+	}
+	return &h, nil
+}
+
+// Save は記録を保存します。
+func (s *Store) Save(h *History) error {
+	data, err := json.Marshal(h)
+	if err != nil {
+		return err
+	}
+	if err := s.backend.Put(Name, data); err != nil {
+		return fmt.Errorf("failed to save article history: %w", err)
+	}
+	return nil
+}
+
+// NormalizeTitle は比較のためにタイトルを正規化します。
+// 全角英数字を半角にして小文字にし、末尾の「 - 媒体名」と、文字と数字以外を除きます。
+func NormalizeTitle(title string) string {
+	title = strings.TrimSpace(title)
+	for _, sep := range []string{" - ", " | ", "｜"} {
+		if i := strings.LastIndex(title, sep); i > 0 && utf8.RuneCountInString(title[i+len(sep):]) <= maxSiteNameLength {
+			title = title[:i]
+			break
+		}
+	}
+	var b strings.Builder
+	for _, r := range title {
+		// Full-width ASCII variants
+		if r >= 0xFF01 && r <= 0xFF5E {
+			r -= 0xFEE0
+		}
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			b.WriteRune(unicode.ToLower(r))
+		}
+	}
+	return b.String()
+}
+
+// Similarity は正規化したタイトルの文字bigramのDice係数（0〜1）を返します。
+func Similarity(a, b string) float64 {
+	x, y := bigrams(NormalizeTitle(a)), bigrams(NormalizeTitle(b))
+	if len(x) == 0 || len(y) == 0 {
+		return 0
+	}
+	common := 0
+	for gram, n := range x {
+		common += min(n, y[gram])
+	}
+	total := 0
+	for _, n := range x {
+		total += n
+	}
+	for _, n := range y {
+		total += n
+	}
+	return 2 * float64(common) / float64(total)
+}
+
+func bigrams(s string) map[string]int {
+	runes := []rune(s)
+	grams := map[string]int{}
+	if len(runes) == 1 {
+		grams[s]++
+	}
+	for i := 0; i+1 < len(runes); i++ {
+		grams[string(runes[i:i+2])]++
+	}
+	return grams
+}
+
+// trackingParams are query parameters that do not change the page
+var trackingParams = []string{"fbclid", "gclid", "ref", "ref_src", "ocid", "cmpid", "spm", "from", "guccounter"}
+
+// CanonicalURL は比較のためにURLを正規化します。
+// スキームを https に、ホストを小文字にして www. と amp. を除き、計測用のクエリパラメータ、フラグメント、AMPのパスと末尾の / を除きます。
+func CanonicalURL(rawURL string) string {
+	u, err := url.Parse(strings.TrimSpace(rawURL))
+	if err != nil || u.Host == "" {
+		return strings.TrimSpace(rawURL)
+	}
+	u.Scheme = "https"
+	u.Host = strings.ToLower(u.Host)
+	for _, prefix := range []string{"www.", "amp.", "m."} {
+		u.Host = strings.TrimPrefix(u.Host, prefix)
+	}
+	u.Fragment = ""
+
+	query := u.Query()
+	for key := range query {
+		if strings.HasPrefix(key, "utm_") || slices.Contains(trackingParams, key) {
+			query.Del(key)
+		}
+	}
+	u.RawQuery = query.Encode()
+
+	u.Path = strings.TrimSuffix(u.Path, "/")
+	u.Path = strings.TrimSuffix(u.Path, "/amp")
+	u.RawPath = ""
+	return u.String()
+}
@@ -0,0 +1,112 @@
+package history
+
+import (
+	"testing"
+	"time"
+
+	"thiroyoshi.com/blog-post/storage"
+)
+
+var base = time.Date(2025, 5, 1, 12, 0, 0, 0, time.UTC)
+
+func TestNormalizeTitle(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		title string
+		want  string
+	}{
+		{title: "【フォートナイト】新シーズン「Ｃ６Ｓ３」開幕！ - ゲームメディア", want: "フォートナイト新シーズンc6s3開幕"},
+		{title: "Fortnite v35.10 Patch Notes | Epic Games", want: "fortnitev3510patchnotes"},
+		// A long text after " - " is part of the title, not a site name
+		{title: "Fortnite - the new season brings big changes to the whole map", want: "fortnitethenewseasonbringsbigchangestothewholemap"},
+	}
+	for _, tt := range tests {
+		if got := NormalizeTitle(tt.title); got != tt.want {
+			t.Errorf("NormalizeTitle(%q) = %q, want %q", tt.title, got, tt.want)
+		}
+	}
+}
+
+func TestCanonicalURL(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		url  string
+		want string
+	}{
+		{url: "http://www.Example.com/news/1/?utm_source=x&id=3#top", want: "https://example.com/news/1?id=3"},
+		{url: "https://amp.example.com/news/1/amp/", want: "https://example.com/news/1"},
+		{url: "https://example.com/news/1?fbclid=abc", want: "https://example.com/news/1"},
+		{url: "not a url", want: "not a url"},
+	}
+	for _, tt := range tests {
+		if got := CanonicalURL(tt.url); got != tt.want {
+			t.Errorf("CanonicalURL(%q) = %q, want %q", tt.url, got, tt.want)
+		}
+	}
+}
+
+func TestMatch(t *testing.T) {
+	t.Parallel()
+
+	h := &History{}
+	h.Add("フォートナイト新シーズン開幕、新武器とマップ変更まとめ - ゲームメディア", "新シーズン開幕！", "https://media.example.com/news/1?utm_source=rss", "https://news.google.com/rss/articles/abc?oc=5", base)
+
+	tests := []struct {
+		name     string
+		title    string
+		url      string
+		minScore float64
+		maxScore float64
+	}{
+		{name: "正常系: URLが同じ", title: "別のタイトル", url: "http://www.media.example.com/news/1", minScore: 1, maxScore: 1},
+		{name: "正常系: フィードのリンクが同じ", title: "別のタイトル", url: "https://news.google.com/rss/articles/abc?oc=5", minScore: 1, maxScore: 1},
+		{name: "正常系: 同じニュースの別の媒体", title: "フォートナイト新シーズン開幕！新武器とマップ変更まとめ - 別メディア", url: "https://other.example.com/a", minScore: 0.8, maxScore: 1},
+		{name: "正常系: 見出しに近い", title: "新シーズン開幕", url: "https://other.example.com/b", minScore: 0.8, maxScore: 1},
+		{name: "正常系: 別のニュース", title: "人気アニメとのコラボスキンが登場", url: "https://other.example.com/c", minScore: 0, maxScore: 0.2},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			_, score := h.Match(tt.title, tt.url)
+			if score < tt.minScore || score > tt.maxScore {
+				t.Errorf("Match() score = %.2f, want %.2f-%.2f", score, tt.minScore, tt.maxScore)
+			}
+		})
+	}
+}
+
+func TestPrune(t *testing.T) {
+	t.Parallel()
+
+	h := &History{}
+	h.Add("old", "", "https://example.com/old", "", base.AddDate(0, 0, -40))
+	h.Add("new", "", "https://example.com/new", "", base)
+	h.Prune(base.AddDate(0, 0, -30))
+	if len(h.Entries) != 1 || h.Entries[0].Title != "new" {
+		t.Errorf("Prune() = %+v", h.Entries)
+	}
+}
+
+func TestStore(t *testing.T) {
+	t.Parallel()
+
+	store := NewStore(storage.NewDir(t.TempDir()))
+	h, err := store.Load()
+	if err != nil || len(h.Entries) != 0 {
+		t.Fatalf("Load() = %+v, %v", h, err)
+	}
+	h.Add("title", "heading", "https://example.com/1", "https://news.google.com/rss/articles/1", base)
+	if err := store.Save(h); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+	loaded, err := store.Load()
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if len(loaded.Entries) != 1 || loaded.Entries[0] != h.Entries[0] {
+		t.Errorf("Load() = %+v, want %+v", loaded.Entries, h.Entries)
+	}
+}
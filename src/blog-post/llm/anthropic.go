@@ -0,0 +1,155 @@
+package llm
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"strings"
+	"time"
+)
+
+const (
+	// DefaultAnthropicBaseURL is the Anthropic API
+	DefaultAnthropicBaseURL = "https://api.anthropic.com"
+	anthropicVersion        = "2023-06-01"
+	// anthropicMaxTokens is enough for a whole blog post in Japanese
+	anthropicMaxTokens = 8192
+	// anthropicMaxSearches limits the web searches per request
+	anthropicMaxSearches = 5
+)
+
+// Anthropic はAnthropicのMessages APIで生成します。
+type Anthropic struct {
+	httpClient *http.Client
+	baseURL    string
+	apiKey     string
+	model      string
+}
+
+// NewAnthropic はAnthropicの Generator を作成します。baseURL が空の場合は DefaultAnthropicBaseURL を使います。
+func NewAnthropic(apiKey, baseURL, model string) *Anthropic {
+	if baseURL == "" {
+		baseURL = DefaultAnthropicBaseURL
+	}
+	return &Anthropic{
+		httpClient: &http.Client{Timeout: 5 * time.Minute},
+		baseURL:    strings.TrimRight(baseURL, "/"),
+		apiKey:     apiKey,
+		model:      model,
+	}
+}
+
+type anthropicMessage struct {
+	Role    string `json:"role"`
+	Content string `json:"content"`
+}
+
+type anthropicTool struct {
+	Type        string         `json:"type,omitempty"`
+	Name        string         `json:"name"`
+	Description string         `json:"description,omitempty"`
+	InputSchema map[string]any `json:"input_schema,omitempty"`
+	MaxUses     int            `json:"max_uses,omitempty"`
+}
+
+type anthropicToolChoice struct {
+	Type string `json:"type"`
+	Name string `json:"name"`
+}
+
+type anthropicRequest struct {
+	Model      string               `json:"model"`
+	MaxTokens  int                  `json:"max_tokens"`
+	System     string               `json:"system,omitempty"`
+	Messages   []anthropicMessage   `json:"messages"`
+	Tools      []anthropicTool      `json:"tools,omitempty"`
+	ToolChoice *anthropicToolChoice `json:"tool_choice,omitempty"`
+}
+
+type anthropicResponse struct {
+	Content []struct {
+		Type  string          `json:"type"`
+		Text  string          `json:"text"`
+		Name  string          `json:"name"`
+		Input json.RawMessage `json:"input"`
+	} `json:"content"`
+	StopReason string `json:"stop_reason"`
+	Error      *struct {
+		Type    string `json:"type"`
+		Message string `json:"message"`
+	} `json:"error"`
+}
+
+// Generate はテキストを生成します。Web検索はサーバー側の web_search ツールを使います。
+// 検索結果の引用などテキスト以外のブロックは除き、テキストのブロックをつないで返します。
+// Schema を指定した場合は Schema を入力の形式とするツールを必ず呼ばせ、その入力のJSONを返します。
+func (g *Anthropic) Generate(ctx context.Context, req Request) (string, error) {
+	body := anthropicRequest{
+		Model:     g.model,
+		MaxTokens: anthropicMaxTokens,
+		System:    req.System,
+		Messages:  []anthropicMessage{{Role: "user", Content: req.Prompt}},
+	}
+	if req.WebSearch {
+		body.Tools = []anthropicTool{{Type: "web_search_20250305", Name: "web_search", MaxUses: anthropicMaxSearches}}
+	}
+	if req.Schema != nil {
+		body.Tools = append(body.Tools, anthropicTool{Name: req.Schema.Name, Description: req.Schema.Description, InputSchema: req.Schema.JSON})
+		body.ToolChoice = &anthropicToolChoice{Type: "tool", Name: req.Schema.Name}
+	}
+	payload, err := json.Marshal(body)
+	if err != nil {
+		return "", err
+	}
+
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodPost, g.baseURL+"/v1/messages", bytes.NewReader(payload))
+	if err != nil {
+		return "", err
+	}
+	httpReq.Header.Set("Content-Type", "application/json")
+	httpReq.Header.Set("x-api-key", g.apiKey)
+	httpReq.Header.Set("anthropic-version", anthropicVersion)
+
+	resp, err := g.httpClient.Do(httpReq)
+	if err != nil {
+		return "", fmt.Errorf("failed to request %s: %w", g.model, err)
+	}
+	defer func() {
+		if err := resp.Body.Close(); err != nil {
+			slog.Error("Failed to close response body", "error", err)
+		}
+	}()
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return "", err
+	}
+
+	var result anthropicResponse
+	if err := json.Unmarshal(data, &result); err != nil {
+		return "", fmt.Errorf("invalid anthropic response (status %d): %w", resp.StatusCode, err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		if result.Error != nil {
+			return "", fmt.Errorf("anthropic api returned %d: %s: %s", resp.StatusCode, result.Error.Type, result.Error.Message)
+		}
+		return "", fmt.Errorf("anthropic api returned %d", resp.StatusCode)
+	}
+
+	var text strings.Builder
+	for _, block := range result.Content {
+		if req.Schema != nil && block.Type == "tool_use" && block.Name == req.Schema.Name {
+			return string(block.Input), nil
+		}
+		if block.Type == "text" {
+			text.WriteString(block.Text)
+		}
+	}
+	if text.Len() == 0 {
+		return "", ErrEmptyResponse
+	}
+	return text.String(), nil
+}
@@ -0,0 +1,39 @@
+package llm
+
+import (
+	"context"
+	"sync"
+)
+
+// Fake は決まった応答を返す Generator です。テスト用で、設定のプロバイダーには指定できません。
+// Responses を順に返し、最後の応答の後は最後の応答を繰り返します。Responses が空の場合はプロンプトをそのまま返します。
+type Fake struct {
+	Responses []string
+	// Err is returned instead of a response when set
+	Err error
+
+	mu       sync.Mutex
+	requests []Request
+}
+
+// Generate は次の応答を返し、依頼を記録します。
+func (g *Fake) Generate(_ context.Context, req Request) (string, error) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	g.requests = append(g.requests, req)
+	if g.Err != nil {
+		return "", g.Err
+	}
+	if len(g.Responses) == 0 {
+		return req.Prompt, nil
+	}
+	return g.Responses[min(len(g.requests), len(g.Responses))-1], nil
+}
+
+// Requests はこれまでの依頼を返します。
+func (g *Fake) Requests() []Request {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	return append([]Request(nil), g.requests...)
+}
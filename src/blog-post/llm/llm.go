@@ -0,0 +1,102 @@
+// Package llm はテキスト生成のプロバイダー（OpenAI、Anthropic、OpenAI互換のローカルサーバー）を共通のインターフェースで扱います。
+package llm
+
+import (
+	"context"
+	"errors"
+	"fmt"
+)
+
+const (
+	ProviderOpenAI    = "openai"
+	ProviderAnthropic = "anthropic"
+	// ProviderLocal is any server with the OpenAI chat completions API, such as Ollama or llama.cpp
+	ProviderLocal = "local"
+
+	// DefaultLocalBaseURL is the OpenAI compatible endpoint of Ollama
+	DefaultLocalBaseURL = "http://localhost:11434/v1"
+)
+
+var (
+	// ErrWebSearchUnsupported はプロバイダーがWeb検索に対応していない場合のエラーです。
+	ErrWebSearchUnsupported = errors.New("web search is not supported by the provider")
+	// ErrEmptyResponse はモデルがテキストを返さなかった場合のエラーです。
+	ErrEmptyResponse = errors.New("model returned no text")
+)
+
+// Request はテキスト生成の依頼です。
+type Request struct {
+	// System はシステムプロンプトです。空の場合は送りません
+	System string
+	Prompt string
+	// WebSearch はモデルにWeb検索をさせます
+	WebSearch bool
+	// Schema はJSONで出力させる場合の形式です。対応していないプロバイダーでは使わないため、出力は呼び出し側で確認します
+	Schema *Schema
+}
+
+// Schema は構造化出力のJSON Schemaです。
+type Schema struct {
+	// Name は英数字・_・- の名前です
+	Name        string
+	Description string
+	// JSON is the JSON Schema object. Every property must be required and additionalProperties false for OpenAI's strict mode
+	JSON map[string]any
+}
+
+// Generator はプロンプトからテキストを生成します。
+type Generator interface {
+	Generate(ctx context.Context, req Request) (string, error)
+}
+
+// Stage は生成の段階で使うプロバイダーとモデルです。
+type Stage struct {
+	Provider string `json:"provider"`
+	Model    string `json:"model"`
+	// BaseURL はAPIのURLです。空の場合はプロバイダーの既定のURLを使います
+	BaseURL string `json:"base_url,omitempty"`
+}
+
+// Credentials はプロバイダーのAPIキーです。
+type Credentials struct {
+	OpenAIAPIKey    string
+	AnthropicAPIKey string
+	// LocalAPIKey is sent to the local server, which usually ignores it
+	LocalAPIKey string
+}
+
+// Validate はプロバイダーとモデルが指定されているかを確認します。
+func (s Stage) Validate() error {
+	switch s.Provider {
+	case ProviderOpenAI, ProviderAnthropic, ProviderLocal:
+		if s.Model == "" {
+			return fmt.Errorf("model is required for provider %q", s.Provider)
+		}
+	default:
+		return fmt.Errorf("unknown provider %q", s.Provider)
+	}
+	return nil
+}
+
+// New は段階の設定から Generator を作成します。
+func New(stage Stage, credentials Credentials) (Generator, error) {
+	if err := stage.Validate(); err != nil {
+		return nil, err
+	}
+	switch stage.Provider {
+	case ProviderOpenAI:
+		if credentials.OpenAIAPIKey == "" {
+			return nil, errors.New("openai api key is not configured")
+		}
+		return NewOpenAI(credentials.OpenAIAPIKey, stage.BaseURL, stage.Model), nil
+	case ProviderAnthropic:
+		if credentials.AnthropicAPIKey == "" {
+			return nil, errors.New("anthropic api key is not configured")
+		}
+		return NewAnthropic(credentials.AnthropicAPIKey, stage.BaseURL, stage.Model), nil
+	case ProviderLocal:
+		return NewLocal(credentials.LocalAPIKey, stage.BaseURL, stage.Model), nil
+	default:
+		return nil, fmt.Errorf("unknown provider %q", stage.Provider)
+	}
+}
@@ -0,0 +1,191 @@
+package llm
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestOpenAIGenerate(t *testing.T) {
+	t.Parallel()
+
+	var got map[string]any
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/v1/chat/completions" || r.Header.Get("Authorization") != "Bearer key" {
+			http.Error(w, "bad request", http.StatusBadRequest)
+			return
+		}
+		_ = json.NewDecoder(r.Body).Decode(&got)
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = fmt.Fprint(w, `{"id":"1","object":"chat.completion","created":0,"model":"gpt-4o","choices":[{"index":0,"finish_reason":"stop","message":{"role":"assistant","content":"要約です"}}]}`)
+	}))
+	defer server.Close()
+
+	text, err := NewOpenAI("key", server.URL+"/v1", "gpt-4o").Generate(context.Background(), Request{System: "system", Prompt: "prompt", WebSearch: true})
+	if err != nil {
+		t.Fatalf("Generate() error = %v", err)
+	}
+	if text != "要約です" {
+		t.Errorf("Generate() = %q", text)
+	}
+	if got["model"] != "gpt-4o" || got["web_search_options"] == nil {
+		t.Errorf("request = %v", got)
+	}
+	if messages, _ := got["messages"].([]any); len(messages) != 2 {
+		t.Errorf("messages = %v, want system and user", got["messages"])
+	}
+
+	if _, err := NewOpenAI("key", server.URL+"/v1", "o3-mini").Generate(context.Background(), Request{Prompt: "prompt", Schema: testSchema}); err != nil {
+		t.Fatalf("Generate() error = %v", err)
+	}
+	format, _ := got["response_format"].(map[string]any)
+	if jsonSchema, _ := format["json_schema"].(map[string]any); format["type"] != "json_schema" || jsonSchema["name"] != "post" || jsonSchema["strict"] != true {
+		t.Errorf("response_format = %v", got["response_format"])
+	}
+}
+
+var testSchema = &Schema{
+	Name: "post",
+	JSON: map[string]any{
+		"type":                 "object",
+		"properties":           map[string]any{"title": map[string]any{"type": "string"}},
+		"required":             []string{"title"},
+		"additionalProperties": false,
+	},
+}
+
+func TestLocalWebSearch(t *testing.T) {
+	t.Parallel()
+
+	_, err := NewLocal("", "", "llama3").Generate(context.Background(), Request{Prompt: "prompt", WebSearch: true})
+	if !errors.Is(err, ErrWebSearchUnsupported) {
+		t.Errorf("Generate() error = %v, want %v", err, ErrWebSearchUnsupported)
+	}
+}
+
+func TestAnthropicGenerate(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name      string
+		webSearch bool
+		schema    *Schema
+		status    int
+		response  string
+		want      string
+		wantErr   bool
+	}{
+		{
+			name:     "正常系: テキストのブロック",
+			status:   http.StatusOK,
+			response: `{"content":[{"type":"text","text":"記事の"},{"type":"text","text":"要約"}],"stop_reason":"end_turn"}`,
+			want:     "記事の要約",
+		},
+		{
+			name:      "正常系: Web検索の結果を除く",
+			webSearch: true,
+			status:    http.StatusOK,
+			response:  `{"content":[{"type":"server_tool_use","id":"1","name":"web_search"},{"type":"web_search_tool_result","content":[]},{"type":"text","text":"検索した要約"}],"stop_reason":"end_turn"}`,
+			want:      "検索した要約",
+		},
+		{
+			name:     "正常系: スキーマのツールの入力",
+			schema:   testSchema,
+			status:   http.StatusOK,
+			response: `{"content":[{"type":"tool_use","id":"1","name":"post","input":{"title":"タイトル"}}],"stop_reason":"tool_use"}`,
+			want:     `{"title":"タイトル"}`,
+		},
+		{
+			name:     "異常系: APIのエラー",
+			status:   http.StatusTooManyRequests,
+			response: `{"type":"error","error":{"type":"rate_limit_error","message":"slow down"}}`,
+			wantErr:  true,
+		},
+		{
+			name:     "異常系: テキストがない",
+			status:   http.StatusOK,
+			response: `{"content":[],"stop_reason":"end_turn"}`,
+			wantErr:  true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			var got anthropicRequest
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if r.URL.Path != "/v1/messages" || r.Header.Get("x-api-key") != "key" || r.Header.Get("anthropic-version") == "" {
+					http.Error(w, "bad request", http.StatusBadRequest)
+					return
+				}
+				_ = json.NewDecoder(r.Body).Decode(&got)
+				w.WriteHeader(tt.status)
+				_, _ = fmt.Fprint(w, tt.response)
+			}))
+			defer server.Close()
+
+			text, err := NewAnthropic("key", server.URL, "claude-model").Generate(context.Background(), Request{System: "system", Prompt: "prompt", WebSearch: tt.webSearch, Schema: tt.schema})
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Generate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if text != tt.want {
+				t.Errorf("Generate() = %q, want %q", text, tt.want)
+			}
+			if got.Model != "claude-model" || got.System != "system" || len(got.Messages) != 1 || (len(got.Tools) > 0) != (tt.webSearch || tt.schema != nil) || (got.ToolChoice != nil) != (tt.schema != nil) {
+				t.Errorf("request = %+v", got)
+			}
+		})
+	}
+}
+
+func TestFake(t *testing.T) {
+	t.Parallel()
+
+	fake := &Fake{Responses: []string{"first", "second"}}
+	for _, want := range []string{"first", "second", "second"} {
+		if got, err := fake.Generate(context.Background(), Request{Prompt: want}); err != nil || got != want {
+			t.Errorf("Generate() = %q, %v, want %q", got, err, want)
+		}
+	}
+	if len(fake.Requests()) != 3 {
+		t.Errorf("Requests() = %d, want 3", len(fake.Requests()))
+	}
+
+	echo := &Fake{}
+	if got, _ := echo.Generate(context.Background(), Request{Prompt: "prompt"}); got != "prompt" {
+		t.Errorf("Generate() = %q, want prompt", got)
+	}
+}
+
+func TestNew(t *testing.T) {
+	t.Parallel()
+
+	credentials := Credentials{OpenAIAPIKey: "openai", AnthropicAPIKey: "anthropic"}
+	tests := []struct {
+		name        string
+		stage       Stage
+		credentials Credentials
+		wantErr     bool
+	}{
+		{name: "正常系: OpenAI", stage: Stage{Provider: ProviderOpenAI, Model: "o3-mini"}, credentials: credentials},
+		{name: "正常系: Anthropic", stage: Stage{Provider: ProviderAnthropic, Model: "claude"}, credentials: credentials},
+		{name: "正常系: ローカル", stage: Stage{Provider: ProviderLocal, Model: "llama3"}},
+		{name: "異常系: フェイクは設定から作れない", stage: Stage{Provider: "fake"}, wantErr: true},
+		{name: "異常系: 不明なプロバイダー", stage: Stage{Provider: "unknown", Model: "x"}, credentials: credentials, wantErr: true},
+		{name: "異常系: モデルがない", stage: Stage{Provider: ProviderOpenAI}, credentials: credentials, wantErr: true},
+		{name: "異常系: APIキーがない", stage: Stage{Provider: ProviderAnthropic, Model: "claude"}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			if _, err := New(tt.stage, tt.credentials); (err != nil) != tt.wantErr {
+				t.Errorf("New() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,91 @@
+package llm
+
+import (
+	"context"
+	"fmt"
+
+	openai "github.com/openai/openai-go"
+	option "github.com/openai/openai-go/option"
+	param "github.com/openai/openai-go/packages/param"
+)
+
+// OpenAI はOpenAIのChat Completions APIで生成します。ローカルサーバーもこの実装を使います。
+type OpenAI struct {
+	client openai.Client
+	model  string
+	// webSearch is false for local servers, which have no search
+	webSearch bool
+	// structured is false for local servers, whose support for json_schema response formats varies
+	structured bool
+}
+
+// NewOpenAI はOpenAIの Generator を作成します。baseURL が空の場合はOpenAIのAPIを使います。
+func NewOpenAI(apiKey, baseURL, model string) *OpenAI {
+	opts := []option.RequestOption{option.WithAPIKey(apiKey)}
+	if baseURL != "" {
+		opts = append(opts, option.WithBaseURL(baseURL))
+	}
+	return &OpenAI{client: openai.NewClient(opts...), model: model, webSearch: true, structured: true}
+}
+
+// NewLocal はOllamaやllama.cppなどOpenAI互換のローカルサーバーの Generator を作成します。
+// baseURL が空の場合は DefaultLocalBaseURL を使います。
+func NewLocal(apiKey, baseURL, model string) *OpenAI {
+	if baseURL == "" {
+		baseURL = DefaultLocalBaseURL
+	}
+	if apiKey == "" {
+		apiKey = "local"
+	}
+	g := NewOpenAI(apiKey, baseURL, model)
+	g.webSearch = false
+	g.structured = false
+	return g
+}
+
+// Generate はテキストを生成します。Web検索は検索に対応したモデル（gpt-4o-search-preview など）で使えます。
+// Schema を指定した場合は json_schema の response_format で出力の形式を指定します。
+func (g *OpenAI) Generate(ctx context.Context, req Request) (string, error) {
+	if req.WebSearch && !g.webSearch {
+		return "", ErrWebSearchUnsupported
+	}
+
+	var messages []openai.ChatCompletionMessageParamUnion
+	if req.System != "" {
+		messages = append(messages, openai.SystemMessage(req.System))
+	}
+	messages = append(messages, openai.UserMessage(req.Prompt))
+	params := openai.ChatCompletionNewParams{Messages: messages, Model: g.model}
+	if req.WebSearch {
+		params.WebSearchOptions = openai.ChatCompletionNewParamsWebSearchOptions{
+			SearchContextSize: "medium",
+			UserLocation: openai.ChatCompletionNewParamsWebSearchOptionsUserLocation{
+				Approximate: openai.ChatCompletionNewParamsWebSearchOptionsUserLocationApproximate{
+					Timezone: param.Opt[string]{Value: "Asia/Tokyo"},
+				},
+			},
+		}
+	}
+
+	if req.Schema != nil && g.structured {
+		params.ResponseFormat = openai.ChatCompletionNewParamsResponseFormatUnion{
+			OfJSONSchema: &openai.ResponseFormatJSONSchemaParam{
+				JSONSchema: openai.ResponseFormatJSONSchemaJSONSchemaParam{
+					Name:        req.Schema.Name,
+					Description: param.Opt[string]{Value: req.Schema.Description},
+					Schema:      req.Schema.JSON,
+					Strict:      param.Opt[bool]{Value: true},
+				},
+			},
+		}
+	}
+
+	chatCompletion, err := g.client.Chat.Completions.New(ctx, params)
+	if err != nil {
+		return "", fmt.Errorf("failed to request %s: %w", g.model, err)
+	}
+	if len(chatCompletion.Choices) == 0 || chatCompletion.Choices[0].Message.Content == "" {
+		return "", ErrEmptyResponse
+	}
+	return chatCompletion.Choices[0].Message.Content, nil
+}
@@ -1,13 +1,15 @@
 package blogpost
 
 import (
+	"errors"
 	"fmt"
 	"log/slog"
 	"net/http"
 	"time"
 
 	"github.com/GoogleCloudPlatform/functions-framework-go/functions"
-	"thiroyoshi.com/blog-post/x"
+	"thiroyoshi.com/video-converter/bluesky"
+	"thiroyoshi.com/video-converter/fediverse"
 )
 
 // blogPost is an HTTP Cloud Function.
@@ -30,50 +32,80 @@ func RunBlogPost() error {
 	jst, err := time.LoadLocation("Asia/Tokyo")
 	if err != nil {
 		slog.Error("Failed to get timezone", "error", err)
-		postFailedMessageToSlack(err)
+		notifyFailure(stepLoadTimezone, err)
 		return fmt.Errorf("failed to load JST location: %v", err)
 	}
 
 	now := time.Now().In(jst)
-	searchword := "Fortnite"
+	sources, err := newsSources()
+	if err != nil {
+		slog.Error("Failed to load news sources", "error", err)
+		notifyFailure(stepFetchRSS, err)
+		return fmt.Errorf("failed to load news sources: %v", err)
+	}
 
-	articles, err := getLatestFromRSS(searchword, now, nil, "")
+	articles, err := getLatestFromRSS(sources, now, nil, "")
 	if err != nil {
 		slog.Error("Failed to get RSS feed", "error", err)
-		postFailedMessageToSlack(err)
+		notifyFailure(stepFetchRSS, err)
 		return fmt.Errorf("failed to retrieve RSS feed: %v", err)
 	}
 
-	summaries, err := getSummaries(articles, 10, now)
+	historyStore := newHistoryStore()
+	covered := loadHistory(historyStore)
+	articles = filterCovered(articles, covered)
+	record := &runRecord{Articles: len(articles)}
+	defer record.log()
+
+	summaries, groundingSources, err := getSummaries(articles, 10, now)
 	if err != nil {
 		slog.Error("Failed to get article summaries", "error", err)
-		postFailedMessageToSlack(err)
+		notifyFailure(stepSummarize, err)
 		return fmt.Errorf("failed to get article summaries: %v", err)
 	}
+	record.Summaries = len(groundingSources)
+	summaries = appendXDigest(summaries, now)
 
-	title, content, err := generatePostByArticles(summaries, now)
+	title, content, err := generatePostByArticles(summaries, groundingSources, now, record)
 	if err != nil {
 		slog.Error("Failed to generate blog post", "error", err)
-		postFailedMessageToSlack(err)
+		notifyFailure(stepGenerate, err)
 		return fmt.Errorf("failed to generate blog post: %v", err)
 	}
+	record.Title = title
 	url, err := post(title, content)
 	if err != nil {
 		slog.Error("Failed to post to Hatena Blog", "error", err)
-		postFailedMessageToSlack(err)
+		notifyFailure(stepPostHatena, err)
 		return fmt.Errorf("failed to post to Hatena Blog: %v", err)
 	}
+	record.URL = url
 
-	xMessage := fmt.Sprintf("ブログを更新しました！\n#Fortnite #フォートナイト #はてなブログ #GABA\n\n%s\n%s", title, url)
-	if err = x.PostToX(xMessage); err != nil {
-		slog.Error("Failed to post message to X", "error", err)
+	recordCovered(covered, content, groundingSources, now)
+	if err := historyStore.Save(covered); err != nil {
+		slog.Warn("Failed to save article history", "error", err)
+	}
+
+	var xErr, blueskyErr, fediErr error
+	announced, err := newAnnouncement(title, url, content, now)
+	if err != nil {
+		slog.Error("Failed to compose announcement", "error", err)
+		xErr, blueskyErr, fediErr = err, err, err
+	} else {
+		if xErr = announceToX(announced); xErr != nil {
+			slog.Error("Failed to post message to X", "error", xErr)
+		}
+		blueskyErr = announceToBluesky(announced)
+		if blueskyErr != nil && !errors.Is(blueskyErr, bluesky.ErrNotConfigured) {
+			slog.Error("Failed to post message to Bluesky", "error", blueskyErr)
+		}
+		fediErr = announceToFediverse(announced)
+		if fediErr != nil && !errors.Is(fediErr, fediverse.ErrNotConfigured) {
+			slog.Error("Failed to post message to Mastodon/Misskey", "error", fediErr)
+		}
 	}
 
-	// message := fmt.Sprintf("GABAのブログを更新しました！\n\n%s\n%s", title, url)
-	// if err = postMessageToSlack(message); err != nil {
-	// 	slog.Error("Failed to post message to Slack", "error", err)
-	// 	return fmt.Errorf("failed to post message to slack: %v", err)
-	// }
+	notifySuccess(title, url, map[string]error{stepPostX: xErr, stepPostBluesky: blueskyErr, stepPostFedi: fediErr})
 
 	fmt.Printf("Blog post successfully completed!\nTitle: %s\nURL: %s\n", title, url)
 	return nil
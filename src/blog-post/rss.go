@@ -1,30 +1,25 @@
 package blogpost
 
 import (
-	"encoding/xml"
+	"errors"
 	"fmt"
 	"log/slog"
 	"net/http"
 	"sort"
+	"sync"
 	"time"
 )
 
-// RSS feed structure
-type RSS struct {
-	XMLName xml.Name `xml:"rss"`
-	Channel struct {
-		Items []struct {
-			Title   string `xml:"title"`
-			Link    string `xml:"link"`
-			PubDate string `xml:"pubDate"`
-		} `xml:"item"`
-	} `xml:"channel"`
-}
-
+// Article はニュースの記事です。
 type Article struct {
 	Title   string
 	Link    string
 	PubDate time.Time
+	// Description はフィードの記事の概要をテキストにしたものです
+	Description string
+	// Source は記事の配信元の名前です
+	Source     string
+	Categories []string
 }
 
 // HTTPClient interface definition
@@ -35,7 +30,9 @@ type HTTPClient interface {
 // Default HTTP client
 var defaultHTTPClient HTTPClient = &http.Client{}
 
-func getLatestFromRSS(searchword string, now time.Time, httpClient HTTPClient, baseURL string) ([]Article, error) {
+// getLatestFromRSS は検索条件ごとのRSSを並行して取得し、重複を除いて新しい順に並べた記事を返します。
+// 取得に失敗した検索条件は飛ばし、すべての検索条件で失敗した場合はエラーを返します。
+func getLatestFromRSS(sources []Source, now time.Time, httpClient HTTPClient, baseURL string) ([]Article, error) {
 	if httpClient == nil {
 		httpClient = defaultHTTPClient
 	}
@@ -43,54 +40,86 @@ func getLatestFromRSS(searchword string, now time.Time, httpClient HTTPClient, b
 		baseURL = "https://news.google.com/rss/search"
 	}
 
-	today := now.Format("2006-01-02")
-	lastweek := now.AddDate(0, 0, -7).Format("2006-01-02")
+	results := make([][]Article, len(sources))
+	errs := make([]error, len(sources))
+	var wg sync.WaitGroup
+	for i, source := range sources {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			results[i], errs[i] = fetchSource(source, now, httpClient, baseURL)
+		}()
+	}
+	wg.Wait()
+
+	var articles []Article
+	seen := map[string]bool{}
+	failed := 0
+	for i, result := range results {
+		if errs[i] != nil {
+			slog.Error("Failed to retrieve RSS feed", "query", sources[i].Query, "feed", sources[i].Feed, "error", errs[i])
+			failed++
+			continue
+		}
+		for _, article := range result {
+			// The same news often appears in several queries
+			if seen[article.Link] || seen[article.Title] {
+				continue
+			}
+			seen[article.Link], seen[article.Title] = true, true
+			articles = append(articles, article)
+		}
+	}
+	if failed > 0 && failed == len(sources) {
+		return nil, errors.Join(errs...)
+	}
+
+	slog.Info("Articles retrieved from RSS feed", "count", len(articles), "sources", len(sources))
+
+	// Sort articles by date with latest first
+	sort.SliceStable(articles, func(i, j int) bool {
+		return articles[i].PubDate.After(articles[j].PubDate)
+	})
+
+	slog.Info("Articles sorted by date", "articles", articles)
 
-	url := fmt.Sprintf("%s?q=%s+after:%s+before:%s&hl=ja&gl=JP&ceid=JP:ja", baseURL, searchword, lastweek, today)
+	return articles, nil
+}
+
+// fetchSource はひとつの検索条件のフィードを取得します。
+func fetchSource(source Source, now time.Time, httpClient HTTPClient, baseURL string) (articles []Article, err error) {
+	url := source.Feed
+	if url == "" {
+		url = source.feedURL(baseURL, now)
+	}
 	slog.Info("RSS feed URL", "url", url)
 
 	// Get RSS feed
 	resp, err := httpClient.Get(url)
 	if err != nil {
-		slog.Error("Failed to retrieve RSS feed", "error", err)
 		return nil, fmt.Errorf("failed to retrieve RSS feed: %v", err)
 	}
 	defer func() {
-		if cerr := resp.Body.Close(); cerr != nil {
+		if cerr := resp.Body.Close(); cerr != nil && err == nil {
 			err = fmt.Errorf("failed to close response: %v", cerr)
 		}
 	}()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("failed to retrieve RSS feed: status %d", resp.StatusCode)
+	}
 
-	// Parse XML
-	var rss RSS
-	if err := xml.NewDecoder(resp.Body).Decode(&rss); err != nil {
-		slog.Error("Failed to parse XML", "error", err)
-		return nil, fmt.Errorf("failed to parse XML: %v", err)
+	items, err := parseFeed(resp.Body)
+	if err != nil {
+		return nil, err
 	}
 
-	// Extract article information
-	var articles []Article
-	for _, item := range rss.Channel.Items {
-		pubDate, err := time.Parse(time.RFC1123, item.PubDate)
-		if err != nil {
+	// Feeds of news sites are not limited to the window like Google News searches
+	since := now.AddDate(0, 0, -source.windowDays())
+	for _, item := range items {
+		if item.PubDate.Before(since) || source.excludes(item.Title) {
 			continue
 		}
-
-		articles = append(articles, Article{
-			Title:   item.Title,
-			Link:    item.Link,
-			PubDate: pubDate,
-		})
+		articles = append(articles, item)
 	}
-
-	slog.Info("Articles retrieved from RSS feed", "count", len(articles))
-
-	// Sort articles by date with latest first
-	sort.Slice(articles, func(i, j int) bool {
-		return articles[i].PubDate.After(articles[j].PubDate)
-	})
-
-	slog.Info("Articles sorted by date", "articles", articles)
-
 	return articles, nil
 }
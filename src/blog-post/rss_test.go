@@ -98,7 +98,7 @@ func TestGetLatestFromRSS(t *testing.T) {
 			}
 
 			// テスト実行
-			articles, err := getLatestFromRSS(tc.searchword, tc.now, mockClient, server.URL)
+			articles, err := getLatestFromRSS([]Source{{Query: tc.searchword}}, tc.now, mockClient, server.URL)
 
 			// エラーチェック
 			if (err != nil) != tc.wantErr {
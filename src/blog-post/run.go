@@ -0,0 +1,38 @@
+package blogpost
+
+import (
+	"context"
+	"log/slog"
+)
+
+// runRecord は1回の投稿処理の記録です。処理の最後にまとめてログに出します。
+type runRecord struct {
+	// Articles is the number of articles left after removing the covered topics
+	Articles int
+	// Summaries is the number of articles summarised
+	Summaries int
+	// Attempts is the number of times the post was generated
+	Attempts int
+	// Grounding is the result of checking the links and dates of the post against the summarised articles
+	Grounding groundingReport
+	Title     string
+	URL       string
+}
+
+// log は記録をログに出します。情報源と一致しなかった<section>がある場合は警告とします。
+func (r *runRecord) log() {
+	level := slog.LevelInfo
+	if len(r.Grounding.Regenerated) > 0 || len(r.Grounding.Removed) > 0 || len(r.Grounding.Unlinked) > 0 {
+		level = slog.LevelWarn
+	}
+	slog.Log(context.Background(), level, "Run record",
+		"articles", r.Articles,
+		"summaries", r.Summaries,
+		"attempts", r.Attempts,
+		"sections_checked", r.Grounding.Checked,
+		"sections_regenerated", r.Grounding.Regenerated,
+		"sections_removed", r.Grounding.Removed,
+		"links_removed", r.Grounding.Unlinked,
+		"title", r.Title,
+		"url", r.URL)
+}
@@ -0,0 +1,301 @@
+package blogpost
+
+import (
+	"fmt"
+	"log/slog"
+	"net/url"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"golang.org/x/net/html"
+	"golang.org/x/net/html/atom"
+)
+
+// allowedElements are the elements kept in generated content, with the attributes kept on each of them.
+// Other elements are unwrapped (their children are kept) unless they are in droppedElements.
+var allowedElements = map[atom.Atom][]string{
+	atom.Section: nil, atom.H2: nil, atom.H3: nil, atom.P: {"class"}, atom.Br: nil,
+	atom.A: {"href", "title"}, atom.Strong: nil, atom.Em: nil, atom.B: nil, atom.I: nil, atom.U: nil,
+	atom.Ul: nil, atom.Ol: nil, atom.Li: nil, atom.Blockquote: nil, atom.Span: nil,
+}
+
+// allowedClasses are the class names kept on paragraphs
+var allowedClasses = map[string]bool{"date": true}
+
+// droppedElements are removed together with their content
+var droppedElements = map[atom.Atom]bool{
+	atom.Script: true, atom.Style: true, atom.Iframe: true, atom.Frame: true, atom.Frameset: true, atom.Object: true,
+	atom.Embed: true, atom.Applet: true, atom.Form: true, atom.Input: true, atom.Button: true, atom.Textarea: true,
+	atom.Select: true, atom.Noscript: true, atom.Template: true, atom.Svg: true, atom.Math: true, atom.Link: true,
+	atom.Meta: true, atom.Base: true, atom.Title: true, atom.Head: true, atom.Audio: true, atom.Video: true, atom.Canvas: true,
+}
+
+// articleDatePattern matches dates such as 2025-05-01, 2025/5/1 and 2025年5月1日
+var articleDatePattern = regexp.MustCompile(`(\d{4})\s*[-/年.]\s*(\d{1,2})\s*[-/月.]\s*(\d{1,2})`)
+
+// maxDateLength is the longest paragraph in runes treated as a date line
+const maxDateLength = 40
+
+// sanitizeReport は記事本文から取り除いた・直した内容です。
+type sanitizeReport struct {
+	// Removed are the elements and attributes removed, such as "script" or "a@onclick"
+	Removed []string
+	// Repaired are the sections fixed, with what was fixed
+	Repaired []string
+	// Dropped are the sections removed, with the reason
+	Dropped []string
+}
+
+// log は取り除いた・直した内容をログに出します。
+func (r sanitizeReport) log() {
+	if len(r.Removed) > 0 {
+		slog.Warn("Removed disallowed HTML from generated content", "removed", r.Removed)
+	}
+	for _, repaired := range r.Repaired {
+		slog.Info("Repaired section of generated content", "section", repaired)
+	}
+	for _, dropped := range r.Dropped {
+		slog.Warn("Dropped invalid section of generated content", "section", dropped)
+	}
+}
+
+// sanitizeContent は生成された記事本文のHTMLから許可していない要素と属性を取り除き、<section>の構成を確認します。
+// <section>には見出し（<h2>）・日付（<p class='date'>）・本文・情報源のリンクが必要です。
+// 直せる<section>は直し、直せない<section>は取り除きます。日付と情報源のない最後の<section>はまとめとして<section>を外します。
+// 有効な<section>が1つもない場合は ContentError を返します。
+func sanitizeContent(content string) (string, sanitizeReport, error) {
+	var report sanitizeReport
+	body, err := parseFragment(content)
+	if err != nil {
+		return "", report, err
+	}
+
+	sanitizeChildren(body, &report)
+	if valid := repairSections(body, &report); valid == 0 {
+		return "", report, &ContentError{Problems: append([]string{"有効な<section>がない"}, report.Dropped...)}
+	}
+	content, err = renderChildren(body)
+	return content, report, err
+}
+
+// parseFragment は記事本文のHTMLを<body>の子要素として読み込みます。
+func parseFragment(content string) (*html.Node, error) {
+	body := &html.Node{Type: html.ElementNode, Data: "body", DataAtom: atom.Body}
+	nodes, err := html.ParseFragment(strings.NewReader(content), body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse content HTML: %w", err)
+	}
+	for _, n := range nodes {
+		body.AppendChild(n)
+	}
+	return body, nil
+}
+
+// renderChildren は子要素をHTMLにします。
+func renderChildren(n *html.Node) (string, error) {
+	var b strings.Builder
+	for c := n.FirstChild; c != nil; c = c.NextSibling {
+		if err := html.Render(&b, c); err != nil {
+			return "", err
+		}
+	}
+	return b.String(), nil
+}
+
+// sanitizeChildren は子要素から許可していない要素と属性を取り除きます。
+func sanitizeChildren(n *html.Node, report *sanitizeReport) {
+	for c := n.FirstChild; c != nil; {
+		next := c.NextSibling
+		switch c.Type {
+		case html.CommentNode, html.DoctypeNode:
+			n.RemoveChild(c)
+		case html.ElementNode:
+			allowed, ok := allowedElements[c.DataAtom]
+			switch {
+			case droppedElements[c.DataAtom]:
+				report.Removed = append(report.Removed, c.Data)
+				n.RemoveChild(c)
+			case !ok:
+				// Unwrap the element; its children are sanitised first so that they are not visited again
+				sanitizeChildren(c, report)
+				report.Removed = append(report.Removed, c.Data)
+				for child := c.FirstChild; child != nil; child = c.FirstChild {
+					c.RemoveChild(child)
+					n.InsertBefore(child, c)
+				}
+				n.RemoveChild(c)
+			default:
+				sanitizeAttributes(c, allowed, report)
+				sanitizeChildren(c, report)
+			}
+		}
+		c = next
+	}
+}
+
+// sanitizeAttributes は許可していない属性と、http・https 以外のリンクを取り除きます。
+func sanitizeAttributes(n *html.Node, allowed []string, report *sanitizeReport) {
+	var attrs []html.Attribute
+	for _, a := range n.Attr {
+		keep := a.Namespace == "" && slices.Contains(allowed, a.Key)
+		switch {
+		case keep && a.Key == "href":
+			keep = safeURL(a.Val)
+		case keep && a.Key == "class":
+			var classes []string
+			for _, class := range strings.Fields(a.Val) {
+				if allowedClasses[class] {
+					classes = append(classes, class)
+				}
+			}
+			a.Val = strings.Join(classes, " ")
+			keep = a.Val != ""
+		}
+		if keep {
+			attrs = append(attrs, a)
+		} else {
+			report.Removed = append(report.Removed, n.Data+"@"+a.Key)
+		}
+	}
+	n.Attr = attrs
+}
+
+// safeURL はリンクが http・https の絶対URLかを返します。
+func safeURL(raw string) bool {
+	u, err := url.Parse(strings.TrimSpace(raw))
+	return err == nil && (u.Scheme == "http" || u.Scheme == "https") && u.Host != ""
+}
+
+// repairSections は<section>の構成を確認して直し、有効な<section>の数を返します。
+func repairSections(body *html.Node, report *sanitizeReport) int {
+	sections := sectionNodes(body)
+	valid := 0
+	for i, n := range sections {
+		label := fmt.Sprintf("section %d", i+1)
+		repairs := repairSection(n)
+		section := newSection(n)
+		if heading := section.Heading; heading != "" {
+			label = fmt.Sprintf("section %d (%s)", i+1, heading)
+		}
+
+		var missing []string
+		if section.Heading == "" {
+			missing = append(missing, "見出し")
+		}
+		if section.Date == "" {
+			missing = append(missing, "日付")
+		}
+		if section.Body == "" {
+			missing = append(missing, "本文")
+		}
+		if section.SourceURL == "" {
+			missing = append(missing, "情報源のリンク")
+		}
+
+		switch {
+		case len(missing) == 0:
+			valid++
+			if len(repairs) > 0 {
+				report.Repaired = append(report.Repaired, label+": "+strings.Join(repairs, "、"))
+			}
+		case i == len(sections)-1 && section.Date == "" && section.SourceURL == "" && section.Body != "":
+			// The closing summary asked for by the refine prompt is not a topic
+			for c := n.FirstChild; c != nil; c = n.FirstChild {
+				n.RemoveChild(c)
+				n.Parent.InsertBefore(c, n)
+			}
+			n.Parent.RemoveChild(n)
+			report.Repaired = append(report.Repaired, label+": まとめとして<section>を外した")
+		default:
+			n.Parent.RemoveChild(n)
+			report.Dropped = append(report.Dropped, label+": "+strings.Join(missing, "・")+"がない")
+		}
+	}
+	return valid
+}
+
+// sectionNodes は<section>の要素を順に返します。
+func sectionNodes(root *html.Node) []*html.Node {
+	var sections []*html.Node
+	var walk func(n *html.Node)
+	walk = func(n *html.Node) {
+		if n.Type == html.ElementNode && n.DataAtom == atom.Section {
+			sections = append(sections, n)
+			return
+		}
+		for c := n.FirstChild; c != nil; c = c.NextSibling {
+			walk(c)
+		}
+	}
+	walk(root)
+	return sections
+}
+
+// repairSection は<section>の直せる構成を直し、直した内容を返します。
+// <h2>がない場合は<h3>を<h2>にし、日付の段落にclassがない場合は付け、日付を「公開日：YYYY-MM-DD」の形式にそろえます。
+func repairSection(n *html.Node) []string {
+	var repairs []string
+	var h2, h3, date, dateCandidate *html.Node
+	var walk func(c *html.Node)
+	walk = func(c *html.Node) {
+		if c.Type != html.ElementNode {
+			return
+		}
+		switch c.DataAtom {
+		case atom.H2:
+			if h2 == nil {
+				h2 = c
+			}
+		case atom.H3:
+			if h3 == nil {
+				h3 = c
+			}
+		case atom.P:
+			text := nodeText(c)
+			if hasClass(c, "date") && date == nil {
+				date = c
+			} else if dateCandidate == nil && utf8.RuneCountInString(text) <= maxDateLength && articleDatePattern.MatchString(text) {
+				dateCandidate = c
+			}
+			return
+		}
+		for child := c.FirstChild; child != nil; child = child.NextSibling {
+			walk(child)
+		}
+	}
+	for c := n.FirstChild; c != nil; c = c.NextSibling {
+		walk(c)
+	}
+
+	if h2 == nil && h3 != nil {
+		h3.Data, h3.DataAtom = "h2", atom.H2
+		repairs = append(repairs, "<h3>を<h2>にした")
+	}
+	if date == nil && dateCandidate != nil {
+		date = dateCandidate
+		date.Attr = append(date.Attr, html.Attribute{Key: "class", Val: "date"})
+		repairs = append(repairs, "日付の段落にclassを付けた")
+	}
+	if date != nil {
+		text := nodeText(date)
+		m := articleDatePattern.FindStringSubmatch(text)
+		if m == nil {
+			// A date line without a date is dropped, so the section is reported as missing its date
+			date.Parent.RemoveChild(date)
+			return append(repairs, "日付のない日付の段落を取り除いた")
+		}
+		month, _ := strconv.Atoi(m[2])
+		day, _ := strconv.Atoi(m[3])
+		if normalized := fmt.Sprintf("公開日：%s-%02d-%02d", m[1], month, day); text != normalized {
+			for c := date.FirstChild; c != nil; c = date.FirstChild {
+				date.RemoveChild(c)
+			}
+			date.AppendChild(&html.Node{Type: html.TextNode, Data: normalized})
+			repairs = append(repairs, "日付を「"+normalized+"」にした")
+		}
+	}
+	return repairs
+}
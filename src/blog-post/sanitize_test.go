@@ -0,0 +1,98 @@
+package blogpost
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+const validSection = `<section><h2>新シーズン開幕</h2><p class="date">公開日：2025-05-01</p><p>新しい武器が追加されました！</p><a href="https://example.com/1">情報源</a></section>`
+
+func TestSanitizeContent(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name         string
+		content      string
+		want         string
+		wantRemoved  []string
+		wantRepaired int
+		wantDropped  int
+		wantErr      bool
+	}{
+		{
+			name:    "正常系: 正しい記事はそのまま",
+			content: validSection,
+			want:    validSection,
+		},
+		{
+			name: "正常系: スクリプトとiframeを中身ごと取り除く",
+			content: `<script>alert(1)</script><section><h2>新シーズン開幕</h2><p class="date">公開日：2025-05-01</p>` +
+				`<p>新しい武器が追加されました！<iframe src="https://evil.example.com"></iframe></p><a href="https://example.com/1">情報源</a></section>`,
+			want:        validSection,
+			wantRemoved: []string{"script", "iframe"},
+		},
+		{
+			name: "正常系: イベントハンドラと危険なリンクを取り除く",
+			content: `<section onclick="steal()"><h2 style="color:red">新シーズン開幕</h2><p class="date lead">公開日：2025-05-01</p>` +
+				`<p>新しい武器が<a href="javascript:alert(1)">追加</a>されました！</p><a href="https://example.com/1" onmouseover="x()">情報源</a></section>`,
+			want: `<section><h2>新シーズン開幕</h2><p class="date">公開日：2025-05-01</p>` +
+				`<p>新しい武器が<a>追加</a>されました！</p><a href="https://example.com/1">情報源</a></section>`,
+			wantRemoved: []string{"section@onclick", "h2@style", "a@href", "a@onmouseover"},
+		},
+		{
+			name:        "正常系: 許可していない要素は中身を残す",
+			content:     `<div class="topic"><section><h2>新シーズン開幕</h2><p class="date">公開日：2025-05-01</p><p><font color="red">新しい武器が追加されました！</font></p><a href="https://example.com/1">情報源</a></section></div>`,
+			want:        validSection,
+			wantRemoved: []string{"font", "div"},
+		},
+		{
+			name:         "正常系: 見出しと日付を直す",
+			content:      `<section><h3>新シーズン開幕</h3><p>2025年5月1日</p><p>新しい武器が追加されました！</p><a href="https://example.com/1">情報源</a></section>`,
+			want:         validSection,
+			wantRepaired: 1,
+		},
+		{
+			name:         "正常系: 最後のまとめは<section>を外す",
+			content:      validSection + `<section><h2>まとめ</h2><p>今週も盛りだくさんでした！みんなはどう思う？</p></section>`,
+			want:         validSection + `<h2>まとめ</h2><p>今週も盛りだくさんでした！みんなはどう思う？</p>`,
+			wantRepaired: 1,
+		},
+		{
+			name:        "正常系: 情報源のない<section>を取り除く",
+			content:     `<section><h2>噂</h2><p class="date">公開日：2025-05-01</p><p>新モードが来るらしい</p></section>` + validSection,
+			want:        validSection,
+			wantDropped: 1,
+		},
+		{
+			name:        "異常系: 有効な<section>がない",
+			content:     `<section><h2>噂</h2><p>新モードが来るらしい</p><a href="https://example.com/2">情報源</a></section><p>本文のみ</p>`,
+			wantDropped: 1,
+			wantErr:     true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			got, report, err := sanitizeContent(tt.content)
+			if tt.wantErr {
+				var contentErr *ContentError
+				if !errors.As(err, &contentErr) {
+					t.Fatalf("sanitizeContent() error = %v, want ContentError", err)
+				}
+			} else if err != nil {
+				t.Fatalf("sanitizeContent() error = %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("sanitizeContent() =\n%s\nwant\n%s", got, tt.want)
+			}
+			if strings.Join(report.Removed, ",") != strings.Join(tt.wantRemoved, ",") {
+				t.Errorf("Removed = %v, want %v", report.Removed, tt.wantRemoved)
+			}
+			if len(report.Repaired) != tt.wantRepaired || len(report.Dropped) != tt.wantDropped {
+				t.Errorf("Repaired = %v, Dropped = %v, want %d, %d", report.Repaired, report.Dropped, tt.wantRepaired, tt.wantDropped)
+			}
+		})
+	}
+}
@@ -0,0 +1,134 @@
+package blogpost
+
+import (
+	"fmt"
+	"strings"
+
+	"golang.org/x/net/html"
+	"golang.org/x/net/html/atom"
+)
+
+// Section は生成された記事本文の<section>1つ分（1トピック）を表す構造体
+type Section struct {
+	Heading     string
+	Date        string
+	Body        string
+	SourceTitle string
+	SourceURL   string
+}
+
+// parseSections は記事本文のHTMLから<section>ごとのトピックを取り出す
+func parseSections(content string) ([]Section, error) {
+	doc, err := html.Parse(strings.NewReader(content))
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse content HTML: %v", err)
+	}
+
+	var sections []Section
+	var walk func(n *html.Node)
+	walk = func(n *html.Node) {
+		if n.Type == html.ElementNode && n.DataAtom == atom.Section {
+			sections = append(sections, newSection(n))
+			return
+		}
+		for c := n.FirstChild; c != nil; c = c.NextSibling {
+			walk(c)
+		}
+	}
+	walk(doc)
+
+	return sections, nil
+}
+
+func newSection(n *html.Node) Section {
+	var section Section
+	var body []string
+
+	var walk func(n *html.Node)
+	walk = func(n *html.Node) {
+		if n.Type == html.ElementNode {
+			switch n.DataAtom {
+			case atom.H2:
+				if section.Heading == "" {
+					section.Heading = nodeText(n)
+				}
+				return
+			case atom.P:
+				if hasClass(n, "date") {
+					section.Date = nodeText(n)
+					return
+				}
+				// A source link may be wrapped in a paragraph
+				var links []string
+				for c := n.FirstChild; c != nil; c = c.NextSibling {
+					if c.Type == html.ElementNode && c.DataAtom == atom.A {
+						walk(c)
+						links = append(links, nodeText(c))
+					}
+				}
+				if text := nodeText(n); text != "" && text != strings.Join(links, "") {
+					body = append(body, text)
+				}
+				return
+			case atom.A:
+				if section.SourceURL == "" {
+					section.SourceURL = attr(n, "href")
+					section.SourceTitle = nodeText(n)
+				}
+				return
+			}
+		}
+		for c := n.FirstChild; c != nil; c = c.NextSibling {
+			walk(c)
+		}
+	}
+	walk(n)
+
+	section.Body = strings.Join(body, "\n")
+	return section
+}
+
+// nodeText はノード配下のテキストを連結し、余分な空白を取り除いて返す
+func nodeText(n *html.Node) string {
+	var b strings.Builder
+	var walk func(n *html.Node)
+	walk = func(n *html.Node) {
+		if n.Type == html.TextNode {
+			b.WriteString(n.Data)
+		}
+		if n.Type == html.ElementNode && n.DataAtom == atom.Br {
+			b.WriteString("\n")
+		}
+		for c := n.FirstChild; c != nil; c = c.NextSibling {
+			walk(c)
+		}
+	}
+	walk(n)
+
+	lines := strings.Split(b.String(), "\n")
+	var trimmed []string
+	for _, line := range lines {
+		if line = strings.Join(strings.Fields(line), " "); line != "" {
+			trimmed = append(trimmed, line)
+		}
+	}
+	return strings.Join(trimmed, "\n")
+}
+
+func attr(n *html.Node, key string) string {
+	for _, a := range n.Attr {
+		if a.Key == key {
+			return a.Val
+		}
+	}
+	return ""
+}
+
+func hasClass(n *html.Node, class string) bool {
+	for _, c := range strings.Fields(attr(n, "class")) {
+		if c == class {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,50 @@
+package blogpost
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseSections(t *testing.T) {
+	t.Parallel()
+
+	content := `
+		<p>どうも。GABAです！</p>
+		<section>
+			<h2>新シーズン開幕！</h2>
+			<p class='date'>公開日：2025-05-01</p>
+			<p>新しいマップが追加されました！<br>ぜひチェックしてください。</p>
+			<a href="https://example.com/1">公式ニュース</a>
+		</section>
+		<section>
+			<h2>コラボスキン登場</h2>
+			<p class="date">公開日：2025-04-30</p>
+			<p>人気キャラクターがやってきます。</p>
+			<p><a href="https://example.com/2">コラボ情報</a></p>
+		</section>`
+
+	got, err := parseSections(content)
+	if err != nil {
+		t.Fatalf("parseSections() error = %v", err)
+	}
+
+	want := []Section{
+		{
+			Heading:     "新シーズン開幕！",
+			Date:        "公開日：2025-05-01",
+			Body:        "新しいマップが追加されました！\nぜひチェックしてください。",
+			SourceTitle: "公式ニュース",
+			SourceURL:   "https://example.com/1",
+		},
+		{
+			Heading:     "コラボスキン登場",
+			Date:        "公開日：2025-04-30",
+			Body:        "人気キャラクターがやってきます。",
+			SourceTitle: "コラボ情報",
+			SourceURL:   "https://example.com/2",
+		},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("parseSections() = %+v, want %+v", got, want)
+	}
+}
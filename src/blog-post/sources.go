@@ -0,0 +1,134 @@
+package blogpost
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+)
+
+const (
+	defaultLocale     = "ja-JP"
+	defaultWindowDays = 7
+)
+
+// regionalLanguages are the languages whose Google News hl parameter includes the region (e.g. en-US)
+var regionalLanguages = map[string]bool{"en": true, "es": true, "pt": true, "zh": true}
+
+// Source はGoogleニュースで記事を探す検索条件、またはニュースサイトのフィードです。
+type Source struct {
+	Query string `json:"query"`
+	// Feed はニュースサイトの RSS・Atom・JSON Feed のURLです。指定した場合は Query・Sites・Locale を使いません
+	Feed string `json:"feed"`
+	// Exclude は検索から除く語です。タイトルに含まれる記事も除きます
+	Exclude []string `json:"exclude"`
+	// Sites は記事を探すサイトのドメインです。空の場合はすべてのサイトから探します
+	Sites []string `json:"sites"`
+	// Locale は "ja-JP" のような言語と地域です。空の場合は ja-JP です
+	Locale string `json:"locale"`
+	// WindowDays は何日前までの記事を探すかです。0の場合は7日です
+	WindowDays int `json:"window_days"`
+}
+
+// SourcesConfig は記事を探す検索条件の一覧です。
+type SourcesConfig struct {
+	Sources []Source `json:"sources"`
+}
+
+// DefaultSources は日本語のフォートナイトのニュースを探す検索条件です。
+var DefaultSources = []Source{
+	{Query: "Fortnite"},
+	{Query: "フォートナイト"},
+	{Query: "Fortnite アップデート"},
+	{Query: "FNCS"},
+}
+
+// newsSources は設定の news_sources（環境変数 NEWS_SOURCES）の検索条件を返します。
+func newsSources() ([]Source, error) {
+	path := os.Getenv("NEWS_SOURCES")
+	if config, err := loadConfig(); err == nil && config.NewsSources != "" {
+		path = config.NewsSources
+	}
+	return loadSources(path)
+}
+
+// loadSources はJSONファイルから検索条件を読み込みます。pathが空の場合やファイルに sources がない場合は DefaultSources を使います。
+func loadSources(path string) ([]Source, error) {
+	if path == "" {
+		return DefaultSources, nil
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read sources: %w", err)
+	}
+	var config SourcesConfig
+	if err := json.Unmarshal(data, &config); err != nil {
+		return nil, fmt.Errorf("failed to parse sources: %w", err)
+	}
+	if len(config.Sources) == 0 {
+		return DefaultSources, nil
+	}
+	for _, source := range config.Sources {
+		if strings.TrimSpace(source.Query) == "" && source.Feed == "" {
+			return nil, fmt.Errorf("source has neither query nor feed")
+		}
+	}
+	return config.Sources, nil
+}
+
+// excludes はタイトルに除く語が含まれるかを返します。Googleニュースの除外が効かない場合があるため、取得した記事でも確認します。
+func (s Source) excludes(title string) bool {
+	title = strings.ToLower(title)
+	for _, word := range s.Exclude {
+		if strings.Contains(title, strings.ToLower(word)) {
+			return true
+		}
+	}
+	return false
+}
+
+// searchQuery はGoogleニュースの検索語を作ります。
+func (s Source) searchQuery(now time.Time) string {
+	terms := []string{s.Query}
+	if len(s.Sites) > 0 {
+		sites := make([]string, len(s.Sites))
+		for i, site := range s.Sites {
+			sites[i] = "site:" + site
+		}
+		terms = append(terms, "("+strings.Join(sites, " OR ")+")")
+	}
+	for _, word := range s.Exclude {
+		terms = append(terms, "-"+word)
+	}
+	terms = append(terms, "after:"+now.AddDate(0, 0, -s.windowDays()).Format("2006-01-02"), "before:"+now.Format("2006-01-02"))
+	return strings.Join(terms, " ")
+}
+
+func (s Source) windowDays() int {
+	if s.WindowDays <= 0 {
+		return defaultWindowDays
+	}
+	return s.WindowDays
+}
+
+// feedURL はGoogleニュースの検索結果のRSSのURLを返します。
+func (s Source) feedURL(baseURL string, now time.Time) string {
+	locale := s.Locale
+	if locale == "" {
+		locale = defaultLocale
+	}
+	lang, region, _ := strings.Cut(locale, "-")
+	hl := lang
+	if regionalLanguages[lang] && region != "" {
+		hl = locale
+	}
+	params := url.Values{
+		"q":    {s.searchQuery(now)},
+		"hl":   {hl},
+		"gl":   {region},
+		"ceid": {region + ":" + lang},
+	}
+	return baseURL + "?" + params.Encode()
+}
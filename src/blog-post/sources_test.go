@@ -0,0 +1,138 @@
+package blogpost
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestSourceFeedURL(t *testing.T) {
+	t.Parallel()
+
+	now := time.Date(2024, 4, 8, 12, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name   string
+		source Source
+		want   url.Values
+	}{
+		{
+			name:   "正常系: 既定の言語と期間",
+			source: Source{Query: "Fortnite"},
+			want:   url.Values{"q": {"Fortnite after:2024-04-01 before:2024-04-08"}, "hl": {"ja"}, "gl": {"JP"}, "ceid": {"JP:ja"}},
+		},
+		{
+			name:   "正常系: サイトと除外語と期間",
+			source: Source{Query: "Fortnite アップデート", Sites: []string{"gamespark.jp", "4gamer.net"}, Exclude: []string{"株価"}, WindowDays: 3},
+			want: url.Values{
+				"q":  {"Fortnite アップデート (site:gamespark.jp OR site:4gamer.net) -株価 after:2024-04-05 before:2024-04-08"},
+				"hl": {"ja"}, "gl": {"JP"}, "ceid": {"JP:ja"},
+			},
+		},
+		{
+			name:   "正常系: 英語",
+			source: Source{Query: "FNCS", Locale: "en-US"},
+			want:   url.Values{"q": {"FNCS after:2024-04-01 before:2024-04-08"}, "hl": {"en-US"}, "gl": {"US"}, "ceid": {"US:en"}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			got, err := url.Parse(tt.source.feedURL("https://news.google.com/rss/search", now))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got.Query().Encode() != tt.want.Encode() {
+				t.Errorf("feedURL() query = %v, want %v", got.Query(), tt.want)
+			}
+		})
+	}
+}
+
+func TestGetLatestFromRSSMultipleSources(t *testing.T) {
+	t.Parallel()
+
+	item := func(title, link, date string) string {
+		return fmt.Sprintf("<item><title>%s</title><link>%s</link><pubDate>%s</pubDate></item>", title, link, date)
+	}
+	feeds := map[string]string{
+		"Fortnite": item("新シーズン開幕", "http://example.com/1", "Mon, 01 Apr 2024 10:00:00 GMT") +
+			item("Fortnite 株価", "http://example.com/stock", "Mon, 01 Apr 2024 11:00:00 GMT"),
+		"フォートナイト": item("新シーズン開幕", "http://example.com/1", "Mon, 01 Apr 2024 10:00:00 GMT") +
+			item("コラボスキン登場", "http://example.com/2", "Mon, 01 Apr 2024 12:00:00 GMT"),
+	}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		query, _, _ := strings.Cut(r.URL.Query().Get("q"), " ")
+		body, ok := feeds[query]
+		if !ok {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		_, _ = fmt.Fprintf(w, "<rss><channel>%s</channel></rss>", body)
+	}))
+	defer server.Close()
+
+	now := time.Date(2024, 4, 2, 0, 0, 0, 0, time.UTC)
+	sources := []Source{{Query: "Fortnite", Exclude: []string{"株価"}}, {Query: "フォートナイト"}, {Query: "FNCS"}}
+	articles, err := getLatestFromRSS(sources, now, server.Client(), server.URL)
+	if err != nil {
+		t.Fatalf("getLatestFromRSS() error = %v", err)
+	}
+	var links []string
+	for _, article := range articles {
+		links = append(links, article.Link)
+	}
+	// FNCS fails, the duplicate and the excluded article are dropped
+	if got := strings.Join(links, ","); got != "http://example.com/2,http://example.com/1" {
+		t.Errorf("links = %s", got)
+	}
+
+	if _, err := getLatestFromRSS([]Source{{Query: "FNCS"}}, now, server.Client(), server.URL); err == nil {
+		t.Error("getLatestFromRSS() should fail when every source fails")
+	}
+}
+
+func TestLoadSources(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	write := func(name, data string) string {
+		path := filepath.Join(dir, name)
+		if err := os.WriteFile(path, []byte(data), 0o600); err != nil {
+			t.Fatal(err)
+		}
+		return path
+	}
+
+	tests := []struct {
+		name    string
+		path    string
+		want    int
+		wantErr bool
+	}{
+		{name: "正常系: 指定なし", path: "", want: len(DefaultSources)},
+		{name: "正常系: ファイルの検索条件", path: write("sources.json", `{"sources": [{"query": "FNCS", "locale": "en-US"}]}`), want: 1},
+		{name: "正常系: 検索条件のないファイル", path: write("empty.json", `{}`), want: len(DefaultSources)},
+		{name: "異常系: 検索語が空", path: write("blank.json", `{"sources": [{"query": " "}]}`), wantErr: true},
+		{name: "異常系: ファイルがない", path: filepath.Join(dir, "missing.json"), wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			got, err := loadSources(tt.path)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("loadSources() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if len(got) != tt.want {
+				t.Errorf("loadSources() = %v", got)
+			}
+		})
+	}
+}
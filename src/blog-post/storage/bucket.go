@@ -0,0 +1,105 @@
+// Package storage は実行ごとに書き換わるデータを保存します。
+// Cloud Functions上ではCloud Storageのバケットを、ローカルではディレクトリを使います。
+package storage
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/url"
+
+	"thiroyoshi.com/video-converter/secret"
+)
+
+// ErrNotFound はオブジェクトが存在しない場合のエラーです。
+var ErrNotFound = errors.New("object not found")
+
+const (
+	storageEndpoint = "https://storage.googleapis.com/storage/v1"
+	uploadEndpoint  = "https://storage.googleapis.com/upload/storage/v1"
+)
+
+// Bucket は Cloud Storage のバケットにオブジェクトを保存します。
+type Bucket struct {
+	name           string
+	endpoint       string
+	uploadEndpoint string
+	httpClient     *http.Client
+	token          func() (string, error)
+}
+
+// NewBucket はバケットの保存先を作成します。バケット自体は事前に作成しておく必要があります。
+func NewBucket(name string) *Bucket {
+	b := &Bucket{
+		name:           name,
+		endpoint:       storageEndpoint,
+		uploadEndpoint: uploadEndpoint,
+		httpClient:     &http.Client{},
+	}
+	b.token = func() (string, error) { return secret.AccessToken(b.httpClient) }
+	return b
+}
+
+// Get はオブジェクトを読み込みます。オブジェクトがない場合は ErrNotFound を返します。
+func (b *Bucket) Get(name string) ([]byte, error) {
+	u := fmt.Sprintf("%s/b/%s/o/%s?alt=media", b.endpoint, url.PathEscape(b.name), url.PathEscape(name))
+	body, status, err := b.do("GET", u, nil)
+	if err != nil {
+		return nil, err
+	}
+	if status == http.StatusNotFound {
+		return nil, ErrNotFound
+	}
+	if status != http.StatusOK {
+		slog.Error("Cloud Storage error response", "status", status, "body", string(body))
+		return nil, fmt.Errorf("failed to read object %s: status code %d", name, status)
+	}
+	return body, nil
+}
+
+// Put はオブジェクトを上書きします。
+func (b *Bucket) Put(name string, value []byte) error {
+	u := fmt.Sprintf("%s/b/%s/o?uploadType=media&name=%s", b.uploadEndpoint, url.PathEscape(b.name), url.QueryEscape(name))
+	body, status, err := b.do("POST", u, value)
+	if err != nil {
+		return err
+	}
+	if status != http.StatusOK {
+		slog.Error("Cloud Storage error response", "status", status, "body", string(body))
+		return fmt.Errorf("failed to write object %s: status code %d", name, status)
+	}
+	return nil
+}
+
+func (b *Bucket) do(method, url string, data []byte) ([]byte, int, error) {
+	token, err := b.token()
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to get access token: %w", err)
+	}
+
+	req, err := http.NewRequest(method, url, bytes.NewReader(data))
+	if err != nil {
+		return nil, 0, err
+	}
+	req.Header.Set("Authorization", "Bearer "+token)
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := b.httpClient.Do(req)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer func() {
+		if cerr := resp.Body.Close(); cerr != nil {
+			slog.Error("failed to close response body", "error", cerr)
+		}
+	}()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, 0, err
+	}
+	return body, resp.StatusCode, nil
+}
@@ -0,0 +1,64 @@
+package storage
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestBucket(t *testing.T) {
+	t.Parallel()
+
+	objects := map[string][]byte{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer test-token" {
+			t.Errorf("Authorization = %q, want bearer token", r.Header.Get("Authorization"))
+		}
+
+		switch {
+		case r.Method == "POST" && r.URL.Path == "/upload/b/state/o":
+			if r.URL.Query().Get("uploadType") != "media" {
+				t.Errorf("uploadType = %q, want media", r.URL.Query().Get("uploadType"))
+			}
+			body, _ := io.ReadAll(r.Body)
+			objects[r.URL.Query().Get("name")] = body
+			_, _ = w.Write([]byte(`{"name": "article-history"}`))
+		case r.Method == "GET" && r.URL.Path == "/b/state/o/article-history":
+			if r.URL.Query().Get("alt") != "media" {
+				t.Errorf("alt = %q, want media", r.URL.Query().Get("alt"))
+			}
+			data, ok := objects["article-history"]
+			if !ok {
+				w.WriteHeader(http.StatusNotFound)
+				return
+			}
+			_, _ = w.Write(data)
+		default:
+			t.Errorf("unexpected request: %s %s", r.Method, r.URL.Path)
+		}
+	}))
+	defer server.Close()
+
+	b := NewBucket("state")
+	b.endpoint = server.URL
+	b.uploadEndpoint = server.URL + "/upload"
+	b.token = func() (string, error) { return "test-token", nil }
+
+	if _, err := b.Get("article-history"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Get() error = %v, want ErrNotFound", err)
+	}
+	for _, value := range []string{`{"entries":[]}`, `{"entries":[{"title":"a"}]}`} {
+		if err := b.Put("article-history", []byte(value)); err != nil {
+			t.Fatalf("Put() error = %v", err)
+		}
+		got, err := b.Get("article-history")
+		if err != nil {
+			t.Fatalf("Get() error = %v", err)
+		}
+		if string(got) != value {
+			t.Errorf("Get() = %q, want %q", got, value)
+		}
+	}
+}
@@ -0,0 +1,47 @@
+package storage
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// Dir はディレクトリ内のファイルにオブジェクトを保存します。ローカル実行用です。
+type Dir struct {
+	path string
+}
+
+// NewDir はディレクトリの保存先を作成します。
+func NewDir(path string) *Dir {
+	return &Dir{path: path}
+}
+
+// Get はオブジェクトを読み込みます。オブジェクトがない場合は ErrNotFound を返します。
+func (d *Dir) Get(name string) ([]byte, error) {
+	data, err := os.ReadFile(filepath.Join(d.path, name))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read object %s: %w", name, err)
+	}
+	return data, nil
+}
+
+// Put はオブジェクトを上書きします。
+func (d *Dir) Put(name string, value []byte) error {
+	if err := os.MkdirAll(d.path, 0o755); err != nil {
+		return fmt.Errorf("failed to create directory: %w", err)
+	}
+
+	// Write to a temporary file first so that a crash never leaves a partial object
+	tmp := filepath.Join(d.path, name+".tmp")
+	if err := os.WriteFile(tmp, value, 0o644); err != nil {
+		return fmt.Errorf("failed to write object %s: %w", name, err)
+	}
+	if err := os.Rename(tmp, filepath.Join(d.path, name)); err != nil {
+		return fmt.Errorf("failed to write object %s: %w", name, err)
+	}
+	return nil
+}
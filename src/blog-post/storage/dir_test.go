@@ -0,0 +1,27 @@
+package storage
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestDir(t *testing.T) {
+	t.Parallel()
+
+	d := NewDir(t.TempDir() + "/state")
+	if _, err := d.Get("article-history"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Get() error = %v, want ErrNotFound", err)
+	}
+	for _, value := range []string{`{"entries":[]}`, `{"entries":[{"title":"a"}]}`} {
+		if err := d.Put("article-history", []byte(value)); err != nil {
+			t.Fatalf("Put() error = %v", err)
+		}
+		got, err := d.Get("article-history")
+		if err != nil {
+			t.Fatalf("Get() error = %v", err)
+		}
+		if string(got) != value {
+			t.Errorf("Get() = %q, want %q", got, value)
+		}
+	}
+}
@@ -0,0 +1,180 @@
+package blogpost
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+	"unicode/utf8"
+
+	"thiroyoshi.com/blog-post/llm"
+)
+
+// maxRepairs is how many times a malformed response is sent back to the model to be fixed
+const maxRepairs = 2
+
+// contentSchema は ContentJson のJSON Schemaです。
+var contentSchema = &llm.Schema{
+	Name:        "blog_post",
+	Description: "ブログ記事のタイトルとHTMLの本文",
+	JSON: map[string]any{
+		"type": "object",
+		"properties": map[string]any{
+			"title":   map[string]any{"type": "string", "description": "記事のタイトル"},
+			"content": map[string]any{"type": "string", "description": "記事本文のHTML。各トピックは<section>タグで囲む"},
+		},
+		"required":             []string{"title", "content"},
+		"additionalProperties": false,
+	},
+}
+
+// Prompt for repairing a response that is not the requested JSON
+var repairPrompt = `
+	以下の出力は、求めているJSONの形式になっていません。
+
+	【問題点】
+	%s
+
+	【出力】
+	%s
+
+	内容は変えずに問題点だけを直し、次の形式のJSONオブジェクトのみを出力してください。バッククオートや説明文は付けないこと。
+	{"title": "記事のタイトル", "content": "記事本文のHTML。各トピックは<section>タグで囲む"}
+	`
+
+// errNoJSON is returned when the response contains no JSON object
+var errNoJSON = errors.New("no JSON object in the response")
+
+// ContentError は出力が ContentJson として正しくない理由です。
+type ContentError struct {
+	Problems []string
+}
+
+func (e *ContentError) Error() string {
+	return "invalid content json: " + strings.Join(e.Problems, "; ")
+}
+
+// extractJSON はモデルの出力からJSONオブジェクトを取り出します。
+// 前後の説明文やコードフェンスを除き、文字列の中の改行などの制御文字はエスケープします。
+// 最初に見つかった、括弧の対応が取れていてJSONとして読めるオブジェクトを返します。
+func extractJSON(text string) (string, error) {
+	for start := strings.IndexByte(text, '{'); start >= 0; {
+		if candidate, ok := balancedObject(text[start:]); ok && json.Valid([]byte(candidate)) {
+			return candidate, nil
+		}
+		next := strings.IndexByte(text[start+1:], '{')
+		if next < 0 {
+			break
+		}
+		start += next + 1
+	}
+	return "", errNoJSON
+}
+
+// balancedObject は { から対応する } までを返します。文字列の中の改行・タブなどの制御文字はエスケープします。
+func balancedObject(text string) (string, bool) {
+	var b strings.Builder
+	depth := 0
+	inString, escaped := false, false
+	for _, r := range text {
+		switch {
+		case inString && escaped:
+			escaped = false
+		case inString && r == '\\':
+			escaped = true
+		case inString && r == '"':
+			inString = false
+		case inString && r < 0x20:
+			// Models sometimes put raw line breaks in the HTML string
+			_, _ = fmt.Fprintf(&b, `\u%04x`, r)
+			continue
+		case inString:
+		case r == '"':
+			inString = true
+		case r == '{':
+			depth++
+		case r == '}':
+			depth--
+		}
+		b.WriteRune(r)
+		if depth == 0 {
+			return b.String(), true
+		}
+	}
+	return "", false
+}
+
+// parseContent はモデルの出力から ContentJson を取り出して確認します。
+func parseContent(resp string) (ContentJson, error) {
+	var content ContentJson
+	object, err := extractJSON(resp)
+	if err != nil {
+		return content, &ContentError{Problems: []string{"JSONオブジェクトがない"}}
+	}
+	if err := json.Unmarshal([]byte(object), &content); err != nil {
+		return content, &ContentError{Problems: []string{fmt.Sprintf("JSONの形式が正しくない（%v）", err)}}
+	}
+	return content, validateContent(content)
+}
+
+// validateContent は ContentJson の項目を確認します。
+func validateContent(content ContentJson) error {
+	var problems []string
+	if strings.TrimSpace(content.Title) == "" {
+		problems = append(problems, "title が空")
+	} else if strings.ContainsAny(content.Title, "<>") {
+		problems = append(problems, "title にHTMLタグが含まれている")
+	}
+	if strings.TrimSpace(content.Content) == "" {
+		problems = append(problems, "content が空")
+	} else if !strings.Contains(content.Content, "<section") {
+		problems = append(problems, "content に<section>タグで囲んだトピックがない")
+	}
+	if len(problems) > 0 {
+		return &ContentError{Problems: problems}
+	}
+	return nil
+}
+
+// generateContent は構造化出力で ContentJson を生成します。
+// 出力が正しくない場合は、問題点と出力を示して直すよう maxRepairs 回まで依頼します。
+func generateContent(generator llm.Generator, req llm.Request, stage string) (ContentJson, error) {
+	req.Schema = contentSchema
+	resp, err := generator.Generate(context.TODO(), req)
+	if err != nil {
+		return ContentJson{}, err
+	}
+
+	for attempt := 0; ; attempt++ {
+		content, err := parseContent(resp)
+		var contentErr *ContentError
+		if err == nil || !errors.As(err, &contentErr) {
+			return content, err
+		}
+		if attempt == maxRepairs {
+			slog.Error("Failed to repair response", "stage", stage, "response", resp, "error", err)
+			return content, err
+		}
+
+		slog.Warn("Response is not valid content JSON, asking for a repair", "stage", stage, "problems", contentErr.Problems, "attempt", attempt+1, "length", utf8.RuneCountInString(resp))
+		resp, err = generator.Generate(context.TODO(), llm.Request{
+			Prompt: fmt.Sprintf(repairPrompt, "・"+strings.Join(contentErr.Problems, "\n\t・"), resp),
+			Schema: contentSchema,
+		})
+		if err != nil {
+			return ContentJson{}, fmt.Errorf("failed to repair response: %w", err)
+		}
+	}
+}
+
+// retryable は修正しても正しくならなかった出力を、記事の生成からやり直せるかを返します。
+func retryable(err error, attempt, maxRetries int) bool {
+	var contentErr *ContentError
+	if !errors.As(err, &contentErr) || attempt == maxRetries-1 {
+		return false
+	}
+	slog.Warn("Regenerating post after invalid response", "problems", contentErr.Problems, "attempt", attempt+1, "maxRetries", maxRetries)
+	return true
+}
@@ -0,0 +1,127 @@
+package blogpost
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"thiroyoshi.com/blog-post/llm"
+)
+
+func TestExtractJSON(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		text    string
+		want    string
+		wantErr bool
+	}{
+		{name: "正常系: JSONのみ", text: `{"title":"t","content":"c"}`, want: `{"title":"t","content":"c"}`},
+		{name: "正常系: コードフェンス", text: "```json\n{\"title\":\"t\"}\n```", want: `{"title":"t"}`},
+		{name: "正常系: 前後の説明文", text: "以下が記事です。\n{\"title\":\"t\"}\nいかがでしょうか？ {追記}", want: `{"title":"t"}`},
+		{name: "正常系: 文字列の中の括弧", text: `{"title":"{新シーズン}","content":"<p>a}b</p>"}`, want: `{"title":"{新シーズン}","content":"<p>a}b</p>"}`},
+		{name: "正常系: 文字列の中の改行", text: "{\"content\":\"<p>1行目</p>\n<p>2行目</p>\"}", want: `{"content":"<p>1行目</p>\u000a<p>2行目</p>"}`},
+		{name: "正常系: 読めない括弧の後のJSON", text: `{説明} {"title":"t"}`, want: `{"title":"t"}`},
+		{name: "異常系: JSONがない", text: "記事を作成できませんでした", wantErr: true},
+		{name: "異常系: 閉じていない", text: `{"title":"t"`, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			got, err := extractJSON(tt.text)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("extractJSON() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("extractJSON() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseContent(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name         string
+		resp         string
+		wantProblems []string
+	}{
+		{name: "正常系: 正しい出力", resp: `{"title":"新シーズン開幕","content":"<section><h2>見出し</h2></section>"}`},
+		{name: "異常系: JSONがない", resp: "申し訳ありません", wantProblems: []string{"JSONオブジェクトがない"}},
+		{name: "異常系: 型が違う", resp: `{"title":1,"content":"<section></section>"}`, wantProblems: []string{"JSONの形式が正しくない"}},
+		{name: "異常系: 項目が空", resp: `{"title":"","content":""}`, wantProblems: []string{"title が空", "content が空"}},
+		{name: "異常系: セクションがない", resp: `{"title":"<b>t</b>","content":"<p>本文</p>"}`, wantProblems: []string{"title にHTMLタグ", "<section>タグ"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			_, err := parseContent(tt.resp)
+			if tt.wantProblems == nil {
+				if err != nil {
+					t.Errorf("parseContent() error = %v", err)
+				}
+				return
+			}
+			var contentErr *ContentError
+			if !errors.As(err, &contentErr) || len(contentErr.Problems) != len(tt.wantProblems) {
+				t.Fatalf("parseContent() error = %v, want %d problems", err, len(tt.wantProblems))
+			}
+			for i, want := range tt.wantProblems {
+				if !strings.Contains(contentErr.Problems[i], want) {
+					t.Errorf("problem %d = %q, want %q", i, contentErr.Problems[i], want)
+				}
+			}
+		})
+	}
+}
+
+func TestGenerateContent(t *testing.T) {
+	t.Parallel()
+
+	valid := `{"title":"新シーズン開幕","content":"<section><h2>見出し</h2></section>"}`
+	tests := []struct {
+		name      string
+		responses []string
+		err       error
+		wantCalls int
+		wantErr   bool
+	}{
+		{name: "正常系: そのまま読める", responses: []string{valid}, wantCalls: 1},
+		{name: "正常系: 修正で直る", responses: []string{`{"title":"","content":"<section></section>"}`, valid}, wantCalls: 2},
+		{name: "異常系: 修正しても直らない", responses: []string{"記事を作成できませんでした"}, wantCalls: 1 + maxRepairs, wantErr: true},
+		{name: "異常系: 生成の失敗", err: errors.New("rate limited"), wantCalls: 1, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			fake := &llm.Fake{Responses: tt.responses, Err: tt.err}
+			content, err := generateContent(fake, llm.Request{Prompt: "記事を書いて"}, stageDraft)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("generateContent() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			requests := fake.Requests()
+			if len(requests) != tt.wantCalls {
+				t.Fatalf("requests = %d, want %d", len(requests), tt.wantCalls)
+			}
+			for _, req := range requests {
+				if req.Schema != contentSchema {
+					t.Errorf("request has no schema: %+v", req)
+				}
+			}
+			if tt.wantErr {
+				return
+			}
+			if content.Title != "新シーズン開幕" {
+				t.Errorf("Title = %q", content.Title)
+			}
+			if tt.wantCalls > 1 && !strings.Contains(requests[1].Prompt, "title が空") {
+				t.Errorf("repair prompt does not name the problem: %q", requests[1].Prompt)
+			}
+		})
+	}
+}
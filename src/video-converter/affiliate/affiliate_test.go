@@ -0,0 +1,191 @@
+package affiliate
+
+import (
+	"math/rand/v2"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func testCatalog() *Catalog {
+	return &Catalog{
+		Categories: map[string][]string{
+			"controller": {"コントローラー", "DualSense"},
+			"audio":      {"イヤホン", "足音"},
+			"pc":         {"PC版"},
+		},
+		Products: []Product{
+			{ID: "pad", Title: "Pad", URL: "https://example.com/pad", Category: "controller", Weight: 3},
+			{ID: "pad2", Title: "Pad 2", URL: "https://example.com/pad2", Category: "controller", Weight: 1},
+			{ID: "buds", Title: "Buds", URL: "https://example.com/buds", Category: "audio", Weight: 1},
+			{ID: "mic", Title: "Mic", Label: "マイク", URL: "https://example.com/mic", Category: "audio"},
+		},
+		Setup: []string{"mic"},
+	}
+}
+
+func TestLoad(t *testing.T) {
+	t.Parallel()
+
+	catalog, err := Load("")
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if len(catalog.Linkable()) == 0 || len(catalog.SetupProducts()) != len(catalog.Setup) {
+		t.Errorf("catalog = %+v", catalog)
+	}
+}
+
+func TestValidate(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name string
+		edit func(c *Catalog)
+		want []string
+	}{
+		{name: "正常系: 問題なし", edit: func(c *Catalog) {}},
+		{
+			name: "異常系: URLの重複",
+			edit: func(c *Catalog) { c.Products[1].URL = c.Products[0].URL },
+			want: []string{"pad2: same url as pad"},
+		},
+		{
+			name: "異常系: IDの重複と未定義のカテゴリ",
+			edit: func(c *Catalog) { c.Products[2].ID = "pad"; c.Products[2].Category = "mouse" },
+			want: []string{"pad: duplicate id", `pad: unknown category "mouse"`},
+		},
+		{
+			name: "異常系: プレイ環境の商品",
+			edit: func(c *Catalog) { c.Setup = []string{"mic", "buds", "keyboard"} },
+			want: []string{"buds: label is empty but the product is in setup", "keyboard: setup refers to an unknown product"},
+		},
+		{
+			name: "異常系: 不正なURL",
+			edit: func(c *Catalog) { c.Products[0].URL = "amzn.to/abc" },
+			want: []string{`pad: invalid url "amzn.to/abc"`},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			catalog := testCatalog()
+			tt.edit(catalog)
+			var got []string
+			for _, p := range catalog.Validate() {
+				got = append(got, p.String())
+			}
+			if strings.Join(got, "|") != strings.Join(tt.want, "|") {
+				t.Errorf("Validate() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMatch(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name string
+		text string
+		want string
+	}{
+		{name: "正常系: コントローラーのニュース", text: "新しいdualsenseが発売", want: "pad,pad2"},
+		{name: "正常系: 複数のカテゴリ", text: "足音が聞きやすくなり、コントローラーの感度も調整", want: "pad,pad2,buds"},
+		{name: "正常系: 重み0の商品は選ばない", text: "マイクの設定", want: ""},
+		{name: "正常系: 一致なし", text: "新しいスキンが登場", want: ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			var ids []string
+			for _, p := range testCatalog().Match(tt.text) {
+				ids = append(ids, p.ID)
+			}
+			if got := strings.Join(ids, ","); got != tt.want {
+				t.Errorf("Match() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPick(t *testing.T) {
+	t.Parallel()
+
+	r := rand.New(rand.NewPCG(1, 2))
+	products := testCatalog().Products
+	counts := map[string]int{}
+	for range 1000 {
+		p, ok := Pick(r, products, map[string]bool{"buds": true})
+		if !ok {
+			t.Fatal("Pick() returned no product")
+		}
+		counts[p.ID]++
+	}
+	// pad has three times the weight of pad2, buds is excluded and mic has no weight
+	if counts["buds"] != 0 || counts["mic"] != 0 || counts["pad"] < 2*counts["pad2"] {
+		t.Errorf("counts = %v", counts)
+	}
+	if _, ok := Pick(r, products, map[string]bool{"pad": true, "pad2": true, "buds": true}); ok {
+		t.Error("Pick() should return false when every product is excluded")
+	}
+}
+
+func TestCheckLinks(t *testing.T) {
+	t.Parallel()
+
+	mux := http.NewServeMux()
+	server := httptest.NewServer(mux)
+	defer server.Close()
+	mux.HandleFunc("/dp/B0AAAAAAAA", func(w http.ResponseWriter, r *http.Request) {})
+	mux.HandleFunc("/short/a", func(w http.ResponseWriter, r *http.Request) {
+		http.Redirect(w, r, "/dp/B0AAAAAAAA?tag=a", http.StatusMovedPermanently)
+	})
+	mux.HandleFunc("/short/b", func(w http.ResponseWriter, r *http.Request) {
+		http.Redirect(w, r, "/dp/B0AAAAAAAA/?tag=b", http.StatusMovedPermanently)
+	})
+	mux.HandleFunc("/no-head", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodHead {
+			w.WriteHeader(http.StatusMethodNotAllowed)
+		}
+	})
+
+	products := []Product{
+		{ID: "a", URL: server.URL + "/short/a"},
+		{ID: "b", URL: server.URL + "/short/b"},
+		{ID: "dead", URL: server.URL + "/gone"},
+		{ID: "get", URL: server.URL + "/no-head"},
+	}
+	results, problems := CheckLinks(server.Client(), products, 2)
+	if results[3].StatusCode != http.StatusOK {
+		t.Errorf("GET fallback status = %d", results[3].StatusCode)
+	}
+	var got []string
+	for _, p := range problems {
+		got = append(got, p.ProductID)
+	}
+	if strings.Join(got, ",") != "b,dead" {
+		t.Errorf("problems = %v", problems)
+	}
+}
+
+func TestProductKey(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		url  string
+		want string
+	}{
+		{url: "https://www.amazon.co.jp/-/ja/dp/B0CKXWZ1XX/ref=sr_1_1?tag=gaba-22", want: "amazon:B0CKXWZ1XX"},
+		{url: "https://www.amazon.co.jp/gp/product/B0CKXWZ1XX?psc=1", want: "amazon:B0CKXWZ1XX"},
+		{url: "https://shop.example.com/items/1/?ref=x", want: "shop.example.com/items/1"},
+	}
+	for _, tt := range tests {
+		if got := productKey(tt.url); got != tt.want {
+			t.Errorf("productKey(%q) = %s, want %s", tt.url, got, tt.want)
+		}
+	}
+}
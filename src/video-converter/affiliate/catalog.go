@@ -0,0 +1,199 @@
+// Package affiliate はブログ記事と動画の説明に載せるアフィリエイトリンクのカタログです。
+// 商品のタイトル・URL・カテゴリ・重みを catalog.json にまとめ、記事のトピックに合う商品を選びます。
+package affiliate
+
+import (
+	_ "embed"
+	"encoding/json"
+	"fmt"
+	"math/rand/v2"
+	"net/url"
+	"os"
+	"strings"
+)
+
+//go:embed catalog.json
+var defaultCatalog []byte
+
+// Product はカタログの商品です。
+type Product struct {
+	ID    string `json:"id"`
+	Title string `json:"title"`
+	// Label は動画の説明のプレイ環境で商品の前に付ける名前です（例: マイク）
+	Label    string `json:"label"`
+	URL      string `json:"url"`
+	Category string `json:"category"`
+	// Weight はブログ記事で選ばれる重みです。0の場合はブログ記事には載せません
+	Weight int `json:"weight"`
+}
+
+// Catalog は商品の一覧です。
+type Catalog struct {
+	// Categories はカテゴリごとのキーワードです。記事のトピックにキーワードが含まれるとそのカテゴリの商品を選びます
+	Categories map[string][]string `json:"categories"`
+	Products   []Product           `json:"products"`
+	// Setup は動画の説明のプレイ環境に載せる商品のIDです。この順に載せます
+	Setup []string `json:"setup"`
+}
+
+// Problem はカタログやリンクの問題です。
+type Problem struct {
+	ProductID string
+	Message   string
+}
+
+func (p Problem) String() string {
+	if p.ProductID == "" {
+		return p.Message
+	}
+	return p.ProductID + ": " + p.Message
+}
+
+// Load はJSONファイルからカタログを読み込みます。pathが空の場合はこのパッケージの catalog.json を使います。
+// Validate で問題が見つかった場合はエラーを返します。
+func Load(path string) (*Catalog, error) {
+	catalog, err := Read(path)
+	if err != nil {
+		return nil, err
+	}
+	if problems := catalog.Validate(); len(problems) > 0 {
+		return nil, fmt.Errorf("invalid affiliate catalog: %s (and %d more)", problems[0], len(problems)-1)
+	}
+	return catalog, nil
+}
+
+// Read は Validate を通さずにカタログを読み込みます。カタログの問題をすべて確認するときに使います。
+func Read(path string) (*Catalog, error) {
+	data := defaultCatalog
+	if path != "" {
+		var err error
+		if data, err = os.ReadFile(path); err != nil {
+			return nil, fmt.Errorf("failed to read affiliate catalog: %w", err)
+		}
+	}
+	var catalog Catalog
+	if err := json.Unmarshal(data, &catalog); err != nil {
+		return nil, fmt.Errorf("failed to parse affiliate catalog: %w", err)
+	}
+	return &catalog, nil
+}
+
+// Validate はIDやURLの重複、未定義のカテゴリなど、リンクにアクセスせずに分かる問題を返します。
+func (c *Catalog) Validate() []Problem {
+	var problems []Problem
+	ids := map[string]bool{}
+	urls := map[string]string{}
+	for _, p := range c.Products {
+		add := func(format string, args ...any) {
+			problems = append(problems, Problem{ProductID: p.ID, Message: fmt.Sprintf(format, args...)})
+		}
+		switch {
+		case p.ID == "":
+			add("id is empty (%s)", p.Title)
+		case ids[p.ID]:
+			add("duplicate id")
+		}
+		ids[p.ID] = true
+		if p.Title == "" {
+			add("title is empty")
+		}
+		if u, err := url.Parse(p.URL); err != nil || (u.Scheme != "https" && u.Scheme != "http") || u.Host == "" {
+			add("invalid url %q", p.URL)
+		} else if other, ok := urls[p.URL]; ok {
+			add("same url as %s", other)
+		} else {
+			urls[p.URL] = p.ID
+		}
+		if _, ok := c.Categories[p.Category]; !ok {
+			add("unknown category %q", p.Category)
+		}
+		if p.Weight < 0 {
+			add("weight is negative")
+		}
+	}
+	for _, id := range c.Setup {
+		if p, ok := c.product(id); !ok {
+			problems = append(problems, Problem{ProductID: id, Message: "setup refers to an unknown product"})
+		} else if p.Label == "" {
+			problems = append(problems, Problem{ProductID: id, Message: "label is empty but the product is in setup"})
+		}
+	}
+	return problems
+}
+
+func (c *Catalog) product(id string) (Product, bool) {
+	for _, p := range c.Products {
+		if p.ID == id {
+			return p, true
+		}
+	}
+	return Product{}, false
+}
+
+// SetupProducts は動画の説明のプレイ環境に載せる商品を返します。
+func (c *Catalog) SetupProducts() []Product {
+	products := make([]Product, 0, len(c.Setup))
+	for _, id := range c.Setup {
+		if p, ok := c.product(id); ok {
+			products = append(products, p)
+		}
+	}
+	return products
+}
+
+// Match はテキストにキーワードが含まれるカテゴリの、ブログ記事に載せる商品を返します。
+// キーワードは大文字と小文字を区別しません。
+func (c *Catalog) Match(text string) []Product {
+	text = strings.ToLower(text)
+	matched := map[string]bool{}
+	for category, keywords := range c.Categories {
+		for _, keyword := range keywords {
+			if strings.Contains(text, strings.ToLower(keyword)) {
+				matched[category] = true
+				break
+			}
+		}
+	}
+	var products []Product
+	for _, p := range c.Products {
+		if p.Weight > 0 && matched[p.Category] {
+			products = append(products, p)
+		}
+	}
+	return products
+}
+
+// Linkable はブログ記事に載せるすべての商品を返します。
+func (c *Catalog) Linkable() []Product {
+	var products []Product
+	for _, p := range c.Products {
+		if p.Weight > 0 {
+			products = append(products, p)
+		}
+	}
+	return products
+}
+
+// Pick は重みに比例した確率で商品を1つ選びます。exclude のIDの商品は選びません。
+func Pick(r *rand.Rand, products []Product, exclude map[string]bool) (Product, bool) {
+	total := 0
+	for _, p := range products {
+		if p.Weight > 0 && !exclude[p.ID] {
+			total += p.Weight
+		}
+	}
+	if total == 0 {
+		return Product{}, false
+	}
+	n := r.IntN(total)
+	for _, p := range products {
+		if p.Weight <= 0 || exclude[p.ID] {
+			continue
+		}
+		if n < p.Weight {
+			return p, true
+		}
+		n -= p.Weight
+	}
+	return Product{}, false
+}
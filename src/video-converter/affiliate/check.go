@@ -0,0 +1,111 @@
+package affiliate
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strings"
+	"sync"
+)
+
+// userAgent is sent because some shops reject requests without one
+const userAgent = "Mozilla/5.0 (compatible; affiliate-check)"
+
+// asinPattern extracts the product ID from an Amazon product URL
+var asinPattern = regexp.MustCompile(`/(?:dp|gp/product|gp/aw/d)/([A-Z0-9]{10})`)
+
+// LinkResult はリンクの確認結果です。
+type LinkResult struct {
+	Product    Product
+	StatusCode int
+	// FinalURL はリダイレクトを辿った後のURLです
+	FinalURL string
+	Err      error
+}
+
+// CheckLinks はすべての商品のリンクにアクセスし、リンク切れと、短縮URLが違っても同じ商品を指しているリンクを返します。
+// workers 件ずつ並行して確認します。
+func CheckLinks(client *http.Client, products []Product, workers int) ([]LinkResult, []Problem) {
+	results := make([]LinkResult, len(products))
+	jobs := make(chan int)
+	var wg sync.WaitGroup
+	for range max(workers, 1) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				results[i] = checkLink(client, products[i])
+			}
+		}()
+	}
+	for i := range products {
+		jobs <- i
+	}
+	close(jobs)
+	wg.Wait()
+
+	var problems []Problem
+	seen := map[string]string{}
+	for _, r := range results {
+		switch {
+		case r.Err != nil:
+			problems = append(problems, Problem{ProductID: r.Product.ID, Message: fmt.Sprintf("request failed: %v", r.Err)})
+			continue
+		case r.StatusCode == http.StatusNotFound || r.StatusCode == http.StatusGone:
+			problems = append(problems, Problem{ProductID: r.Product.ID, Message: fmt.Sprintf("dead link (%d) %s", r.StatusCode, r.FinalURL)})
+			continue
+		case r.StatusCode >= 400:
+			problems = append(problems, Problem{ProductID: r.Product.ID, Message: fmt.Sprintf("unexpected status %d %s", r.StatusCode, r.FinalURL)})
+		}
+		key := productKey(r.FinalURL)
+		if other, ok := seen[key]; ok {
+			problems = append(problems, Problem{ProductID: r.Product.ID, Message: fmt.Sprintf("points to the same page as %s (%s)", other, key)})
+			continue
+		}
+		seen[key] = r.Product.ID
+	}
+	return results, problems
+}
+
+func checkLink(client *http.Client, product Product) LinkResult {
+	result := LinkResult{Product: product}
+	resp, err := get(client, http.MethodHead, product.URL)
+	// Some shops do not allow HEAD
+	if err == nil && (resp.StatusCode == http.StatusMethodNotAllowed || resp.StatusCode == http.StatusForbidden) {
+		_ = resp.Body.Close()
+		resp, err = get(client, http.MethodGet, product.URL)
+	}
+	if err != nil {
+		result.Err = err
+		return result
+	}
+	_ = resp.Body.Close()
+	result.StatusCode = resp.StatusCode
+	result.FinalURL = resp.Request.URL.String()
+	return result
+}
+
+func get(client *http.Client, method, rawURL string) (*http.Response, error) {
+	req, err := http.NewRequest(method, rawURL, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("User-Agent", userAgent)
+	return client.Do(req)
+}
+
+// productKey は同じ商品のページを同じ値にします。Amazonの商品ページは商品IDで比べ、それ以外はクエリを除いたURLで比べます。
+func productKey(rawURL string) string {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return rawURL
+	}
+	host := strings.TrimPrefix(u.Host, "www.")
+	if strings.HasPrefix(host, "amazon.") {
+		if m := asinPattern.FindStringSubmatch(u.Path); m != nil {
+			return "amazon:" + m[1]
+		}
+	}
+	return host + strings.TrimSuffix(u.Path, "/")
+}
@@ -0,0 +1,176 @@
+package bluesky
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+)
+
+const (
+	// DefaultPDS is the PDS used when BLUESKY_PDS_URL is not set
+	DefaultPDS = "https://bsky.social"
+	// Collection of Bluesky posts
+	postCollection = "app.bsky.feed.post"
+	// MaxGraphemes はBlueskyの投稿本文の上限です。
+	MaxGraphemes = 300
+)
+
+// ErrNotConfigured はBlueskyの認証情報が設定されていないことを表します。
+var ErrNotConfigured = errors.New("bluesky credentials are not configured")
+
+// Client はAT ProtocolのPDSにアプリパスワードで接続するクライアントです。
+type Client struct {
+	httpClient *http.Client
+	pds        string
+	identifier string
+	password   string
+
+	did       string
+	accessJwt string
+}
+
+// NewClient はBlueskyのクライアントを作成します。identifierにはハンドルかメールアドレスを指定します。
+func NewClient(pds, identifier, password string) *Client {
+	if pds == "" {
+		pds = DefaultPDS
+	}
+	return &Client{
+		httpClient: &http.Client{Timeout: 30 * time.Second},
+		pds:        strings.TrimRight(pds, "/"),
+		identifier: identifier,
+		password:   password,
+	}
+}
+
+// NewClientFromEnv は BLUESKY_HANDLE、BLUESKY_APP_PASSWORD、BLUESKY_PDS_URL からクライアントを作成します。
+// 認証情報が設定されていない場合は ErrNotConfigured を返します。
+func NewClientFromEnv() (*Client, error) {
+	handle := os.Getenv("BLUESKY_HANDLE")
+	password := os.Getenv("BLUESKY_APP_PASSWORD")
+	if handle == "" || password == "" {
+		return nil, ErrNotConfigured
+	}
+	return NewClient(os.Getenv("BLUESKY_PDS_URL"), handle, password), nil
+}
+
+// login は com.atproto.server.createSession でセッションを作成します。
+func (c *Client) login() error {
+	if c.accessJwt != "" {
+		return nil
+	}
+
+	var session struct {
+		DID       string `json:"did"`
+		AccessJwt string `json:"accessJwt"`
+	}
+	payload := map[string]string{"identifier": c.identifier, "password": c.password}
+	if err := c.procedure("com.atproto.server.createSession", payload, &session); err != nil {
+		return fmt.Errorf("failed to create bluesky session: %w", err)
+	}
+	c.did, c.accessJwt = session.DID, session.AccessJwt
+	return nil
+}
+
+// Blob はuploadBlobで返されるBlobの参照です。
+type Blob struct {
+	Type string `json:"$type"`
+	Ref  struct {
+		Link string `json:"$link"`
+	} `json:"ref"`
+	MimeType string `json:"mimeType"`
+	Size     int    `json:"size"`
+}
+
+// UploadBlob は画像などのBlobをアップロードします。
+func (c *Client) UploadBlob(data []byte, mimeType string) (*Blob, error) {
+	if err := c.login(); err != nil {
+		return nil, err
+	}
+
+	var result struct {
+		Blob Blob `json:"blob"`
+	}
+	if err := c.call("com.atproto.repo.uploadBlob", mimeType, bytes.NewReader(data), &result); err != nil {
+		return nil, fmt.Errorf("failed to upload blob: %w", err)
+	}
+	return &result.Blob, nil
+}
+
+// CreatePost は app.bsky.feed.post のレコードを作成し、作成したレコードのURIを返します。
+func (c *Client) CreatePost(post Post) (string, error) {
+	if n := len([]rune(post.Text)); n > MaxGraphemes {
+		return "", fmt.Errorf("bluesky post is too long: %d characters", n)
+	}
+	if err := c.login(); err != nil {
+		return "", err
+	}
+
+	post.Type = postCollection
+	if post.CreatedAt == "" {
+		post.CreatedAt = time.Now().UTC().Format(time.RFC3339)
+	}
+
+	payload := map[string]any{
+		"repo":       c.did,
+		"collection": postCollection,
+		"record":     post,
+	}
+	var result struct {
+		URI string `json:"uri"`
+	}
+	if err := c.procedure("com.atproto.repo.createRecord", payload, &result); err != nil {
+		return "", fmt.Errorf("failed to create bluesky post: %w", err)
+	}
+	slog.Info("Bluesky post created", "uri", result.URI)
+	return result.URI, nil
+}
+
+// procedure はJSONを送るXRPCのprocedureを呼び出します。
+func (c *Client) procedure(nsid string, payload, out any) error {
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+	return c.call(nsid, "application/json", bytes.NewReader(body), out)
+}
+
+func (c *Client) call(nsid, contentType string, body io.Reader, out any) error {
+	req, err := http.NewRequest("POST", c.pds+"/xrpc/"+nsid, body)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", contentType)
+	if c.accessJwt != "" {
+		req.Header.Set("Authorization", "Bearer "+c.accessJwt)
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err := resp.Body.Close(); err != nil {
+			slog.Error("Failed to close response body", "error", err)
+		}
+	}()
+
+	respBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+	if resp.StatusCode != http.StatusOK {
+		slog.Error("Bluesky API error response", "nsid", nsid, "status", resp.Status, "body", string(respBody))
+		return fmt.Errorf("%s returned unexpected status code: %d", nsid, resp.StatusCode)
+	}
+	if out == nil {
+		return nil
+	}
+	return json.Unmarshal(respBody, out)
+}
@@ -0,0 +1,62 @@
+package bluesky
+
+import (
+	"regexp"
+	"strings"
+)
+
+// Facet は本文中のリンクやハッシュタグの位置を表します。位置はUTF-8のバイトオフセットで指定します。
+type Facet struct {
+	Index    ByteSlice      `json:"index"`
+	Features []FacetFeature `json:"features"`
+}
+
+// ByteSlice は本文中のバイト範囲です。
+type ByteSlice struct {
+	ByteStart int `json:"byteStart"`
+	ByteEnd   int `json:"byteEnd"`
+}
+
+// FacetFeature はFacetの種類です。リンクはURI、ハッシュタグはTagを持ちます。
+type FacetFeature struct {
+	Type string `json:"$type"`
+	URI  string `json:"uri,omitempty"`
+	Tag  string `json:"tag,omitempty"`
+}
+
+var (
+	urlPattern     = regexp.MustCompile(`https?://[^\s<>"]+`)
+	hashtagPattern = regexp.MustCompile(`(?:^|\s)([#＃][^\s#＃]+)`)
+)
+
+// Trailing punctuation is not part of links and tags
+const trailingPunctuation = ".,;:!?)]}'\"。、！？」』）"
+
+// DetectFacets は本文中のリンクとハッシュタグのFacetを作成します。
+func DetectFacets(text string) []Facet {
+	var facets []Facet
+
+	for _, loc := range urlPattern.FindAllStringIndex(text, -1) {
+		uri := strings.TrimRight(text[loc[0]:loc[1]], trailingPunctuation)
+		facets = append(facets, Facet{
+			Index:    ByteSlice{ByteStart: loc[0], ByteEnd: loc[0] + len(uri)},
+			Features: []FacetFeature{{Type: "app.bsky.richtext.facet#link", URI: uri}},
+		})
+	}
+
+	for _, loc := range hashtagPattern.FindAllStringSubmatchIndex(text, -1) {
+		start := loc[2]
+		tag := strings.TrimRight(text[start:loc[3]], trailingPunctuation)
+		name := strings.TrimLeft(tag, "#＃")
+		// Tags made only of digits are not hashtags
+		if name == "" || strings.Trim(name, "0123456789") == "" {
+			continue
+		}
+		facets = append(facets, Facet{
+			Index:    ByteSlice{ByteStart: start, ByteEnd: start + len(tag)},
+			Features: []FacetFeature{{Type: "app.bsky.richtext.facet#tag", Tag: name}},
+		})
+	}
+
+	return facets
+}
@@ -0,0 +1,53 @@
+package bluesky
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestDetectFacets(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name string
+		text string
+		want []Facet
+	}{
+		{
+			name: "正常系: リンク",
+			text: "見てね https://example.com/a?b=1.",
+			want: []Facet{
+				{Index: ByteSlice{ByteStart: 10, ByteEnd: 35}, Features: []FacetFeature{{Type: "app.bsky.richtext.facet#link", URI: "https://example.com/a?b=1"}}},
+			},
+		},
+		{
+			name: "正常系: 日本語のハッシュタグはバイト位置で指定する",
+			text: "#Fortnite #フォートナイト",
+			want: []Facet{
+				{Index: ByteSlice{ByteStart: 0, ByteEnd: 9}, Features: []FacetFeature{{Type: "app.bsky.richtext.facet#tag", Tag: "Fortnite"}}},
+				{Index: ByteSlice{ByteStart: 10, ByteEnd: 32}, Features: []FacetFeature{{Type: "app.bsky.richtext.facet#tag", Tag: "フォートナイト"}}},
+			},
+		},
+		{
+			name: "正常系: 数字だけのタグとURL内の#は無視する",
+			text: "#123 https://example.com/#top",
+			want: []Facet{
+				{Index: ByteSlice{ByteStart: 5, ByteEnd: 29}, Features: []FacetFeature{{Type: "app.bsky.richtext.facet#link", URI: "https://example.com/#top"}}},
+			},
+		},
+		{
+			name: "正常系: リンクもタグもない",
+			text: "こんにちは",
+			want: nil,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			if got := DetectFacets(tt.text); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("DetectFacets() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,104 @@
+package bluesky
+
+import (
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+)
+
+// Maximum size of a thumbnail blob
+const maxThumbSize = 1000000
+
+// Post は app.bsky.feed.post のレコードです。
+type Post struct {
+	Type      string   `json:"$type"`
+	Text      string   `json:"text"`
+	CreatedAt string   `json:"createdAt"`
+	Langs     []string `json:"langs,omitempty"`
+	Facets    []Facet  `json:"facets,omitempty"`
+	Embed     *Embed   `json:"embed,omitempty"`
+}
+
+// Embed はリンクカードの埋め込みです。
+type Embed struct {
+	Type     string   `json:"$type"`
+	External External `json:"external"`
+}
+
+// External はリンクカードの内容です。
+type External struct {
+	URI         string `json:"uri"`
+	Title       string `json:"title"`
+	Description string `json:"description"`
+	Thumb       *Blob  `json:"thumb,omitempty"`
+}
+
+// LinkCard は投稿に付けるリンクカードです。ImageURLの画像をサムネイルとしてアップロードします。
+type LinkCard struct {
+	URL         string
+	Title       string
+	Description string
+	ImageURL    string
+}
+
+// Publish はFacetを付けた本文を投稿します。cardがnilでなければリンクカードを付けます。
+// サムネイルの取得やアップロードに失敗した場合は、サムネイルなしのカードで投稿します。
+func (c *Client) Publish(text string, card *LinkCard) (string, error) {
+	post := Post{
+		Text:   text,
+		Langs:  []string{"ja"},
+		Facets: DetectFacets(text),
+	}
+	if card != nil {
+		external := External{URI: card.URL, Title: card.Title, Description: card.Description}
+		if card.ImageURL != "" {
+			thumb, err := c.uploadImageFromURL(card.ImageURL)
+			if err != nil {
+				slog.Warn("Failed to upload link card thumbnail, posting without it", "error", err)
+			} else {
+				external.Thumb = thumb
+			}
+		}
+		post.Embed = &Embed{Type: "app.bsky.embed.external", External: external}
+	}
+	return c.CreatePost(post)
+}
+
+// Publish は環境変数の認証情報でBlueskyに投稿します。認証情報がない場合は ErrNotConfigured を返します。
+func Publish(text string, card *LinkCard) (string, error) {
+	client, err := NewClientFromEnv()
+	if err != nil {
+		return "", err
+	}
+	return client.Publish(text, card)
+}
+
+func (c *Client) uploadImageFromURL(imageURL string) (*Blob, error) {
+	resp, err := c.httpClient.Get(imageURL)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		if err := resp.Body.Close(); err != nil {
+			slog.Error("Failed to close response body", "error", err)
+		}
+	}()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("failed to download image: status %d", resp.StatusCode)
+	}
+
+	data, err := io.ReadAll(io.LimitReader(resp.Body, maxThumbSize+1))
+	if err != nil {
+		return nil, err
+	}
+	if len(data) > maxThumbSize {
+		return nil, fmt.Errorf("image is larger than %d bytes", maxThumbSize)
+	}
+
+	mimeType := resp.Header.Get("Content-Type")
+	if mimeType == "" {
+		mimeType = http.DetectContentType(data)
+	}
+	return c.UploadBlob(data, mimeType)
+}
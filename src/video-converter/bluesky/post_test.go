@@ -0,0 +1,190 @@
+package bluesky
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// fakePDS はcreateSession、uploadBlob、createRecordだけを実装したPDSです。
+type fakePDS struct {
+	mu       sync.Mutex
+	sessions int
+	blobs    int
+	records  []map[string]any
+}
+
+func (p *fakePDS) handler(t *testing.T) http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/xrpc/com.atproto.server.createSession", func(w http.ResponseWriter, r *http.Request) {
+		var req map[string]string
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			t.Errorf("failed to decode session request: %v", err)
+		}
+		if req["password"] != "app-password" {
+			w.WriteHeader(http.StatusUnauthorized)
+			_, _ = w.Write([]byte(`{"error":"AuthenticationRequired"}`))
+			return
+		}
+		p.mu.Lock()
+		p.sessions++
+		p.mu.Unlock()
+		_, _ = w.Write([]byte(`{"did":"did:plc:test","handle":"gaba.bsky.social","accessJwt":"jwt"}`))
+	})
+	mux.HandleFunc("/xrpc/com.atproto.repo.uploadBlob", func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer jwt" {
+			t.Errorf("Authorization = %q, want Bearer jwt", r.Header.Get("Authorization"))
+		}
+		data, _ := io.ReadAll(r.Body)
+		p.mu.Lock()
+		p.blobs++
+		p.mu.Unlock()
+		_, _ = w.Write([]byte(`{"blob":{"$type":"blob","ref":{"$link":"bafkrei"},"mimeType":"` + r.Header.Get("Content-Type") + `","size":` + jsonInt(len(data)) + `}}`))
+	})
+	mux.HandleFunc("/xrpc/com.atproto.repo.createRecord", func(w http.ResponseWriter, r *http.Request) {
+		var req map[string]any
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			t.Errorf("failed to decode record request: %v", err)
+		}
+		p.mu.Lock()
+		p.records = append(p.records, req)
+		p.mu.Unlock()
+		_, _ = w.Write([]byte(`{"uri":"at://did:plc:test/app.bsky.feed.post/1","cid":"bafy"}`))
+	})
+	mux.HandleFunc("/thumb.jpg", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "image/jpeg")
+		_, _ = w.Write([]byte("jpeg"))
+	})
+	return mux
+}
+
+func jsonInt(n int) string {
+	b, _ := json.Marshal(n)
+	return string(b)
+}
+
+func TestPublish(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name      string
+		password  string
+		text      string
+		card      bool
+		imagePath string
+		wantErr   bool
+		wantThumb bool
+	}{
+		{
+			name:      "正常系: リンクカードとサムネイル付きで投稿する",
+			password:  "app-password",
+			text:      "動画をアップしました！ https://youtu.be/abc #Fortnite",
+			card:      true,
+			imagePath: "/thumb.jpg",
+			wantThumb: true,
+		},
+		{
+			name:      "正常系: サムネイルの取得に失敗してもカードは付ける",
+			password:  "app-password",
+			text:      "ブログを更新しました",
+			card:      true,
+			imagePath: "/missing.jpg",
+		},
+		{
+			name:     "正常系: カードなし",
+			password: "app-password",
+			text:     "ブログを更新しました",
+		},
+		{
+			name:     "異常系: 認証に失敗する",
+			password: "wrong",
+			text:     "ブログを更新しました",
+			wantErr:  true,
+		},
+		{
+			name:     "異常系: 本文が長すぎる",
+			password: "app-password",
+			text:     strings.Repeat("あ", MaxGraphemes+1),
+			wantErr:  true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			pds := &fakePDS{}
+			server := httptest.NewServer(pds.handler(t))
+			defer server.Close()
+
+			client := NewClient(server.URL, "gaba.bsky.social", tt.password)
+			var card *LinkCard
+			if tt.card {
+				card = &LinkCard{URL: "https://youtu.be/abc", Title: "タイトル", Description: "説明", ImageURL: server.URL + tt.imagePath}
+			}
+
+			uri, err := client.Publish(tt.text, card)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Publish() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				if len(pds.records) != 0 {
+					t.Errorf("records = %d, want 0", len(pds.records))
+				}
+				return
+			}
+			if uri != "at://did:plc:test/app.bsky.feed.post/1" {
+				t.Errorf("uri = %q", uri)
+			}
+			if len(pds.records) != 1 {
+				t.Fatalf("records = %d, want 1", len(pds.records))
+			}
+
+			req := pds.records[0]
+			if req["repo"] != "did:plc:test" || req["collection"] != "app.bsky.feed.post" {
+				t.Errorf("repo = %v, collection = %v", req["repo"], req["collection"])
+			}
+			record := req["record"].(map[string]any)
+			if record["$type"] != "app.bsky.feed.post" || record["text"] != tt.text || record["createdAt"] == "" {
+				t.Errorf("record = %+v", record)
+			}
+
+			embed, hasEmbed := record["embed"].(map[string]any)
+			if hasEmbed != tt.card {
+				t.Fatalf("embed = %v, want card %v", record["embed"], tt.card)
+			}
+			if !tt.card {
+				return
+			}
+			external := embed["external"].(map[string]any)
+			if external["uri"] != "https://youtu.be/abc" || external["title"] != "タイトル" {
+				t.Errorf("external = %+v", external)
+			}
+			if _, hasThumb := external["thumb"]; hasThumb != tt.wantThumb {
+				t.Errorf("thumb = %v, want %v", external["thumb"], tt.wantThumb)
+			}
+		})
+	}
+}
+
+func TestNewClientFromEnv(t *testing.T) {
+	t.Setenv("BLUESKY_HANDLE", "")
+	t.Setenv("BLUESKY_APP_PASSWORD", "")
+	if _, err := NewClientFromEnv(); err != ErrNotConfigured {
+		t.Errorf("NewClientFromEnv() error = %v, want ErrNotConfigured", err)
+	}
+
+	t.Setenv("BLUESKY_HANDLE", "gaba.bsky.social")
+	t.Setenv("BLUESKY_APP_PASSWORD", "app-password")
+	client, err := NewClientFromEnv()
+	if err != nil {
+		t.Fatalf("NewClientFromEnv() error = %v", err)
+	}
+	if client.pds != DefaultPDS {
+		t.Errorf("pds = %q, want %q", client.pds, DefaultPDS)
+	}
+}
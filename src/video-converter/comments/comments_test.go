@@ -0,0 +1,280 @@
+package comments
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"thiroyoshi.com/video-converter/notifier"
+	"thiroyoshi.com/video-converter/youtube"
+)
+
+type fakeAPI struct {
+	comments  []youtube.Comment
+	since     time.Time
+	moderated []string
+	status    youtube.ModerationStatus
+	replies   map[string]string
+	// moderateErr is returned by SetModerationStatus
+	moderateErr error
+}
+
+func (f *fakeAPI) CommentThreads(_ string, since time.Time) ([]youtube.Comment, error) {
+	f.since = since
+	var comments []youtube.Comment
+	for _, c := range f.comments {
+		if c.PublishedAt.After(since) {
+			comments = append(comments, c)
+		}
+	}
+	return comments, nil
+}
+
+func (f *fakeAPI) SetModerationStatus(ids []string, status youtube.ModerationStatus, _ bool) error {
+	if f.moderateErr != nil {
+		return f.moderateErr
+	}
+	f.moderated = append(f.moderated, ids...)
+	f.status = status
+	return nil
+}
+
+func (f *fakeAPI) Reply(parentID, text string) (string, error) {
+	if f.replies == nil {
+		f.replies = map[string]string{}
+	}
+	f.replies[parentID] = text
+	return parentID + ".reply", nil
+}
+
+func TestSpamReason(t *testing.T) {
+	t.Parallel()
+
+	rules := DefaultRules.Spam
+	tests := []struct {
+		name       string
+		text       string
+		duplicates int
+		want       string
+	}{
+		{name: "正常系: 普通のコメント", text: "今日のビクロイかっこよかったです！", want: ""},
+		{name: "正常系: 許可したドメインのリンク", text: "前回の動画 https://youtu.be/abc も良かった", want: ""},
+		{name: "正常系: 許可したドメインのサブドメイン", text: "https://m.youtube.com/watch?v=abc", want: ""},
+		{name: "異常系: リンク", text: "詳しくは https://spam.example.net/x へ", want: "リンク（spam.example.net）を含む"},
+		{name: "異常系: スキームのないリンク", text: "見てねbit.ly", want: "リンク（bit.ly）を含む"},
+		{name: "異常系: ブロックする語句", text: "FREE V-Bucks配布中", want: "「free v-bucks」を含む"},
+		{name: "異常系: 連投", text: "すごい", duplicates: 2, want: "同じ内容のコメントが3件以上"},
+		{name: "正常系: 連投の上限未満", text: "すごい", duplicates: 1, want: ""},
+		{name: "異常系: 同じ文字の繰り返し", text: "w" + strings.Repeat("あ", 16), want: "同じ文字の繰り返し"},
+		{name: "正常系: 上限以内の繰り返し", text: strings.Repeat("w", 15), want: ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			got := rules.spamReason(youtube.Comment{Text: tt.text}, tt.duplicates)
+			if got != tt.want {
+				t.Errorf("spamReason(%q) = %q, want %q", tt.text, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestWatch(t *testing.T) {
+	t.Parallel()
+
+	base := time.Date(2025, 5, 1, 12, 0, 0, 0, time.UTC)
+	comment := func(id, author, text string, minutes int) youtube.Comment {
+		return youtube.Comment{ID: id, VideoID: "v1", AuthorName: author, AuthorChannelID: "UC" + author, Text: text, PublishedAt: base.Add(time.Duration(minutes) * time.Minute)}
+	}
+	api := &fakeAPI{comments: []youtube.Comment{
+		comment("c6", "me", "ありがとう", 6),
+		comment("c5", "bot3", "神動画", 5),
+		comment("c4", "bot2", "神動画", 4),
+		comment("c3", "bot1", "神動画", 3),
+		comment("c2", "spammer", "稼げる副業はこちら", 2),
+		comment("c1", "fan", "いつも見てます", 1),
+		comment("c0", "old", "昨日のコメント", -24*60-1),
+	}}
+
+	drafter, err := NewTemplateDrafter([]string{"{{.Author}}さん、コメントありがとうございます！"})
+	if err != nil {
+		t.Fatalf("NewTemplateDrafter() error = %v", err)
+	}
+	var messages []notifier.Message
+	watcher := &Watcher{
+		API:       api,
+		ChannelID: "UCme",
+		Rules:     DefaultRules,
+		Drafter:   drafter,
+		Store:     NewStateStore(filepath.Join(t.TempDir(), "comments.json")),
+		Notify: func(msg notifier.Message) error {
+			messages = append(messages, msg)
+			return nil
+		},
+	}
+
+	now := base.Add(10 * time.Minute)
+	result, err := watcher.Watch(now)
+	if err != nil {
+		t.Fatalf("Watch() error = %v", err)
+	}
+	// 初回は24時間前以降のコメントだけを対象にする
+	if !api.since.Equal(now.Add(-initialLookback)) {
+		t.Errorf("since = %v", api.since)
+	}
+	// 3件目の同じコメントから連投とみなし、チャンネル自身のコメントは対象外
+	if got := strings.Join(api.moderated, ","); got != "c2,c5" || api.status != youtube.ModerationHeldForReview {
+		t.Errorf("moderated = %s (%s)", got, api.status)
+	}
+	var genuine []string
+	for _, c := range result.Genuine {
+		genuine = append(genuine, c.ID)
+	}
+	if got := strings.Join(genuine, ","); got != "c1,c3,c4" {
+		t.Errorf("genuine = %s", got)
+	}
+	// 通常のコメント3件とスパムのまとめ1件
+	if len(messages) != 4 || messages[0].Event != notifier.EventComment || !strings.Contains(messages[0].Text, "fanさん、コメントありがとうございます！") {
+		t.Fatalf("messages = %+v", messages)
+	}
+	if !strings.Contains(messages[3].Title, "2件を保留") || !strings.Contains(messages[3].Text, "「副業」を含む") {
+		t.Errorf("spam message = %+v", messages[3])
+	}
+
+	// 2回目は前回以降のコメントだけを取得する
+	messages = nil
+	if _, err := watcher.Watch(now.Add(time.Hour)); err != nil {
+		t.Fatalf("Watch() error = %v", err)
+	}
+	if !api.since.Equal(base.Add(6*time.Minute)) || len(messages) != 0 {
+		t.Errorf("since = %v, messages = %d", api.since, len(messages))
+	}
+
+	// 承認した下書きだけを返信する
+	if _, err := watcher.Approve("c1", ""); err != nil {
+		t.Fatalf("Approve() error = %v", err)
+	}
+	if _, err := watcher.Approve("c3", "ありがとう！"); err != nil {
+		t.Fatalf("Approve() error = %v", err)
+	}
+	if err := watcher.Discard("c4"); err != nil {
+		t.Fatalf("Discard() error = %v", err)
+	}
+	if api.replies["c1"] != "fanさん、コメントありがとうございます！" || api.replies["c3"] != "ありがとう！" || len(api.replies) != 2 {
+		t.Errorf("replies = %v", api.replies)
+	}
+	if err := watcher.Discard("c4"); err == nil {
+		t.Error("Discard() of a discarded draft should fail")
+	}
+	state, err := watcher.Store.Load()
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if len(state.Pending) != 0 {
+		t.Errorf("pending = %+v", state.Pending)
+	}
+}
+
+func TestWatchRetriesFailedComments(t *testing.T) {
+	t.Parallel()
+
+	base := time.Date(2025, 5, 1, 12, 0, 0, 0, time.UTC)
+	api := &fakeAPI{
+		comments: []youtube.Comment{
+			{ID: "c2", VideoID: "v1", AuthorName: "spammer", AuthorChannelID: "UCspammer", Text: "稼げる副業はこちら", PublishedAt: base.Add(2 * time.Minute)},
+			{ID: "c1", VideoID: "v1", AuthorName: "fan", AuthorChannelID: "UCfan", Text: "いつも見てます", PublishedAt: base.Add(time.Minute)},
+		},
+		moderateErr: errors.New("quota exceeded"),
+	}
+	notifyErr := errors.New("slack down")
+	var notified []string
+	watcher := &Watcher{
+		API:       api,
+		ChannelID: "UCme",
+		Rules:     DefaultRules,
+		Store:     NewStateStore(filepath.Join(t.TempDir(), "comments.json")),
+		Notify: func(msg notifier.Message) error {
+			if notifyErr != nil {
+				return notifyErr
+			}
+			notified = append(notified, msg.Title)
+			return nil
+		},
+	}
+
+	now := base.Add(10 * time.Minute)
+	if _, err := watcher.Watch(now); err == nil {
+		t.Fatal("Watch() error = nil, want moderation and notification errors")
+	}
+	state, err := watcher.Store.Load()
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if len(state.Unhandled) != 2 || state.Unhandled[0].Comment.ID != "c1" || state.Unhandled[1].SpamReason == "" {
+		t.Fatalf("unhandled = %+v", state.Unhandled)
+	}
+
+	// 次回は失敗したコメントをやり直す
+	api.moderateErr, notifyErr = nil, nil
+	result, err := watcher.Watch(now.Add(time.Hour))
+	if err != nil {
+		t.Fatalf("Watch() error = %v", err)
+	}
+	if len(result.Genuine) != 1 || len(result.Spam) != 1 || strings.Join(api.moderated, ",") != "c2" {
+		t.Errorf("result = %+v, moderated = %v", result, api.moderated)
+	}
+	if len(notified) != 2 {
+		t.Errorf("notified = %v", notified)
+	}
+	if state, err = watcher.Store.Load(); err != nil || len(state.Unhandled) != 0 {
+		t.Errorf("unhandled = %+v, %v", state.Unhandled, err)
+	}
+}
+
+func TestLoadRules(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	write := func(name, content string) string {
+		path := filepath.Join(dir, name)
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		return path
+	}
+
+	tests := []struct {
+		name    string
+		path    string
+		wantErr bool
+		check   func(Rules) bool
+	}{
+		{name: "正常系: 指定なし", path: "", check: func(r Rules) bool { return r.Spam.Action == ActionHold }},
+		{
+			name: "正常系: 指定した項目だけ上書き",
+			path: write("reject.json", `{"spam":{"action":"reject","ban_author":true},"reply":{"mode":"template","templates":["ありがとう"]}}`),
+			check: func(r Rules) bool {
+				return r.Spam.Action == ActionReject && r.Spam.BlockLinks && r.Reply.Mode == ReplyTemplate
+			},
+		},
+		{name: "異常系: 不明な対応", path: write("bad.json", `{"spam":{"action":"delete"}}`), wantErr: true},
+		{name: "異常系: ファイルがない", path: filepath.Join(dir, "missing.json"), wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			rules, err := LoadRules(tt.path)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("LoadRules() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && !tt.check(rules) {
+				t.Errorf("LoadRules() = %+v", rules)
+			}
+		})
+	}
+}
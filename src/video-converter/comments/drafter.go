@@ -0,0 +1,112 @@
+package comments
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/rand/v2"
+	"os"
+	"strings"
+	"text/template"
+
+	"github.com/openai/openai-go"
+	"github.com/openai/openai-go/option"
+
+	"thiroyoshi.com/video-converter/youtube"
+)
+
+const defaultReplyPrompt = `あなたはフォートナイトの動画を投稿しているYouTuber「GABA」です。
+視聴者からの次のコメントに、感謝を込めた短い返信を日本語で1〜2文で書いてください。
+絵文字は1つまでにし、URLや宣伝、約束できないこと（プレゼントやコラボなど）は書かないでください。
+返信文だけを出力してください。
+
+コメントの投稿者: %s
+コメント: %s`
+
+// Drafter はコメントへの返信の下書きを作ります。
+type Drafter interface {
+	Draft(comment youtube.Comment) (string, error)
+}
+
+// NewDrafter はルールに応じた Drafter を返します。返信の下書きを作らない場合はnilを返します。
+func NewDrafter(rules ReplyRules) (Drafter, error) {
+	switch rules.Mode {
+	case ReplyNone, "":
+		return nil, nil
+	case ReplyTemplate:
+		return NewTemplateDrafter(rules.Templates)
+	case ReplyLLM:
+		apiKey := os.Getenv("OPENAI_API_KEY")
+		if apiKey == "" {
+			return nil, errors.New("OPENAI_API_KEY is required for llm replies")
+		}
+		return NewLLMDrafter(apiKey, rules.Prompt), nil
+	default:
+		return nil, fmt.Errorf("unknown reply mode: %q", rules.Mode)
+	}
+}
+
+// TemplateDrafter はテンプレートのいずれかをランダムに選んで返信文を作ります。
+type TemplateDrafter struct {
+	templates []*template.Template
+}
+
+// NewTemplateDrafter はテンプレートを解析して TemplateDrafter を作成します。
+func NewTemplateDrafter(texts []string) (*TemplateDrafter, error) {
+	if len(texts) == 0 {
+		return nil, errors.New("no reply templates configured")
+	}
+	d := &TemplateDrafter{}
+	for i, text := range texts {
+		tmpl, err := template.New(fmt.Sprintf("reply%d", i)).Parse(text)
+		if err != nil {
+			return nil, fmt.Errorf("invalid reply template %d: %w", i, err)
+		}
+		d.templates = append(d.templates, tmpl)
+	}
+	return d, nil
+}
+
+// Draft はテンプレートにコメントの投稿者と本文を当てはめます。
+func (d *TemplateDrafter) Draft(comment youtube.Comment) (string, error) {
+	tmpl := d.templates[rand.IntN(len(d.templates))]
+	data := struct{ Author, Text string }{Author: comment.AuthorName, Text: comment.Text}
+
+	var b strings.Builder
+	if err := tmpl.Execute(&b, data); err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(b.String()), nil
+}
+
+// LLMDrafter はOpenAIで返信文を作ります。
+type LLMDrafter struct {
+	client openai.Client
+	prompt string
+}
+
+// NewLLMDrafter は LLMDrafter を作成します。promptが空の場合はデフォルトの指示を使います。
+// promptには投稿者名とコメントの本文を順に %s で埋め込みます。
+func NewLLMDrafter(apiKey, prompt string) *LLMDrafter {
+	if prompt == "" {
+		prompt = defaultReplyPrompt
+	}
+	return &LLMDrafter{client: openai.NewClient(option.WithAPIKey(apiKey)), prompt: prompt}
+}
+
+// Draft はコメントへの返信文を生成します。
+func (d *LLMDrafter) Draft(comment youtube.Comment) (string, error) {
+	chatCompletion, err := d.client.Chat.Completions.New(context.TODO(), openai.ChatCompletionNewParams{
+		Messages: []openai.ChatCompletionMessageParamUnion{
+			openai.UserMessage(fmt.Sprintf(d.prompt, comment.AuthorName, comment.Text)),
+		},
+		Model: openai.ChatModelO3Mini,
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to draft reply: %w", err)
+	}
+	if len(chatCompletion.Choices) == 0 {
+		return "", errors.New("no reply drafted")
+	}
+	return strings.TrimSpace(chatCompletion.Choices[0].Message.Content), nil
+}
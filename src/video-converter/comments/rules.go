@@ -0,0 +1,166 @@
+// Package comments はYouTubeのコメントを監視し、スパムの保留・拒否、新着コメントの通知と返信の下書きを行います。
+package comments
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"regexp"
+	"slices"
+	"strings"
+	"unicode"
+
+	"thiroyoshi.com/video-converter/youtube"
+)
+
+// Action はスパムと判定したコメントへの対応です。
+type Action string
+
+const (
+	// ActionHold はコメントを保留にし、YouTube Studioでの確認待ちにします
+	ActionHold Action = "hold"
+	// ActionReject はコメントを拒否します
+	ActionReject Action = "reject"
+	// ActionNone は判定だけ行い、コメントの状態は変えません
+	ActionNone Action = "none"
+)
+
+// ReplyMode は返信の下書きの作り方です。
+type ReplyMode string
+
+const (
+	ReplyNone     ReplyMode = "none"
+	ReplyTemplate ReplyMode = "template"
+	ReplyLLM      ReplyMode = "llm"
+)
+
+// Rules は監視のルールです。
+type Rules struct {
+	Spam  SpamRules  `json:"spam"`
+	Reply ReplyRules `json:"reply"`
+}
+
+// SpamRules はスパムと判定する条件と対応です。
+type SpamRules struct {
+	// Action はスパムへの対応です
+	Action Action `json:"action"`
+	// BanAuthor は拒否したコメントの投稿者のコメントを今後も自動で拒否します。Action が reject の場合だけ有効です
+	BanAuthor bool `json:"ban_author"`
+	// BlockLinks はリンクを含むコメントをスパムとします
+	BlockLinks bool `json:"block_links"`
+	// AllowedDomains はリンクがあってもスパムとしないドメインです
+	AllowedDomains []string `json:"allowed_domains"`
+	// Blocklist はいずれかを含むコメントをスパムとする語句です。大文字・小文字は区別しません
+	Blocklist []string `json:"blocklist"`
+	// MaxDuplicates は同じ本文のコメントがこの件数を超えたらスパムとします。0の場合は判定しません
+	MaxDuplicates int `json:"max_duplicates"`
+	// MaxCharRepeat は同じ文字がこの回数を超えて続くコメントをスパムとします。0の場合は判定しません
+	MaxCharRepeat int `json:"max_char_repeat"`
+}
+
+// ReplyRules は返信の下書きの設定です。下書きは承認するまで投稿しません。
+type ReplyRules struct {
+	Mode ReplyMode `json:"mode"`
+	// Templates は text/template の返信文です。{{.Author}} と {{.Text}} を使えます
+	Templates []string `json:"templates"`
+	// Prompt は Mode が llm の場合に使う指示です。空の場合はデフォルトを使います
+	Prompt string `json:"prompt"`
+}
+
+// DefaultRules はリンク付きのコメントと定番の勧誘文句を保留にし、返信の下書きは作らないルールです。
+var DefaultRules = Rules{
+	Spam: SpamRules{
+		Action:         ActionHold,
+		BlockLinks:     true,
+		AllowedDomains: []string{"youtube.com", "youtu.be", "gaba-fortnite.hatenablog.com"},
+		Blocklist:      []string{"LINE追加", "副業", "稼げる", "プロフ見て", "telegram", "whatsapp", "free v-bucks", "無料ブイバックス"},
+		MaxDuplicates:  2,
+		MaxCharRepeat:  15,
+	},
+	Reply: ReplyRules{Mode: ReplyNone},
+}
+
+// LoadRules はJSONファイルからルールを読み込みます。ファイルにない項目は DefaultRules の値を使い、pathが空の場合は DefaultRules を返します。
+func LoadRules(path string) (Rules, error) {
+	if path == "" {
+		return DefaultRules, nil
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return Rules{}, fmt.Errorf("failed to read rules: %w", err)
+	}
+	rules := DefaultRules
+	// json.Unmarshal reuses the backing arrays of slices, so copy them to keep DefaultRules intact
+	rules.Spam.AllowedDomains = slices.Clone(rules.Spam.AllowedDomains)
+	rules.Spam.Blocklist = slices.Clone(rules.Spam.Blocklist)
+	if err := json.Unmarshal(data, &rules); err != nil {
+		return Rules{}, fmt.Errorf("failed to parse rules: %w", err)
+	}
+	switch rules.Spam.Action {
+	case ActionHold, ActionReject, ActionNone:
+	default:
+		return Rules{}, fmt.Errorf("unknown spam action: %q", rules.Spam.Action)
+	}
+	return rules, nil
+}
+
+var linkPattern = regexp.MustCompile(`(?i)(?:https?://|www\.)([a-z0-9.-]+)|\b([a-z0-9-]+\.(?:com|net|org|io|me|ly|gg|xyz|jp|ru|cn|info|link|click))\b`)
+
+// spamReason はスパムと判定した理由を返します。スパムでない場合は空文字を返します。
+// duplicates はこれまでに同じ本文のコメントを見た件数です。
+func (r SpamRules) spamReason(comment youtube.Comment, duplicates int) string {
+	if r.BlockLinks {
+		for _, match := range linkPattern.FindAllStringSubmatch(comment.Text, -1) {
+			domain := match[1] + match[2]
+			if !allowedDomain(r.AllowedDomains, domain) {
+				return fmt.Sprintf("リンク（%s）を含む", domain)
+			}
+		}
+	}
+	lower := strings.ToLower(comment.Text)
+	for _, word := range r.Blocklist {
+		if word != "" && strings.Contains(lower, strings.ToLower(word)) {
+			return fmt.Sprintf("「%s」を含む", word)
+		}
+	}
+	if r.MaxDuplicates > 0 && duplicates >= r.MaxDuplicates {
+		return fmt.Sprintf("同じ内容のコメントが%d件以上", r.MaxDuplicates+1)
+	}
+	if r.MaxCharRepeat > 0 && longestRun(comment.Text) > r.MaxCharRepeat {
+		return "同じ文字の繰り返し"
+	}
+	return ""
+}
+
+// allowedDomain はdomainがdomainsのいずれかか、そのサブドメインかを返します。
+func allowedDomain(domains []string, domain string) bool {
+	domain = strings.TrimPrefix(strings.ToLower(domain), "www.")
+	for _, d := range domains {
+		d = strings.ToLower(d)
+		if domain == d || strings.HasSuffix(domain, "."+d) {
+			return true
+		}
+	}
+	return false
+}
+
+// longestRun は空白以外の同じ文字が続く最大の回数を返します。
+func longestRun(text string) int {
+	longest, run := 0, 0
+	var prev rune
+	for _, r := range text {
+		if r == prev && !unicode.IsSpace(r) {
+			run++
+		} else {
+			run = 1
+		}
+		prev = r
+		longest = max(longest, run)
+	}
+	return longest
+}
+
+// normalize は重複の判定に使う本文です。空白と大文字・小文字の違いを無視します。
+func normalize(text string) string {
+	return strings.ToLower(strings.Join(strings.Fields(text), ""))
+}
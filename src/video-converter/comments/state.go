@@ -0,0 +1,145 @@
+package comments
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"thiroyoshi.com/video-converter/youtube"
+)
+
+const (
+	// Number of recent comment texts kept to detect repeated comments
+	maxRecentTexts = 1000
+	// Number of runs a comment whose moderation or notification failed is retried
+	maxAttempts = 5
+)
+
+// Draft は承認待ちの返信の下書きです。
+type Draft struct {
+	CommentID string    `json:"comment_id"`
+	VideoID   string    `json:"video_id"`
+	Author    string    `json:"author"`
+	Comment   string    `json:"comment"`
+	Reply     string    `json:"reply"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// Unhandled は保留・拒否または通知に失敗し、次回の監視でやり直すコメントです。
+type Unhandled struct {
+	Comment youtube.Comment `json:"comment"`
+	// SpamReason はスパムと判定した理由で、スパムでない場合は空です
+	SpamReason string `json:"spam_reason,omitempty"`
+	Attempts   int    `json:"attempts"`
+}
+
+// State は実行をまたいで引き継ぐ監視の状態です。
+type State struct {
+	// LastChecked は取得済みのコメントのうち最も新しい投稿日時です。処理に失敗したコメントは Unhandled に残します
+	LastChecked time.Time `json:"last_checked"`
+	// RecentTexts は最近のコメント本文のハッシュで、同じ内容の連投の判定に使います
+	RecentTexts []string    `json:"recent_texts"`
+	Pending     []Draft     `json:"pending"`
+	Unhandled   []Unhandled `json:"unhandled,omitempty"`
+}
+
+// duplicates はtextと同じ本文のコメントを最近見た件数を返します。
+func (s *State) duplicates(text string) int {
+	hash := textHash(text)
+	n := 0
+	for _, h := range s.RecentTexts {
+		if h == hash {
+			n++
+		}
+	}
+	return n
+}
+
+// remember はtextを最近のコメントとして記録します。
+func (s *State) remember(text string) {
+	s.RecentTexts = append(s.RecentTexts, textHash(text))
+	if over := len(s.RecentTexts) - maxRecentTexts; over > 0 {
+		s.RecentTexts = s.RecentTexts[over:]
+	}
+}
+
+// Draft はコメントIDに対応する下書きを返します。
+func (s *State) Draft(commentID string) (Draft, bool) {
+	for _, draft := range s.Pending {
+		if draft.CommentID == commentID {
+			return draft, true
+		}
+	}
+	return Draft{}, false
+}
+
+// removeDraft はコメントIDに対応する下書きを取り除きます。
+func (s *State) removeDraft(commentID string) bool {
+	for i, draft := range s.Pending {
+		if draft.CommentID == commentID {
+			s.Pending = append(s.Pending[:i], s.Pending[i+1:]...)
+			return true
+		}
+	}
+	return false
+}
+
+func textHash(text string) string {
+	sum := sha256.Sum256([]byte(normalize(text)))
+	return hex.EncodeToString(sum[:8])
+}
+
+// StateStore は状態をJSONファイルに保存します。
+type StateStore struct {
+	path string
+}
+
+// NewStateStore はpathのファイルを使うストアを作成します。
+func NewStateStore(path string) *StateStore {
+	return &StateStore{path: path}
+}
+
+// Load は状態を読み込みます。ファイルがない場合は空の状態を返します。
+func (s *StateStore) Load() (*State, error) {
+	data, err := os.ReadFile(s.path)
+	if errors.Is(err, os.ErrNotExist) {
+		return &State{}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read comment state: %w", err)
+	}
+	var state State
+	if err := json.Unmarshal(data, &state); err != nil {
+		return nil, fmt.Errorf("failed to parse comment state: %w", err)
+	}
+	return &state, nil
+}
+
+// Save は状態を一時ファイルに書いてから置き換えます。
+func (s *StateStore) Save(state *State) error {
+	data, err := json.MarshalIndent(state, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(s.path), 0o755); err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(s.path), ".comments-*")
+	if err != nil {
+		return err
+	}
+	defer func() { _ = os.Remove(tmp.Name()) }()
+	if _, err := tmp.Write(data); err != nil {
+		_ = tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), s.path)
+}
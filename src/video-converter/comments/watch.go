@@ -0,0 +1,269 @@
+package comments
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+
+	"thiroyoshi.com/video-converter/notifier"
+	"thiroyoshi.com/video-converter/youtube"
+)
+
+// Period checked on the first run when no state is recorded yet
+const initialLookback = 24 * time.Hour
+
+// API はコメントの監視に使うYouTube Data APIの操作です。
+type API interface {
+	CommentThreads(channelID string, since time.Time) ([]youtube.Comment, error)
+	SetModerationStatus(ids []string, status youtube.ModerationStatus, banAuthor bool) error
+	Reply(parentID, text string) (string, error)
+}
+
+// Spam はスパムと判定したコメントとその理由です。
+type Spam struct {
+	Comment youtube.Comment
+	Reason  string
+}
+
+// Result は1回の監視の結果です。
+type Result struct {
+	Genuine []youtube.Comment
+	Spam    []Spam
+	Drafts  []Draft
+}
+
+// Watcher はチャンネルのコメントを監視します。
+type Watcher struct {
+	API       API
+	ChannelID string
+	Rules     Rules
+	// Drafter がnilの場合は返信の下書きを作りません
+	Drafter Drafter
+	Store   *StateStore
+	Notify  func(notifier.Message) error
+}
+
+// Watch は前回以降の新しいコメントを取得し、スパムを保留または拒否して、それ以外のコメントを通知します。
+// 返信の下書きは承認待ちとして状態に保存し、Approve で投稿します。
+// 保留・拒否や通知に失敗したコメントは状態に残し、次回の監視でやり直します。
+func (w *Watcher) Watch(now time.Time) (*Result, error) {
+	state, err := w.Store.Load()
+	if err != nil {
+		return nil, err
+	}
+	since := state.LastChecked
+	if since.IsZero() {
+		since = now.Add(-initialLookback)
+	}
+
+	comments, err := w.API.CommentThreads(w.ChannelID, since)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch comments: %w", err)
+	}
+
+	result := &Result{}
+	// Comments that failed on earlier runs are retried first and classified as they were then
+	attempts := map[string]int{}
+	for _, u := range state.Unhandled {
+		attempts[u.Comment.ID] = u.Attempts
+		if u.SpamReason != "" {
+			result.Spam = append(result.Spam, Spam{Comment: u.Comment, Reason: u.SpamReason})
+		} else {
+			result.Genuine = append(result.Genuine, u.Comment)
+		}
+	}
+	state.Unhandled = nil
+
+	// 連投の判定のため古い順に処理する
+	for i := len(comments) - 1; i >= 0; i-- {
+		comment := comments[i]
+		if comment.PublishedAt.After(state.LastChecked) {
+			state.LastChecked = comment.PublishedAt
+		}
+		if _, retried := attempts[comment.ID]; retried || comment.AuthorChannelID == w.ChannelID {
+			continue
+		}
+
+		reason := w.Rules.Spam.spamReason(comment, state.duplicates(comment.Text))
+		state.remember(comment.Text)
+		if reason != "" {
+			result.Spam = append(result.Spam, Spam{Comment: comment, Reason: reason})
+			continue
+		}
+		result.Genuine = append(result.Genuine, comment)
+	}
+
+	var errs []error
+	// retry keeps a failed comment for the next run until it has been tried maxAttempts times
+	retry := func(comment youtube.Comment, spamReason string) {
+		n := attempts[comment.ID] + 1
+		if n >= maxAttempts {
+			slog.Error("giving up comment after repeated failures", "comment", comment.ID, "attempts", n)
+			return
+		}
+		state.Unhandled = append(state.Unhandled, Unhandled{Comment: comment, SpamReason: spamReason, Attempts: n})
+	}
+
+	moderateErr := w.moderate(result.Spam)
+	for _, comment := range result.Genuine {
+		draft, err := w.draft(comment, now)
+		if err != nil {
+			slog.Error("failed to draft reply", "comment", comment.ID, "error", err)
+		}
+		if err := w.Notify(commentMessage(comment, draft)); err != nil {
+			errs = append(errs, fmt.Errorf("failed to notify comment %s: %w", comment.ID, err))
+			// The reply is drafted again on the retry
+			retry(comment, "")
+			continue
+		}
+		if draft != nil {
+			state.Pending = append(state.Pending, *draft)
+			result.Drafts = append(result.Drafts, *draft)
+		}
+	}
+	if len(result.Spam) > 0 {
+		// The spam is reported only after it has been moderated, and both are retried together
+		err := moderateErr
+		if err == nil {
+			if err = w.Notify(spamMessage(result.Spam, w.Rules.Spam.Action)); err != nil {
+				err = fmt.Errorf("failed to notify spam: %w", err)
+			}
+		}
+		if err != nil {
+			errs = append(errs, err)
+			for _, s := range result.Spam {
+				retry(s.Comment, s.Reason)
+			}
+		}
+	}
+
+	if err := w.Store.Save(state); err != nil {
+		errs = append(errs, err)
+	}
+	return result, errors.Join(errs...)
+}
+
+// moderate はスパムをルールに従って保留または拒否します。
+func (w *Watcher) moderate(spam []Spam) error {
+	var status youtube.ModerationStatus
+	switch w.Rules.Spam.Action {
+	case ActionHold:
+		status = youtube.ModerationHeldForReview
+	case ActionReject:
+		status = youtube.ModerationRejected
+	default:
+		return nil
+	}
+	if len(spam) == 0 {
+		return nil
+	}
+
+	ids := make([]string, 0, len(spam))
+	for _, s := range spam {
+		ids = append(ids, s.Comment.ID)
+	}
+	if err := w.API.SetModerationStatus(ids, status, w.Rules.Spam.BanAuthor); err != nil {
+		return fmt.Errorf("failed to set moderation status: %w", err)
+	}
+	slog.Info("moderated spam comments", "count", len(ids), "status", status)
+	return nil
+}
+
+func (w *Watcher) draft(comment youtube.Comment, now time.Time) (*Draft, error) {
+	if w.Drafter == nil {
+		return nil, nil
+	}
+	reply, err := w.Drafter.Draft(comment)
+	if err != nil {
+		return nil, err
+	}
+	if reply == "" {
+		return nil, nil
+	}
+	return &Draft{
+		CommentID: comment.ID,
+		VideoID:   comment.VideoID,
+		Author:    comment.AuthorName,
+		Comment:   comment.Text,
+		Reply:     reply,
+		CreatedAt: now,
+	}, nil
+}
+
+// Approve は承認待ちの下書きを返信として投稿します。replyが空でなければ下書きの代わりに投稿します。
+func (w *Watcher) Approve(commentID, reply string) (string, error) {
+	state, err := w.Store.Load()
+	if err != nil {
+		return "", err
+	}
+	draft, ok := state.Draft(commentID)
+	if !ok {
+		return "", fmt.Errorf("no pending reply for comment %s", commentID)
+	}
+	if reply == "" {
+		reply = draft.Reply
+	}
+
+	id, err := w.API.Reply(commentID, reply)
+	if err != nil {
+		return "", fmt.Errorf("failed to post reply: %w", err)
+	}
+	state.removeDraft(commentID)
+	return id, w.Store.Save(state)
+}
+
+// Discard は承認待ちの下書きを破棄します。
+func (w *Watcher) Discard(commentID string) error {
+	state, err := w.Store.Load()
+	if err != nil {
+		return err
+	}
+	if !state.removeDraft(commentID) {
+		return fmt.Errorf("no pending reply for comment %s", commentID)
+	}
+	return w.Store.Save(state)
+}
+
+func commentMessage(comment youtube.Comment, draft *Draft) notifier.Message {
+	text := comment.Text
+	if draft != nil {
+		text += fmt.Sprintf("\n\n返信の下書き（承認待ち: %s）:\n%s", comment.ID, draft.Reply)
+	}
+	return notifier.Message{
+		Event: notifier.EventComment,
+		Title: comment.AuthorName + "さんからコメントがありました",
+		Text:  text,
+		Links: []notifier.Link{{Label: "コメントを見る", URL: comment.URL()}},
+	}
+}
+
+func spamMessage(spam []Spam, action Action) notifier.Message {
+	var b strings.Builder
+	for _, s := range spam {
+		fmt.Fprintf(&b, "- %s: %s（%s）\n", s.Comment.AuthorName, truncate(s.Comment.Text, 50), s.Reason)
+	}
+
+	title := fmt.Sprintf("スパムの疑いがあるコメントが%d件ありました", len(spam))
+	switch action {
+	case ActionHold:
+		title = fmt.Sprintf("スパムの疑いがあるコメント%d件を保留にしました", len(spam))
+	case ActionReject:
+		title = fmt.Sprintf("スパムの疑いがあるコメント%d件を拒否しました", len(spam))
+	}
+	return notifier.Message{
+		Event: notifier.EventComment,
+		Title: title,
+		Text:  b.String(),
+		Links: []notifier.Link{{Label: "YouTube Studioで確認", URL: "https://studio.youtube.com/"}},
+	}
+}
+
+func truncate(s string, n int) string {
+	runes := []rune(strings.ReplaceAll(s, "\n", " "))
+	if len(runes) <= n {
+		return string(runes)
+	}
+	return string(runes[:n]) + "…"
+}
@@ -0,0 +1,150 @@
+// Package disclosure はアフィリエイトや広告のリンクを含む投稿に、ステルスマーケティング規制で求められる広告の表示を付けます。
+// 投稿先ごとに決まった位置に表示を入れ、表示のないまま広告のリンクを投稿しないように確認します。
+package disclosure
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"html"
+	"maps"
+	"net/url"
+	"os"
+	"regexp"
+	"slices"
+	"strings"
+)
+
+// Medium は投稿先です。
+type Medium string
+
+const (
+	// MediumHatena ははてなブログの記事本文（HTML）です。表示は本文の先頭に入れます
+	MediumHatena Medium = "hatena"
+	// MediumYouTube はYouTubeの動画の説明です。表示は説明の1行目に入れます
+	MediumYouTube Medium = "youtube"
+	// MediumX はXの投稿です。表示は投稿の先頭に入れます
+	MediumX Medium = "x"
+	// MediumBluesky はBlueskyの投稿です。表示は投稿の先頭に入れます
+	MediumBluesky Medium = "bluesky"
+	// MediumFediverse はMastodonとMisskeyの投稿です。表示は投稿の先頭に入れます
+	MediumFediverse Medium = "fediverse"
+)
+
+// ErrMissingDisclosure は広告のリンクがあるのに広告の表示がないことを表します。
+var ErrMissingDisclosure = errors.New("advertising disclosure is missing")
+
+// Rules は広告のリンクの判定と、投稿先ごとの広告の表示です。
+type Rules struct {
+	// Links は広告のリンクのホストです。サブドメインも一致します。
+	// "amazon.co.jp?tag" のように ? の後にクエリパラメータを書くと、そのパラメータがあるリンクだけを広告とします
+	Links []string `json:"links"`
+	// Labels は投稿先ごとの広告の表示です。空の場合は広告のリンクを含む投稿をブロックします
+	Labels map[Medium]string `json:"labels"`
+}
+
+// DefaultRules はAmazonアソシエイトと主なASPのリンクを広告とし、「PR」の表示を付けます。
+var DefaultRules = Rules{
+	Links: []string{
+		"amzn.to", "amzn.asia", "amazon.co.jp?tag", "amazon.com?tag",
+		"a.r10.to", "hb.afl.rakuten.co.jp", "px.a8.net", "ck.jp.ap.valuecommerce.com", "af.moshimo.com",
+	},
+	Labels: map[Medium]string{
+		MediumHatena:    "【PR】この記事にはアフィリエイト広告（Amazonアソシエイトなど）が含まれています。",
+		MediumYouTube:   "【PR】この説明にはアフィリエイトリンク（Amazonアソシエイトなど）が含まれています。",
+		MediumX:         "#PR",
+		MediumBluesky:   "#PR",
+		MediumFediverse: "#PR",
+	},
+}
+
+// LoadRules はJSONファイルからルールを読み込みます。ファイルにない項目は DefaultRules の値を使い、pathが空の場合は DefaultRules を使います。
+// labels は投稿先ごとに上書きします。
+func LoadRules(path string) (Rules, error) {
+	if path == "" {
+		return DefaultRules, nil
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return Rules{}, fmt.Errorf("failed to read disclosure rules: %w", err)
+	}
+	rules := DefaultRules
+	// json.Unmarshal reuses slices and maps, so copy them to keep DefaultRules intact
+	rules.Links = slices.Clone(rules.Links)
+	rules.Labels = maps.Clone(rules.Labels)
+	if err := json.Unmarshal(data, &rules); err != nil {
+		return Rules{}, fmt.Errorf("failed to parse disclosure rules: %w", err)
+	}
+	return rules, nil
+}
+
+var (
+	urlPattern = regexp.MustCompile(`https?://[^\s"'<>）」]+`)
+	// sponsoredPattern matches links marked as sponsored in HTML
+	sponsoredPattern = regexp.MustCompile(`(?i)<a\s[^>]*rel=["'][^"']*\bsponsored\b[^"']*["'][^>]*>`)
+)
+
+// AdLinks は本文に含まれる広告のリンクを返します。HTMLで rel="sponsored" が付いたリンクも広告とします。
+func (r Rules) AdLinks(content string) []string {
+	var links []string
+	for _, raw := range urlPattern.FindAllString(html.UnescapeString(content), -1) {
+		if r.isAd(raw) && !slices.Contains(links, raw) {
+			links = append(links, raw)
+		}
+	}
+	links = append(links, sponsoredPattern.FindAllString(content, -1)...)
+	return links
+}
+
+func (r Rules) isAd(raw string) bool {
+	u, err := url.Parse(raw)
+	if err != nil {
+		return false
+	}
+	host := strings.ToLower(strings.TrimPrefix(u.Hostname(), "www."))
+	for _, link := range r.Links {
+		domain, param, _ := strings.Cut(link, "?")
+		if host != domain && !strings.HasSuffix(host, "."+domain) {
+			continue
+		}
+		if param == "" || u.Query().Has(param) {
+			return true
+		}
+	}
+	return false
+}
+
+// hasLabel は本文に広告の表示があるかを返します。
+func hasLabel(content, label string) bool {
+	return strings.Contains(content, label) || strings.Contains(content, html.EscapeString(label))
+}
+
+// Check は広告のリンクがあるのに広告の表示がない場合に ErrMissingDisclosure を返します。
+func (r Rules) Check(medium Medium, content string) error {
+	links := r.AdLinks(content)
+	if len(links) == 0 {
+		return nil
+	}
+	label := r.Labels[medium]
+	if label == "" || !hasLabel(content, label) {
+		return fmt.Errorf("%w in %s content with %s", ErrMissingDisclosure, medium, strings.Join(links, ", "))
+	}
+	return nil
+}
+
+// Disclose は広告のリンクがあり、広告の表示がない場合に投稿先の決まった位置に表示を入れます。
+// 広告のリンクがない本文はそのまま返します。投稿先の表示が設定されていない場合は ErrMissingDisclosure を返します。
+func (r Rules) Disclose(medium Medium, content string) (string, error) {
+	label := r.Labels[medium]
+	if len(r.AdLinks(content)) > 0 && label != "" && !hasLabel(content, label) {
+		switch medium {
+		case MediumHatena:
+			content = fmt.Sprintf("<p class=\"disclosure\">%s</p>\n", html.EscapeString(label)) + content
+		case MediumYouTube:
+			content = label + "\n\n" + content
+		default:
+			content = label + "\n" + content
+		}
+	}
+	return content, r.Check(medium, content)
+}
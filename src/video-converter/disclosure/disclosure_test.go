@@ -0,0 +1,125 @@
+package disclosure
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestAdLinks(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		content string
+		want    []string
+	}{
+		{name: "正常系: 短縮URL", content: "▼ マイク\n　https://amzn.to/4j9UTcf", want: []string{"https://amzn.to/4j9UTcf"}},
+		{
+			name:    "正常系: タグ付きのAmazonのURL",
+			content: `<a href="https://www.amazon.co.jp/dp/B0TEST?tag=gaba-22&amp;th=1">商品</a>`,
+			want:    []string{"https://www.amazon.co.jp/dp/B0TEST?tag=gaba-22&th=1"},
+		},
+		{name: "正常系: タグのないAmazonのURLは広告ではない", content: "https://www.amazon.co.jp/dp/B0TEST"},
+		{name: "正常系: サブドメイン", content: "https://sub.px.a8.net/svt/ejp?a8mat=1", want: []string{"https://sub.px.a8.net/svt/ejp?a8mat=1"}},
+		{
+			name:    "正常系: sponsoredのリンク",
+			content: `<a rel="nofollow sponsored" href="https://shop.example.com/">提供</a>`,
+			want:    []string{`<a rel="nofollow sponsored" href="https://shop.example.com/">`},
+		},
+		{name: "正常系: 広告のリンクなし", content: "https://www.youtube.com/watch?v=abc https://gaba-fortnite.hatenablog.com/"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			got := DefaultRules.AdLinks(tt.content)
+			if strings.Join(got, "|") != strings.Join(tt.want, "|") {
+				t.Errorf("AdLinks() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDisclose(t *testing.T) {
+	t.Parallel()
+
+	noLabel := DefaultRules
+	noLabel.Labels = map[Medium]string{}
+
+	tests := []struct {
+		name    string
+		rules   Rules
+		medium  Medium
+		content string
+		want    string
+		wantErr error
+	}{
+		{
+			name:    "正常系: はてなブログは本文の先頭",
+			rules:   DefaultRules,
+			medium:  MediumHatena,
+			content: `<p>どうも。</p><p><a href="https://amzn.to/abc">商品</a></p>`,
+			want:    `<p class="disclosure">` + DefaultRules.Labels[MediumHatena] + "</p>\n" + `<p>どうも。</p><p><a href="https://amzn.to/abc">商品</a></p>`,
+		},
+		{
+			name:    "正常系: YouTubeは説明の1行目",
+			rules:   DefaultRules,
+			medium:  MediumYouTube,
+			content: "プレイログです。\nhttps://amzn.to/abc",
+			want:    DefaultRules.Labels[MediumYouTube] + "\n\nプレイログです。\nhttps://amzn.to/abc",
+		},
+		{name: "正常系: Xは投稿の先頭", rules: DefaultRules, medium: MediumX, content: "おすすめ https://amzn.to/abc", want: "#PR\nおすすめ https://amzn.to/abc"},
+		{name: "正常系: Blueskyは投稿の先頭", rules: DefaultRules, medium: MediumBluesky, content: "おすすめ https://amzn.to/abc", want: "#PR\nおすすめ https://amzn.to/abc"},
+		{name: "正常系: Mastodon・Misskeyは投稿の先頭", rules: DefaultRules, medium: MediumFediverse, content: "おすすめ https://amzn.to/abc", want: "#PR\nおすすめ https://amzn.to/abc"},
+		{name: "正常系: 表示済みなら入れない", rules: DefaultRules, medium: MediumX, content: "#PR おすすめ https://amzn.to/abc", want: "#PR おすすめ https://amzn.to/abc"},
+		{name: "正常系: 広告のリンクがなければそのまま", rules: noLabel, medium: MediumX, content: "更新しました https://example.com", want: "更新しました https://example.com"},
+		{name: "異常系: 表示が設定されていない", rules: noLabel, medium: MediumYouTube, content: "https://amzn.to/abc", wantErr: ErrMissingDisclosure},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			got, err := tt.rules.Disclose(tt.medium, tt.content)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Disclose() error = %v, want %v", err, tt.wantErr)
+			}
+			if err == nil && got != tt.want {
+				t.Errorf("Disclose() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCheck(t *testing.T) {
+	t.Parallel()
+
+	err := DefaultRules.Check(MediumHatena, `<p><a href="https://amzn.to/abc">商品</a></p>`)
+	if !errors.Is(err, ErrMissingDisclosure) || !strings.Contains(err.Error(), "https://amzn.to/abc") {
+		t.Errorf("Check() error = %v", err)
+	}
+	if err := DefaultRules.Check(MediumHatena, "<p>広告なし</p>"); err != nil {
+		t.Errorf("Check() error = %v", err)
+	}
+}
+
+func TestLoadRules(t *testing.T) {
+	t.Parallel()
+
+	path := filepath.Join(t.TempDir(), "disclosure.json")
+	if err := os.WriteFile(path, []byte(`{"labels": {"x": "【広告】"}}`), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	rules, err := LoadRules(path)
+	if err != nil {
+		t.Fatalf("LoadRules() error = %v", err)
+	}
+	if rules.Labels[MediumX] != "【広告】" || rules.Labels[MediumHatena] != DefaultRules.Labels[MediumHatena] || len(rules.Links) != len(DefaultRules.Links) {
+		t.Errorf("LoadRules() = %+v", rules)
+	}
+	if DefaultRules.Labels[MediumX] != "#PR" {
+		t.Error("LoadRules() modified DefaultRules")
+	}
+}
@@ -0,0 +1,158 @@
+// Package fediverse はMastodonとMisskeyのアカウントに告知を投稿します。
+package fediverse
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+)
+
+// ErrNotConfigured は投稿先のアカウントが設定されていないことを表します。
+var ErrNotConfigured = errors.New("fediverse accounts are not configured")
+
+// Maximum size of an attached image
+const maxMediaSize = 8 * 1024 * 1024
+
+// Media は投稿に添付する画像です。
+type Media struct {
+	URL         string
+	Description string
+}
+
+// Publisher はひとつのアカウントに投稿します。
+type Publisher interface {
+	Name() string
+	Publish(text string, media *Media) (string, error)
+}
+
+// Account はアカウントごとの設定です。Typeは "mastodon" か "misskey" を指定します。
+// Visibilityはインスタンスごとの公開範囲で、空の場合は各APIのデフォルトを使います。
+type Account struct {
+	Type       string `json:"type"`
+	Instance   string `json:"instance"`
+	Token      string `json:"token"`
+	Visibility string `json:"visibility"`
+	// LocalOnly はMisskeyでローカルタイムラインだけに流す場合に指定します
+	LocalOnly bool `json:"local_only"`
+}
+
+// AccountsFromEnv は FEDIVERSE_ACCOUNTS のJSON配列、または FEDIVERSE_ACCOUNTS_FILE のファイルからアカウントを読み込みます。
+func AccountsFromEnv() ([]Account, error) {
+	data := []byte(os.Getenv("FEDIVERSE_ACCOUNTS"))
+	if path := os.Getenv("FEDIVERSE_ACCOUNTS_FILE"); len(data) == 0 && path != "" {
+		var err error
+		if data, err = os.ReadFile(path); err != nil {
+			return nil, fmt.Errorf("failed to read fediverse accounts: %w", err)
+		}
+	}
+	if len(data) == 0 {
+		return nil, nil
+	}
+
+	var accounts []Account
+	if err := json.Unmarshal(data, &accounts); err != nil {
+		return nil, fmt.Errorf("failed to parse fediverse accounts: %w", err)
+	}
+	return accounts, nil
+}
+
+// NewPublisher はアカウントの種類に応じたPublisherを作成します。
+func NewPublisher(account Account) (Publisher, error) {
+	if account.Instance == "" || account.Token == "" {
+		return nil, fmt.Errorf("instance and token are required for %s account", account.Type)
+	}
+	client := &http.Client{Timeout: 30 * time.Second}
+	instance := strings.TrimRight(account.Instance, "/")
+
+	switch account.Type {
+	case "mastodon":
+		return &Mastodon{httpClient: client, instance: instance, token: account.Token, visibility: account.Visibility}, nil
+	case "misskey":
+		return &Misskey{httpClient: client, instance: instance, token: account.Token, visibility: account.Visibility, localOnly: account.LocalOnly}, nil
+	default:
+		return nil, fmt.Errorf("unknown fediverse account type: %q", account.Type)
+	}
+}
+
+// PublishAll はすべてのアカウントに投稿します。アカウントがない場合は ErrNotConfigured を返します。
+// 一部のアカウントへの投稿に失敗しても残りのアカウントには投稿し、失敗をまとめて返します。
+func PublishAll(accounts []Account, text string, media *Media) error {
+	if len(accounts) == 0 {
+		return ErrNotConfigured
+	}
+
+	var errs []error
+	for _, account := range accounts {
+		publisher, err := NewPublisher(account)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		url, err := publisher.Publish(text, media)
+		if err != nil {
+			slog.Error("Failed to publish to fediverse", "account", publisher.Name(), "error", err)
+			errs = append(errs, fmt.Errorf("%s: %w", publisher.Name(), err))
+			continue
+		}
+		slog.Info("Published to fediverse", "account", publisher.Name(), "url", url)
+	}
+	return errors.Join(errs...)
+}
+
+// downloadMedia は添付する画像を取得します。
+func downloadMedia(client *http.Client, url string) ([]byte, string, error) {
+	resp, err := client.Get(url)
+	if err != nil {
+		return nil, "", err
+	}
+	defer func() {
+		if err := resp.Body.Close(); err != nil {
+			slog.Error("Failed to close response body", "error", err)
+		}
+	}()
+	if resp.StatusCode != http.StatusOK {
+		return nil, "", fmt.Errorf("failed to download media: status %d", resp.StatusCode)
+	}
+
+	data, err := io.ReadAll(io.LimitReader(resp.Body, maxMediaSize+1))
+	if err != nil {
+		return nil, "", err
+	}
+	if len(data) > maxMediaSize {
+		return nil, "", fmt.Errorf("media is larger than %d bytes", maxMediaSize)
+	}
+	contentType := resp.Header.Get("Content-Type")
+	if contentType == "" {
+		contentType = http.DetectContentType(data)
+	}
+	return data, contentType, nil
+}
+
+// doJSON はリクエストを送り、成功した場合はレスポンスをoutにデコードします。
+func doJSON(client *http.Client, req *http.Request, out any) error {
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err := resp.Body.Close(); err != nil {
+			slog.Error("Failed to close response body", "error", err)
+		}
+	}()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		slog.Error("Fediverse API error response", "url", req.URL.String(), "status", resp.Status, "body", string(body))
+		return fmt.Errorf("%s returned unexpected status code: %d", req.URL.Path, resp.StatusCode)
+	}
+	return json.Unmarshal(body, out)
+}
@@ -0,0 +1,228 @@
+package fediverse
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// fakeInstance はMastodonとMisskeyの投稿APIを実装したサーバーです。
+type fakeInstance struct {
+	mu       sync.Mutex
+	statuses []map[string]any
+	uploads  []string
+}
+
+func (f *fakeInstance) handler(t *testing.T) http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/image.jpg", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "image/jpeg")
+		_, _ = w.Write([]byte("jpeg"))
+	})
+	mux.HandleFunc("/api/v2/media", func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer mastodon-token" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		f.recordUpload(t, r, "")
+		_, _ = w.Write([]byte(`{"id":"m1"}`))
+	})
+	mux.HandleFunc("/api/v1/statuses", func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer mastodon-token" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		f.recordStatus(t, r)
+		_, _ = w.Write([]byte(`{"id":"1","url":"https://mstdn.example/@gaba/1"}`))
+	})
+	mux.HandleFunc("/api/drive/files/create", func(w http.ResponseWriter, r *http.Request) {
+		f.recordUpload(t, r, "misskey-token")
+		_, _ = w.Write([]byte(`{"id":"f1"}`))
+	})
+	mux.HandleFunc("/api/notes/create", func(w http.ResponseWriter, r *http.Request) {
+		f.recordStatus(t, r)
+		_, _ = w.Write([]byte(`{"createdNote":{"id":"n1"}}`))
+	})
+	return mux
+}
+
+func (f *fakeInstance) recordUpload(t *testing.T, r *http.Request, wantToken string) {
+	if err := r.ParseMultipartForm(1 << 20); err != nil {
+		t.Errorf("ParseMultipartForm() error = %v", err)
+		return
+	}
+	if wantToken != "" && r.FormValue("i") != wantToken {
+		t.Errorf("i = %q, want %q", r.FormValue("i"), wantToken)
+	}
+	file, _, err := r.FormFile("file")
+	if err != nil {
+		t.Errorf("FormFile() error = %v", err)
+		return
+	}
+	data, _ := io.ReadAll(file)
+	f.mu.Lock()
+	f.uploads = append(f.uploads, string(data))
+	f.mu.Unlock()
+}
+
+func (f *fakeInstance) recordStatus(t *testing.T, r *http.Request) {
+	var payload map[string]any
+	if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+		t.Errorf("failed to decode payload: %v", err)
+	}
+	f.mu.Lock()
+	f.statuses = append(f.statuses, payload)
+	f.mu.Unlock()
+}
+
+func TestPublishers(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name        string
+		account     Account
+		media       bool
+		wantURL     string
+		wantPayload map[string]any
+		wantErr     bool
+	}{
+		{
+			name:    "正常系: Mastodonに画像付きで投稿する",
+			account: Account{Type: "mastodon", Token: "mastodon-token", Visibility: "unlisted"},
+			media:   true,
+			wantURL: "https://mstdn.example/@gaba/1",
+			wantPayload: map[string]any{
+				"status":     "告知",
+				"visibility": "unlisted",
+				"media_ids":  []any{"m1"},
+			},
+		},
+		{
+			name:        "正常系: Mastodonで公開範囲を指定しない",
+			account:     Account{Type: "mastodon", Token: "mastodon-token"},
+			wantURL:     "https://mstdn.example/@gaba/1",
+			wantPayload: map[string]any{"status": "告知"},
+		},
+		{
+			name:    "正常系: Misskeyに画像付きでローカル限定で投稿する",
+			account: Account{Type: "misskey", Token: "misskey-token", Visibility: "home", LocalOnly: true},
+			media:   true,
+			wantURL: "/notes/n1",
+			wantPayload: map[string]any{
+				"i":          "misskey-token",
+				"text":       "告知",
+				"visibility": "home",
+				"localOnly":  true,
+				"fileIds":    []any{"f1"},
+			},
+		},
+		{
+			name:    "異常系: Mastodonの認証に失敗する",
+			account: Account{Type: "mastodon", Token: "wrong"},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			instance := &fakeInstance{}
+			server := httptest.NewServer(instance.handler(t))
+			defer server.Close()
+
+			tt.account.Instance = server.URL
+			publisher, err := NewPublisher(tt.account)
+			if err != nil {
+				t.Fatalf("NewPublisher() error = %v", err)
+			}
+
+			var media *Media
+			if tt.media {
+				media = &Media{URL: server.URL + "/image.jpg", Description: "サムネイル"}
+			}
+			url, err := publisher.Publish("告知", media)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Publish() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+
+			if !strings.HasSuffix(url, tt.wantURL) {
+				t.Errorf("url = %q, want suffix %q", url, tt.wantURL)
+			}
+			if len(instance.statuses) != 1 {
+				t.Fatalf("statuses = %d, want 1", len(instance.statuses))
+			}
+			got, _ := json.Marshal(instance.statuses[0])
+			want, _ := json.Marshal(tt.wantPayload)
+			if string(got) != string(want) {
+				t.Errorf("payload = %s, want %s", got, want)
+			}
+			if tt.media && (len(instance.uploads) != 1 || instance.uploads[0] != "jpeg") {
+				t.Errorf("uploads = %v, want [jpeg]", instance.uploads)
+			}
+		})
+	}
+}
+
+func TestNewPublisher(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		account Account
+		wantErr bool
+	}{
+		{name: "正常系: Mastodon", account: Account{Type: "mastodon", Instance: "https://mstdn.jp", Token: "t"}},
+		{name: "正常系: Misskey", account: Account{Type: "misskey", Instance: "https://misskey.io/", Token: "t"}},
+		{name: "異常系: 不明な種類", account: Account{Type: "pleroma", Instance: "https://example.com", Token: "t"}, wantErr: true},
+		{name: "異常系: トークンがない", account: Account{Type: "mastodon", Instance: "https://mstdn.jp"}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			if _, err := NewPublisher(tt.account); (err != nil) != tt.wantErr {
+				t.Errorf("NewPublisher() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestPublishAll(t *testing.T) {
+	instance := &fakeInstance{}
+	server := httptest.NewServer(instance.handler(t))
+	defer server.Close()
+
+	t.Setenv("FEDIVERSE_ACCOUNTS", "")
+	t.Setenv("FEDIVERSE_ACCOUNTS_FILE", "")
+	accounts, err := AccountsFromEnv()
+	if err != nil {
+		t.Fatalf("AccountsFromEnv() error = %v", err)
+	}
+	if err := PublishAll(accounts, "告知", nil); !errors.Is(err, ErrNotConfigured) {
+		t.Errorf("PublishAll() error = %v, want ErrNotConfigured", err)
+	}
+
+	// 失敗したアカウントがあっても残りのアカウントには投稿する
+	t.Setenv("FEDIVERSE_ACCOUNTS", `[
+		{"type": "mastodon", "instance": "`+server.URL+`", "token": "wrong"},
+		{"type": "misskey", "instance": "`+server.URL+`", "token": "misskey-token"}
+	]`)
+	if accounts, err = AccountsFromEnv(); err != nil || len(accounts) != 2 {
+		t.Fatalf("AccountsFromEnv() = %v, %v", accounts, err)
+	}
+	if err := PublishAll(accounts, "告知", nil); err == nil || !strings.Contains(err.Error(), "mastodon:") {
+		t.Errorf("PublishAll() error = %v, want mastodon error", err)
+	}
+	if len(instance.statuses) != 1 {
+		t.Errorf("statuses = %d, want 1", len(instance.statuses))
+	}
+}
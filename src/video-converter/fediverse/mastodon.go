@@ -0,0 +1,103 @@
+package fediverse
+
+import (
+	"bytes"
+	"encoding/json"
+	"log/slog"
+	"mime/multipart"
+	"net/http"
+	"net/textproto"
+)
+
+// Mastodon はMastodon APIで投稿します。
+type Mastodon struct {
+	httpClient *http.Client
+	instance   string
+	token      string
+	visibility string
+}
+
+// Name はログと通知に使うアカウント名です。
+func (m *Mastodon) Name() string {
+	return "mastodon:" + m.instance
+}
+
+// Publish は POST /api/v1/statuses で投稿し、投稿のURLを返します。
+// 画像のアップロードに失敗した場合は、画像なしで投稿します。
+func (m *Mastodon) Publish(text string, media *Media) (string, error) {
+	payload := map[string]any{"status": text}
+	if m.visibility != "" {
+		payload["visibility"] = m.visibility
+	}
+	if media != nil && media.URL != "" {
+		id, err := m.uploadMedia(media)
+		if err != nil {
+			slog.Warn("Failed to upload media to Mastodon, posting without it", "instance", m.instance, "error", err)
+		} else {
+			payload["media_ids"] = []string{id}
+		}
+	}
+
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return "", err
+	}
+	req, err := http.NewRequest("POST", m.instance+"/api/v1/statuses", bytes.NewReader(body))
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "Bearer "+m.token)
+
+	var status struct {
+		URL string `json:"url"`
+	}
+	if err := doJSON(m.httpClient, req, &status); err != nil {
+		return "", err
+	}
+	return status.URL, nil
+}
+
+// uploadMedia は POST /api/v2/media で画像をアップロードし、メディアIDを返します。
+func (m *Mastodon) uploadMedia(media *Media) (string, error) {
+	data, contentType, err := downloadMedia(m.httpClient, media.URL)
+	if err != nil {
+		return "", err
+	}
+
+	body := &bytes.Buffer{}
+	writer := multipart.NewWriter(body)
+	header := textproto.MIMEHeader{}
+	header.Set("Content-Disposition", `form-data; name="file"; filename="image"`)
+	header.Set("Content-Type", contentType)
+	part, err := writer.CreatePart(header)
+	if err != nil {
+		return "", err
+	}
+	if _, err := part.Write(data); err != nil {
+		return "", err
+	}
+	if media.Description != "" {
+		if err := writer.WriteField("description", media.Description); err != nil {
+			return "", err
+		}
+	}
+	if err := writer.Close(); err != nil {
+		return "", err
+	}
+
+	req, err := http.NewRequest("POST", m.instance+"/api/v2/media", body)
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Content-Type", writer.FormDataContentType())
+	req.Header.Set("Authorization", "Bearer "+m.token)
+
+	var attachment struct {
+		ID string `json:"id"`
+	}
+	if err := doJSON(m.httpClient, req, &attachment); err != nil {
+		return "", err
+	}
+	return attachment.ID, nil
+}
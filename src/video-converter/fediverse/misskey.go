@@ -0,0 +1,110 @@
+package fediverse
+
+import (
+	"bytes"
+	"encoding/json"
+	"log/slog"
+	"mime/multipart"
+	"net/http"
+	"net/textproto"
+)
+
+// Misskey はMisskey APIで投稿します。
+type Misskey struct {
+	httpClient *http.Client
+	instance   string
+	token      string
+	visibility string
+	localOnly  bool
+}
+
+// Name はログと通知に使うアカウント名です。
+func (m *Misskey) Name() string {
+	return "misskey:" + m.instance
+}
+
+// Publish は notes/create でノートを作成し、ノートのURLを返します。
+// 画像のアップロードに失敗した場合は、画像なしで投稿します。
+func (m *Misskey) Publish(text string, media *Media) (string, error) {
+	payload := map[string]any{"i": m.token, "text": text}
+	if m.visibility != "" {
+		payload["visibility"] = m.visibility
+	}
+	if m.localOnly {
+		payload["localOnly"] = true
+	}
+	if media != nil && media.URL != "" {
+		id, err := m.uploadFile(media)
+		if err != nil {
+			slog.Warn("Failed to upload media to Misskey, posting without it", "instance", m.instance, "error", err)
+		} else {
+			payload["fileIds"] = []string{id}
+		}
+	}
+
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return "", err
+	}
+	req, err := http.NewRequest("POST", m.instance+"/api/notes/create", bytes.NewReader(body))
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	var result struct {
+		CreatedNote struct {
+			ID string `json:"id"`
+		} `json:"createdNote"`
+	}
+	if err := doJSON(m.httpClient, req, &result); err != nil {
+		return "", err
+	}
+	return m.instance + "/notes/" + result.CreatedNote.ID, nil
+}
+
+// uploadFile は drive/files/create で画像をドライブにアップロードし、ファイルIDを返します。
+func (m *Misskey) uploadFile(media *Media) (string, error) {
+	data, contentType, err := downloadMedia(m.httpClient, media.URL)
+	if err != nil {
+		return "", err
+	}
+
+	body := &bytes.Buffer{}
+	writer := multipart.NewWriter(body)
+	if err := writer.WriteField("i", m.token); err != nil {
+		return "", err
+	}
+	if media.Description != "" {
+		if err := writer.WriteField("comment", media.Description); err != nil {
+			return "", err
+		}
+	}
+	header := textproto.MIMEHeader{}
+	header.Set("Content-Disposition", `form-data; name="file"; filename="image"`)
+	header.Set("Content-Type", contentType)
+	part, err := writer.CreatePart(header)
+	if err != nil {
+		return "", err
+	}
+	if _, err := part.Write(data); err != nil {
+		return "", err
+	}
+	if err := writer.Close(); err != nil {
+		return "", err
+	}
+
+	req, err := http.NewRequest("POST", m.instance+"/api/drive/files/create", body)
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Content-Type", writer.FormDataContentType())
+
+	var file struct {
+		ID string `json:"id"`
+	}
+	if err := doJSON(m.httpClient, req, &file); err != nil {
+		return "", err
+	}
+	return file.ID, nil
+}
@@ -3,25 +3,28 @@ package videoconverter
 import (
 	"bytes"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
 	"net/http/httptest"
-	"reflect"
 	"strings"
 	"testing"
+	"time"
+
+	"thiroyoshi.com/video-converter/youtube"
 )
 
-type TokenRefresher interface {
-	RefreshAccessToken() (string, error)
+type ClientFactory interface {
+	NewYouTubeClient() (*youtube.Client, error)
 }
 
 type VideoUpdater interface {
-	UpdateVideoSnippet(videoID, title, accessToken string) ([]byte, error)
+	UpdateVideoSnippet(client *youtube.Client, videoID, title string) ([]byte, error)
 }
 
 type PlaylistManager interface {
-	AddVideoToPlaylist(videoID, playlistID, accessToken string) ([]byte, error)
+	AddVideoToPlaylist(client *youtube.Client, videoID, playlistID string) error
 }
 
 type SocialPoster interface {
@@ -29,34 +32,38 @@ type SocialPoster interface {
 }
 
 type Dependencies struct {
-	TokenRefresher  TokenRefresher
+	ClientFactory   ClientFactory
 	VideoUpdater    VideoUpdater
 	PlaylistManager PlaylistManager
 	SocialPoster    SocialPoster
 }
 
-type RealTokenRefresher struct{}
+type RealClientFactory struct{}
 
-func (r *RealTokenRefresher) RefreshAccessToken() (string, error) {
-	return refreshAccessToken()
+func (r *RealClientFactory) NewYouTubeClient() (*youtube.Client, error) {
+	return youtube.NewClientFromEnv()
 }
 
 type RealVideoUpdater struct{}
 
-func (r *RealVideoUpdater) UpdateVideoSnippet(videoID, title, accessToken string) ([]byte, error) {
-	return updateVideoSnippet(videoID, title, accessToken)
+func (r *RealVideoUpdater) UpdateVideoSnippet(client *youtube.Client, videoID, title string) ([]byte, error) {
+	return updateVideoSnippet(client, videoID, title)
 }
 
 type RealPlaylistManager struct{}
 
-func (r *RealPlaylistManager) AddVideoToPlaylist(videoID, playlistID, accessToken string) ([]byte, error) {
-	return addVideoToPlaylist(videoID, playlistID, accessToken)
+func (r *RealPlaylistManager) AddVideoToPlaylist(client *youtube.Client, videoID, playlistID string) error {
+	return addVideoToPlaylist(client, videoID, playlistID)
 }
 
 type RealSocialPoster struct{}
 
 func (r *RealSocialPoster) PostX(url string) error {
-	return postX(url)
+	message, err := composeAnnouncement("Test Video Title", url, time.Now())
+	if err != nil {
+		return err
+	}
+	return postX(message, url)
 }
 
 func videoConverterWithDeps(deps Dependencies) http.HandlerFunc {
@@ -71,7 +78,7 @@ func videoConverterWithDeps(deps Dependencies) http.HandlerFunc {
 			return
 		}
 
-		accessToken, err := deps.TokenRefresher.RefreshAccessToken()
+		client, err := deps.ClientFactory.NewYouTubeClient()
 		if err != nil {
 			w.WriteHeader(http.StatusInternalServerError)
 			if _, err := fmt.Fprint(w, err); err != nil {
@@ -120,7 +127,7 @@ func videoConverterWithDeps(deps Dependencies) http.HandlerFunc {
 		title := fmt.Sprintf("GABAのプレイログ %s #Fortnite #gameplay #フォートナイト #プレイ動画 #ps5", "2023-01-01 00:00:00")
 		playlistID := playlistNormal
 
-		resp, err := deps.VideoUpdater.UpdateVideoSnippet(videoID, title, accessToken)
+		resp, err := deps.VideoUpdater.UpdateVideoSnippet(client, videoID, title)
 		if err != nil {
 			w.WriteHeader(http.StatusInternalServerError)
 			if _, err := fmt.Fprint(w, err); err != nil {
@@ -129,8 +136,7 @@ func videoConverterWithDeps(deps Dependencies) http.HandlerFunc {
 			return
 		}
 
-		_, err = deps.PlaylistManager.AddVideoToPlaylist(videoID, playlistID, accessToken)
-		if err != nil {
+		if err := deps.PlaylistManager.AddVideoToPlaylist(client, videoID, playlistID); err != nil {
 			w.WriteHeader(http.StatusInternalServerError)
 			if _, err := fmt.Fprint(w, err); err != nil {
 				fmt.Printf("failed to write error to response: %v\n", err)
@@ -155,22 +161,22 @@ func videoConverterWithDeps(deps Dependencies) http.HandlerFunc {
 	}
 }
 
-type MockTokenRefresher struct {
+type MockClientFactory struct {
 	ShouldError bool
 }
 
-func (m *MockTokenRefresher) RefreshAccessToken() (string, error) {
+func (m *MockClientFactory) NewYouTubeClient() (*youtube.Client, error) {
 	if m.ShouldError {
-		return "", fmt.Errorf("mock refresh token error")
+		return nil, fmt.Errorf("mock youtube client error")
 	}
-	return "mock_access_token", nil
+	return youtube.NewClient(youtube.Credentials{ClientID: "id", ClientSecret: "secret", RefreshToken: "refresh"}), nil
 }
 
 type MockVideoUpdater struct {
 	ShouldError bool
 }
 
-func (m *MockVideoUpdater) UpdateVideoSnippet(videoID, title, accessToken string) ([]byte, error) {
+func (m *MockVideoUpdater) UpdateVideoSnippet(client *youtube.Client, videoID, title string) ([]byte, error) {
 	if m.ShouldError {
 		return nil, fmt.Errorf("mock update snippet error")
 	}
@@ -181,11 +187,11 @@ type MockPlaylistManager struct {
 	ShouldError bool
 }
 
-func (m *MockPlaylistManager) AddVideoToPlaylist(videoID, playlistID, accessToken string) ([]byte, error) {
+func (m *MockPlaylistManager) AddVideoToPlaylist(client *youtube.Client, videoID, playlistID string) error {
 	if m.ShouldError {
-		return nil, fmt.Errorf("mock add to playlist error")
+		return fmt.Errorf("mock add to playlist error")
 	}
-	return []byte(`{"id": "mock_item_id"}`), nil
+	return nil
 }
 
 type MockSocialPoster struct {
@@ -234,6 +240,17 @@ func setupTestServers(t *testing.T) (map[string]*httptest.Server, func()) {
 	}))
 
 	youtubePlaylistsServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// 追加する前に、すでに再生リストに含まれていないかを確認する
+		if r.Method == "GET" {
+			if r.URL.Query().Get("videoId") != "test_video_id" {
+				t.Errorf("Expected videoId=test_video_id, got %s", r.URL.Query().Get("videoId"))
+			}
+			w.Header().Set("Content-Type", "application/json")
+			if _, err := w.Write([]byte(`{"items": []}`)); err != nil {
+				t.Errorf("Failed to write response: %v", err)
+			}
+			return
+		}
 		if r.Method != "POST" {
 			t.Errorf("Expected POST request, got %s", r.Method)
 		}
@@ -270,11 +287,33 @@ func setupTestServers(t *testing.T) (map[string]*httptest.Server, func()) {
 		}
 	}))
 
+	twitterUploadServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != "POST" {
+			t.Errorf("Expected POST request, got %s", r.Method)
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
+		if _, err := w.Write([]byte(`{"media_id_string": "9876543210"}`)); err != nil {
+			t.Errorf("Failed to write response: %v", err)
+		}
+	}))
+
+	thumbnailServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "image/jpeg")
+		w.WriteHeader(http.StatusOK)
+		if _, err := w.Write([]byte("test_thumbnail")); err != nil {
+			t.Errorf("Failed to write response: %v", err)
+		}
+	}))
+
 	servers := map[string]*httptest.Server{
 		"oauth":             oauthServer,
 		"youtube_videos":    youtubeVideosServer,
 		"youtube_playlists": youtubePlaylistsServer,
 		"twitter":           twitterServer,
+		"twitter_upload":    twitterUploadServer,
+		"thumbnail":         thumbnailServer,
 	}
 
 	cleanup := func() {
@@ -286,31 +325,25 @@ func setupTestServers(t *testing.T) (map[string]*httptest.Server, func()) {
 	return servers, cleanup
 }
 
-func TestRefreshAccessToken(t *testing.T) {
-	t.Parallel()
-
-	servers, cleanup := setupTestServers(t)
-	defer cleanup()
-
-	originalTransport := http.DefaultTransport
-	defer func() {
-		http.DefaultTransport = originalTransport
-	}()
-
-	http.DefaultTransport = &customTransport{
-		originalTransport: originalTransport,
-		servers:           servers,
+func TestYouTubeClientFromEnv(t *testing.T) {
+	t.Setenv("YOUTUBE_CLIENT_ID", "")
+	t.Setenv("YOUTUBE_CLIENT_SECRET", "")
+	t.Setenv("YOUTUBE_REFRESH_TOKEN", "")
+	if _, err := (&RealClientFactory{}).NewYouTubeClient(); !errors.Is(err, youtube.ErrNotConfigured) {
+		t.Errorf("NewYouTubeClient() error = %v, want ErrNotConfigured", err)
 	}
 
-	token, err := refreshAccessToken()
-
-	if err != nil {
-		t.Errorf("refreshAccessToken() returned an error: %v", err)
+	setYouTubeCredentials(t)
+	if _, err := (&RealClientFactory{}).NewYouTubeClient(); err != nil {
+		t.Errorf("NewYouTubeClient() error = %v", err)
 	}
+}
 
-	if token != "test_access_token" {
-		t.Errorf("refreshAccessToken() returned wrong token: got %v, want %v", token, "test_access_token")
-	}
+// setYouTubeCredentials はテスト用のYouTubeの認証情報を環境変数に設定します。
+func setYouTubeCredentials(t *testing.T) {
+	t.Setenv("YOUTUBE_CLIENT_ID", "test-client-id")
+	t.Setenv("YOUTUBE_CLIENT_SECRET", "test-client-secret")
+	t.Setenv("YOUTUBE_REFRESH_TOKEN", "test-refresh-token")
 }
 
 type customTransport struct {
@@ -335,7 +368,7 @@ func (t *customTransport) RoundTrip(req *http.Request) (*http.Response, error) {
 	}
 
 	switch {
-	case strings.Contains(req.URL.Host, "accounts.google.com") || strings.Contains(req.URL.Path, "oauth2/token"):
+	case strings.Contains(req.URL.Host, "oauth2.googleapis.com") || strings.Contains(req.URL.Path, "oauth2/token"):
 		server = t.servers["oauth"]
 		pathToUse = "/"
 		fmt.Println("DEBUG: Routing to OAuth mock server")
@@ -351,6 +384,16 @@ func (t *customTransport) RoundTrip(req *http.Request) (*http.Response, error) {
 			fmt.Println("DEBUG: Routing to YouTube Playlists mock server")
 		}
 
+	case strings.Contains(req.URL.Host, "upload.twitter.com"):
+		server = t.servers["twitter_upload"]
+		pathToUse = "/"
+		fmt.Println("DEBUG: Routing to Twitter upload mock server")
+
+	case strings.Contains(req.URL.Host, "i.ytimg.com"):
+		server = t.servers["thumbnail"]
+		pathToUse = "/"
+		fmt.Println("DEBUG: Routing to thumbnail mock server")
+
 	case strings.Contains(req.URL.Host, "api.twitter.com") || strings.Contains(req.URL.Path, "tweets"):
 		server = t.servers["twitter"]
 		pathToUse = "/2/tweets"
@@ -399,22 +442,27 @@ func TestUpdateVideoSnippet(t *testing.T) {
 		servers:           servers,
 	}
 
-	body, err := updateVideoSnippet("test_video_id", "Test Video Title", "test_access_token")
+	client := youtube.NewClient(youtube.Credentials{ClientID: "id", ClientSecret: "secret", RefreshToken: "refresh"})
+	body, err := updateVideoSnippet(client, "test_video_id", "Test Video Title")
 
 	if err != nil {
 		t.Errorf("updateVideoSnippet() returned an error: %v", err)
 	}
 
-	var got, want map[string]interface{}
+	var got struct {
+		ID      string `json:"id"`
+		Snippet struct {
+			Title string `json:"title"`
+		} `json:"snippet"`
+	}
 	if err := json.Unmarshal(body, &got); err != nil {
 		t.Errorf("Failed to unmarshal response body: %v", err)
 	}
-	if err := json.Unmarshal([]byte(`{"id": "test_video_id", "snippet": {"title": "Test Video Title"}}`), &want); err != nil {
-		t.Errorf("Failed to unmarshal expected body: %v", err)
+	if got.ID != "test_video_id" {
+		t.Errorf("updateVideoSnippet() id = %q, want test_video_id", got.ID)
 	}
-
-	if !reflect.DeepEqual(got, want) {
-		t.Errorf("updateVideoSnippet() returned wrong body: got %v, want %v", got, want)
+	if !strings.Contains(got.Snippet.Title, "Test Video Title") {
+		t.Errorf("updateVideoSnippet() title = %q, want it to contain the video title", got.Snippet.Title)
 	}
 }
 
@@ -434,23 +482,10 @@ func TestAddVideoToPlaylist(t *testing.T) {
 		servers:           servers,
 	}
 
-	body, err := addVideoToPlaylist("test_video_id", "test_playlist_id", "test_access_token")
-
-	if err != nil {
+	client := youtube.NewClient(youtube.Credentials{ClientID: "id", ClientSecret: "secret", RefreshToken: "refresh"})
+	if err := addVideoToPlaylist(client, "test_video_id", "test_playlist_id"); err != nil {
 		t.Errorf("addVideoToPlaylist() returned an error: %v", err)
 	}
-
-	var got, want map[string]interface{}
-	if err := json.Unmarshal(body, &got); err != nil {
-		t.Errorf("Failed to unmarshal response body: %v", err)
-	}
-	if err := json.Unmarshal([]byte(`{"id": "test_item_id", "snippet": {"playlistId": "test_playlist_id", "resourceId": {"videoId": "test_video_id"}}}`), &want); err != nil {
-		t.Errorf("Failed to unmarshal expected body: %v", err)
-	}
-
-	if !reflect.DeepEqual(got, want) {
-		t.Errorf("addVideoToPlaylist() returned wrong body: got %v, want %v", got, want)
-	}
 }
 
 func TestVideoConverter(t *testing.T) {
@@ -461,7 +496,7 @@ func TestVideoConverter(t *testing.T) {
 		method           string
 		header           map[string]string
 		body             string
-		refreshTokenErr  bool
+		clientErr        bool
 		updateSnippetErr bool
 		addToPlaylistErr bool
 		postXErr         bool
@@ -473,7 +508,7 @@ func TestVideoConverter(t *testing.T) {
 			method:           "POST",
 			header:           map[string]string{"X-GABA-Header": "gabafortnite"},
 			body:             `{"url": "https://www.youtube.com/watch?v=test_video_id", "title": "Test Video", "published_at": "2023-01-01T00:00:00Z"}`,
-			refreshTokenErr:  false,
+			clientErr:        false,
 			updateSnippetErr: false,
 			addToPlaylistErr: false,
 			postXErr:         false,
@@ -494,35 +529,35 @@ func TestVideoConverter(t *testing.T) {
 			expectedStatus: http.StatusUnauthorized,
 		},
 		{
-			name:            "Refresh token error",
-			method:          "POST",
-			header:          map[string]string{"X-GABA-Header": "gabafortnite"},
-			body:            `{"url": "https://www.youtube.com/watch?v=test_video_id", "title": "Test Video", "published_at": "2023-01-01T00:00:00Z"}`,
-			refreshTokenErr: true,
-			expectedStatus:  http.StatusInternalServerError,
+			name:           "YouTube client error",
+			method:         "POST",
+			header:         map[string]string{"X-GABA-Header": "gabafortnite"},
+			body:           `{"url": "https://www.youtube.com/watch?v=test_video_id", "title": "Test Video", "published_at": "2023-01-01T00:00:00Z"}`,
+			clientErr:      true,
+			expectedStatus: http.StatusInternalServerError,
 		},
 		{
-			name:            "Invalid request body",
-			method:          "POST",
-			header:          map[string]string{"X-GABA-Header": "gabafortnite"},
-			body:            `invalid json`,
-			refreshTokenErr: false,
-			expectedStatus:  http.StatusBadRequest,
+			name:           "Invalid request body",
+			method:         "POST",
+			header:         map[string]string{"X-GABA-Header": "gabafortnite"},
+			body:           `invalid json`,
+			clientErr:      false,
+			expectedStatus: http.StatusBadRequest,
 		},
 		{
-			name:            "Invalid URL format",
-			method:          "POST",
-			header:          map[string]string{"X-GABA-Header": "gabafortnite"},
-			body:            `{"url": "https://www.youtube.com/invalid_url", "title": "Test Video", "published_at": "2023-01-01T00:00:00Z"}`,
-			refreshTokenErr: false,
-			expectedStatus:  http.StatusBadRequest,
+			name:           "Invalid URL format",
+			method:         "POST",
+			header:         map[string]string{"X-GABA-Header": "gabafortnite"},
+			body:           `{"url": "https://www.youtube.com/invalid_url", "title": "Test Video", "published_at": "2023-01-01T00:00:00Z"}`,
+			clientErr:      false,
+			expectedStatus: http.StatusBadRequest,
 		},
 		{
 			name:             "Update snippet error",
 			method:           "POST",
 			header:           map[string]string{"X-GABA-Header": "gabafortnite"},
 			body:             `{"url": "https://www.youtube.com/watch?v=test_video_id", "title": "Test Video", "published_at": "2023-01-01T00:00:00Z"}`,
-			refreshTokenErr:  false,
+			clientErr:        false,
 			updateSnippetErr: true,
 			expectedStatus:   http.StatusInternalServerError,
 		},
@@ -531,7 +566,7 @@ func TestVideoConverter(t *testing.T) {
 			method:           "POST",
 			header:           map[string]string{"X-GABA-Header": "gabafortnite"},
 			body:             `{"url": "https://www.youtube.com/watch?v=test_video_id", "title": "Test Video", "published_at": "2023-01-01T00:00:00Z"}`,
-			refreshTokenErr:  false,
+			clientErr:        false,
 			updateSnippetErr: false,
 			addToPlaylistErr: true,
 			expectedStatus:   http.StatusInternalServerError,
@@ -541,7 +576,7 @@ func TestVideoConverter(t *testing.T) {
 			method:           "POST",
 			header:           map[string]string{"X-GABA-Header": "gabafortnite"},
 			body:             `{"url": "https://www.youtube.com/watch?v=test_video_id", "title": "Test Video", "published_at": "2023-01-01T00:00:00Z"}`,
-			refreshTokenErr:  false,
+			clientErr:        false,
 			updateSnippetErr: false,
 			addToPlaylistErr: false,
 			postXErr:         true,
@@ -563,8 +598,8 @@ func TestVideoConverter(t *testing.T) {
 			rr := httptest.NewRecorder()
 
 			deps := Dependencies{
-				TokenRefresher: &MockTokenRefresher{
-					ShouldError: tc.refreshTokenErr,
+				ClientFactory: &MockClientFactory{
+					ShouldError: tc.clientErr,
 				},
 				VideoUpdater: &MockVideoUpdater{
 					ShouldError: tc.updateSnippetErr,
@@ -592,6 +627,15 @@ func TestOriginalVideoConverter(t *testing.T) {
 	servers, cleanup := setupTestServers(t)
 	defer cleanup()
 
+	setYouTubeCredentials(t)
+
+	// The X requests are sent to the mock server with OAuth 1.0a
+	t.Setenv("X_OAUTH2_CLIENT_ID", "")
+	t.Setenv("X_API_KEY", "test-key")
+	t.Setenv("X_API_SECRET_KEY", "test-key-secret")
+	t.Setenv("X_ACCESS_TOKEN", "test-token")
+	t.Setenv("X_ACCESS_TOKEN_SECRET", "test-token-secret")
+
 	// テスト用のトランスポートを設定
 	testTransport := &customTransport{
 		originalTransport: http.DefaultTransport,
@@ -615,7 +659,7 @@ func TestOriginalVideoConverter(t *testing.T) {
 
 	// テスト用の依存関係を設定
 	deps := Dependencies{
-		TokenRefresher:  &RealTokenRefresher{},
+		ClientFactory:   &RealClientFactory{},
 		VideoUpdater:    &RealVideoUpdater{},
 		PlaylistManager: &RealPlaylistManager{},
 		SocialPoster:    &RealSocialPoster{},
@@ -644,3 +688,42 @@ func TestOriginalVideoConverter(t *testing.T) {
 		t.Errorf("Response does not contain expected video ID")
 	}
 }
+
+func TestVideoSnippet(t *testing.T) {
+	t.Parallel()
+
+	got, err := videoSnippet(`Title with "quotes"`)
+	if err != nil {
+		t.Fatalf("videoSnippet() error = %v", err)
+	}
+	if got.Title != `Title with "quotes"` || got.CategoryID != "20" || len(got.Tags) == 0 {
+		t.Errorf("videoSnippet() = %+v", got)
+	}
+	if !strings.HasPrefix(got.Description, "【PR】") || !strings.Contains(got.Description, "\n\nGABAのフォートナイトのプレイログです。") ||
+		!strings.Contains(got.Description, "▼ マイク：Razer Seiren X") {
+		t.Errorf("description = %q", got.Description)
+	}
+}
+
+func TestComposeAnnouncement(t *testing.T) {
+	t.Parallel()
+
+	jst := time.FixedZone("JST", 9*60*60)
+	monday := time.Date(2025, 5, 5, 8, 0, 0, 0, jst)
+	url := "https://www.youtube.com/watch?v=test_video_id"
+
+	first, err := composeAnnouncement("Test Video", url, monday)
+	if err != nil {
+		t.Fatalf("composeAnnouncement() error = %v", err)
+	}
+	if !strings.Contains(first, url) {
+		t.Errorf("composeAnnouncement() = %q, want the video URL", first)
+	}
+	// A retry on the same day posts the same text, and the next day uses the next variant
+	if retry, _ := composeAnnouncement("Test Video", url, monday.Add(time.Hour)); retry != first {
+		t.Errorf("composeAnnouncement() on the same day = %q, want %q", retry, first)
+	}
+	if next, _ := composeAnnouncement("Test Video", url, monday.AddDate(0, 0, 1)); next == first {
+		t.Errorf("composeAnnouncement() on the next day = %q, want another variant", next)
+	}
+}
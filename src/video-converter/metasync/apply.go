@@ -0,0 +1,118 @@
+package metasync
+
+import (
+	"fmt"
+	"log/slog"
+	"time"
+
+	"thiroyoshi.com/video-converter/youtube"
+)
+
+// Quota costs of the YouTube Data API
+const (
+	listCost   = 1
+	updateCost = 50
+)
+
+// Number of videos per videos.list request
+const listBatchSize = 50
+
+// sleep is replaced in tests
+var sleep = time.Sleep
+
+// ApplyOptions は反映の進め方です。
+type ApplyOptions struct {
+	// QuotaUnits は今回の apply で使ってよいクォータのユニット数です。更新1件に50ユニットを使います
+	QuotaUnits int
+	// Interval は更新の間隔です
+	Interval time.Duration
+	// Save は変更を反映するたびに呼ばれ、途中で止まっても続きから再開できるように計画を保存します
+	Save func(*Plan) error
+}
+
+// ApplyResult は反映の結果です。
+type ApplyResult struct {
+	Applied int
+	// Skipped は削除された動画と、計画を作った後に手で変更された動画の件数です
+	Skipped int
+}
+
+// Apply は反映していない変更を順に反映します。更新の直前に現在のメタデータを取得し、計画を作ったときから
+// 変わっている動画は上書きせずに飛ばします。クォータを使い切った場合は ErrQuotaExhausted を返します。
+func Apply(api API, plan *Plan, opts ApplyOptions) (*ApplyResult, error) {
+	var pending []string
+	for _, change := range plan.Changes {
+		if !change.Done {
+			pending = append(pending, change.VideoID)
+		}
+	}
+	result := &ApplyResult{}
+	if len(pending) == 0 {
+		return result, nil
+	}
+
+	snippets, err := api.VideoSnippets(pending)
+	if err != nil {
+		return result, fmt.Errorf("failed to get current snippets: %w", err)
+	}
+	live := make(map[string]youtube.VideoSnippet, len(snippets))
+	for _, snippet := range snippets {
+		live[snippet.ID] = snippet
+	}
+	budget := opts.QuotaUnits - listCost*((len(pending)+listBatchSize-1)/listBatchSize)
+
+	for i := range plan.Changes {
+		change := &plan.Changes[i]
+		if change.Done {
+			continue
+		}
+		snippet, ok := live[change.VideoID]
+		current := metadataOf(snippet)
+		switch {
+		case !ok:
+			slog.Warn("video not found, skipping", "video", change.VideoID)
+			result.Skipped++
+			continue
+		case current.equal(change.After):
+			change.Done = true
+			if err := opts.Save(plan); err != nil {
+				return result, err
+			}
+			continue
+		case !current.equal(change.Before):
+			slog.Warn("video metadata changed since the plan was made, run plan again", "video", change.VideoID)
+			result.Skipped++
+			continue
+		}
+
+		if budget < updateCost {
+			return result, ErrQuotaExhausted
+		}
+		if result.Applied > 0 && opts.Interval > 0 {
+			sleep(opts.Interval)
+		}
+
+		snippet.Description = change.After.Description
+		snippet.Tags = change.After.Tags
+		snippet.CategoryID = change.After.CategoryID
+		if err := api.UpdateVideoSnippet(snippet); err != nil {
+			if youtube.IsQuotaExceeded(err) {
+				return result, fmt.Errorf("%w: %v", ErrQuotaExhausted, err)
+			}
+			return result, fmt.Errorf("failed to update %s: %w", change.VideoID, err)
+		}
+		budget -= updateCost
+		result.Applied++
+		change.Done = true
+		slog.Info("video metadata updated", "video", change.VideoID, "title", change.Title)
+		if err := opts.Save(plan); err != nil {
+			return result, err
+		}
+	}
+	return result, nil
+}
+
+// Text は反映の結果の表示です。
+func (r *ApplyResult) Text(plan *Plan) string {
+	return fmt.Sprintf("Apply: %d updated, %d skipped, %d remaining.\n", r.Applied, r.Skipped, plan.Pending()-r.Skipped)
+}
@@ -0,0 +1,192 @@
+package metasync
+
+import (
+	"errors"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"thiroyoshi.com/video-converter/youtube"
+)
+
+var base = time.Date(2025, 5, 1, 12, 0, 0, 0, time.UTC)
+
+const template = "GABAのプレイログです。\n▼ マイク：New Mic\n#Fortnite #ps5"
+
+func render(title string) Metadata {
+	return Metadata{Description: template, Tags: []string{"Fortnite", "ps5"}, CategoryID: "20"}
+}
+
+type fakeAPI struct {
+	snippets map[string]youtube.VideoSnippet
+	order    []string
+	updated  []string
+	updateFn func(youtube.VideoSnippet) error
+}
+
+func newFakeAPI() *fakeAPI {
+	api := &fakeAPI{snippets: map[string]youtube.VideoSnippet{}}
+	add := func(id, title, description string, days int) {
+		api.order = append(api.order, id)
+		api.snippets[id] = youtube.VideoSnippet{ID: id, Title: title, Description: description, Tags: []string{"Fortnite", "ps5"}, CategoryID: "20", PublishedAt: base.AddDate(0, 0, days)}
+	}
+	old := "GABAのプレイログです。\n▼ マイク：Old Mic\n#Fortnite #ps5"
+	add("v1", "No-Cut Fortnite C6S3", old, 0)
+	add("v2", "No-Cut Fortnite C6S3", old+"\n", 1)
+	// 行末の空白と改行コードの違いだけの動画は変更しない
+	add("v3", "No-Cut Fortnite C6S4", strings.ReplaceAll(template, "\n", " \r\n"), 2)
+	add("v4", "Vlog", old, 3)
+	add("v5", "No-Cut Fortnite C6S2", old, -30)
+	return api
+}
+
+func (f *fakeAPI) UploadsPlaylistID() (string, error) { return "uploads", nil }
+
+func (f *fakeAPI) PlaylistItems(playlistID string) ([]youtube.PlaylistVideo, error) {
+	var items []youtube.PlaylistVideo
+	for _, id := range f.order {
+		items = append(items, youtube.PlaylistVideo{VideoID: id})
+	}
+	return items, nil
+}
+
+func (f *fakeAPI) VideoSnippets(ids []string) ([]youtube.VideoSnippet, error) {
+	var snippets []youtube.VideoSnippet
+	for _, id := range ids {
+		if snippet, ok := f.snippets[id]; ok {
+			snippets = append(snippets, snippet)
+		}
+	}
+	return snippets, nil
+}
+
+func (f *fakeAPI) UpdateVideoSnippet(snippet youtube.VideoSnippet) error {
+	if f.updateFn != nil {
+		if err := f.updateFn(snippet); err != nil {
+			return err
+		}
+	}
+	f.updated = append(f.updated, snippet.ID)
+	f.snippets[snippet.ID] = snippet
+	return nil
+}
+
+func TestDiffLines(t *testing.T) {
+	t.Parallel()
+
+	got := diffLines("a\nb\nc\nd", "a\nB\nc\nd\ne")
+	want := []string{"- b", "+ B", "+ e"}
+	if strings.Join(got, "|") != strings.Join(want, "|") {
+		t.Errorf("diffLines() = %q, want %q", got, want)
+	}
+}
+
+func TestBuildPlan(t *testing.T) {
+	t.Parallel()
+
+	selector := Selector{From: base.AddDate(0, 0, -7), TitlePattern: regexp.MustCompile("Fortnite")}
+	plan, err := BuildPlan(newFakeAPI(), selector, render, base)
+	if err != nil {
+		t.Fatalf("BuildPlan() error = %v", err)
+	}
+	// v4 はタイトル、v5 は公開日が対象外で、v3 は差分なし
+	var ids []string
+	for _, change := range plan.Changes {
+		ids = append(ids, change.VideoID)
+	}
+	if strings.Join(ids, ",") != "v1,v2" || plan.Matched != 3 {
+		t.Fatalf("changes = %v, matched = %d", ids, plan.Matched)
+	}
+
+	text := plan.Text()
+	for _, want := range []string{"~ v1 No-Cut Fortnite C6S3\n    - ▼ マイク：Old Mic\n    + ▼ マイク：New Mic\n", "Plan: 2 to change, 1 unchanged, 0 already applied."} {
+		if !strings.Contains(text, want) {
+			t.Errorf("Text() does not contain %q\n%s", want, text)
+		}
+	}
+}
+
+func TestApply(t *testing.T) {
+	sleep = func(time.Duration) {}
+	defer func() { sleep = time.Sleep }()
+
+	tests := []struct {
+		name        string
+		quota       int
+		edit        func(api *fakeAPI)
+		updateFn    func(youtube.VideoSnippet) error
+		wantUpdated string
+		wantSkipped int
+		wantErr     error
+	}{
+		{name: "正常系: すべて反映", quota: 10000, wantUpdated: "v1,v2"},
+		{name: "正常系: クォータの範囲で止める", quota: 60, wantUpdated: "v1", wantErr: ErrQuotaExhausted},
+		{
+			name:  "正常系: 計画の後に変更された動画は飛ばす",
+			quota: 10000,
+			edit: func(api *fakeAPI) {
+				s := api.snippets["v1"]
+				s.Description = "手で直した説明"
+				api.snippets["v1"] = s
+			},
+			wantUpdated: "v2",
+			wantSkipped: 1,
+		},
+		{
+			name:  "異常系: APIのクォータ超過",
+			quota: 10000,
+			updateFn: func(s youtube.VideoSnippet) error {
+				if s.ID == "v2" {
+					return &youtube.APIError{Path: "/videos", StatusCode: 403, Reason: "quotaExceeded"}
+				}
+				return nil
+			},
+			wantUpdated: "v1",
+			wantErr:     ErrQuotaExhausted,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			api := newFakeAPI()
+			plan, err := BuildPlan(api, Selector{TitlePattern: regexp.MustCompile("C6S3")}, render, base)
+			if err != nil {
+				t.Fatalf("BuildPlan() error = %v", err)
+			}
+			path := filepath.Join(t.TempDir(), "plan.json")
+			save := func(p *Plan) error { return SavePlan(path, p) }
+			if err := save(plan); err != nil {
+				t.Fatal(err)
+			}
+			if tt.edit != nil {
+				tt.edit(api)
+			}
+			api.updateFn = tt.updateFn
+
+			result, err := Apply(api, plan, ApplyOptions{QuotaUnits: tt.quota, Interval: time.Second, Save: save})
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Apply() error = %v, want %v", err, tt.wantErr)
+			}
+			if got := strings.Join(api.updated, ","); got != tt.wantUpdated || result.Skipped != tt.wantSkipped {
+				t.Errorf("updated = %s, skipped = %d", got, result.Skipped)
+			}
+
+			// 保存した計画から再開すると反映済みの動画は更新しない
+			saved, err := LoadPlan(path)
+			if err != nil {
+				t.Fatalf("LoadPlan() error = %v", err)
+			}
+			api.updated, api.updateFn = nil, nil
+			if _, err := Apply(api, saved, ApplyOptions{QuotaUnits: 10000, Save: save}); err != nil {
+				t.Fatalf("Apply() resume error = %v", err)
+			}
+			for _, id := range strings.Split(tt.wantUpdated, ",") {
+				if slices.Contains(api.updated, id) {
+					t.Errorf("resumed apply updated %s again", id)
+				}
+			}
+		})
+	}
+}
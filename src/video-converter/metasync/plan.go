@@ -0,0 +1,201 @@
+// Package metasync は過去の動画の説明・タグ・カテゴリを現在のテンプレートに揃えます。
+// plan で差分を確認して計画をファイルに保存し、apply でクォータに合わせて少しずつ反映します。
+package metasync
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
+	"time"
+
+	"thiroyoshi.com/video-converter/youtube"
+)
+
+// Metadata はテンプレートで揃える動画のメタデータです。
+type Metadata struct {
+	Description string   `json:"description"`
+	Tags        []string `json:"tags"`
+	CategoryID  string   `json:"category_id"`
+}
+
+func (m Metadata) equal(other Metadata) bool {
+	return normalizeDescription(m.Description) == normalizeDescription(other.Description) &&
+		slices.Equal(m.Tags, other.Tags) && m.CategoryID == other.CategoryID
+}
+
+// normalizeDescription は比較のため改行コードと行末の空白、前後の空行の違いを無視します。
+func normalizeDescription(s string) string {
+	lines := strings.Split(strings.ReplaceAll(s, "\r\n", "\n"), "\n")
+	for i, line := range lines {
+		lines[i] = strings.TrimRight(line, " \t")
+	}
+	return strings.Trim(strings.Join(lines, "\n"), "\n")
+}
+
+func metadataOf(snippet youtube.VideoSnippet) Metadata {
+	return Metadata{Description: snippet.Description, Tags: snippet.Tags, CategoryID: snippet.CategoryID}
+}
+
+// Renderer は動画のタイトルから現在のテンプレートのメタデータを作ります。
+type Renderer func(title string) Metadata
+
+// Selector は対象にする動画の条件です。空の条件は判定しません。
+type Selector struct {
+	// PlaylistID が空の場合はアップロードしたすべての動画を対象にします
+	PlaylistID string
+	// From と To は公開日時の範囲です。To は含みません
+	From, To     time.Time
+	TitlePattern *regexp.Regexp
+}
+
+func (s Selector) matches(snippet youtube.VideoSnippet) bool {
+	if !s.From.IsZero() && snippet.PublishedAt.Before(s.From) {
+		return false
+	}
+	if !s.To.IsZero() && !snippet.PublishedAt.Before(s.To) {
+		return false
+	}
+	return s.TitlePattern == nil || s.TitlePattern.MatchString(snippet.Title)
+}
+
+// API はメタデータの一括更新に使うYouTube Data APIの操作です。
+type API interface {
+	UploadsPlaylistID() (string, error)
+	PlaylistItems(playlistID string) ([]youtube.PlaylistVideo, error)
+	VideoSnippets(ids []string) ([]youtube.VideoSnippet, error)
+	UpdateVideoSnippet(snippet youtube.VideoSnippet) error
+}
+
+// Change はひとつの動画の変更です。
+type Change struct {
+	VideoID string   `json:"video_id"`
+	Title   string   `json:"title"`
+	Before  Metadata `json:"before"`
+	After   Metadata `json:"after"`
+	// Done は反映済みであることを表し、apply を再実行したときに飛ばします
+	Done bool `json:"done"`
+}
+
+// Plan は変更の一覧です。apply の進み具合もこのファイルに記録します。
+type Plan struct {
+	CreatedAt time.Time `json:"created_at"`
+	Matched   int       `json:"matched"`
+	Changes   []Change  `json:"changes"`
+}
+
+// BuildPlan は条件に一致する動画の現在のメタデータとテンプレートを比べ、差分のある動画の変更を作ります。
+func BuildPlan(api API, selector Selector, render Renderer, now time.Time) (*Plan, error) {
+	playlistID := selector.PlaylistID
+	if playlistID == "" {
+		uploads, err := api.UploadsPlaylistID()
+		if err != nil {
+			return nil, err
+		}
+		playlistID = uploads
+	}
+	items, err := api.PlaylistItems(playlistID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list videos: %w", err)
+	}
+	ids := make([]string, 0, len(items))
+	for _, item := range items {
+		ids = append(ids, item.VideoID)
+	}
+	snippets, err := api.VideoSnippets(ids)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get video snippets: %w", err)
+	}
+
+	plan := &Plan{CreatedAt: now}
+	for _, snippet := range snippets {
+		if !selector.matches(snippet) {
+			continue
+		}
+		plan.Matched++
+		before, after := metadataOf(snippet), render(snippet.Title)
+		if before.equal(after) {
+			continue
+		}
+		plan.Changes = append(plan.Changes, Change{VideoID: snippet.ID, Title: snippet.Title, Before: before, After: after})
+	}
+	return plan, nil
+}
+
+// Pending は反映していない変更の件数を返します。
+func (p *Plan) Pending() int {
+	n := 0
+	for _, change := range p.Changes {
+		if !change.Done {
+			n++
+		}
+	}
+	return n
+}
+
+// Text はTerraformのplanのような差分の表示です。
+func (p *Plan) Text() string {
+	var b strings.Builder
+	for _, change := range p.Changes {
+		mark := "~"
+		if change.Done {
+			mark = "✓"
+		}
+		fmt.Fprintf(&b, "%s %s %s\n", mark, change.VideoID, change.Title)
+		if change.Done {
+			continue
+		}
+		for _, line := range diffLines(normalizeDescription(change.Before.Description), normalizeDescription(change.After.Description)) {
+			fmt.Fprintf(&b, "    %s\n", line)
+		}
+		if !slices.Equal(change.Before.Tags, change.After.Tags) {
+			fmt.Fprintf(&b, "    tags: %s → %s\n", strings.Join(change.Before.Tags, ", "), strings.Join(change.After.Tags, ", "))
+		}
+		if change.Before.CategoryID != change.After.CategoryID {
+			fmt.Fprintf(&b, "    category: %s → %s\n", change.Before.CategoryID, change.After.CategoryID)
+		}
+	}
+	fmt.Fprintf(&b, "Plan: %d to change, %d unchanged, %d already applied.\n", p.Pending(), p.Matched-len(p.Changes), len(p.Changes)-p.Pending())
+	return b.String()
+}
+
+// LoadPlan は計画をファイルから読み込みます。
+func LoadPlan(path string) (*Plan, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read plan: %w", err)
+	}
+	var plan Plan
+	if err := json.Unmarshal(data, &plan); err != nil {
+		return nil, fmt.Errorf("failed to parse plan: %w", err)
+	}
+	return &plan, nil
+}
+
+// SavePlan は計画を一時ファイルに書いてから置き換えます。
+func SavePlan(path string, plan *Plan) error {
+	data, err := json.MarshalIndent(plan, "", "  ")
+	if err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(path), ".plan-*")
+	if err != nil {
+		return err
+	}
+	defer func() { _ = os.Remove(tmp.Name()) }()
+	if _, err := tmp.Write(data); err != nil {
+		_ = tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
+
+// ErrQuotaExhausted は今日のクォータの範囲で反映できる分を終えたことを表します。翌日に apply を再実行すると続きから反映します。
+var ErrQuotaExhausted = errors.New("quota for today is used up, run apply again tomorrow to continue")
@@ -0,0 +1,64 @@
+package notifier
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+)
+
+// Channel は通知の送信先です。Slack、Discord、メール、汎用Webhookがこれを実装します。
+type Channel interface {
+	Name() string
+	Notify(msg Message) error
+}
+
+// postJSON はpayloadをJSONにしてPOSTし、2xx以外のステータスをエラーとして返します。
+func postJSON(client *http.Client, url string, payload any, header http.Header) error {
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return fmt.Errorf("failed to marshal payload: %w", err)
+	}
+	return postBody(client, url, body, header)
+}
+
+func postBody(client *http.Client, url string, body []byte, header http.Header) error {
+	req, err := http.NewRequest("POST", url, bytes.NewBuffer(body))
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	for key, values := range header {
+		for _, value := range values {
+			req.Header.Add(key, value)
+		}
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to send request: %w", err)
+	}
+	defer func() {
+		if cerr := resp.Body.Close(); cerr != nil {
+			slog.Error("failed to close response body", "error", cerr)
+		}
+	}()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("non-2xx status: %d", resp.StatusCode)
+	}
+	return nil
+}
+
+// stepLabel はステップの結果を絵文字を使わないテキストで表します。
+func stepLabel(status StepStatus) string {
+	switch status {
+	case StepOK:
+		return "OK"
+	case StepFailed:
+		return "NG"
+	default:
+		return "SKIP"
+	}
+}
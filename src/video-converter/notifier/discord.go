@@ -0,0 +1,120 @@
+package notifier
+
+import (
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strings"
+)
+
+// Embed colors used for Discord messages
+const (
+	discordColorSuccess = 0x2eb67d
+	discordColorFailure = 0xe01e5a
+	discordColorDigest  = 0x36c5f0
+)
+
+// Discord はDiscordのWebhookにEmbed形式のメッセージを送ります。
+type Discord struct {
+	webhookURL string
+	httpClient *http.Client
+}
+
+// NewDiscord はDiscordの通知先を作成します。
+func NewDiscord(webhookURL string) *Discord {
+	return &Discord{
+		webhookURL: webhookURL,
+		httpClient: &http.Client{},
+	}
+}
+
+// Name は通知先の名前を返します。
+func (d *Discord) Name() string {
+	return "discord"
+}
+
+// Notify はメッセージをDiscordのWebhookへ送ります。
+func (d *Discord) Notify(msg Message) error {
+	if err := postJSON(d.httpClient, d.webhookURL, buildDiscordPayload(msg), nil); err != nil {
+		slog.Error("failed to post message to discord", "error", err)
+		return fmt.Errorf("failed to post message to discord: %w", err)
+	}
+
+	slog.Info("successfully posted message to discord", "event", msg.Event)
+	return nil
+}
+
+type discordPayload struct {
+	Embeds []discordEmbed `json:"embeds"`
+}
+
+type discordEmbed struct {
+	Title       string         `json:"title"`
+	Description string         `json:"description,omitempty"`
+	URL         string         `json:"url,omitempty"`
+	Color       int            `json:"color"`
+	Thumbnail   *discordImage  `json:"thumbnail,omitempty"`
+	Fields      []discordField `json:"fields,omitempty"`
+	Footer      *discordFooter `json:"footer,omitempty"`
+}
+
+type discordImage struct {
+	URL string `json:"url"`
+}
+
+type discordField struct {
+	Name   string `json:"name"`
+	Value  string `json:"value"`
+	Inline bool   `json:"inline"`
+}
+
+type discordFooter struct {
+	Text string `json:"text"`
+}
+
+func buildDiscordPayload(msg Message) discordPayload {
+	lines := []string{}
+	if msg.Text != "" {
+		lines = append(lines, msg.Text)
+	}
+	for _, link := range msg.Links {
+		lines = append(lines, fmt.Sprintf("[%s](%s)", link.Label, link.URL))
+	}
+
+	embed := discordEmbed{
+		Title:       msg.Title,
+		Description: strings.Join(lines, "\n"),
+		Color:       discordColor(msg.Event),
+		Footer:      &discordFooter{Text: "event: " + string(msg.Event)},
+	}
+	if len(msg.Links) > 0 {
+		embed.URL = msg.Links[0].URL
+	}
+	if msg.ThumbnailURL != "" {
+		embed.Thumbnail = &discordImage{URL: msg.ThumbnailURL}
+	}
+
+	for _, step := range msg.Steps {
+		value := stepLabel(step.Status)
+		if step.Detail != "" {
+			value += " " + step.Detail
+		}
+		embed.Fields = append(embed.Fields, discordField{Name: step.Name, Value: value, Inline: true})
+	}
+	if msg.Err != nil {
+		embed.Fields = append(embed.Fields, discordField{Name: "エラー内容", Value: fmt.Sprintf("```%v```", msg.Err)})
+	}
+
+	return discordPayload{Embeds: []discordEmbed{embed}}
+}
+
+func discordColor(event Event) int {
+	switch event {
+	case EventFailure:
+		return discordColorFailure
+	case EventDigest:
+		return discordColorDigest
+	default:
+		return discordColorSuccess
+	}
+}
@@ -0,0 +1,99 @@
+package notifier
+
+import (
+	"bytes"
+	"encoding/base64"
+	"fmt"
+	"log/slog"
+	"mime"
+	"net"
+	"net/smtp"
+	"strings"
+	"time"
+)
+
+// EmailConfig はSMTPでメールを送るための設定です。
+type EmailConfig struct {
+	Host     string
+	Port     string
+	Username string
+	Password string
+	From     string
+	To       []string
+}
+
+// Email はSMTPサーバー経由でメールを送ります。
+type Email struct {
+	config EmailConfig
+}
+
+// NewEmail はメールの通知先を作成します。
+func NewEmail(config EmailConfig) *Email {
+	return &Email{config: config}
+}
+
+// Name は通知先の名前を返します。
+func (e *Email) Name() string {
+	return "email"
+}
+
+// Notify はメッセージをプレーンテキストのメールとして送ります。
+// Usernameが空の場合は認証せずに送信します。
+func (e *Email) Notify(msg Message) error {
+	var auth smtp.Auth
+	if e.config.Username != "" {
+		auth = smtp.PlainAuth("", e.config.Username, e.config.Password, e.config.Host)
+	}
+
+	addr := net.JoinHostPort(e.config.Host, e.config.Port)
+	if err := smtp.SendMail(addr, auth, e.config.From, e.config.To, e.buildMail(msg)); err != nil {
+		slog.Error("failed to send email", "error", err)
+		return fmt.Errorf("failed to send email: %w", err)
+	}
+
+	slog.Info("successfully sent email", "event", msg.Event, "to", e.config.To)
+	return nil
+}
+
+func (e *Email) buildMail(msg Message) []byte {
+	var body strings.Builder
+	if msg.Text != "" {
+		body.WriteString(msg.Text + "\n\n")
+	}
+	for _, link := range msg.Links {
+		fmt.Fprintf(&body, "%s: %s\n", link.Label, link.URL)
+	}
+	if len(msg.Steps) > 0 {
+		body.WriteString("\n")
+		for _, step := range msg.Steps {
+			fmt.Fprintf(&body, "[%s] %s", stepLabel(step.Status), step.Name)
+			if step.Detail != "" {
+				fmt.Fprintf(&body, " (%s)", step.Detail)
+			}
+			body.WriteString("\n")
+		}
+	}
+	if msg.Err != nil {
+		fmt.Fprintf(&body, "\nエラー内容: %v\n", msg.Err)
+	}
+
+	var mail bytes.Buffer
+	fmt.Fprintf(&mail, "From: %s\r\n", e.config.From)
+	fmt.Fprintf(&mail, "To: %s\r\n", strings.Join(e.config.To, ", "))
+	fmt.Fprintf(&mail, "Subject: %s\r\n", mime.BEncoding.Encode("UTF-8", fmt.Sprintf("[%s] %s", msg.Event, msg.Title)))
+	fmt.Fprintf(&mail, "Date: %s\r\n", time.Now().Format(time.RFC1123Z))
+	mail.WriteString("MIME-Version: 1.0\r\n")
+	mail.WriteString("Content-Type: text/plain; charset=UTF-8\r\n")
+	mail.WriteString("Content-Transfer-Encoding: base64\r\n")
+	mail.WriteString("\r\n")
+
+	// Wrap base64 lines at 76 characters as required by RFC 2045
+	encoded := base64.StdEncoding.EncodeToString([]byte(body.String()))
+	for len(encoded) > 76 {
+		mail.WriteString(encoded[:76] + "\r\n")
+		encoded = encoded[76:]
+	}
+	mail.WriteString(encoded + "\r\n")
+
+	return mail.Bytes()
+}
@@ -0,0 +1,124 @@
+package notifier
+
+import (
+	"bufio"
+	"encoding/base64"
+	"errors"
+	"net"
+	"strings"
+	"testing"
+)
+
+// fakeSMTPServer は受け取ったメールを記録するだけの最小限のSMTPサーバーです。
+type fakeSMTPServer struct {
+	listener net.Listener
+	mails    chan string
+}
+
+func newFakeSMTPServer(t *testing.T) *fakeSMTPServer {
+	t.Helper()
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("failed to listen: %v", err)
+	}
+	s := &fakeSMTPServer{listener: listener, mails: make(chan string, 1)}
+	go s.serve()
+	return s
+}
+
+func (s *fakeSMTPServer) serve() {
+	conn, err := s.listener.Accept()
+	if err != nil {
+		return
+	}
+	defer func() { _ = conn.Close() }()
+
+	reader := bufio.NewReader(conn)
+	write := func(line string) { _, _ = conn.Write([]byte(line + "\r\n")) }
+
+	write("220 localhost ESMTP fake")
+	var data strings.Builder
+	inData := false
+	for {
+		line, err := reader.ReadString('\n')
+		if err != nil {
+			return
+		}
+		if inData {
+			if line == ".\r\n" {
+				inData = false
+				s.mails <- data.String()
+				write("250 OK")
+				continue
+			}
+			data.WriteString(line)
+			continue
+		}
+
+		switch cmd := strings.ToUpper(strings.TrimSpace(line)); {
+		case strings.HasPrefix(cmd, "EHLO"), strings.HasPrefix(cmd, "HELO"):
+			write("250 localhost")
+		case strings.HasPrefix(cmd, "DATA"):
+			inData = true
+			write("354 End data with <CR><LF>.<CR><LF>")
+		case strings.HasPrefix(cmd, "QUIT"):
+			write("221 Bye")
+			return
+		default:
+			write("250 OK")
+		}
+	}
+}
+
+func TestEmailNotify(t *testing.T) {
+	t.Parallel()
+
+	server := newFakeSMTPServer(t)
+	defer func() { _ = server.listener.Close() }()
+
+	host, port, err := net.SplitHostPort(server.listener.Addr().String())
+	if err != nil {
+		t.Fatalf("failed to split address: %v", err)
+	}
+
+	email := NewEmail(EmailConfig{
+		Host: host,
+		Port: port,
+		From: "bot@example.com",
+		To:   []string{"gaba@example.com"},
+	})
+
+	err = email.Notify(Message{
+		Event: EventFailure,
+		Title: "ブログ更新に失敗しました",
+		Links: []Link{{Label: "記事", URL: "https://example.com/entry"}},
+		Steps: []Step{{Name: "RSSフィードの取得", Status: StepFailed}},
+		Err:   errors.New("timeout"),
+	})
+	if err != nil {
+		t.Fatalf("Notify() error = %v", err)
+	}
+
+	mail := <-server.mails
+	if !strings.Contains(mail, "To: gaba@example.com") {
+		t.Errorf("mail does not contain To header: %q", mail)
+	}
+	if !strings.Contains(mail, "Subject: =?UTF-8?b?") {
+		t.Errorf("mail subject is not MIME encoded: %q", mail)
+	}
+
+	parts := strings.SplitN(mail, "\r\n\r\n", 2)
+	if len(parts) != 2 {
+		t.Fatalf("mail has no body: %q", mail)
+	}
+	body, err := base64.StdEncoding.DecodeString(strings.ReplaceAll(parts[1], "\r\n", ""))
+	if err != nil {
+		t.Fatalf("failed to decode body: %v", err)
+	}
+	for _, want := range []string{"記事: https://example.com/entry", "[NG] RSSフィードの取得", "エラー内容: timeout"} {
+		if !strings.Contains(string(body), want) {
+			t.Errorf("mail body = %q, want to contain %q", body, want)
+		}
+	}
+}
@@ -0,0 +1,52 @@
+// Package notifier は、処理結果をSlackなどの通知先へ送るためのパッケージです。
+package notifier
+
+// Event は通知の種類を表します。通知先は種類ごとに設定できます。
+type Event string
+
+const (
+	// EventSuccess は処理が成功したときの通知です。
+	EventSuccess Event = "success"
+	// EventFailure は処理が失敗したときの通知です。
+	EventFailure Event = "failure"
+	// EventDigest は定期的なまとめの通知です。
+	EventDigest Event = "digest"
+	// EventComment はYouTubeに新しいコメントが付いたときの通知です。
+	EventComment Event = "comment"
+)
+
+// events is the list of events that can be configured per event.
+var events = []Event{EventSuccess, EventFailure, EventDigest, EventComment}
+
+// StepStatus は処理ステップの結果を表します。
+type StepStatus string
+
+const (
+	StepOK      StepStatus = "ok"
+	StepFailed  StepStatus = "failed"
+	StepSkipped StepStatus = "skipped"
+)
+
+// Step は通知に載せる処理ステップとその結果です。
+type Step struct {
+	Name   string
+	Status StepStatus
+	Detail string
+}
+
+// Link は通知に載せるリンクです。
+type Link struct {
+	Label string
+	URL   string
+}
+
+// Message は通知先に依存しない通知内容です。
+type Message struct {
+	Event        Event
+	Title        string
+	Text         string
+	ThumbnailURL string
+	Links        []Link
+	Steps        []Step
+	Err          error
+}
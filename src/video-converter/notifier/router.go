@@ -0,0 +1,108 @@
+package notifier
+
+import (
+	"errors"
+	"log/slog"
+	"os"
+	"strings"
+)
+
+// Router はイベントの種類ごとに設定された通知先へメッセージを振り分けます。
+type Router struct {
+	channels map[string]Channel
+	names    []string
+	routes   map[Event][]string
+}
+
+// NewRouter は通知先とルーティングを指定してRouterを作成します。
+// routesにイベントが含まれない場合、そのイベントはすべての通知先へ送られます。
+func NewRouter(routes map[Event][]string, channels ...Channel) *Router {
+	r := &Router{
+		channels: map[string]Channel{},
+		routes:   routes,
+	}
+	for _, channel := range channels {
+		r.channels[channel.Name()] = channel
+		r.names = append(r.names, channel.Name())
+	}
+	return r
+}
+
+// FromEnv は環境変数から通知先とルーティングを読み込みます。
+//
+// 通知先は次の環境変数が設定されている場合のみ有効になります。
+//   - Slack: SLACK_WEBHOOK_URL または SLACK_WEBHOOK_URL_SUCCESS / _FAILURE / _DIGEST / _COMMENT
+//   - Discord: DISCORD_WEBHOOK_URL
+//   - メール: SMTP_HOST, SMTP_PORT, SMTP_FROM, SMTP_TO（カンマ区切り）, 任意でSMTP_USERNAME, SMTP_PASSWORD
+//   - 汎用Webhook: NOTIFY_WEBHOOK_URL, NOTIFY_WEBHOOK_SECRET
+//
+// NOTIFY_ROUTE_SUCCESS / _FAILURE / _DIGEST / _COMMENT に通知先の名前をカンマ区切りで指定すると、
+// イベントごとの送信先を絞り込めます（例: NOTIFY_ROUTE_FAILURE=slack,email）。
+func FromEnv() *Router {
+	var channels []Channel
+	if slack := SlackConfigFromEnv(); len(slack.Webhooks) > 0 {
+		channels = append(channels, NewSlack(slack))
+	} else {
+		slog.Warn("slack webhook URL not configured, slack notifications are disabled")
+	}
+
+	if url := os.Getenv("DISCORD_WEBHOOK_URL"); url != "" {
+		channels = append(channels, NewDiscord(url))
+	}
+
+	if host := os.Getenv("SMTP_HOST"); host != "" {
+		channels = append(channels, NewEmail(EmailConfig{
+			Host:     host,
+			Port:     os.Getenv("SMTP_PORT"),
+			Username: os.Getenv("SMTP_USERNAME"),
+			Password: os.Getenv("SMTP_PASSWORD"),
+			From:     os.Getenv("SMTP_FROM"),
+			To:       splitList(os.Getenv("SMTP_TO")),
+		}))
+	}
+
+	if url := os.Getenv("NOTIFY_WEBHOOK_URL"); url != "" {
+		channels = append(channels, NewWebhook(url, os.Getenv("NOTIFY_WEBHOOK_SECRET")))
+	}
+
+	routes := map[Event][]string{}
+	for _, event := range events {
+		if names := splitList(os.Getenv("NOTIFY_ROUTE_" + strings.ToUpper(string(event)))); len(names) > 0 {
+			routes[event] = names
+		}
+	}
+
+	return NewRouter(routes, channels...)
+}
+
+// Notify はイベントに対応するすべての通知先へメッセージを送ります。
+// 一部の通知先で失敗しても残りの通知先には送信し、失敗をまとめて返します。
+func (r *Router) Notify(msg Message) error {
+	names, ok := r.routes[msg.Event]
+	if !ok {
+		names = r.names
+	}
+
+	var errs []error
+	for _, name := range names {
+		channel, ok := r.channels[name]
+		if !ok {
+			slog.Warn("notification channel not configured, skipping", "channel", name, "event", msg.Event)
+			continue
+		}
+		if err := channel.Notify(msg); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+func splitList(value string) []string {
+	var list []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			list = append(list, item)
+		}
+	}
+	return list
+}
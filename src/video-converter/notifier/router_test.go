@@ -0,0 +1,48 @@
+package notifier
+
+import (
+	"errors"
+	"testing"
+)
+
+type recordChannel struct {
+	name string
+	err  error
+	got  []Event
+}
+
+func (c *recordChannel) Name() string { return c.name }
+
+func (c *recordChannel) Notify(msg Message) error {
+	c.got = append(c.got, msg.Event)
+	return c.err
+}
+
+func TestRouterNotify(t *testing.T) {
+	t.Parallel()
+
+	slack := &recordChannel{name: "slack"}
+	discord := &recordChannel{name: "discord", err: errors.New("discord down")}
+	email := &recordChannel{name: "email"}
+
+	router := NewRouter(map[Event][]string{
+		EventFailure: {"slack", "email", "unknown"},
+	}, slack, discord, email)
+
+	if err := router.Notify(Message{Event: EventFailure}); err != nil {
+		t.Errorf("Notify(failure) error = %v, want nil", err)
+	}
+	if err := router.Notify(Message{Event: EventSuccess}); err == nil {
+		t.Errorf("Notify(success) error = nil, want discord error")
+	}
+
+	if len(slack.got) != 2 {
+		t.Errorf("slack received %v, want failure and success", slack.got)
+	}
+	if len(discord.got) != 1 || discord.got[0] != EventSuccess {
+		t.Errorf("discord received %v, want only success", discord.got)
+	}
+	if len(email.got) != 2 {
+		t.Errorf("email received %v, want failure and success", email.got)
+	}
+}
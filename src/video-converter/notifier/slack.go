@@ -0,0 +1,159 @@
+package notifier
+
+import (
+	"fmt"
+	"log/slog"
+	"net/http"
+	"os"
+	"strings"
+)
+
+// SlackConfig はイベントの種類ごとのIncoming Webhook URLです。
+type SlackConfig struct {
+	Webhooks map[Event]string
+}
+
+// SlackConfigFromEnv は環境変数からSlackの設定を読み込みます。
+// SLACK_WEBHOOK_URL を全イベント共通の送信先とし、
+// SLACK_WEBHOOK_URL_SUCCESS / _FAILURE / _DIGEST / _COMMENT があればイベントごとに上書きします。
+// どちらも設定されていないイベントは送信先に含めません。
+func SlackConfigFromEnv() SlackConfig {
+	base := os.Getenv("SLACK_WEBHOOK_URL")
+
+	config := SlackConfig{Webhooks: map[Event]string{}}
+	for _, event := range events {
+		url := os.Getenv("SLACK_WEBHOOK_URL_" + strings.ToUpper(string(event)))
+		if url == "" {
+			url = base
+		}
+		if url != "" {
+			config.Webhooks[event] = url
+		}
+	}
+	return config
+}
+
+// Slack はIncoming WebhookにBlock Kit形式のメッセージを送ります。
+type Slack struct {
+	webhooks   map[Event]string
+	httpClient *http.Client
+}
+
+// NewSlack はSlackの通知先を作成します。
+func NewSlack(config SlackConfig) *Slack {
+	return &Slack{
+		webhooks:   config.Webhooks,
+		httpClient: &http.Client{},
+	}
+}
+
+// Name は通知先の名前を返します。
+func (s *Slack) Name() string {
+	return "slack"
+}
+
+// Notify はメッセージをイベントに対応するWebhookへ送ります。
+// 送信先が設定されていないイベントは送信せずに終了します。
+func (s *Slack) Notify(msg Message) error {
+	url := s.webhooks[msg.Event]
+	if url == "" {
+		slog.Warn("slack webhook URL not configured, skipping slack notification", "event", msg.Event)
+		return nil
+	}
+
+	if err := postJSON(s.httpClient, url, buildSlackPayload(msg), nil); err != nil {
+		slog.Error("failed to post message to slack", "error", err)
+		return fmt.Errorf("failed to post message to slack: %w", err)
+	}
+
+	slog.Info("successfully posted message to slack", "event", msg.Event)
+	return nil
+}
+
+type slackPayload struct {
+	Text   string       `json:"text"`
+	Blocks []slackBlock `json:"blocks"`
+}
+
+type slackBlock struct {
+	Type      string       `json:"type"`
+	Text      *slackText   `json:"text,omitempty"`
+	Fields    []slackText  `json:"fields,omitempty"`
+	Elements  []slackText  `json:"elements,omitempty"`
+	Accessory *slackAccess `json:"accessory,omitempty"`
+}
+
+type slackText struct {
+	Type string `json:"type"`
+	Text string `json:"text"`
+}
+
+type slackAccess struct {
+	Type     string `json:"type"`
+	ImageURL string `json:"image_url"`
+	AltText  string `json:"alt_text"`
+}
+
+func buildSlackPayload(msg Message) slackPayload {
+	// text is used by Slack as the fallback for notifications
+	fallback := msg.Title
+	if msg.Text != "" {
+		fallback += "\n" + msg.Text
+	}
+
+	blocks := []slackBlock{
+		{Type: "header", Text: &slackText{Type: "plain_text", Text: msg.Title}},
+	}
+
+	if msg.Text != "" || len(msg.Links) > 0 {
+		lines := []string{}
+		if msg.Text != "" {
+			lines = append(lines, msg.Text)
+		}
+		for _, link := range msg.Links {
+			lines = append(lines, fmt.Sprintf("<%s|%s>", link.URL, link.Label))
+		}
+		section := slackBlock{Type: "section", Text: &slackText{Type: "mrkdwn", Text: strings.Join(lines, "\n")}}
+		if msg.ThumbnailURL != "" {
+			section.Accessory = &slackAccess{Type: "image", ImageURL: msg.ThumbnailURL, AltText: msg.Title}
+		}
+		blocks = append(blocks, section)
+	}
+
+	if len(msg.Steps) > 0 {
+		var fields []slackText
+		for _, step := range msg.Steps {
+			text := fmt.Sprintf("%s *%s*", stepEmoji(step.Status), step.Name)
+			if step.Detail != "" {
+				text += "\n" + step.Detail
+			}
+			fields = append(fields, slackText{Type: "mrkdwn", Text: text})
+		}
+		blocks = append(blocks, slackBlock{Type: "section", Fields: fields})
+	}
+
+	if msg.Err != nil {
+		blocks = append(blocks, slackBlock{
+			Type: "section",
+			Text: &slackText{Type: "mrkdwn", Text: fmt.Sprintf("*エラー内容*\n```%v```", msg.Err)},
+		})
+	}
+
+	blocks = append(blocks, slackBlock{
+		Type:     "context",
+		Elements: []slackText{{Type: "mrkdwn", Text: "event: " + string(msg.Event)}},
+	})
+
+	return slackPayload{Text: fallback, Blocks: blocks}
+}
+
+func stepEmoji(status StepStatus) string {
+	switch status {
+	case StepOK:
+		return ":white_check_mark:"
+	case StepFailed:
+		return ":x:"
+	default:
+		return ":fast_forward:"
+	}
+}
@@ -0,0 +1,181 @@
+package notifier
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestSlackNotify(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name       string
+		event      Event
+		status     int
+		wantPath   string
+		wantErr    bool
+		wantNoSend bool
+	}{
+		{
+			name:     "成功通知は成功用のWebhookへ送られる",
+			event:    EventSuccess,
+			status:   http.StatusOK,
+			wantPath: "/success",
+		},
+		{
+			name:     "失敗通知は失敗用のWebhookへ送られる",
+			event:    EventFailure,
+			status:   http.StatusOK,
+			wantPath: "/failure",
+		},
+		{
+			name:       "送信先がないイベントは送信しない",
+			event:      EventDigest,
+			status:     http.StatusOK,
+			wantNoSend: true,
+		},
+		{
+			name:     "2xx以外はエラーになる",
+			event:    EventSuccess,
+			status:   http.StatusInternalServerError,
+			wantPath: "/success",
+			wantErr:  true,
+		},
+	}
+
+	for _, tc := range tests {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			var gotPath string
+			var gotBody []byte
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				gotPath = r.URL.Path
+				gotBody, _ = io.ReadAll(r.Body)
+				w.WriteHeader(tc.status)
+			}))
+			defer server.Close()
+
+			slack := NewSlack(SlackConfig{Webhooks: map[Event]string{
+				EventSuccess: server.URL + "/success",
+				EventFailure: server.URL + "/failure",
+			}})
+
+			err := slack.Notify(Message{Event: tc.event, Title: "title", Text: "text"})
+			if (err != nil) != tc.wantErr {
+				t.Fatalf("Notify() error = %v, wantErr %v", err, tc.wantErr)
+			}
+
+			if tc.wantNoSend {
+				if gotPath != "" {
+					t.Errorf("Notify() sent request to %s, want no request", gotPath)
+				}
+				return
+			}
+			if gotPath != tc.wantPath {
+				t.Errorf("Notify() sent request to %s, want %s", gotPath, tc.wantPath)
+			}
+
+			var payload slackPayload
+			if err := json.Unmarshal(gotBody, &payload); err != nil {
+				t.Fatalf("failed to parse payload: %v", err)
+			}
+			if len(payload.Blocks) == 0 {
+				t.Errorf("payload has no blocks")
+			}
+		})
+	}
+}
+
+func TestBuildSlackPayload(t *testing.T) {
+	t.Parallel()
+
+	msg := Message{
+		Event:        EventFailure,
+		Title:        "動画の設定に失敗しました",
+		Text:         "No-Cut Fortnite",
+		ThumbnailURL: "https://i.ytimg.com/vi/abc/hqdefault.jpg",
+		Links:        []Link{{Label: "YouTube", URL: "https://www.youtube.com/watch?v=abc"}},
+		Steps: []Step{
+			{Name: "snippet", Status: StepOK},
+			{Name: "playlist", Status: StepFailed, Detail: "status 500"},
+		},
+		Err: errors.New("boom"),
+	}
+
+	payload := buildSlackPayload(msg)
+
+	if payload.Blocks[0].Type != "header" || payload.Blocks[0].Text.Text != msg.Title {
+		t.Errorf("first block = %+v, want header with title", payload.Blocks[0])
+	}
+
+	section := payload.Blocks[1]
+	if section.Accessory == nil || section.Accessory.ImageURL != msg.ThumbnailURL {
+		t.Errorf("section accessory = %+v, want thumbnail image", section.Accessory)
+	}
+	if !strings.Contains(section.Text.Text, "<https://www.youtube.com/watch?v=abc|YouTube>") {
+		t.Errorf("section text = %q, want link", section.Text.Text)
+	}
+
+	steps := payload.Blocks[2]
+	if len(steps.Fields) != 2 || !strings.Contains(steps.Fields[1].Text, ":x:") {
+		t.Errorf("step fields = %+v, want failed step with :x:", steps.Fields)
+	}
+
+	errBlock := payload.Blocks[3]
+	if !strings.Contains(errBlock.Text.Text, "boom") {
+		t.Errorf("error block = %q, want error detail", errBlock.Text.Text)
+	}
+}
+
+func TestSlackConfigFromEnv(t *testing.T) {
+	tests := []struct {
+		name    string
+		base    string
+		failure string
+		want    map[Event]string
+	}{
+		{name: "設定なしでは送信先がない", want: map[Event]string{}},
+		{
+			name:    "失敗通知のみ設定",
+			failure: "https://hooks.example.com/failure",
+			want:    map[Event]string{EventFailure: "https://hooks.example.com/failure"},
+		},
+		{
+			name:    "共通の設定をイベントごとに上書き",
+			base:    "https://hooks.example.com/base",
+			failure: "https://hooks.example.com/failure",
+			want: map[Event]string{
+				EventSuccess: "https://hooks.example.com/base",
+				EventFailure: "https://hooks.example.com/failure",
+				EventDigest:  "https://hooks.example.com/base",
+				EventComment: "https://hooks.example.com/base",
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("SLACK_WEBHOOK_URL", tt.base)
+			t.Setenv("SLACK_WEBHOOK_URL_FAILURE", tt.failure)
+			for _, event := range []Event{EventSuccess, EventDigest, EventComment} {
+				t.Setenv("SLACK_WEBHOOK_URL_"+strings.ToUpper(string(event)), "")
+			}
+
+			got := SlackConfigFromEnv().Webhooks
+			if len(got) != len(tt.want) {
+				t.Fatalf("Webhooks = %v, want %v", got, tt.want)
+			}
+			for event, url := range tt.want {
+				if got[event] != url {
+					t.Errorf("Webhooks[%s] = %q, want %q", event, got[event], url)
+				}
+			}
+		})
+	}
+}
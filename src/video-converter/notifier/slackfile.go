@@ -0,0 +1,125 @@
+package notifier
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+)
+
+const slackAPIEndpoint = "https://slack.com/api"
+
+// SlackFileUploader はSlackのボットトークンでチャンネルにファイルを投稿します。
+// Incoming Webhookでは画像を添付できないため、グラフなどの画像はこちらで送ります。
+type SlackFileUploader struct {
+	token       string
+	channel     string
+	apiEndpoint string
+	httpClient  *http.Client
+}
+
+// NewSlackFileUploader はファイルの投稿先を作成します。channelにはチャンネルIDを指定します。
+func NewSlackFileUploader(token, channel string) *SlackFileUploader {
+	return &SlackFileUploader{
+		token:       token,
+		channel:     channel,
+		apiEndpoint: slackAPIEndpoint,
+		httpClient:  &http.Client{},
+	}
+}
+
+// SlackFileUploaderFromEnv は SLACK_BOT_TOKEN と SLACK_CHANNEL_ID からファイルの投稿先を作成します。
+// どちらかが設定されていない場合はfalseを返します。
+func SlackFileUploaderFromEnv() (*SlackFileUploader, bool) {
+	token, channel := os.Getenv("SLACK_BOT_TOKEN"), os.Getenv("SLACK_CHANNEL_ID")
+	if token == "" || channel == "" {
+		return nil, false
+	}
+	return NewSlackFileUploader(token, channel), true
+}
+
+// Upload はファイルをアップロードしてチャンネルに共有します。commentはファイルと一緒に投稿する本文です。
+// files.getUploadURLExternal でアップロード先を取得し、送信後に files.completeUploadExternal で共有します。
+func (u *SlackFileUploader) Upload(filename, title, comment string, data []byte) error {
+	var upload struct {
+		UploadURL string `json:"upload_url"`
+		FileID    string `json:"file_id"`
+	}
+	form := url.Values{"filename": {filename}, "length": {strconv.Itoa(len(data))}}
+	if err := u.call("files.getUploadURLExternal", "application/x-www-form-urlencoded", strings.NewReader(form.Encode()), &upload); err != nil {
+		return err
+	}
+
+	resp, err := u.httpClient.Post(upload.UploadURL, "application/octet-stream", bytes.NewReader(data))
+	if err != nil {
+		return fmt.Errorf("failed to upload file to slack: %w", err)
+	}
+	if cerr := resp.Body.Close(); cerr != nil {
+		slog.Error("failed to close response body", "error", cerr)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("failed to upload file to slack: status %d", resp.StatusCode)
+	}
+
+	complete := map[string]any{
+		"files":           []map[string]string{{"id": upload.FileID, "title": title}},
+		"channel_id":      u.channel,
+		"initial_comment": comment,
+	}
+	body, err := json.Marshal(complete)
+	if err != nil {
+		return err
+	}
+	if err := u.call("files.completeUploadExternal", "application/json; charset=utf-8", bytes.NewReader(body), nil); err != nil {
+		return err
+	}
+
+	slog.Info("successfully uploaded file to slack", "filename", filename)
+	return nil
+}
+
+// call はSlack Web APIを呼び出します。Web APIはエラーでも200を返すため、レスポンスの ok で判定します。
+func (u *SlackFileUploader) call(method, contentType string, body io.Reader, out any) error {
+	req, err := http.NewRequest("POST", u.apiEndpoint+"/"+method, body)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", contentType)
+	req.Header.Set("Authorization", "Bearer "+u.token)
+
+	resp, err := u.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to call slack %s: %w", method, err)
+	}
+	defer func() {
+		if cerr := resp.Body.Close(); cerr != nil {
+			slog.Error("failed to close response body", "error", cerr)
+		}
+	}()
+
+	respBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+	var result struct {
+		OK    bool   `json:"ok"`
+		Error string `json:"error"`
+	}
+	if err := json.Unmarshal(respBody, &result); err != nil {
+		return fmt.Errorf("failed to parse slack %s response: %w", method, err)
+	}
+	if !result.OK {
+		return fmt.Errorf("slack %s failed: %w", method, errors.New(result.Error))
+	}
+	if out == nil {
+		return nil
+	}
+	return json.Unmarshal(respBody, out)
+}
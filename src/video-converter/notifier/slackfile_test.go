@@ -0,0 +1,85 @@
+package notifier
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestSlackFileUploader(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		getURL  string
+		wantErr bool
+	}{
+		{name: "正常系: アップロードして共有する", getURL: `{"ok":true,"upload_url":"UPLOAD","file_id":"F1"}`},
+		{name: "異常系: トークンが無効", getURL: `{"ok":false,"error":"invalid_auth"}`, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			var uploaded string
+			var completed map[string]any
+			var server *httptest.Server
+			server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				switch r.URL.Path {
+				case "/api/files.getUploadURLExternal":
+					if r.Header.Get("Authorization") != "Bearer xoxb-test" {
+						t.Errorf("Authorization = %q", r.Header.Get("Authorization"))
+					}
+					if err := r.ParseForm(); err != nil || r.PostForm.Get("filename") != "chart.png" || r.PostForm.Get("length") != "3" {
+						t.Errorf("form = %v, err = %v", r.PostForm, err)
+					}
+					_, _ = w.Write([]byte(replaceUploadURL(tt.getURL, server.URL+"/upload")))
+				case "/upload":
+					data, _ := io.ReadAll(r.Body)
+					uploaded = string(data)
+				case "/api/files.completeUploadExternal":
+					if err := json.NewDecoder(r.Body).Decode(&completed); err != nil {
+						t.Errorf("failed to decode: %v", err)
+					}
+					_, _ = w.Write([]byte(`{"ok":true}`))
+				default:
+					t.Errorf("unexpected path: %s", r.URL.Path)
+				}
+			}))
+			defer server.Close()
+
+			uploader := NewSlackFileUploader("xoxb-test", "C123")
+			uploader.apiEndpoint = server.URL + "/api"
+			uploader.httpClient = server.Client()
+
+			err := uploader.Upload("chart.png", "グラフ", "今週の推移", []byte("png"))
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Upload() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			if uploaded != "png" {
+				t.Errorf("uploaded = %q, want png", uploaded)
+			}
+			if completed["channel_id"] != "C123" || completed["initial_comment"] != "今週の推移" {
+				t.Errorf("completed = %v", completed)
+			}
+		})
+	}
+}
+
+func replaceUploadURL(body, url string) string {
+	var v map[string]any
+	if err := json.Unmarshal([]byte(body), &v); err != nil {
+		return body
+	}
+	if _, ok := v["upload_url"]; ok {
+		v["upload_url"] = url
+	}
+	b, _ := json.Marshal(v)
+	return string(b)
+}
@@ -0,0 +1,113 @@
+package notifier
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"time"
+)
+
+// SignatureHeader は汎用Webhookの署名を格納するヘッダー名です。
+// 値は "sha256=" に続けてリクエストボディのHMAC-SHA256を16進数で表したものです。
+const SignatureHeader = "X-Notifier-Signature-256"
+
+// Webhook は任意のURLへHMAC署名付きのJSONを送ります。
+type Webhook struct {
+	url        string
+	secret     []byte
+	httpClient *http.Client
+	now        func() time.Time
+}
+
+// NewWebhook は汎用Webhookの通知先を作成します。
+func NewWebhook(url, secret string) *Webhook {
+	return &Webhook{
+		url:        url,
+		secret:     []byte(secret),
+		httpClient: &http.Client{},
+		now:        time.Now,
+	}
+}
+
+// Name は通知先の名前を返します。
+func (h *Webhook) Name() string {
+	return "webhook"
+}
+
+// Notify はメッセージをJSONにして署名付きで送ります。
+func (h *Webhook) Notify(msg Message) error {
+	body, err := json.Marshal(buildWebhookPayload(msg, h.now()))
+	if err != nil {
+		slog.Error("failed to marshal webhook payload", "error", err)
+		return err
+	}
+
+	header := http.Header{}
+	header.Set(SignatureHeader, Sign(h.secret, body))
+
+	if err := postBody(h.httpClient, h.url, body, header); err != nil {
+		slog.Error("failed to post message to webhook", "error", err)
+		return fmt.Errorf("failed to post message to webhook: %w", err)
+	}
+
+	slog.Info("successfully posted message to webhook", "event", msg.Event)
+	return nil
+}
+
+// Sign はbodyのHMAC-SHA256署名をSignatureHeaderの形式で返します。
+func Sign(secret, body []byte) string {
+	mac := hmac.New(sha256.New, secret)
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// Verify は署名がbodyに対して正しいかを検証します。受信側での検証用です。
+func Verify(secret, body []byte, signature string) bool {
+	return hmac.Equal([]byte(Sign(secret, body)), []byte(signature))
+}
+
+type webhookPayload struct {
+	Event        Event         `json:"event"`
+	Title        string        `json:"title"`
+	Text         string        `json:"text,omitempty"`
+	ThumbnailURL string        `json:"thumbnail_url,omitempty"`
+	Links        []webhookLink `json:"links,omitempty"`
+	Steps        []webhookStep `json:"steps,omitempty"`
+	Error        string        `json:"error,omitempty"`
+	Timestamp    string        `json:"timestamp"`
+}
+
+type webhookLink struct {
+	Label string `json:"label"`
+	URL   string `json:"url"`
+}
+
+type webhookStep struct {
+	Name   string     `json:"name"`
+	Status StepStatus `json:"status"`
+	Detail string     `json:"detail,omitempty"`
+}
+
+func buildWebhookPayload(msg Message, now time.Time) webhookPayload {
+	payload := webhookPayload{
+		Event:        msg.Event,
+		Title:        msg.Title,
+		Text:         msg.Text,
+		ThumbnailURL: msg.ThumbnailURL,
+		Timestamp:    now.Format(time.RFC3339),
+	}
+	for _, link := range msg.Links {
+		payload.Links = append(payload.Links, webhookLink(link))
+	}
+	for _, step := range msg.Steps {
+		payload.Steps = append(payload.Steps, webhookStep(step))
+	}
+	if msg.Err != nil {
+		payload.Error = msg.Err.Error()
+	}
+	return payload
+}
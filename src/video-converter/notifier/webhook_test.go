@@ -0,0 +1,82 @@
+package notifier
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestWebhookNotify(t *testing.T) {
+	t.Parallel()
+
+	secret := []byte("test-secret")
+	var gotBody []byte
+	var gotSignature string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotBody, _ = io.ReadAll(r.Body)
+		gotSignature = r.Header.Get(SignatureHeader)
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+
+	webhook := NewWebhook(server.URL, string(secret))
+	if err := webhook.Notify(Message{Event: EventDigest, Title: "今週のまとめ"}); err != nil {
+		t.Fatalf("Notify() error = %v", err)
+	}
+
+	if !Verify(secret, gotBody, gotSignature) {
+		t.Errorf("signature %q does not match body", gotSignature)
+	}
+	if Verify([]byte("wrong-secret"), gotBody, gotSignature) {
+		t.Errorf("signature verified with wrong secret")
+	}
+
+	var payload webhookPayload
+	if err := json.Unmarshal(gotBody, &payload); err != nil {
+		t.Fatalf("failed to parse payload: %v", err)
+	}
+	if payload.Event != EventDigest || payload.Title != "今週のまとめ" {
+		t.Errorf("payload = %+v, want digest event with title", payload)
+	}
+}
+
+func TestDiscordNotify(t *testing.T) {
+	t.Parallel()
+
+	var payload discordPayload
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+			t.Errorf("failed to parse payload: %v", err)
+		}
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+
+	discord := NewDiscord(server.URL)
+	err := discord.Notify(Message{
+		Event:        EventSuccess,
+		Title:        "動画をアップロードしました",
+		ThumbnailURL: "https://i.ytimg.com/vi/abc/hqdefault.jpg",
+		Links:        []Link{{Label: "YouTube", URL: "https://www.youtube.com/watch?v=abc"}},
+		Steps:        []Step{{Name: "Xへの投稿", Status: StepOK}},
+	})
+	if err != nil {
+		t.Fatalf("Notify() error = %v", err)
+	}
+
+	if len(payload.Embeds) != 1 {
+		t.Fatalf("got %d embeds, want 1", len(payload.Embeds))
+	}
+	embed := payload.Embeds[0]
+	if embed.Thumbnail == nil || embed.Thumbnail.URL != "https://i.ytimg.com/vi/abc/hqdefault.jpg" {
+		t.Errorf("embed thumbnail = %+v, want video thumbnail", embed.Thumbnail)
+	}
+	if embed.Color != discordColorSuccess {
+		t.Errorf("embed color = %x, want %x", embed.Color, discordColorSuccess)
+	}
+	if len(embed.Fields) != 1 || embed.Fields[0].Value != "OK" {
+		t.Errorf("embed fields = %+v, want one OK step", embed.Fields)
+	}
+}
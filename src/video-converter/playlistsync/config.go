@@ -0,0 +1,127 @@
+// Package playlistsync はアップロードした動画と管理している再生リストを突き合わせ、
+// 重複・追加漏れ・別の再生リストへの混入を直す計画を作って適用します。
+package playlistsync
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"regexp"
+	"time"
+
+	"thiroyoshi.com/video-converter/youtube"
+)
+
+// SortOrder は再生リストの並び順です。
+type SortOrder string
+
+const (
+	// SortNone は並び順を変えません
+	SortNone SortOrder = ""
+	// SortNewest は公開日時の新しい順に並べます
+	SortNewest SortOrder = "newest"
+	// SortOldest は公開日時の古い順に並べます
+	SortOldest SortOrder = "oldest"
+)
+
+// Playlist は管理する再生リストと、その再生リストに入れる動画の条件です。
+type Playlist struct {
+	ID   string `json:"id"`
+	Name string `json:"name"`
+	// TitlePattern はタイトルの正規表現です。空の場合はすべての動画が対象です
+	TitlePattern string `json:"title_pattern"`
+	// MinSeconds と MaxSeconds は動画の長さの範囲です。0の場合は判定しません
+	MinSeconds int `json:"min_seconds"`
+	MaxSeconds int `json:"max_seconds"`
+
+	pattern *regexp.Regexp
+}
+
+// Config は再生リストの管理の設定です。
+type Config struct {
+	// Playlists は上から順に条件を判定し、最初に一致した再生リストをその動画の再生リストとします
+	Playlists []Playlist `json:"playlists"`
+	Sort      SortOrder  `json:"sort"`
+	// MaxActions は1回の実行で行う操作の上限です。追加・削除・移動はそれぞれ50ユニットのクォータを使います
+	MaxActions int `json:"max_actions"`
+}
+
+// DefaultConfig は動画の変換で使っている2つの再生リストの設定です。
+// 3分以下の動画をショートに、それ以外のフォートナイトの動画をノーカットに入れます。
+var DefaultConfig = Config{
+	Playlists: []Playlist{
+		{ID: "PLTSYDCu3sM9LEQ27HYpSlCMrxHyquc-_O", Name: "ショート", MaxSeconds: 180},
+		{ID: "PLTSYDCu3sM9JLlRtt7LU6mfM8N8zQSYGq", Name: "ノーカット", TitlePattern: "(?i)fortnite|フォートナイト"},
+	},
+	MaxActions: 150,
+}
+
+// LoadConfig はJSONファイルから設定を読み込みます。ファイルにない項目は DefaultConfig の値を使い、pathが空の場合は DefaultConfig を使います。
+// playlists を指定した場合は既定の再生リストをすべて置き換えます。
+func LoadConfig(path string) (Config, error) {
+	config := DefaultConfig
+	if path != "" {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return Config{}, fmt.Errorf("failed to read playlist config: %w", err)
+		}
+		// Playlists in the file replace the default ones as a whole
+		config.Playlists = nil
+		if err := json.Unmarshal(data, &config); err != nil {
+			return Config{}, fmt.Errorf("failed to parse playlist config: %w", err)
+		}
+		if config.Playlists == nil {
+			config.Playlists = DefaultConfig.Playlists
+		}
+	}
+	config.Playlists = append([]Playlist(nil), config.Playlists...)
+	return config, config.compile()
+}
+
+func (c *Config) compile() error {
+	switch c.Sort {
+	case SortNone, SortNewest, SortOldest:
+	default:
+		return fmt.Errorf("unknown sort order: %q", c.Sort)
+	}
+	for i := range c.Playlists {
+		p := &c.Playlists[i]
+		if p.ID == "" {
+			return fmt.Errorf("playlist %d has no id", i)
+		}
+		if p.Name == "" {
+			p.Name = p.ID
+		}
+		if p.TitlePattern == "" {
+			continue
+		}
+		pattern, err := regexp.Compile(p.TitlePattern)
+		if err != nil {
+			return fmt.Errorf("invalid title pattern of playlist %s: %w", p.Name, err)
+		}
+		p.pattern = pattern
+	}
+	return nil
+}
+
+// matches は動画がこの再生リストの条件に一致するかを返します。
+func (p Playlist) matches(video youtube.Video) bool {
+	if p.MinSeconds > 0 && video.Duration < time.Duration(p.MinSeconds)*time.Second {
+		return false
+	}
+	// A duration of 0 means it is unknown, such as a live stream
+	if p.MaxSeconds > 0 && (video.Duration == 0 || video.Duration > time.Duration(p.MaxSeconds)*time.Second) {
+		return false
+	}
+	return p.pattern == nil || p.pattern.MatchString(video.Title)
+}
+
+// expected は動画を入れるべき再生リストを返します。どの条件にも一致しない場合はnilを返します。
+func (c Config) expected(video youtube.Video) *Playlist {
+	for i := range c.Playlists {
+		if c.Playlists[i].matches(video) {
+			return &c.Playlists[i]
+		}
+	}
+	return nil
+}
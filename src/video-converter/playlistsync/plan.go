@@ -0,0 +1,224 @@
+package playlistsync
+
+import (
+	"fmt"
+	"log/slog"
+	"slices"
+	"sort"
+	"strings"
+
+	"thiroyoshi.com/video-converter/youtube"
+)
+
+// ActionType は再生リストの操作の種類です。
+type ActionType string
+
+const (
+	ActionInsert ActionType = "insert"
+	ActionDelete ActionType = "delete"
+	ActionMove   ActionType = "move"
+)
+
+// Action はひとつの再生リストの操作です。
+type Action struct {
+	Type     ActionType
+	Playlist Playlist
+	VideoID  string
+	Title    string
+	// ItemID は削除・移動するアイテムのIDです
+	ItemID string
+	// Position は追加・移動する位置です。追加でnilの場合は末尾に追加します
+	Position *int
+	Reason   string
+}
+
+// Plan は再生リストを直すための操作の一覧です。削除、追加・移動の順に適用します。
+type Plan struct {
+	Uploads int
+	Actions []Action
+}
+
+// API は再生リストの突き合わせに使うYouTube Data APIの操作です。
+type API interface {
+	UploadsPlaylistID() (string, error)
+	PlaylistItems(playlistID string) ([]youtube.PlaylistVideo, error)
+	Videos(ids []string) ([]youtube.Video, error)
+	InsertPlaylistItem(playlistID, videoID string, position *int) (string, error)
+	DeletePlaylistItem(itemID string) error
+	MovePlaylistItem(itemID, playlistID, videoID string, position int) error
+}
+
+// Fetch はアップロードしたすべての動画と、管理している再生リストのアイテムを取得します。
+func Fetch(api API, config Config) ([]youtube.Video, map[string][]youtube.PlaylistVideo, error) {
+	uploadsID, err := api.UploadsPlaylistID()
+	if err != nil {
+		return nil, nil, err
+	}
+	uploads, err := api.PlaylistItems(uploadsID)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to list uploads: %w", err)
+	}
+	ids := make([]string, 0, len(uploads))
+	for _, upload := range uploads {
+		ids = append(ids, upload.VideoID)
+	}
+	videos, err := api.Videos(ids)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to get uploaded videos: %w", err)
+	}
+
+	items := map[string][]youtube.PlaylistVideo{}
+	for _, playlist := range config.Playlists {
+		list, err := api.PlaylistItems(playlist.ID)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to list playlist %s: %w", playlist.Name, err)
+		}
+		items[playlist.ID] = list
+	}
+	return videos, items, nil
+}
+
+// BuildPlan はアップロードした動画と再生リストのアイテムを突き合わせ、操作の一覧を作ります。
+// 重複したアイテムは最初のひとつを残して削除し、別の再生リストに入れるべき動画は削除して、足りない動画を追加します。
+// アップロードした動画にない動画と、どの再生リストの条件にも一致しない動画はそのままにします。
+func BuildPlan(config Config, uploads []youtube.Video, items map[string][]youtube.PlaylistVideo) *Plan {
+	videos := make(map[string]youtube.Video, len(uploads))
+	for _, video := range uploads {
+		videos[video.ID] = video
+	}
+
+	plan := &Plan{Uploads: len(uploads)}
+	var placements []Action
+	for _, playlist := range config.Playlists {
+		seen := map[string]bool{}
+		var kept []youtube.PlaylistVideo
+		for _, item := range items[playlist.ID] {
+			video, uploaded := videos[item.VideoID]
+			switch {
+			case seen[item.VideoID]:
+				plan.Actions = append(plan.Actions, deleteAction(playlist, item, "重複"))
+			case uploaded && config.expected(video) != nil && config.expected(video).ID != playlist.ID:
+				plan.Actions = append(plan.Actions, deleteAction(playlist, item, fmt.Sprintf("「%s」に入れる動画", config.expected(video).Name)))
+			default:
+				kept = append(kept, item)
+			}
+			seen[item.VideoID] = true
+		}
+
+		var missing []youtube.Video
+		for _, video := range uploads {
+			if expected := config.expected(video); expected != nil && expected.ID == playlist.ID && !seen[video.ID] {
+				missing = append(missing, video)
+			}
+		}
+		placements = append(placements, place(playlist, kept, missing, config.Sort)...)
+	}
+	plan.Actions = append(plan.Actions, placements...)
+	return plan
+}
+
+func deleteAction(playlist Playlist, item youtube.PlaylistVideo, reason string) Action {
+	return Action{Type: ActionDelete, Playlist: playlist, VideoID: item.VideoID, Title: item.Title, ItemID: item.ItemID, Reason: reason}
+}
+
+// place は足りない動画を追加する操作を返します。並び順を指定した場合は、追加と移動で公開日時の順に並べます。
+func place(playlist Playlist, kept []youtube.PlaylistVideo, missing []youtube.Video, order SortOrder) []Action {
+	var actions []Action
+	if order == SortNone {
+		for _, video := range missing {
+			actions = append(actions, Action{Type: ActionInsert, Playlist: playlist, VideoID: video.ID, Title: video.Title, Reason: "再生リストにない"})
+		}
+		return actions
+	}
+
+	// entry is an item of the playlist, or a missing video when itemID is empty
+	type entry struct {
+		itemID, videoID, title string
+		publishedAt            int64
+	}
+	var current []entry
+	for _, item := range kept {
+		current = append(current, entry{itemID: item.ItemID, videoID: item.VideoID, title: item.Title, publishedAt: item.PublishedAt.UnixNano()})
+	}
+	desired := slices.Clone(current)
+	for _, video := range missing {
+		desired = append(desired, entry{videoID: video.ID, title: video.Title, publishedAt: video.PublishedAt.UnixNano()})
+	}
+	sort.SliceStable(desired, func(i, j int) bool {
+		if order == SortNewest {
+			return desired[i].publishedAt > desired[j].publishedAt
+		}
+		return desired[i].publishedAt < desired[j].publishedAt
+	})
+
+	// 操作を順に適用したときの位置になるよう、現在の並びを更新しながら比べる
+	for i, want := range desired {
+		if i < len(current) && current[i].videoID == want.videoID {
+			continue
+		}
+		position := i
+		if want.itemID == "" {
+			actions = append(actions, Action{Type: ActionInsert, Playlist: playlist, VideoID: want.videoID, Title: want.title, Position: &position, Reason: "再生リストにない"})
+		} else {
+			from := slices.IndexFunc(current, func(e entry) bool { return e.itemID == want.itemID })
+			current = slices.Delete(current, from, from+1)
+			actions = append(actions, Action{Type: ActionMove, Playlist: playlist, VideoID: want.videoID, Title: want.title, ItemID: want.itemID, Position: &position, Reason: "並び順"})
+		}
+		current = slices.Insert(current, i, want)
+	}
+	return actions
+}
+
+// Count は指定した種類の操作の件数を返します。
+func (p *Plan) Count(t ActionType) int {
+	n := 0
+	for _, action := range p.Actions {
+		if action.Type == t {
+			n++
+		}
+	}
+	return n
+}
+
+// Text は計画の表示です。
+func (p *Plan) Text() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "アップロードした動画 %d本\n", p.Uploads)
+	fmt.Fprintf(&b, "削除 %d件 / 追加 %d件 / 移動 %d件\n", p.Count(ActionDelete), p.Count(ActionInsert), p.Count(ActionMove))
+	for _, action := range p.Actions {
+		position := ""
+		if action.Position != nil {
+			position = fmt.Sprintf(" → %d番目", *action.Position+1)
+		}
+		fmt.Fprintf(&b, "  %-6s [%s] %s %s%s（%s）\n", action.Type, action.Playlist.Name, action.VideoID, action.Title, position, action.Reason)
+	}
+	return b.String()
+}
+
+// Apply は計画の操作を順に行います。maxActionsに達したら残りは次回に回します。行った操作の件数を返します。
+// 並べ替えの位置は前の操作を前提にしているため、失敗した時点で止めます。
+func Apply(api API, plan *Plan, maxActions int) (int, error) {
+	done := 0
+	for _, action := range plan.Actions {
+		if maxActions > 0 && done >= maxActions {
+			slog.Warn("reached the maximum number of actions, run again to continue", "max", maxActions, "remaining", len(plan.Actions)-done)
+			break
+		}
+
+		var err error
+		switch action.Type {
+		case ActionDelete:
+			err = api.DeletePlaylistItem(action.ItemID)
+		case ActionInsert:
+			_, err = api.InsertPlaylistItem(action.Playlist.ID, action.VideoID, action.Position)
+		case ActionMove:
+			err = api.MovePlaylistItem(action.ItemID, action.Playlist.ID, action.VideoID, *action.Position)
+		}
+		if err != nil {
+			return done, fmt.Errorf("failed to %s %s in %s: %w", action.Type, action.VideoID, action.Playlist.Name, err)
+		}
+		slog.Info("playlist item updated", "action", action.Type, "playlist", action.Playlist.Name, "video", action.VideoID)
+		done++
+	}
+	return done, nil
+}
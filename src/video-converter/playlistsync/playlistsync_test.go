@@ -0,0 +1,235 @@
+package playlistsync
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"thiroyoshi.com/video-converter/youtube"
+)
+
+var base = time.Date(2025, 5, 1, 12, 0, 0, 0, time.UTC)
+
+func video(id, title string, days int, duration time.Duration) youtube.Video {
+	return youtube.Video{ID: id, Title: title, PublishedAt: base.AddDate(0, 0, days), Duration: duration}
+}
+
+func item(itemID, videoID string, days int) youtube.PlaylistVideo {
+	return youtube.PlaylistVideo{ItemID: itemID, VideoID: videoID, Title: videoID, PublishedAt: base.AddDate(0, 0, days)}
+}
+
+func testConfig(t *testing.T, sort SortOrder) Config {
+	t.Helper()
+	config := Config{
+		Playlists: []Playlist{
+			{ID: "short", Name: "ショート", MaxSeconds: 180},
+			{ID: "normal", Name: "ノーカット", TitlePattern: "Fortnite"},
+		},
+		Sort: sort,
+	}
+	if err := config.compile(); err != nil {
+		t.Fatal(err)
+	}
+	return config
+}
+
+// describe は操作を比べやすい文字列にします。
+func describe(actions []Action) []string {
+	var got []string
+	for _, a := range actions {
+		s := string(a.Type) + " " + a.Playlist.ID + " " + a.VideoID
+		if a.Position != nil {
+			s += " @" + string(rune('0'+*a.Position))
+		}
+		got = append(got, s)
+	}
+	return got
+}
+
+func TestBuildPlan(t *testing.T) {
+	t.Parallel()
+
+	uploads := []youtube.Video{
+		video("n1", "No-Cut Fortnite 1", 0, 20*time.Minute),
+		video("n2", "No-Cut Fortnite 2", 1, 20*time.Minute),
+		video("n3", "No-Cut Fortnite 3", 2, 20*time.Minute),
+		video("s1", "Fortnite #shorts", 3, 40*time.Second),
+		video("other", "Vlog", 4, 10*time.Minute),
+	}
+	items := map[string][]youtube.PlaylistVideo{
+		"short": {item("i1", "s1", 3)},
+		// n3 が重複し、s1 はショートに入れる動画、n1 は追加漏れ、other と外部の動画はそのまま
+		"normal": {item("i2", "n3", 2), item("i3", "n3", 2), item("i4", "s1", 3), item("i5", "n2", 1), item("i6", "other", 4), item("i7", "external", 5)},
+	}
+
+	tests := []struct {
+		name string
+		sort SortOrder
+		want []string
+	}{
+		{
+			name: "正常系: 並べ替えなし",
+			sort: SortNone,
+			want: []string{"delete normal n3", "delete normal s1", "insert normal n1"},
+		},
+		{
+			name: "正常系: 古い順に並べ替え",
+			sort: SortOldest,
+			// 削除後の並びは n3, n2, other, external で、n1 を先頭に追加し n2 を2番目に移動する
+			want: []string{"delete normal n3", "delete normal s1", "insert normal n1 @0", "move normal n2 @1"},
+		},
+		{
+			name: "正常系: 新しい順に並べ替え",
+			sort: SortNewest,
+			want: []string{"delete normal n3", "delete normal s1", "move normal external @0", "move normal other @1", "insert normal n1 @4"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			plan := BuildPlan(testConfig(t, tt.sort), uploads, items)
+			if got := describe(plan.Actions); strings.Join(got, "\n") != strings.Join(tt.want, "\n") {
+				t.Errorf("actions =\n%s\nwant\n%s", strings.Join(got, "\n"), strings.Join(tt.want, "\n"))
+			}
+		})
+	}
+
+	plan := BuildPlan(testConfig(t, SortNone), uploads, items)
+	text := plan.Text()
+	for _, want := range []string{"削除 2件 / 追加 1件 / 移動 0件", "（重複）", "（「ショート」に入れる動画）", "（再生リストにない）"} {
+		if !strings.Contains(text, want) {
+			t.Errorf("Text() does not contain %q\n%s", want, text)
+		}
+	}
+}
+
+type fakeAPI struct {
+	playlists map[string][]youtube.PlaylistVideo
+	videos    []youtube.Video
+	calls     []string
+	failOn    string
+}
+
+func (f *fakeAPI) UploadsPlaylistID() (string, error) { return "uploads", nil }
+
+func (f *fakeAPI) PlaylistItems(playlistID string) ([]youtube.PlaylistVideo, error) {
+	return f.playlists[playlistID], nil
+}
+
+func (f *fakeAPI) Videos(ids []string) ([]youtube.Video, error) { return f.videos, nil }
+
+func (f *fakeAPI) InsertPlaylistItem(playlistID, videoID string, position *int) (string, error) {
+	f.calls = append(f.calls, "insert "+videoID)
+	if f.failOn == videoID {
+		return "", errors.New("quota exceeded")
+	}
+	return "new", nil
+}
+
+func (f *fakeAPI) DeletePlaylistItem(itemID string) error {
+	f.calls = append(f.calls, "delete "+itemID)
+	return nil
+}
+
+func (f *fakeAPI) MovePlaylistItem(itemID, playlistID, videoID string, position int) error {
+	f.calls = append(f.calls, "move "+itemID)
+	return nil
+}
+
+func TestFetchAndApply(t *testing.T) {
+	t.Parallel()
+
+	newAPI := func() *fakeAPI {
+		return &fakeAPI{
+			playlists: map[string][]youtube.PlaylistVideo{
+				"uploads": {item("u1", "n1", 0), item("u2", "n2", 1)},
+				"normal":  {item("i1", "n2", 1), item("i2", "n2", 1)},
+			},
+			videos: []youtube.Video{video("n1", "Fortnite 1", 0, time.Hour), video("n2", "Fortnite 2", 1, time.Hour)},
+		}
+	}
+
+	tests := []struct {
+		name       string
+		maxActions int
+		failOn     string
+		wantDone   int
+		wantErr    bool
+	}{
+		{name: "正常系: すべて適用", wantDone: 2},
+		{name: "正常系: 上限で止める", maxActions: 1, wantDone: 1},
+		{name: "異常系: 失敗した時点で止める", failOn: "n1", wantDone: 1, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			api := newAPI()
+			api.failOn = tt.failOn
+			config := testConfig(t, SortNone)
+			uploads, items, err := Fetch(api, config)
+			if err != nil {
+				t.Fatalf("Fetch() error = %v", err)
+			}
+			plan := BuildPlan(config, uploads, items)
+
+			done, err := Apply(api, plan, tt.maxActions)
+			if (err != nil) != tt.wantErr || done != tt.wantDone {
+				t.Errorf("Apply() = %d, %v, want %d, wantErr %v (calls %v)", done, err, tt.wantDone, tt.wantErr, api.calls)
+			}
+			if api.calls[0] != "delete i2" {
+				t.Errorf("calls = %v", api.calls)
+			}
+		})
+	}
+}
+
+func TestLoadConfig(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	write := func(name, content string) string {
+		path := filepath.Join(dir, name)
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		return path
+	}
+
+	tests := []struct {
+		name    string
+		path    string
+		wantErr bool
+		check   func(Config) bool
+	}{
+		{name: "正常系: 指定なし", path: "", check: func(c Config) bool { return len(c.Playlists) == 2 && c.MaxActions == 150 }},
+		{
+			name: "正常系: 再生リストは丸ごと置き換える",
+			path: write("one.json", `{"playlists":[{"id":"PLx","title_pattern":"Vlog"}],"sort":"newest"}`),
+			check: func(c Config) bool {
+				p := c.Playlists[0]
+				return len(c.Playlists) == 1 && p.Name == "PLx" && p.MaxSeconds == 0 && p.pattern != nil && c.Sort == SortNewest && c.MaxActions == 150
+			},
+		},
+		{name: "正常系: 再生リストの指定がなければ既定を使う", path: write("sort.json", `{"sort":"oldest"}`), check: func(c Config) bool { return len(c.Playlists) == 2 }},
+		{name: "異常系: 不正な正規表現", path: write("bad.json", `{"playlists":[{"id":"PLx","title_pattern":"("}]}`), wantErr: true},
+		{name: "異常系: 不明な並び順", path: write("sort-bad.json", `{"sort":"random"}`), wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			config, err := LoadConfig(tt.path)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("LoadConfig() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && !tt.check(config) {
+				t.Errorf("LoadConfig() = %+v", config)
+			}
+		})
+	}
+}
@@ -0,0 +1,47 @@
+package secret
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// File はディレクトリ内のファイルにシークレットを保存します。ローカル実行用です。
+type File struct {
+	dir string
+}
+
+// NewFile はファイルに保存するProviderを作成します。
+func NewFile(dir string) *File {
+	return &File{dir: dir}
+}
+
+// Get はシークレットを読み込みます。
+func (f *File) Get(name string) ([]byte, error) {
+	data, err := os.ReadFile(filepath.Join(f.dir, name))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read secret %s: %w", name, err)
+	}
+	return data, nil
+}
+
+// Put はシークレットを保存します。ファイルは所有者のみ読み書きできる権限で作成します。
+func (f *File) Put(name string, value []byte) error {
+	if err := os.MkdirAll(f.dir, 0o700); err != nil {
+		return fmt.Errorf("failed to create secrets directory: %w", err)
+	}
+
+	// Write to a temporary file first so that a crash never leaves a partial secret
+	tmp := filepath.Join(f.dir, name+".tmp")
+	if err := os.WriteFile(tmp, value, 0o600); err != nil {
+		return fmt.Errorf("failed to write secret %s: %w", name, err)
+	}
+	if err := os.Rename(tmp, filepath.Join(f.dir, name)); err != nil {
+		return fmt.Errorf("failed to write secret %s: %w", name, err)
+	}
+	return nil
+}
@@ -0,0 +1,31 @@
+// Package secret は、トークンなどのシークレットを読み書きするためのパッケージです。
+// Cloud Functions上ではSecret Managerを、ローカルではファイルを使います。
+package secret
+
+import (
+	"errors"
+	"os"
+)
+
+// ErrNotFound はシークレットが存在しない場合のエラーです。
+var ErrNotFound = errors.New("secret not found")
+
+// Provider はシークレットの読み書きを行います。
+type Provider interface {
+	Get(name string) ([]byte, error)
+	Put(name string, value []byte) error
+}
+
+// FromEnv は環境変数に応じてProviderを作成します。
+// GOOGLE_CLOUD_PROJECT が設定されている場合はSecret Managerを、それ以外はSECRETS_DIR（既定は .secrets）のファイルを使います。
+func FromEnv() Provider {
+	if project := os.Getenv("GOOGLE_CLOUD_PROJECT"); project != "" {
+		return NewSecretManager(project)
+	}
+
+	dir := os.Getenv("SECRETS_DIR")
+	if dir == "" {
+		dir = ".secrets"
+	}
+	return NewFile(dir)
+}
@@ -0,0 +1,98 @@
+package secret
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestFile(t *testing.T) {
+	t.Parallel()
+
+	f := NewFile(t.TempDir())
+
+	if _, err := f.Get("token"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Get() error = %v, want ErrNotFound", err)
+	}
+	if err := f.Put("token", []byte("value")); err != nil {
+		t.Fatalf("Put() error = %v", err)
+	}
+	got, err := f.Get("token")
+	if err != nil {
+		t.Fatalf("Get() error = %v", err)
+	}
+	if string(got) != "value" {
+		t.Errorf("Get() = %q, want %q", got, "value")
+	}
+}
+
+func TestSecretManager(t *testing.T) {
+	t.Parallel()
+
+	versions := map[string]string{}
+	var destroyed []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer test-token" {
+			t.Errorf("Authorization = %q, want bearer token", r.Header.Get("Authorization"))
+		}
+
+		switch {
+		case r.Method == "POST" && strings.HasSuffix(r.URL.Path, "/secrets/token:addVersion"):
+			body, _ := io.ReadAll(r.Body)
+			var payload secretPayload
+			if err := json.Unmarshal(body, &payload); err != nil {
+				t.Errorf("failed to parse payload: %v", err)
+			}
+			versions["token"] = payload.Payload.Data
+			_, _ = w.Write([]byte(`{"name": "projects/123/secrets/token/versions/3"}`))
+		case r.Method == "GET" && strings.HasSuffix(r.URL.Path, "/secrets/token/versions"):
+			if r.URL.Query().Get("filter") != "state:ENABLED" {
+				t.Errorf("filter = %q, want enabled versions", r.URL.Query().Get("filter"))
+			}
+			// Version 4 was added by another process after this one
+			_, _ = w.Write([]byte(`{"versions": [{"name": "projects/123/secrets/token/versions/4"}, {"name": "projects/123/secrets/token/versions/3"}, {"name": "projects/123/secrets/token/versions/1"}]}`))
+		case r.Method == "POST" && strings.HasSuffix(r.URL.Path, ":destroy"):
+			destroyed = append(destroyed, r.URL.Path)
+			_, _ = w.Write([]byte(`{}`))
+		case r.Method == "GET" && strings.HasSuffix(r.URL.Path, "/versions/latest:access"):
+			data, ok := versions["token"]
+			if !ok {
+				w.WriteHeader(http.StatusNotFound)
+				return
+			}
+			_, _ = w.Write([]byte(`{"payload": {"data": "` + data + `"}}`))
+		default:
+			t.Errorf("unexpected request: %s %s", r.Method, r.URL.Path)
+		}
+	}))
+	defer server.Close()
+
+	s := NewSecretManager("p")
+	s.endpoint = server.URL
+	s.token = func() (string, error) { return "test-token", nil }
+
+	if _, err := s.Get("token"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Get() error = %v, want ErrNotFound", err)
+	}
+	if err := s.Put("token", []byte("value")); err != nil {
+		t.Fatalf("Put() error = %v", err)
+	}
+	if versions["token"] != base64.StdEncoding.EncodeToString([]byte("value")) {
+		t.Errorf("stored payload = %q, want base64 value", versions["token"])
+	}
+	if len(destroyed) != 1 || destroyed[0] != "/projects/123/secrets/token/versions/1:destroy" {
+		t.Errorf("destroyed = %v, want only version 1", destroyed)
+	}
+	got, err := s.Get("token")
+	if err != nil {
+		t.Fatalf("Get() error = %v", err)
+	}
+	if string(got) != "value" {
+		t.Errorf("Get() = %q, want %q", got, "value")
+	}
+}
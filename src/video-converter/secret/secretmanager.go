@@ -0,0 +1,217 @@
+package secret
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+)
+
+const (
+	secretManagerEndpoint = "https://secretmanager.googleapis.com/v1"
+	metadataTokenEndpoint = "http://metadata.google.internal/computeMetadata/v1/instance/service-accounts/default/token"
+)
+
+// SecretManager はGCPのSecret Managerにシークレットを保存します。
+// アクセストークンはメタデータサーバーから取得します。
+// ローカルから使う場合は GOOGLE_OAUTH_ACCESS_TOKEN に `gcloud auth print-access-token` の値を設定します。
+type SecretManager struct {
+	project    string
+	endpoint   string
+	httpClient *http.Client
+	token      func() (string, error)
+}
+
+// NewSecretManager はSecret Managerに保存するProviderを作成します。
+func NewSecretManager(project string) *SecretManager {
+	s := &SecretManager{
+		project:    project,
+		endpoint:   secretManagerEndpoint,
+		httpClient: &http.Client{},
+	}
+	s.token = s.metadataToken
+	return s
+}
+
+type secretPayload struct {
+	Payload struct {
+		Data string `json:"data"`
+	} `json:"payload"`
+}
+
+// Get は最新バージョンのシークレットを読み込みます。
+func (s *SecretManager) Get(name string) ([]byte, error) {
+	url := fmt.Sprintf("%s/projects/%s/secrets/%s/versions/latest:access", s.endpoint, s.project, name)
+	body, status, err := s.do("GET", url, nil)
+	if err != nil {
+		return nil, err
+	}
+	if status == http.StatusNotFound {
+		return nil, ErrNotFound
+	}
+	if status != http.StatusOK {
+		slog.Error("Secret Manager error response", "status", status, "body", string(body))
+		return nil, fmt.Errorf("failed to access secret %s: status code %d", name, status)
+	}
+
+	var payload secretPayload
+	if err := json.Unmarshal(body, &payload); err != nil {
+		return nil, fmt.Errorf("failed to parse secret %s: %w", name, err)
+	}
+	return base64.StdEncoding.DecodeString(payload.Payload.Data)
+}
+
+// Put はシークレットの新しいバージョンを追加し、それより古い有効なバージョンを破棄します。
+// シークレット自体は事前に作成しておく必要があります。古いバージョンを破棄できなくても保存は成功とします。
+func (s *SecretManager) Put(name string, value []byte) error {
+	var payload secretPayload
+	payload.Payload.Data = base64.StdEncoding.EncodeToString(value)
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+
+	url := fmt.Sprintf("%s/projects/%s/secrets/%s:addVersion", s.endpoint, s.project, name)
+	body, status, err := s.do("POST", url, data)
+	if err != nil {
+		return err
+	}
+	if status != http.StatusOK {
+		slog.Error("Secret Manager error response", "status", status, "body", string(body))
+		return fmt.Errorf("failed to add secret version %s: status code %d", name, status)
+	}
+
+	var added secretVersion
+	if err := json.Unmarshal(body, &added); err != nil {
+		slog.Warn("Failed to parse added secret version, keeping older versions", "secret", name, "error", err)
+		return nil
+	}
+	if err := s.destroyOlderVersions(name, added.Name); err != nil {
+		slog.Warn("Failed to destroy older secret versions", "secret", name, "error", err)
+	}
+	return nil
+}
+
+type secretVersion struct {
+	Name string `json:"name"`
+}
+
+// versionNumber は projects/p/secrets/name/versions/3 のようなバージョン名の番号を返します。
+func versionNumber(name string) (int, bool) {
+	n, err := strconv.Atoi(name[strings.LastIndex(name, "/")+1:])
+	return n, err == nil
+}
+
+// destroyOlderVersions は added より古い有効なバージョンを破棄します。
+// 同時に保存された新しいバージョンを消さないよう、番号が added より小さいものだけを対象にします。
+func (s *SecretManager) destroyOlderVersions(name, added string) error {
+	latest, ok := versionNumber(added)
+	if !ok {
+		return fmt.Errorf("unexpected secret version name: %s", added)
+	}
+
+	url := fmt.Sprintf("%s/projects/%s/secrets/%s/versions?filter=state:ENABLED", s.endpoint, s.project, name)
+	body, status, err := s.do("GET", url, nil)
+	if err != nil {
+		return err
+	}
+	if status != http.StatusOK {
+		return fmt.Errorf("failed to list secret versions %s: status code %d", name, status)
+	}
+	var list struct {
+		Versions []secretVersion `json:"versions"`
+	}
+	if err := json.Unmarshal(body, &list); err != nil {
+		return fmt.Errorf("failed to parse secret versions %s: %w", name, err)
+	}
+
+	for _, version := range list.Versions {
+		if n, ok := versionNumber(version.Name); !ok || n >= latest {
+			continue
+		}
+		_, status, err := s.do("POST", fmt.Sprintf("%s/%s:destroy", s.endpoint, version.Name), []byte("{}"))
+		if err != nil {
+			return err
+		}
+		if status != http.StatusOK {
+			return fmt.Errorf("failed to destroy secret version %s: status code %d", version.Name, status)
+		}
+	}
+	return nil
+}
+
+func (s *SecretManager) do(method, url string, data []byte) ([]byte, int, error) {
+	token, err := s.token()
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to get access token: %w", err)
+	}
+
+	req, err := http.NewRequest(method, url, bytes.NewReader(data))
+	if err != nil {
+		return nil, 0, err
+	}
+	req.Header.Set("Authorization", "Bearer "+token)
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := s.httpClient.Do(req)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer func() {
+		if cerr := resp.Body.Close(); cerr != nil {
+			slog.Error("failed to close response body", "error", cerr)
+		}
+	}()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, 0, err
+	}
+	return body, resp.StatusCode, nil
+}
+
+func (s *SecretManager) metadataToken() (string, error) {
+	return AccessToken(s.httpClient)
+}
+
+// AccessToken はGCPのAPIを呼ぶアクセストークンを返します。
+// GOOGLE_OAUTH_ACCESS_TOKEN が設定されていればその値を、それ以外はメタデータサーバーから取得したサービスアカウントのトークンを使います。
+func AccessToken(httpClient *http.Client) (string, error) {
+	if token := os.Getenv("GOOGLE_OAUTH_ACCESS_TOKEN"); token != "" {
+		return token, nil
+	}
+
+	req, err := http.NewRequest("GET", metadataTokenEndpoint, nil)
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Metadata-Flavor", "Google")
+
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer func() {
+		if cerr := resp.Body.Close(); cerr != nil {
+			slog.Error("failed to close response body", "error", cerr)
+		}
+	}()
+
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("metadata server returned status code %d", resp.StatusCode)
+	}
+
+	var token struct {
+		AccessToken string `json:"access_token"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&token); err != nil {
+		return "", err
+	}
+	return token.AccessToken, nil
+}
@@ -0,0 +1,179 @@
+package stats
+
+import (
+	"bytes"
+	"errors"
+	"image"
+	"image/color"
+	"image/draw"
+	"image/png"
+	"time"
+)
+
+const (
+	chartWidth  = 800
+	chartHeight = 400
+	chartMargin = 40
+	gridLines   = 4
+)
+
+var (
+	colorBackground = color.RGBA{R: 255, G: 255, B: 255, A: 255}
+	colorGrid       = color.RGBA{R: 224, G: 224, B: 224, A: 255}
+	colorAxis       = color.RGBA{R: 128, G: 128, B: 128, A: 255}
+	// Line of X followers
+	colorX = color.RGBA{R: 29, G: 155, B: 240, A: 255}
+	// Line of YouTube subscribers
+	colorYouTube = color.RGBA{R: 255, G: 0, B: 0, A: 255}
+)
+
+// ChartLegend はグラフの凡例です。画像には文字を描かないため、通知の本文に添えます。
+const ChartLegend = "青: Xのフォロワー / 赤: YouTubeのチャンネル登録者（それぞれの最小値〜最大値で正規化）"
+
+// ErrNotEnoughData はグラフを描くための記録が足りないことを表します。
+var ErrNotEnoughData = errors.New("at least two snapshots are required for a chart")
+
+// Chart は直近days日のXのフォロワー数とYouTubeのチャンネル登録者数の推移を折れ線グラフのPNGで返します。
+// 2つの系列は桁が違うため、それぞれの最小値と最大値で縦軸を正規化します。
+func Chart(snapshots []Snapshot, days int, now time.Time) ([]byte, error) {
+	from := now.AddDate(0, 0, -days).Format(dateLayout)
+	to := now.Format(dateLayout)
+
+	var points []Snapshot
+	for _, snapshot := range snapshots {
+		if snapshot.Date > from && snapshot.Date <= to {
+			points = append(points, snapshot)
+		}
+	}
+	if len(points) < 2 {
+		return nil, ErrNotEnoughData
+	}
+
+	img := image.NewRGBA(image.Rect(0, 0, chartWidth, chartHeight))
+	draw.Draw(img, img.Bounds(), &image.Uniform{C: colorBackground}, image.Point{}, draw.Src)
+
+	left, right := chartMargin, chartWidth-chartMargin
+	top, bottom := chartMargin, chartHeight-chartMargin
+	for i := 0; i <= gridLines; i++ {
+		y := top + (bottom-top)*i/gridLines
+		drawLine(img, left, y, right, y, colorGrid, 1)
+	}
+	drawLine(img, left, top, left, bottom, colorAxis, 1)
+	drawLine(img, left, bottom, right, bottom, colorAxis, 1)
+
+	// Position of each snapshot on the time axis
+	start, end := points[0].Day(), points[len(points)-1].Day()
+	span := end.Sub(start).Hours()
+	xs := make([]int, len(points))
+	for i, point := range points {
+		ratio := 0.0
+		if span > 0 {
+			ratio = point.Day().Sub(start).Hours() / span
+		}
+		xs[i] = left + int(ratio*float64(right-left))
+	}
+
+	series := []struct {
+		color color.RGBA
+		value func(Snapshot) (float64, bool)
+	}{
+		{colorX, func(s Snapshot) (float64, bool) {
+			if s.X == nil {
+				return 0, false
+			}
+			return float64(s.X.Followers), true
+		}},
+		{colorYouTube, func(s Snapshot) (float64, bool) {
+			if s.YouTube == nil {
+				return 0, false
+			}
+			return float64(s.YouTube.Subscribers), true
+		}},
+	}
+	for _, line := range series {
+		min, max, ok := valueRange(points, line.value)
+		if !ok {
+			continue
+		}
+		prevX, prevY, hasPrev := 0, 0, false
+		for i, point := range points {
+			v, ok := line.value(point)
+			if !ok {
+				hasPrev = false
+				continue
+			}
+			ratio := 0.5
+			if max > min {
+				ratio = (v - min) / (max - min)
+			}
+			y := bottom - int(ratio*float64(bottom-top))
+			if hasPrev {
+				drawLine(img, prevX, prevY, xs[i], y, line.color, 3)
+			}
+			fillRect(img, xs[i]-3, y-3, xs[i]+3, y+3, line.color)
+			prevX, prevY, hasPrev = xs[i], y, true
+		}
+	}
+
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, img); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func valueRange(points []Snapshot, value func(Snapshot) (float64, bool)) (min, max float64, ok bool) {
+	for _, point := range points {
+		v, has := value(point)
+		if !has {
+			continue
+		}
+		if !ok || v < min {
+			min = v
+		}
+		if !ok || v > max {
+			max = v
+		}
+		ok = true
+	}
+	return min, max, ok
+}
+
+// drawLine はBresenhamのアルゴリズムで太さwidthの線を描きます。
+func drawLine(img *image.RGBA, x0, y0, x1, y1 int, c color.Color, width int) {
+	dx, dy := abs(x1-x0), -abs(y1-y0)
+	sx, sy := 1, 1
+	if x0 > x1 {
+		sx = -1
+	}
+	if y0 > y1 {
+		sy = -1
+	}
+	half := width / 2
+	for e := dx + dy; ; {
+		fillRect(img, x0-half, y0-half, x0+half, y0+half, c)
+		if x0 == x1 && y0 == y1 {
+			return
+		}
+		e2 := 2 * e
+		if e2 >= dy {
+			e += dy
+			x0 += sx
+		}
+		if e2 <= dx {
+			e += dx
+			y0 += sy
+		}
+	}
+}
+
+func fillRect(img *image.RGBA, x0, y0, x1, y1 int, c color.Color) {
+	draw.Draw(img, image.Rect(x0, y0, x1+1, y1+1), &image.Uniform{C: c}, image.Point{}, draw.Src)
+}
+
+func abs(n int) int {
+	if n < 0 {
+		return -n
+	}
+	return n
+}
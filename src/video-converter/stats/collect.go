@@ -0,0 +1,57 @@
+package stats
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"thiroyoshi.com/video-converter/x"
+	"thiroyoshi.com/video-converter/youtube"
+)
+
+// XProfiler はXのアカウントの情報を取得します。
+type XProfiler interface {
+	Profile() (*x.User, error)
+}
+
+// ChannelStatistician はYouTubeチャンネルの統計情報を取得します。
+type ChannelStatistician interface {
+	MyChannelStatistics() (*youtube.ChannelStatistics, error)
+}
+
+// Collect はXとYouTubeの数値を取得してスナップショットを作ります。
+// どちらかのサービスがnilまたは取得に失敗した場合も、取得できた数値でスナップショットを作り、エラーを合わせて返します。
+func Collect(now time.Time, xClient XProfiler, ytClient ChannelStatistician) (Snapshot, error) {
+	snapshot := Snapshot{Date: now.Format(dateLayout), Time: now}
+	var errs []error
+
+	if xClient != nil {
+		if user, err := xClient.Profile(); err != nil {
+			errs = append(errs, fmt.Errorf("x: %w", err))
+		} else {
+			snapshot.X = &XStats{
+				Followers: user.PublicMetrics.FollowersCount,
+				Following: user.PublicMetrics.FollowingCount,
+				Tweets:    user.PublicMetrics.TweetCount,
+			}
+		}
+	}
+
+	if ytClient != nil {
+		if channel, err := ytClient.MyChannelStatistics(); err != nil {
+			errs = append(errs, fmt.Errorf("youtube: %w", err))
+		} else {
+			if channel.HiddenSubscriberCount {
+				slog.Warn("YouTube subscriber count is hidden", "channel", channel.ChannelID)
+			}
+			snapshot.YouTube = &YouTubeStats{
+				Subscribers: channel.SubscriberCount,
+				Views:       channel.ViewCount,
+				Videos:      channel.VideoCount,
+			}
+		}
+	}
+
+	return snapshot, errors.Join(errs...)
+}
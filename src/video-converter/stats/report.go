@@ -0,0 +1,79 @@
+package stats
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// ReportTitle は週次レポートのタイトルです。
+const ReportTitle = "今週のフォロワー推移"
+
+// Report は最新のスナップショットと1週間前のスナップショットの比較です。
+type Report struct {
+	Current  Snapshot
+	Baseline *Snapshot
+}
+
+// Weekly はnow以前の最新のスナップショットと、その7日前以前の最新のスナップショットを比べます。
+// スナップショットがない場合はnilを返します。
+func Weekly(snapshots []Snapshot, now time.Time) *Report {
+	today := now.Format(dateLayout)
+	weekAgo := now.AddDate(0, 0, -7).Format(dateLayout)
+
+	var report *Report
+	for i := len(snapshots) - 1; i >= 0; i-- {
+		snapshot := snapshots[i]
+		if report == nil {
+			if snapshot.Date <= today {
+				report = &Report{Current: snapshot}
+			}
+			continue
+		}
+		if snapshot.Date <= weekAgo {
+			report.Baseline = &snapshots[i]
+			break
+		}
+	}
+	return report
+}
+
+// Text は週次レポートの本文です。
+func (r *Report) Text() string {
+	var b strings.Builder
+	if r.Baseline != nil {
+		fmt.Fprintf(&b, "%s（%s〜%s）\n", ReportTitle, r.Baseline.Date, r.Current.Date)
+	} else {
+		fmt.Fprintf(&b, "%s（%s、比較できる1週間前の記録がありません）\n", ReportTitle, r.Current.Date)
+	}
+
+	if x := r.Current.X; x != nil {
+		var base *XStats
+		if r.Baseline != nil {
+			base = r.Baseline.X
+		}
+		b.WriteString("X\n")
+		fmt.Fprintf(&b, "  フォロワー %s\n", withDelta(int64(x.Followers), base, func(s *XStats) int64 { return int64(s.Followers) }))
+		fmt.Fprintf(&b, "  フォロー中 %s\n", withDelta(int64(x.Following), base, func(s *XStats) int64 { return int64(s.Following) }))
+		fmt.Fprintf(&b, "  ツイート %s\n", withDelta(int64(x.Tweets), base, func(s *XStats) int64 { return int64(s.Tweets) }))
+	}
+	if yt := r.Current.YouTube; yt != nil {
+		var base *YouTubeStats
+		if r.Baseline != nil {
+			base = r.Baseline.YouTube
+		}
+		b.WriteString("YouTube\n")
+		fmt.Fprintf(&b, "  チャンネル登録者 %s\n", withDelta(yt.Subscribers, base, func(s *YouTubeStats) int64 { return s.Subscribers }))
+		fmt.Fprintf(&b, "  総再生回数 %s\n", withDelta(yt.Views, base, func(s *YouTubeStats) int64 { return s.Views }))
+		fmt.Fprintf(&b, "  動画数 %s\n", withDelta(yt.Videos, base, func(s *YouTubeStats) int64 { return s.Videos }))
+	}
+	return b.String()
+}
+
+// withDelta は「123（+4）」の形式で値と前週からの差を返します。前週の値がない場合は値だけを返します。
+func withDelta[T any](current int64, base *T, value func(*T) int64) string {
+	if base == nil {
+		return fmt.Sprintf("%d", current)
+	}
+	return fmt.Sprintf("%d（%+d）", current, current-value(base))
+}
@@ -0,0 +1,171 @@
+package stats
+
+import (
+	"bytes"
+	"errors"
+	"image/png"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"thiroyoshi.com/video-converter/x"
+	"thiroyoshi.com/video-converter/youtube"
+)
+
+var jst = time.FixedZone("JST", 9*60*60)
+
+func snapshotOn(day int, followers int, subscribers int64) Snapshot {
+	t := time.Date(2025, 4, day, 9, 0, 0, 0, jst)
+	return Snapshot{
+		Date:    t.Format(dateLayout),
+		Time:    t,
+		X:       &XStats{Followers: followers, Following: 100, Tweets: 1000 + day},
+		YouTube: &YouTubeStats{Subscribers: subscribers, Views: 10000 + int64(day)*100, Videos: 50},
+	}
+}
+
+func TestStore(t *testing.T) {
+	t.Parallel()
+
+	store := NewStore(filepath.Join(t.TempDir(), "data", "stats.jsonl"))
+	if snapshots, err := store.Load(); err != nil || len(snapshots) != 0 {
+		t.Fatalf("Load() = %v, %v, want empty", snapshots, err)
+	}
+
+	for _, snapshot := range []Snapshot{snapshotOn(2, 10, 1), snapshotOn(1, 5, 1), snapshotOn(2, 12, 2)} {
+		if err := store.Put(snapshot); err != nil {
+			t.Fatalf("Put() error = %v", err)
+		}
+	}
+
+	snapshots, err := store.Load()
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	// 同じ日付は置き換え、日付順に並べる
+	if len(snapshots) != 2 || snapshots[0].Date != "2025-04-01" || snapshots[1].X.Followers != 12 {
+		t.Errorf("snapshots = %+v", snapshots)
+	}
+}
+
+type fakeX struct{ err error }
+
+func (f fakeX) Profile() (*x.User, error) {
+	if f.err != nil {
+		return nil, f.err
+	}
+	return &x.User{PublicMetrics: x.UserMetrics{FollowersCount: 300, FollowingCount: 200, TweetCount: 5000}}, nil
+}
+
+type fakeYouTube struct{}
+
+func (fakeYouTube) MyChannelStatistics() (*youtube.ChannelStatistics, error) {
+	return &youtube.ChannelStatistics{SubscriberCount: 40, ViewCount: 9000, VideoCount: 70}, nil
+}
+
+func TestCollect(t *testing.T) {
+	t.Parallel()
+
+	now := time.Date(2025, 4, 20, 9, 0, 0, 0, jst)
+
+	tests := []struct {
+		name        string
+		x           XProfiler
+		wantX       bool
+		wantErr     bool
+		wantYouTube bool
+	}{
+		{name: "正常系: 両方取得する", x: fakeX{}, wantX: true, wantYouTube: true},
+		{name: "異常系: Xの取得に失敗してもYouTubeは記録する", x: fakeX{err: errors.New("unauthorized")}, wantErr: true, wantYouTube: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			snapshot, err := Collect(now, tt.x, fakeYouTube{})
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Collect() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if snapshot.Date != "2025-04-20" || (snapshot.X != nil) != tt.wantX || (snapshot.YouTube != nil) != tt.wantYouTube {
+				t.Errorf("snapshot = %+v", snapshot)
+			}
+			if tt.wantX && snapshot.X.Followers != 300 {
+				t.Errorf("X = %+v", snapshot.X)
+			}
+		})
+	}
+}
+
+func TestWeekly(t *testing.T) {
+	t.Parallel()
+
+	snapshots := []Snapshot{snapshotOn(1, 90, 30), snapshotOn(12, 100, 35), snapshotOn(15, 104, 36), snapshotOn(19, 110, 38)}
+
+	tests := []struct {
+		name string
+		now  time.Time
+		want []string
+	}{
+		{
+			name: "正常系: 1週間前の記録と比べる",
+			now:  time.Date(2025, 4, 19, 12, 0, 0, 0, jst),
+			want: []string{"今週のフォロワー推移（2025-04-12〜2025-04-19）", "フォロワー 110（+10）", "チャンネル登録者 38（+3）", "総再生回数 11900（+700）"},
+		},
+		{
+			name: "正常系: 1週間前の記録がない",
+			now:  time.Date(2025, 4, 5, 12, 0, 0, 0, jst),
+			want: []string{"比較できる1週間前の記録がありません", "フォロワー 90\n"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			report := Weekly(snapshots, tt.now)
+			if report == nil {
+				t.Fatal("Weekly() = nil")
+			}
+			text := report.Text()
+			for _, want := range tt.want {
+				if !strings.Contains(text, want) {
+					t.Errorf("Text() = %q, want to contain %q", text, want)
+				}
+			}
+		})
+	}
+
+	if Weekly(nil, time.Now()) != nil {
+		t.Error("Weekly(nil) != nil")
+	}
+}
+
+func TestChart(t *testing.T) {
+	t.Parallel()
+
+	now := time.Date(2025, 4, 20, 12, 0, 0, 0, jst)
+	snapshots := []Snapshot{snapshotOn(12, 100, 35), snapshotOn(15, 104, 36), {Date: "2025-04-17", Time: now, X: &XStats{Followers: 106}}, snapshotOn(19, 110, 38)}
+
+	data, err := Chart(snapshots, 30, now)
+	if err != nil {
+		t.Fatalf("Chart() error = %v", err)
+	}
+	img, err := png.Decode(bytes.NewReader(data))
+	if err != nil {
+		t.Fatalf("png.Decode() error = %v", err)
+	}
+	if b := img.Bounds(); b.Dx() != chartWidth || b.Dy() != chartHeight {
+		t.Errorf("size = %v", b)
+	}
+
+	// 最新の点は右端の上端に描かれる
+	if got := img.At(chartWidth-chartMargin, chartMargin); got != colorYouTube && got != colorX {
+		t.Errorf("pixel at latest point = %v, want a series color", got)
+	}
+
+	if _, err := Chart(snapshots[:1], 30, now); !errors.Is(err, ErrNotEnoughData) {
+		t.Errorf("Chart() error = %v, want ErrNotEnoughData", err)
+	}
+}
@@ -0,0 +1,138 @@
+// Package stats はXとYouTubeのフォロワー数などを毎日記録し、週ごとの変化をレポートします。
+package stats
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+)
+
+// Date format of snapshots. One snapshot is kept per day.
+const dateLayout = "2006-01-02"
+
+// XStats はXのアカウントの数値です。
+type XStats struct {
+	Followers int `json:"followers"`
+	Following int `json:"following"`
+	Tweets    int `json:"tweets"`
+}
+
+// YouTubeStats はYouTubeチャンネルの数値です。
+type YouTubeStats struct {
+	Subscribers int64 `json:"subscribers"`
+	Views       int64 `json:"views"`
+	Videos      int64 `json:"videos"`
+}
+
+// Snapshot はある日の数値です。取得できなかったサービスはnilになります。
+type Snapshot struct {
+	Date    string        `json:"date"`
+	Time    time.Time     `json:"time"`
+	X       *XStats       `json:"x,omitempty"`
+	YouTube *YouTubeStats `json:"youtube,omitempty"`
+}
+
+// Day はスナップショットの日付を返します。
+func (s Snapshot) Day() time.Time {
+	day, _ := time.ParseInLocation(dateLayout, s.Date, s.Time.Location())
+	return day
+}
+
+// Store はスナップショットを1行1件のJSON Linesで保存する時系列ストアです。
+type Store struct {
+	path string
+}
+
+// NewStore はpathのファイルを使うストアを作成します。
+func NewStore(path string) *Store {
+	return &Store{path: path}
+}
+
+// Load はすべてのスナップショットを日付順に返します。ファイルがない場合は空を返します。
+func (s *Store) Load() ([]Snapshot, error) {
+	file, err := os.Open(s.path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to open stats store: %w", err)
+	}
+	defer func() { _ = file.Close() }()
+
+	var snapshots []Snapshot
+	scanner := bufio.NewScanner(file)
+	for line := 1; scanner.Scan(); line++ {
+		if len(scanner.Bytes()) == 0 {
+			continue
+		}
+		var snapshot Snapshot
+		if err := json.Unmarshal(scanner.Bytes(), &snapshot); err != nil {
+			return nil, fmt.Errorf("invalid snapshot at line %d: %w", line, err)
+		}
+		snapshots = append(snapshots, snapshot)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	sort.SliceStable(snapshots, func(i, j int) bool { return snapshots[i].Date < snapshots[j].Date })
+	return snapshots, nil
+}
+
+// Put はスナップショットを保存します。同じ日付のスナップショットがあれば置き換えます。
+func (s *Store) Put(snapshot Snapshot) error {
+	snapshots, err := s.Load()
+	if err != nil {
+		return err
+	}
+
+	replaced := false
+	for i := range snapshots {
+		if snapshots[i].Date == snapshot.Date {
+			snapshots[i] = snapshot
+			replaced = true
+		}
+	}
+	if !replaced {
+		snapshots = append(snapshots, snapshot)
+	}
+	sort.SliceStable(snapshots, func(i, j int) bool { return snapshots[i].Date < snapshots[j].Date })
+
+	return s.write(snapshots)
+}
+
+// write は一時ファイルに書いてから置き換え、途中で失敗しても既存の記録を壊さないようにします。
+func (s *Store) write(snapshots []Snapshot) error {
+	if dir := filepath.Dir(s.path); dir != "" {
+		if err := os.MkdirAll(dir, 0o755); err != nil {
+			return err
+		}
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(s.path), ".stats-*")
+	if err != nil {
+		return err
+	}
+	defer func() { _ = os.Remove(tmp.Name()) }()
+
+	writer := bufio.NewWriter(tmp)
+	encoder := json.NewEncoder(writer)
+	for _, snapshot := range snapshots {
+		if err := encoder.Encode(snapshot); err != nil {
+			_ = tmp.Close()
+			return err
+		}
+	}
+	if err := writer.Flush(); err != nil {
+		_ = tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), s.path)
+}
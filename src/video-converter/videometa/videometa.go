@@ -0,0 +1,78 @@
+// Package videometa は動画の変換でYouTubeに設定するタイトル以外のメタデータ（説明・タグ・カテゴリ）を定義します。
+// 動画の変換と過去の動画の一括更新で同じ内容を使います。
+package videometa
+
+import (
+	"fmt"
+	"strings"
+
+	"thiroyoshi.com/video-converter/affiliate"
+)
+
+// CategoryGaming is the YouTube category ID of gaming videos
+const CategoryGaming = "20"
+
+// Tags は動画に設定するタグです。
+var Tags = []string{"Fortnite", "フォートナイト", "gameplay", "プレイ動画", "ps5", "ps5Share"}
+
+// descriptionHeader は動画の説明のプレイ環境より前の部分です
+const descriptionHeader = `GABAのフォートナイトのプレイログです。日々のプレイをそのままアップロードしています。
+「ナイス！」「GG！」と思ったら高評価＆チャンネル登録をお願いします！
+一緒にフォートナイトを盛り上げていきましょう！
+
+▼ Recommend video!
+【ノーダメ / 命中率100% / ビクロイ】GABAのプレイログ 2025-04-14 15:28:44
+　https://www.youtube.com/watch?v=EVuULSAjsJM
+
+=========================================
+▼ Subscribe me! チャンネル登録はこちら！
+　https://www.youtube.com/@gabavlog
+▼ Follow my X! フォートナイト関連のアカウントなら100%フォロバします！
+　https://x.com/GABA_FORTNITE
+▼ Read blog! Fortniteプレイ記録と日記とちょっとのお役立ち情報を書いてるブログです。
+　https://gaba-fortnite.hatenablog.com/
+
+=========================================
+`
+
+// descriptionFooter は動画の説明のプレイ環境より後の部分です
+const descriptionFooter = `=========================================
+【プレイリスト集】
+▼ ノーマル/ノーカット無編集
+　https://www.youtube.com/playlist?list=PLTSYDCu3sM9JLlRtt7LU6mfM8N8zQSYGq
+▼ おもしろショート
+　https://www.youtube.com/playlist?list=PLTSYDCu3sM9LEQ27HYpSlCMrxHyquc-_O
+
+#Fortnite #gameplay #フォートナイト #プレイ動画 #ps5 #ps5Share #controller #pad #fortniteclips`
+
+// Description は動画の説明です。プレイ環境はアフィリエイトのカタログの setup から作ります。
+// 機材やハッシュタグを変えたら、過去の動画には cmd/yt-metadata で反映します。
+func Description(catalog *affiliate.Catalog) string {
+	var b strings.Builder
+	b.WriteString(descriptionHeader)
+	b.WriteString("【GABAのプレイ環境】\n")
+	for _, p := range catalog.SetupProducts() {
+		fmt.Fprintf(&b, "▼ %s：%s\n　%s\n", p.Label, p.Title, p.URL)
+	}
+	b.WriteString("\n")
+	b.WriteString(descriptionFooter)
+	return b.String()
+}
+
+// Snippet は動画に設定するメタデータです。
+type Snippet struct {
+	Title       string   `json:"title"`
+	Description string   `json:"description"`
+	CategoryID  string   `json:"categoryId"`
+	Tags        []string `json:"tags"`
+}
+
+// NewSnippet はタイトルに現在の説明・タグ・カテゴリを合わせたメタデータを返します。
+func NewSnippet(title string, catalog *affiliate.Catalog) Snippet {
+	return Snippet{
+		Title:       title,
+		Description: Description(catalog),
+		CategoryID:  CategoryGaming,
+		Tags:        append([]string(nil), Tags...),
+	}
+}
@@ -0,0 +1,85 @@
+package videostats
+
+import (
+	"fmt"
+	"log/slog"
+	"time"
+
+	"thiroyoshi.com/video-converter/youtube"
+)
+
+// Source は動画の数値を取得するYouTubeのAPIです。
+type Source interface {
+	PlaylistItems(playlistID string) ([]youtube.PlaylistVideo, error)
+	PlaylistTitles(ids []string) (map[string]string, error)
+	Videos(ids []string) ([]youtube.Video, error)
+	VideoWatchTime(ids []string, start, end time.Time) (map[string]youtube.WatchTime, error)
+}
+
+// Collect は再生リストに含まれる動画のうち、公開からmaxAge以内の動画の数値を取得します。maxAgeが0の場合はすべての動画を対象にします。
+// 視聴時間は YouTube Analytics API で取得できた場合だけ記録し、取得できなくても失敗にはしません。
+func Collect(src Source, playlistIDs []string, maxAge time.Duration, now time.Time) ([]Record, error) {
+	titles, err := src.PlaylistTitles(playlistIDs)
+	if err != nil {
+		slog.Warn("failed to get playlist titles, using playlist IDs", "error", err)
+		titles = map[string]string{}
+	}
+
+	playlists := map[string][]string{}
+	var ids []string
+	oldest := now
+	for _, playlistID := range playlistIDs {
+		items, err := src.PlaylistItems(playlistID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list playlist %s: %w", playlistID, err)
+		}
+		name := titles[playlistID]
+		if name == "" {
+			name = playlistID
+		}
+		for _, item := range items {
+			if maxAge > 0 && now.Sub(item.PublishedAt) > maxAge {
+				continue
+			}
+			if _, ok := playlists[item.VideoID]; !ok {
+				ids = append(ids, item.VideoID)
+			}
+			playlists[item.VideoID] = append(playlists[item.VideoID], name)
+			if item.PublishedAt.Before(oldest) {
+				oldest = item.PublishedAt
+			}
+		}
+	}
+	if len(ids) == 0 {
+		return nil, nil
+	}
+
+	videos, err := src.Videos(ids)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get video statistics: %w", err)
+	}
+	watchTimes, err := src.VideoWatchTime(ids, oldest, now)
+	if err != nil {
+		slog.Warn("watch time is not available from YouTube Analytics", "error", err)
+	}
+
+	records := make([]Record, 0, len(videos))
+	for _, video := range videos {
+		record := Record{
+			VideoID:     video.ID,
+			Title:       video.Title,
+			PublishedAt: video.PublishedAt,
+			Playlists:   playlists[video.ID],
+			Time:        now,
+			Views:       video.ViewCount,
+			Likes:       video.LikeCount,
+			Comments:    video.CommentCount,
+		}
+		if watchTime, ok := watchTimes[video.ID]; ok {
+			record.MinutesWatched = &watchTime.MinutesWatched
+			record.AverageViewDurationSeconds = &watchTime.AverageViewDurationSeconds
+		}
+		records = append(records, record)
+	}
+	return records, nil
+}
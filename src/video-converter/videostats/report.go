@@ -0,0 +1,209 @@
+package videostats
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+)
+
+// ReportTitle は動画レポートのタイトルです。
+const ReportTitle = "動画の伸び"
+
+// Group name used when the season is not in the video title
+const unknownSeason = "不明"
+
+// seasonPattern matches the season written in the title, such as "C6S4"
+var seasonPattern = regexp.MustCompile(`\bC\d+S\d+\b`)
+
+// VideoReport は1本の動画の集計です。
+type VideoReport struct {
+	VideoID     string
+	Title       string
+	PublishedAt time.Time
+	Season      string
+	Slot        string
+	Playlists   []string
+	// EarlyViews は公開からウィンドウの時間が経ったときの再生回数です。記録が足りない場合は Known が false になります
+	EarlyViews float64
+	Known      bool
+	// Latest は最新の記録です
+	Latest Record
+}
+
+// Group はシーズン・時間帯・再生リストごとの集計です。公開直後の再生回数が分かる動画だけを集計します。
+type Group struct {
+	Name              string
+	Videos            int
+	AverageEarlyViews float64
+}
+
+// Report は公開直後の伸びのランキングと内訳です。
+type Report struct {
+	Window    time.Duration
+	Videos    []VideoReport
+	Seasons   []Group
+	Slots     []Group
+	Playlists []Group
+}
+
+// BuildReport は記録から公開後windowの再生回数を推定し、多い順に並べます。時間帯はlocで判定します。
+func BuildReport(records []Record, window time.Duration, loc *time.Location) *Report {
+	byVideo := map[string][]Record{}
+	var order []string
+	for _, record := range records {
+		if _, ok := byVideo[record.VideoID]; !ok {
+			order = append(order, record.VideoID)
+		}
+		byVideo[record.VideoID] = append(byVideo[record.VideoID], record)
+	}
+
+	report := &Report{Window: window}
+	for _, id := range order {
+		history := byVideo[id]
+		sort.SliceStable(history, func(i, j int) bool { return history[i].Time.Before(history[j].Time) })
+		latest := history[len(history)-1]
+		early, known := earlyViews(history, window)
+		report.Videos = append(report.Videos, VideoReport{
+			VideoID:     id,
+			Title:       latest.Title,
+			PublishedAt: latest.PublishedAt.In(loc),
+			Season:      season(latest.Title),
+			Slot:        timeSlot(latest.PublishedAt.In(loc)),
+			Playlists:   latest.Playlists,
+			EarlyViews:  early,
+			Known:       known,
+			Latest:      latest,
+		})
+	}
+	sort.SliceStable(report.Videos, func(i, j int) bool {
+		a, b := report.Videos[i], report.Videos[j]
+		if a.Known != b.Known {
+			return a.Known
+		}
+		return a.EarlyViews > b.EarlyViews
+	})
+
+	report.Seasons = groupBy(report.Videos, func(v VideoReport) []string { return []string{v.Season} })
+	report.Slots = groupBy(report.Videos, func(v VideoReport) []string { return []string{v.Slot} })
+	report.Playlists = groupBy(report.Videos, func(v VideoReport) []string { return v.Playlists })
+	return report
+}
+
+// earlyViews は公開からwindowが経った時点の再生回数を、その前後の記録から線形に補間します。
+// 公開前の再生回数は0とします。window経過後の最初の記録が2×windowより後の場合は推定しません。
+func earlyViews(history []Record, window time.Duration) (float64, bool) {
+	if len(history) == 0 {
+		return 0, false
+	}
+	published := history[0].PublishedAt
+	target := published.Add(window)
+
+	prevTime, prevViews := published, int64(0)
+	for _, record := range history {
+		if record.Time.Before(target) {
+			prevTime, prevViews = record.Time, record.Views
+			continue
+		}
+		if record.Time.Sub(published) > 2*window {
+			return 0, false
+		}
+		span := record.Time.Sub(prevTime)
+		if span <= 0 {
+			return float64(record.Views), true
+		}
+		ratio := float64(target.Sub(prevTime)) / float64(span)
+		return float64(prevViews) + ratio*float64(record.Views-prevViews), true
+	}
+	return 0, false
+}
+
+func season(title string) string {
+	if s := seasonPattern.FindString(title); s != "" {
+		return s
+	}
+	return unknownSeason
+}
+
+// timeSlot は公開した時刻の時間帯です。
+func timeSlot(t time.Time) string {
+	switch hour := t.Hour(); {
+	case hour < 6:
+		return "深夜（0〜6時）"
+	case hour < 12:
+		return "朝（6〜12時）"
+	case hour < 18:
+		return "昼（12〜18時）"
+	default:
+		return "夜（18〜24時）"
+	}
+}
+
+// groupBy は公開直後の再生回数の平均が多い順にグループを返します。
+func groupBy(videos []VideoReport, keys func(VideoReport) []string) []Group {
+	sums := map[string]float64{}
+	counts := map[string]int{}
+	var names []string
+	for _, video := range videos {
+		if !video.Known {
+			continue
+		}
+		for _, key := range keys(video) {
+			if _, ok := counts[key]; !ok {
+				names = append(names, key)
+			}
+			sums[key] += video.EarlyViews
+			counts[key]++
+		}
+	}
+
+	groups := make([]Group, 0, len(names))
+	for _, name := range names {
+		groups = append(groups, Group{Name: name, Videos: counts[name], AverageEarlyViews: sums[name] / float64(counts[name])})
+	}
+	sort.SliceStable(groups, func(i, j int) bool { return groups[i].AverageEarlyViews > groups[j].AverageEarlyViews })
+	return groups
+}
+
+// Text はレポートの本文です。ランキングは上位top件を載せます。
+func (r *Report) Text(top int) string {
+	var b strings.Builder
+	hours := int(r.Window.Hours())
+	fmt.Fprintf(&b, "%s（公開後%d時間の再生回数）\n", ReportTitle, hours)
+
+	known := 0
+	for _, video := range r.Videos {
+		if video.Known {
+			known++
+		}
+	}
+	if known == 0 {
+		fmt.Fprintf(&b, "公開後%d時間の記録がある動画がまだありません（記録した動画 %d本）\n", hours, len(r.Videos))
+		return b.String()
+	}
+
+	b.WriteString("ランキング\n")
+	for i, video := range r.Videos[:min(top, known)] {
+		fmt.Fprintf(&b, "  %d. %.0f回 %s（%s）\n", i+1, video.EarlyViews, video.Title, video.PublishedAt.Format("2006-01-02 15:04"))
+		fmt.Fprintf(&b, "     現在 %d回 / 高評価 %d / コメント %d", video.Latest.Views, video.Latest.Likes, video.Latest.Comments)
+		if video.Latest.MinutesWatched != nil {
+			fmt.Fprintf(&b, " / 視聴時間 %.0f分", *video.Latest.MinutesWatched)
+		}
+		b.WriteString("\n")
+	}
+	writeGroups(&b, "シーズン別", r.Seasons)
+	writeGroups(&b, "時間帯別", r.Slots)
+	writeGroups(&b, "再生リスト別", r.Playlists)
+	return b.String()
+}
+
+func writeGroups(b *strings.Builder, title string, groups []Group) {
+	if len(groups) == 0 {
+		return
+	}
+	fmt.Fprintf(b, "%s\n", title)
+	for _, group := range groups {
+		fmt.Fprintf(b, "  %s: 平均 %.0f回（%d本）\n", group.Name, group.AverageEarlyViews, group.Videos)
+	}
+}
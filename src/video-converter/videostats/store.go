@@ -0,0 +1,95 @@
+// Package videostats は動画ごとの再生回数などを定期的に記録し、公開直後の伸びをシーズン・時間帯・再生リスト別に集計します。
+package videostats
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+)
+
+// Record はある時点の動画の数値です。
+type Record struct {
+	VideoID     string    `json:"video_id"`
+	Title       string    `json:"title"`
+	PublishedAt time.Time `json:"published_at"`
+	// Playlists は動画が含まれる再生リストのタイトルです
+	Playlists []string  `json:"playlists,omitempty"`
+	Time      time.Time `json:"time"`
+	Views     int64     `json:"views"`
+	Likes     int64     `json:"likes"`
+	Comments  int64     `json:"comments"`
+	// MinutesWatched と AverageViewDurationSeconds は YouTube Analytics API で取得できた場合だけ記録します
+	MinutesWatched             *float64 `json:"minutes_watched,omitempty"`
+	AverageViewDurationSeconds *float64 `json:"average_view_duration_seconds,omitempty"`
+}
+
+// Store はレコードを1行1件のJSON Linesで追記する時系列ストアです。
+type Store struct {
+	path string
+}
+
+// NewStore はpathのファイルを使うストアを作成します。
+func NewStore(path string) *Store {
+	return &Store{path: path}
+}
+
+// Load はすべてのレコードを記録した時刻の順に返します。ファイルがない場合は空を返します。
+func (s *Store) Load() ([]Record, error) {
+	file, err := os.Open(s.path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to open video stats store: %w", err)
+	}
+	defer func() { _ = file.Close() }()
+
+	var records []Record
+	scanner := bufio.NewScanner(file)
+	for line := 1; scanner.Scan(); line++ {
+		if len(scanner.Bytes()) == 0 {
+			continue
+		}
+		var record Record
+		if err := json.Unmarshal(scanner.Bytes(), &record); err != nil {
+			return nil, fmt.Errorf("invalid record at line %d: %w", line, err)
+		}
+		records = append(records, record)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	sort.SliceStable(records, func(i, j int) bool { return records[i].Time.Before(records[j].Time) })
+	return records, nil
+}
+
+// Append はレコードを追記します。
+func (s *Store) Append(records []Record) error {
+	if err := os.MkdirAll(filepath.Dir(s.path), 0o755); err != nil {
+		return err
+	}
+	file, err := os.OpenFile(s.path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o644)
+	if err != nil {
+		return fmt.Errorf("failed to open video stats store: %w", err)
+	}
+
+	writer := bufio.NewWriter(file)
+	encoder := json.NewEncoder(writer)
+	for _, record := range records {
+		if err := encoder.Encode(record); err != nil {
+			_ = file.Close()
+			return err
+		}
+	}
+	if err := writer.Flush(); err != nil {
+		_ = file.Close()
+		return err
+	}
+	return file.Close()
+}
@@ -0,0 +1,182 @@
+package videostats
+
+import (
+	"errors"
+	"math"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"thiroyoshi.com/video-converter/youtube"
+)
+
+var jst = time.FixedZone("JST", 9*60*60)
+
+type fakeSource struct {
+	items        map[string][]youtube.PlaylistVideo
+	videos       map[string]youtube.Video
+	watchTimeErr error
+	requested    []string
+}
+
+func (f *fakeSource) PlaylistItems(playlistID string) ([]youtube.PlaylistVideo, error) {
+	return f.items[playlistID], nil
+}
+
+func (f *fakeSource) PlaylistTitles(ids []string) (map[string]string, error) {
+	return map[string]string{"PL1": "ノーカット"}, nil
+}
+
+func (f *fakeSource) Videos(ids []string) ([]youtube.Video, error) {
+	f.requested = ids
+	var videos []youtube.Video
+	for _, id := range ids {
+		videos = append(videos, f.videos[id])
+	}
+	return videos, nil
+}
+
+func (f *fakeSource) VideoWatchTime(ids []string, start, end time.Time) (map[string]youtube.WatchTime, error) {
+	if f.watchTimeErr != nil {
+		return nil, f.watchTimeErr
+	}
+	return map[string]youtube.WatchTime{"v1": {MinutesWatched: 30, AverageViewDurationSeconds: 60}}, nil
+}
+
+func TestCollect(t *testing.T) {
+	t.Parallel()
+
+	now := time.Date(2025, 5, 10, 12, 0, 0, 0, time.UTC)
+	newSource := func() *fakeSource {
+		return &fakeSource{
+			items: map[string][]youtube.PlaylistVideo{
+				"PL1": {{VideoID: "v1", PublishedAt: now.AddDate(0, 0, -1)}, {VideoID: "old", PublishedAt: now.AddDate(0, 0, -60)}},
+				"PL2": {{VideoID: "v1", PublishedAt: now.AddDate(0, 0, -1)}, {VideoID: "v2", PublishedAt: now.AddDate(0, 0, -2)}},
+			},
+			videos: map[string]youtube.Video{
+				"v1": {ID: "v1", Title: "one", ViewCount: 100, LikeCount: 5, CommentCount: 1},
+				"v2": {ID: "v2", Title: "two", ViewCount: 50},
+			},
+		}
+	}
+
+	tests := []struct {
+		name          string
+		watchTimeErr  error
+		wantWatchTime bool
+	}{
+		{name: "正常系: 視聴時間あり", wantWatchTime: true},
+		{name: "正常系: Analytics APIが使えない場合は視聴時間なし", watchTimeErr: errors.New("forbidden"), wantWatchTime: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			src := newSource()
+			src.watchTimeErr = tt.watchTimeErr
+			records, err := Collect(src, []string{"PL1", "PL2"}, 30*24*time.Hour, now)
+			if err != nil {
+				t.Fatalf("Collect() error = %v", err)
+			}
+			// 古い動画は対象外で、複数の再生リストに含まれる動画は1回だけ取得する
+			if strings.Join(src.requested, ",") != "v1,v2" || len(records) != 2 {
+				t.Fatalf("requested = %v, records = %+v", src.requested, records)
+			}
+			v1 := records[0]
+			if strings.Join(v1.Playlists, ",") != "ノーカット,PL2" || v1.Views != 100 || !v1.Time.Equal(now) {
+				t.Errorf("record = %+v", v1)
+			}
+			if (v1.MinutesWatched != nil) != tt.wantWatchTime || records[1].MinutesWatched != nil {
+				t.Errorf("minutes watched = %v, %v", v1.MinutesWatched, records[1].MinutesWatched)
+			}
+		})
+	}
+}
+
+func TestEarlyViews(t *testing.T) {
+	t.Parallel()
+
+	published := time.Date(2025, 5, 1, 12, 0, 0, 0, time.UTC)
+	record := func(hours float64, views int64) Record {
+		return Record{PublishedAt: published, Time: published.Add(time.Duration(hours * float64(time.Hour))), Views: views}
+	}
+
+	tests := []struct {
+		name      string
+		history   []Record
+		want      float64
+		wantKnown bool
+	}{
+		{name: "正常系: 前後の記録から補間", history: []Record{record(12, 100), record(36, 300)}, want: 200, wantKnown: true},
+		{name: "正常系: ちょうど24時間", history: []Record{record(24, 250)}, want: 250, wantKnown: true},
+		{name: "正常系: 前の記録がなければ公開時を0とする", history: []Record{record(30, 500)}, want: 400, wantKnown: true},
+		{name: "異常系: まだ24時間経っていない", history: []Record{record(6, 50)}, wantKnown: false},
+		{name: "異常系: 24時間後の記録が遠すぎる", history: []Record{record(6, 50), record(72, 900)}, wantKnown: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			got, known := earlyViews(tt.history, 24*time.Hour)
+			if known != tt.wantKnown || math.Abs(got-tt.want) > 0.001 {
+				t.Errorf("earlyViews() = %v, %v, want %v, %v", got, known, tt.want, tt.wantKnown)
+			}
+		})
+	}
+}
+
+func TestReport(t *testing.T) {
+	t.Parallel()
+
+	store := NewStore(filepath.Join(t.TempDir(), "videos.jsonl"))
+	video := func(id, title string, published time.Time, playlists []string, views ...int64) []Record {
+		var records []Record
+		for i, v := range views {
+			records = append(records, Record{VideoID: id, Title: title, PublishedAt: published, Playlists: playlists, Time: published.Add(time.Duration(i+1) * 12 * time.Hour), Views: v})
+		}
+		return records
+	}
+	morning := time.Date(2025, 5, 1, 8, 0, 0, 0, jst)
+	night := time.Date(2025, 5, 2, 21, 0, 0, 0, jst)
+	for _, records := range [][]Record{
+		video("a", "No-Cut Fortnite C6S4 GABA's Gameplay", morning, []string{"ノーカット"}, 50, 100, 150),
+		video("b", "No-Cut Fortnite C6S3 GABA's Gameplay", night, []string{"ノーカット", "ショート"}, 200, 300),
+		video("c", "No-Cut Fortnite C6S4 GABA's Gameplay", night, []string{"ショート"}, 100, 200),
+		video("d", "Fortnite", night, []string{"ショート"}, 10),
+	} {
+		if err := store.Append(records); err != nil {
+			t.Fatalf("Append() error = %v", err)
+		}
+	}
+	records, err := store.Load()
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+
+	report := BuildReport(records, 24*time.Hour, jst)
+	var order []string
+	for _, v := range report.Videos {
+		order = append(order, v.VideoID)
+	}
+	// 24時間後の記録がない動画は最後
+	if strings.Join(order, ",") != "b,c,a,d" || report.Videos[3].Known {
+		t.Errorf("order = %v", order)
+	}
+
+	text := report.Text(2)
+	for _, want := range []string{
+		"1. 300回 No-Cut Fortnite C6S3 GABA's Gameplay（2025-05-02 21:00）",
+		"2. 200回",
+		"  C6S3: 平均 300回（1本）\n  C6S4: 平均 150回（2本）",
+		"  夜（18〜24時）: 平均 250回（2本）\n  朝（6〜12時）: 平均 100回（1本）",
+		"  ショート: 平均 250回（2本）\n  ノーカット: 平均 200回（2本）",
+	} {
+		if !strings.Contains(text, want) {
+			t.Errorf("Text() does not contain %q\n%s", want, text)
+		}
+	}
+	if strings.Contains(text, "3. ") {
+		t.Errorf("Text() should list top 2 only\n%s", text)
+	}
+}
@@ -0,0 +1,83 @@
+package x
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+)
+
+// RateLimitError はX APIのレート制限に達したことを表します。Resetは制限が解除される時刻です。
+type RateLimitError struct {
+	Reset time.Time
+}
+
+func (e *RateLimitError) Error() string {
+	return fmt.Sprintf("X API rate limit exceeded until %s", e.Reset.Format(time.RFC3339))
+}
+
+func (c *Client) getJSON(endpoint string, params url.Values, out any) error {
+	if len(params) > 0 {
+		endpoint += "?" + params.Encode()
+	}
+	return c.doJSON("GET", endpoint, nil, out)
+}
+
+// doJSON はX APIにリクエストを送り、レスポンスをoutにデコードします。payloadがnilでなければJSONで送ります。
+func (c *Client) doJSON(method, endpoint string, payload, out any) error {
+	var body io.Reader
+	if payload != nil {
+		data, err := json.Marshal(payload)
+		if err != nil {
+			return err
+		}
+		body = bytes.NewReader(data)
+	}
+
+	req, err := http.NewRequest(method, endpoint, body)
+	if err != nil {
+		return err
+	}
+	if payload != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err := resp.Body.Close(); err != nil {
+			slog.Error("Failed to close response body", "error", err)
+		}
+	}()
+
+	respBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+	if resp.StatusCode == http.StatusTooManyRequests {
+		return &RateLimitError{Reset: rateLimitReset(resp.Header)}
+	}
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		slog.Error("X API error response", "status", resp.Status, "body", string(respBody))
+		return fmt.Errorf("X API returned unexpected status code: %d", resp.StatusCode)
+	}
+	if out == nil {
+		return nil
+	}
+	return json.Unmarshal(respBody, out)
+}
+
+// rateLimitReset は x-rate-limit-reset ヘッダーのUNIX時刻を返します。ヘッダーがない場合は15分後とします。
+func rateLimitReset(header http.Header) time.Time {
+	if sec, err := strconv.ParseInt(header.Get("x-rate-limit-reset"), 10, 64); err == nil {
+		return time.Unix(sec, 0)
+	}
+	return time.Now().Add(15 * time.Minute)
+}
@@ -0,0 +1,149 @@
+package x
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+
+	"github.com/dghubble/oauth1"
+	"thiroyoshi.com/video-converter/secret"
+)
+
+const (
+	twitterAPIEndpoint  = "https://api.twitter.com/2/tweets"
+	usersEndpoint       = "https://api.twitter.com/2/users"
+	mediaUploadEndpoint = "https://upload.twitter.com/1.1/media/upload.json"
+)
+
+// Tweet はXのツイートデータを表す構造体です。ツイートの本文とその他のメタデータを含みます。
+type Tweet struct {
+	Text  string      `json:"text"`
+	Media *TweetMedia `json:"media,omitempty"`
+	Reply *TweetReply `json:"reply,omitempty"`
+}
+
+// TweetMedia はツイートに添付するメディアです。
+type TweetMedia struct {
+	MediaIDs []string `json:"media_ids"`
+}
+
+// TweetReply は返信先のツイートです。スレッドを作るときに使います。
+type TweetReply struct {
+	InReplyToTweetID string `json:"in_reply_to_tweet_id"`
+}
+
+// Client はX APIのクライアントです。
+type Client struct {
+	httpClient *http.Client
+	// downloadClient fetches media to attach without the X credentials
+	downloadClient *http.Client
+	tweetEndpoint  string
+	uploadEndpoint string
+	usersEndpoint  string
+}
+
+// NewClient はXのクライアントを作成します。
+// OAuth 2.0の設定と保存済みのトークンがあればOAuth 2.0を使い、なければOAuth 1.0aで認証します。
+// どちらの認証情報もない場合はエラーを返します。
+func NewClient() (*Client, error) {
+	provider := secret.FromEnv()
+	if config, ok := OAuth2ConfigFromEnv(); ok {
+		store := NewTokenStore(provider)
+		_, err := store.Load()
+		if err == nil {
+			return NewOAuth2Client(config, store), nil
+		}
+		slog.Warn("X OAuth2 token not available, falling back to OAuth1", "error", err)
+	}
+
+	credentials, err := LoadOAuth1Credentials(provider)
+	if err != nil {
+		return nil, err
+	}
+	return NewOAuth1Client(credentials), nil
+}
+
+// NewOAuth1Client はOAuth 1.0aで認証するクライアントを作成します。
+func NewOAuth1Client(credentials OAuth1Credentials) *Client {
+	config := oauth1.NewConfig(credentials.APIKey, credentials.APISecretKey)
+	token := oauth1.NewToken(credentials.AccessToken, credentials.AccessTokenSecret)
+	return &Client{
+		httpClient:     config.Client(oauth1.NoContext, token),
+		downloadClient: &http.Client{Timeout: mediaDownloadTimeout},
+		tweetEndpoint:  twitterAPIEndpoint,
+		uploadEndpoint: mediaUploadEndpoint,
+		usersEndpoint:  usersEndpoint,
+	}
+}
+
+// NewOAuth2Client はOAuth 2.0のユーザーコンテキストで認証するクライアントを作成します。
+// アクセストークンの期限が切れると自動で更新し、ローテーションされたトークンをstoreに保存します。
+func NewOAuth2Client(config *OAuth2Config, store *TokenStore) *Client {
+	return &Client{
+		httpClient:     &http.Client{Transport: &oauth2Transport{config: config, store: store}},
+		downloadClient: &http.Client{Timeout: mediaDownloadTimeout},
+		tweetEndpoint:  twitterAPIEndpoint,
+		uploadEndpoint: mediaUploadV2Endpoint,
+		usersEndpoint:  usersEndpoint,
+	}
+}
+
+// Post はツイートを投稿し、作成されたツイートのIDを返します。
+func (c *Client) Post(tweet Tweet) (string, error) {
+	// Marshal the Tweet struct to JSON
+	jsonData, err := json.Marshal(tweet)
+	if err != nil {
+		slog.Error("Failed to marshal tweet data", "error", err)
+		return "", err
+	}
+
+	// POSTリクエストを作成
+	req, err := http.NewRequest("POST", c.tweetEndpoint, bytes.NewBuffer(jsonData))
+	if err != nil {
+		slog.Error("Failed to create request", "error", err)
+		return "", err
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+
+	// リクエストを送信
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		slog.Error("Failed to send request", "error", err)
+		return "", err
+	}
+	defer func() {
+		if err := resp.Body.Close(); err != nil {
+			slog.Error("Failed to close response body", "error", err)
+		}
+	}()
+
+	// レスポンスを読み取る
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		slog.Error("Failed to read response", "error", err)
+		return "", err
+	}
+
+	// 結果を表示
+	slog.Info("X API post response", "status", resp.Status, "body", string(body))
+
+	if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusCreated {
+		return "", fmt.Errorf("X API returned unexpected status code: %d", resp.StatusCode)
+	}
+
+	var created struct {
+		Data struct {
+			ID string `json:"id"`
+		} `json:"data"`
+	}
+	if err := json.Unmarshal(body, &created); err != nil {
+		slog.Error("Failed to parse X API response", "error", err)
+		return "", err
+	}
+
+	return created.Data.ID, nil
+}
@@ -0,0 +1,108 @@
+package x
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+	"text/template"
+	"time"
+)
+
+// TweetData はツイートのテンプレートに埋め込む値です。
+type TweetData struct {
+	Title    string
+	URL      string
+	Hashtags string
+}
+
+// Composer はテンプレートからツイート本文を作ります。
+// 複数のテンプレートを登録すると、RotateByDate で投稿日ごとに順番に切り替えます。
+type Composer struct {
+	templates []*template.Template
+	hashtags  []string
+	// reserved is the weighted length kept free for text added after composing
+	reserved int
+	// variant is the index of the template to use, modulo the number of templates
+	variant int
+}
+
+// NewComposer はテンプレートとハッシュタグを指定してComposerを作成します。
+// テンプレートではtext/templateの{{.Title}}、{{.URL}}、{{.Hashtags}}を使えます。
+func NewComposer(templates []string, hashtags []string) (*Composer, error) {
+	if len(templates) == 0 {
+		return nil, fmt.Errorf("no tweet templates")
+	}
+
+	c := &Composer{hashtags: hashtags}
+	for i, text := range templates {
+		tmpl, err := template.New(fmt.Sprintf("tweet-%d", i)).Option("missingkey=error").Parse(text)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse tweet template %d: %w", i, err)
+		}
+		c.templates = append(c.templates, tmpl)
+	}
+	return c, nil
+}
+
+// LoadTemplates はJSON配列で書かれたテンプレートをファイルから読み込みます。
+func LoadTemplates(path string) ([]string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read tweet templates: %w", err)
+	}
+	var templates []string
+	if err := json.Unmarshal(data, &templates); err != nil {
+		return nil, fmt.Errorf("failed to parse tweet templates: %w", err)
+	}
+	return templates, nil
+}
+
+// Reserve は本文を作った後に付け足す文字列（広告の表示など）の分だけ、本文の文字数の上限を減らします。
+func (c *Composer) Reserve(text string) {
+	c.reserved = WeightedLength(text)
+}
+
+// RotateByDate は投稿日からテンプレートを選びます。日が変わるごとに次のテンプレートを使い、同じ日は同じテンプレートを使います。
+func (c *Composer) RotateByDate(t time.Time) {
+	y, m, d := t.Date()
+	c.variant = int(time.Date(y, m, d, 0, 0, 0, 0, time.UTC).Unix() / (24 * 60 * 60))
+}
+
+// Compose はテンプレートから本文を作ります。
+// 重み付き文字数が上限を超える場合はタイトルを切り詰め、それでも収まらない場合はエラーを返します。
+func (c *Composer) Compose(title, url string) (string, error) {
+	limit := MaxWeightedLength - c.reserved
+	tmpl := c.templates[c.variant%len(c.templates)]
+	data := TweetData{Title: title, URL: url, Hashtags: strings.Join(c.hashtags, " ")}
+
+	text, err := execute(tmpl, data)
+	if err != nil {
+		return "", err
+	}
+	if WeightedLength(text) <= limit {
+		return text, nil
+	}
+
+	// Shorten the title by the overflow
+	data.Title = ""
+	withoutTitle, err := execute(tmpl, data)
+	if err != nil {
+		return "", err
+	}
+	budget := limit - WeightedLength(withoutTitle)
+	if budget <= 0 {
+		return "", fmt.Errorf("tweet is too long without title: %d", WeightedLength(withoutTitle))
+	}
+
+	data.Title = Truncate(title, budget)
+	return execute(tmpl, data)
+}
+
+func execute(tmpl *template.Template, data TweetData) (string, error) {
+	var b strings.Builder
+	if err := tmpl.Execute(&b, data); err != nil {
+		return "", fmt.Errorf("failed to execute tweet template: %w", err)
+	}
+	return strings.TrimSpace(b.String()), nil
+}
@@ -0,0 +1,112 @@
+package x
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestComposerCompose(t *testing.T) {
+	t.Parallel()
+
+	templates := []string{
+		"ブログを更新しました！\n{{.Hashtags}}\n\n{{.Title}}\n{{.URL}}",
+		"{{.Title}}\n{{.URL}}",
+	}
+	composer, err := NewComposer(templates, []string{"#Fortnite", "#フォートナイト"})
+	if err != nil {
+		t.Fatalf("NewComposer() error = %v", err)
+	}
+
+	t.Run("選ばれたテンプレートが使われる", func(t *testing.T) {
+		composer.variant = 1
+		got, err := composer.Compose("タイトル", "https://example.com")
+		if err != nil {
+			t.Fatalf("Compose() error = %v", err)
+		}
+		if got != "タイトル\nhttps://example.com" {
+			t.Errorf("Compose() = %q", got)
+		}
+	})
+
+	t.Run("長いタイトルは上限に収まるよう切り詰められる", func(t *testing.T) {
+		composer.variant = 0
+		title := strings.Repeat("フォートナイト", 30)
+		got, err := composer.Compose(title, "https://example.com/entry/2025/05/01/120000")
+		if err != nil {
+			t.Fatalf("Compose() error = %v", err)
+		}
+		if WeightedLength(got) > MaxWeightedLength {
+			t.Errorf("Compose() length = %d, want <= %d", WeightedLength(got), MaxWeightedLength)
+		}
+		if !strings.Contains(got, "…\nhttps://example.com/entry/2025/05/01/120000") {
+			t.Errorf("Compose() = %q, want truncated title followed by URL", got)
+		}
+		if !strings.HasPrefix(got, "ブログを更新しました！\n#Fortnite #フォートナイト") {
+			t.Errorf("Compose() = %q, want hashtags kept", got)
+		}
+	})
+
+	t.Run("後から付け足す文字列の分を空けて切り詰められる", func(t *testing.T) {
+		reserved, err := NewComposer(templates, []string{"#Fortnite"})
+		if err != nil {
+			t.Fatalf("NewComposer() error = %v", err)
+		}
+		reserved.variant = 1
+		reserved.Reserve("#PR\n")
+		got, err := reserved.Compose(strings.Repeat("フォートナイト", 30), "https://example.com")
+		if err != nil {
+			t.Fatalf("Compose() error = %v", err)
+		}
+		if length := WeightedLength("#PR\n" + got); length != MaxWeightedLength {
+			t.Errorf("length with reserved text = %d, want %d", length, MaxWeightedLength)
+		}
+	})
+}
+
+func TestComposerRotateByDate(t *testing.T) {
+	t.Parallel()
+
+	composer, err := NewComposer([]string{"A {{.URL}}", "B {{.URL}}", "C {{.URL}}"}, nil)
+	if err != nil {
+		t.Fatalf("NewComposer() error = %v", err)
+	}
+	jst := time.FixedZone("JST", 9*60*60)
+	compose := func(at time.Time) string {
+		composer.RotateByDate(at)
+		got, err := composer.Compose("タイトル", "u")
+		if err != nil {
+			t.Fatalf("Compose() error = %v", err)
+		}
+		return got
+	}
+
+	// Posts on Monday, Wednesday and Friday use every template in turn
+	monday := time.Date(2025, 5, 5, 8, 0, 0, 0, jst)
+	seen := map[string]bool{}
+	for _, days := range []int{0, 2, 4} {
+		seen[compose(monday.AddDate(0, 0, days))] = true
+	}
+	if len(seen) != 3 {
+		t.Errorf("templates used on Mon/Wed/Fri = %v, want all 3", seen)
+	}
+
+	// Runs on the same day in JST use the same template
+	if first, retry := compose(monday), compose(monday.Add(15*time.Hour)); first != retry {
+		t.Errorf("Compose() on the same day = %q and %q", first, retry)
+	}
+	if got, next := compose(monday), compose(monday.AddDate(0, 0, 1)); got == next {
+		t.Errorf("Compose() on consecutive days = %q, want different templates", got)
+	}
+}
+
+func TestNewComposerInvalidTemplate(t *testing.T) {
+	t.Parallel()
+
+	if _, err := NewComposer(nil, nil); err == nil {
+		t.Errorf("NewComposer(nil) error = nil, want error")
+	}
+	if _, err := NewComposer([]string{"{{.Title"}, nil); err == nil {
+		t.Errorf("NewComposer(invalid) error = nil, want error")
+	}
+}
@@ -0,0 +1,88 @@
+package x
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+// DigestTitle は週次まとめのタイトルです。
+const DigestTitle = "今週のGABAのX"
+
+// Number of tweets shown as the top posts
+const digestTopCount = 3
+
+// Digest は期間中のツイートの集計です。
+type Digest struct {
+	Start time.Time
+	End   time.Time
+
+	Posts    int
+	Replies  int
+	Quotes   int
+	Retweets int
+
+	Likes       int
+	Reposts     int
+	Impressions int
+
+	// Top は自分のポストと引用をエンゲージメントの多い順に並べたものです
+	Top []TimelineTweet
+}
+
+// NewDigest はツイートを集計します。リポストは件数だけ数え、指標には含めません。
+func NewDigest(tweets []TimelineTweet, start, end time.Time) *Digest {
+	d := &Digest{Start: start, End: end}
+
+	var own []TimelineTweet
+	for _, tweet := range tweets {
+		switch tweet.Kind() {
+		case "retweet":
+			d.Retweets++
+			continue
+		case "reply":
+			d.Replies++
+		case "quote":
+			d.Quotes++
+			own = append(own, tweet)
+		default:
+			d.Posts++
+			own = append(own, tweet)
+		}
+		d.Likes += tweet.PublicMetrics.LikeCount
+		d.Reposts += tweet.PublicMetrics.RetweetCount
+		d.Impressions += tweet.PublicMetrics.ImpressionCount
+	}
+
+	sort.SliceStable(own, func(i, j int) bool {
+		return own[i].PublicMetrics.Engagement() > own[j].PublicMetrics.Engagement()
+	})
+	if len(own) > digestTopCount {
+		own = own[:digestTopCount]
+	}
+	d.Top = own
+	return d
+}
+
+// Period は集計期間を「2025/04/14〜2025/04/20」の形式で返します。
+func (d *Digest) Period() string {
+	return fmt.Sprintf("%s〜%s", d.Start.Format("2006/01/02"), d.End.Add(-time.Second).Format("2006/01/02"))
+}
+
+// Text は週次まとめの本文です。Slackの通知やブログのプロンプトにそのまま使えます。
+func (d *Digest) Text() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "%s（%s）\n", DigestTitle, d.Period())
+	fmt.Fprintf(&b, "ポスト %d件 / 返信 %d件 / 引用 %d件 / リポスト %d件\n", d.Posts, d.Replies, d.Quotes, d.Retweets)
+	fmt.Fprintf(&b, "いいね %d / リポストされた数 %d / インプレッション %d\n", d.Likes, d.Reposts, d.Impressions)
+
+	if len(d.Top) > 0 {
+		b.WriteString("\n反応が多かったポスト\n")
+		for i, tweet := range d.Top {
+			text := strings.Join(strings.Fields(tweet.Text), " ")
+			fmt.Fprintf(&b, "%d. %s（いいね %d / リポスト %d / %s）\n", i+1, Truncate(text, 120), tweet.PublicMetrics.LikeCount, tweet.PublicMetrics.RetweetCount, tweet.URL())
+		}
+	}
+	return b.String()
+}
@@ -0,0 +1,53 @@
+package x
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestNewDigest(t *testing.T) {
+	t.Parallel()
+
+	jst := time.FixedZone("JST", 9*60*60)
+	start := time.Date(2025, 4, 14, 0, 0, 0, 0, jst)
+	end := start.AddDate(0, 0, 7)
+
+	tweets := []TimelineTweet{
+		{ID: "1", Text: "動画をアップしました！", PublicMetrics: PublicMetrics{LikeCount: 5, RetweetCount: 1, ImpressionCount: 100}},
+		{ID: "2", Text: "ブログを更新しました！\n#Fortnite", PublicMetrics: PublicMetrics{LikeCount: 20, RetweetCount: 4, ImpressionCount: 900}},
+		{ID: "3", Text: "@friend ナイス！", PublicMetrics: PublicMetrics{LikeCount: 1}, ReferencedTweets: []ReferencedTweet{{Type: "replied_to", ID: "8"}}},
+		{ID: "4", Text: "RT @FortniteJP: 新スキン", PublicMetrics: PublicMetrics{LikeCount: 1000}, ReferencedTweets: []ReferencedTweet{{Type: "retweeted", ID: "9"}}},
+		{ID: "5", Text: "これすごい", PublicMetrics: PublicMetrics{LikeCount: 2}, ReferencedTweets: []ReferencedTweet{{Type: "quoted", ID: "7"}}},
+		{ID: "6", Text: "おはよう", PublicMetrics: PublicMetrics{}},
+	}
+
+	d := NewDigest(tweets, start, end)
+
+	if d.Posts != 3 || d.Replies != 1 || d.Quotes != 1 || d.Retweets != 1 {
+		t.Errorf("counts = posts %d, replies %d, quotes %d, retweets %d", d.Posts, d.Replies, d.Quotes, d.Retweets)
+	}
+	// リポストしたツイートの指標は含めない
+	if d.Likes != 28 || d.Reposts != 5 || d.Impressions != 1000 {
+		t.Errorf("metrics = likes %d, reposts %d, impressions %d", d.Likes, d.Reposts, d.Impressions)
+	}
+
+	var topIDs []string
+	for _, tweet := range d.Top {
+		topIDs = append(topIDs, tweet.ID)
+	}
+	if strings.Join(topIDs, ",") != "2,1,5" {
+		t.Errorf("Top = %v, want [2 1 5]", topIDs)
+	}
+
+	text := d.Text()
+	for _, want := range []string{
+		"今週のGABAのX（2025/04/14〜2025/04/20）",
+		"ポスト 3件 / 返信 1件 / 引用 1件 / リポスト 1件",
+		"1. ブログを更新しました！ #Fortnite（いいね 20 / リポスト 4 / https://x.com/i/web/status/2）",
+	} {
+		if !strings.Contains(text, want) {
+			t.Errorf("Text() = %q, want to contain %q", text, want)
+		}
+	}
+}
@@ -0,0 +1,97 @@
+package x
+
+import (
+	"regexp"
+	"unicode/utf8"
+)
+
+const (
+	// MaxWeightedLength はツイートの最大の重み付き文字数です。
+	MaxWeightedLength = 280
+	// URLはt.coで短縮されるため、長さに関わらずこの文字数として数えられます。
+	transformedURLLength = 23
+)
+
+// Character ranges counted with weight 1 by twitter-text. Everything else counts as 2.
+var lightRanges = [][2]rune{
+	{0, 4351},
+	{8192, 8205},
+	{8208, 8223},
+	{8242, 8247},
+}
+
+var urlPattern = regexp.MustCompile(`https?://[^\s　]+`)
+
+// WeightedLength はtwitter-textのルールに従ってツイートの重み付き文字数を数えます。
+// CJKなどの文字は2、URLは23として数えます。絵文字はZWJや異体字セレクタで連結されたものを含めて2と数えます。
+func WeightedLength(text string) int {
+	length := 0
+	last := 0
+	for _, loc := range urlPattern.FindAllStringIndex(text, -1) {
+		length += textWeight(text[last:loc[0]]) + transformedURLLength
+		last = loc[1]
+	}
+	return length + textWeight(text[last:])
+}
+
+// Truncate は重み付き文字数がmaxを超える場合に末尾を「…」にして切り詰めます。
+func Truncate(text string, max int) string {
+	if WeightedLength(text) <= max {
+		return text
+	}
+
+	const ellipsis = "…"
+	runes := []rune(text)
+	// Find the longest prefix that fits with the ellipsis
+	lo, hi := 0, len(runes)
+	for lo < hi {
+		mid := (lo + hi + 1) / 2
+		if WeightedLength(string(runes[:mid])+ellipsis) <= max {
+			lo = mid
+		} else {
+			hi = mid - 1
+		}
+	}
+	if lo == 0 {
+		return ""
+	}
+	return string(runes[:lo]) + ellipsis
+}
+
+func textWeight(text string) int {
+	weight := 0
+	joined := false
+	for len(text) > 0 {
+		r, size := utf8.DecodeRuneInString(text)
+		text = text[size:]
+
+		switch {
+		case r == 0x200d:
+			// Zero width joiner: the next emoji is part of the same sequence
+			joined = true
+			continue
+		case r >= 0xfe00 && r <= 0xfe0f, r >= 0x1f3fb && r <= 0x1f3ff:
+			// Variation selectors and skin tone modifiers
+			continue
+		case joined && isEmoji(r):
+			joined = false
+			continue
+		}
+		joined = false
+		weight += runeWeight(r)
+	}
+	return weight
+}
+
+func runeWeight(r rune) int {
+	for _, lr := range lightRanges {
+		if r >= lr[0] && r <= lr[1] {
+			return 1
+		}
+	}
+	return 2
+}
+
+func isEmoji(r rune) bool {
+	return (r >= 0x1f000 && r <= 0x1faff) || (r >= 0x2600 && r <= 0x27bf)
+}
@@ -0,0 +1,52 @@
+package x
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestWeightedLength(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name string
+		text string
+		want int
+	}{
+		{name: "ASCII", text: "Fortnite", want: 8},
+		{name: "日本語は2文字として数える", text: "フォートナイト", want: 14},
+		{name: "URLは23文字として数える", text: "見て https://www.youtube.com/watch?v=abcdefghijk", want: 4 + 1 + 23},
+		{name: "短いURLも23文字として数える", text: "https://x.co", want: 23},
+		{name: "改行は1文字", text: "a\nb", want: 3},
+		{name: "絵文字は2文字", text: "🎮", want: 2},
+		{name: "ZWJで連結された絵文字は2文字", text: "👨‍👩‍👧", want: 2},
+		{name: "肌の色の修飾子は数えない", text: "👍🏽", want: 2},
+		{name: "全角記号", text: "！", want: 2},
+	}
+
+	for _, tc := range tests {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+			if got := WeightedLength(tc.text); got != tc.want {
+				t.Errorf("WeightedLength(%q) = %d, want %d", tc.text, got, tc.want)
+			}
+		})
+	}
+}
+
+func TestTruncate(t *testing.T) {
+	t.Parallel()
+
+	if got := Truncate("short", 10); got != "short" {
+		t.Errorf("Truncate() = %q, want unchanged", got)
+	}
+
+	got := Truncate(strings.Repeat("あ", 10), 10)
+	if got != "ああああ…" {
+		t.Errorf("Truncate() = %q, want %q", got, "ああああ…")
+	}
+	if WeightedLength(got) > 10 {
+		t.Errorf("Truncate() length = %d, want <= 10", WeightedLength(got))
+	}
+}
@@ -0,0 +1,232 @@
+package x
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"mime/multipart"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	// Size of each APPEND segment. The API accepts up to 5MB per segment.
+	mediaChunkSize = 1024 * 1024
+	// Maximum number of STATUS checks while media is being processed
+	mediaStatusMaxChecks = 10
+	// Timeout for downloading media given by URL
+	mediaDownloadTimeout = 30 * time.Second
+)
+
+type processingInfo struct {
+	State          string `json:"state"`
+	CheckAfterSecs int    `json:"check_after_secs"`
+	Error          *struct {
+		Message string `json:"message"`
+	} `json:"error"`
+}
+
+// mediaResponse covers both the v1.1 response and the v2 response wrapped in "data"
+type mediaResponse struct {
+	MediaIDString  string          `json:"media_id_string"`
+	ProcessingInfo *processingInfo `json:"processing_info"`
+	Data           *struct {
+		ID             string          `json:"id"`
+		ProcessingInfo *processingInfo `json:"processing_info"`
+	} `json:"data"`
+}
+
+// UploadMedia はINIT/APPEND/FINALIZE/STATUSのチャンクアップロードでメディアをアップロードし、media_idを返します。
+func (c *Client) UploadMedia(data []byte, mediaType string) (string, error) {
+	// INIT
+	initResp, err := c.mediaCommand(url.Values{
+		"command":        {"INIT"},
+		"total_bytes":    {strconv.Itoa(len(data))},
+		"media_type":     {mediaType},
+		"media_category": {mediaCategory(mediaType)},
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to init media upload: %w", err)
+	}
+	mediaID := initResp.MediaIDString
+	if mediaID == "" {
+		return "", fmt.Errorf("media_id not found in INIT response")
+	}
+
+	// APPEND
+	for segment := 0; segment*mediaChunkSize < len(data); segment++ {
+		start := segment * mediaChunkSize
+		end := min(start+mediaChunkSize, len(data))
+		if err := c.appendMedia(mediaID, segment, data[start:end]); err != nil {
+			return "", fmt.Errorf("failed to append media segment %d: %w", segment, err)
+		}
+	}
+
+	// FINALIZE
+	status, err := c.mediaCommand(url.Values{
+		"command":  {"FINALIZE"},
+		"media_id": {mediaID},
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to finalize media upload: %w", err)
+	}
+
+	// STATUS
+	for i := 0; status.ProcessingInfo != nil; i++ {
+		switch status.ProcessingInfo.State {
+		case "succeeded":
+			slog.Info("Media processing succeeded", "media_id", mediaID)
+			return mediaID, nil
+		case "failed":
+			message := "unknown error"
+			if status.ProcessingInfo.Error != nil {
+				message = status.ProcessingInfo.Error.Message
+			}
+			return "", fmt.Errorf("media processing failed: %s", message)
+		}
+		if i >= mediaStatusMaxChecks {
+			return "", fmt.Errorf("media processing did not finish after %d checks", mediaStatusMaxChecks)
+		}
+
+		time.Sleep(time.Duration(status.ProcessingInfo.CheckAfterSecs) * time.Second)
+		status, err = c.mediaStatus(mediaID)
+		if err != nil {
+			return "", fmt.Errorf("failed to check media status: %w", err)
+		}
+	}
+
+	slog.Info("Media uploaded", "media_id", mediaID, "media_type", mediaType, "bytes", len(data))
+	return mediaID, nil
+}
+
+// UploadMediaFromURL は指定したURLの画像などをダウンロードしてアップロードし、media_idを返します。
+func (c *Client) UploadMediaFromURL(mediaURL string) (string, error) {
+	resp, err := c.downloadClient.Get(mediaURL)
+	if err != nil {
+		return "", fmt.Errorf("failed to download media: %w", err)
+	}
+	defer func() {
+		if err := resp.Body.Close(); err != nil {
+			slog.Error("Failed to close response body", "error", err)
+		}
+	}()
+
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("failed to download media: status code %d", resp.StatusCode)
+	}
+
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return "", fmt.Errorf("failed to read media: %w", err)
+	}
+
+	mediaType := resp.Header.Get("Content-Type")
+	if !strings.HasPrefix(mediaType, "image/") && !strings.HasPrefix(mediaType, "video/") {
+		mediaType = http.DetectContentType(data)
+	}
+
+	return c.UploadMedia(data, mediaType)
+}
+
+func (c *Client) mediaCommand(params url.Values) (*mediaResponse, error) {
+	req, err := http.NewRequest("POST", c.uploadEndpoint, strings.NewReader(params.Encode()))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	return c.doMediaRequest(req)
+}
+
+func (c *Client) mediaStatus(mediaID string) (*mediaResponse, error) {
+	params := url.Values{"command": {"STATUS"}, "media_id": {mediaID}}
+	req, err := http.NewRequest("GET", c.uploadEndpoint+"?"+params.Encode(), nil)
+	if err != nil {
+		return nil, err
+	}
+	return c.doMediaRequest(req)
+}
+
+func (c *Client) appendMedia(mediaID string, segment int, chunk []byte) error {
+	var body bytes.Buffer
+	writer := multipart.NewWriter(&body)
+	if err := writer.WriteField("command", "APPEND"); err != nil {
+		return err
+	}
+	if err := writer.WriteField("media_id", mediaID); err != nil {
+		return err
+	}
+	if err := writer.WriteField("segment_index", strconv.Itoa(segment)); err != nil {
+		return err
+	}
+	part, err := writer.CreateFormFile("media", "blob")
+	if err != nil {
+		return err
+	}
+	if _, err := part.Write(chunk); err != nil {
+		return err
+	}
+	if err := writer.Close(); err != nil {
+		return err
+	}
+
+	req, err := http.NewRequest("POST", c.uploadEndpoint, &body)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", writer.FormDataContentType())
+
+	_, err = c.doMediaRequest(req)
+	return err
+}
+
+func (c *Client) doMediaRequest(req *http.Request) (*mediaResponse, error) {
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		if err := resp.Body.Close(); err != nil {
+			slog.Error("Failed to close response body", "error", err)
+		}
+	}()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		slog.Error("X media upload error response", "status", resp.Status, "body", string(body))
+		return nil, fmt.Errorf("X media upload returned unexpected status code: %d", resp.StatusCode)
+	}
+
+	// APPEND returns an empty body
+	result := &mediaResponse{}
+	if len(body) == 0 {
+		return result, nil
+	}
+	if err := json.Unmarshal(body, result); err != nil {
+		return nil, fmt.Errorf("failed to parse media response: %w", err)
+	}
+	if result.Data != nil {
+		result.MediaIDString = result.Data.ID
+		result.ProcessingInfo = result.Data.ProcessingInfo
+	}
+	return result, nil
+}
+
+func mediaCategory(mediaType string) string {
+	switch {
+	case mediaType == "image/gif":
+		return "tweet_gif"
+	case strings.HasPrefix(mediaType, "video/"):
+		return "tweet_video"
+	default:
+		return "tweet_image"
+	}
+}
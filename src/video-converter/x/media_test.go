@@ -0,0 +1,207 @@
+package x
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+)
+
+func newTestClient(server *httptest.Server) *Client {
+	return &Client{
+		httpClient:     server.Client(),
+		downloadClient: server.Client(),
+		tweetEndpoint:  server.URL + "/2/tweets",
+		uploadEndpoint: server.URL + "/1.1/media/upload.json",
+		usersEndpoint:  server.URL + "/2/users",
+	}
+}
+
+func TestUploadMedia(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name        string
+		size        int
+		finalize    string
+		status      []string
+		wantErr     bool
+		wantAppends int
+	}{
+		{
+			name:        "正常系: 1チャンクの画像",
+			size:        100,
+			finalize:    `{"media_id_string": "123"}`,
+			wantAppends: 1,
+		},
+		{
+			name:        "正常系: 複数チャンクに分割される",
+			size:        mediaChunkSize*2 + 1,
+			finalize:    `{"media_id_string": "123"}`,
+			wantAppends: 3,
+		},
+		{
+			name:        "正常系: v2のdataで包まれたレスポンス",
+			size:        100,
+			finalize:    `{"data": {"id": "123", "processing_info": {"state": "succeeded"}}}`,
+			wantAppends: 1,
+		},
+		{
+			name:        "正常系: STATUSで処理完了を待つ",
+			size:        100,
+			finalize:    `{"media_id_string": "123", "processing_info": {"state": "pending", "check_after_secs": 0}}`,
+			status:      []string{`{"processing_info": {"state": "in_progress", "check_after_secs": 0}}`, `{"processing_info": {"state": "succeeded"}}`},
+			wantAppends: 1,
+		},
+		{
+			name:        "異常系: 処理に失敗した場合",
+			size:        100,
+			finalize:    `{"media_id_string": "123", "processing_info": {"state": "pending", "check_after_secs": 0}}`,
+			status:      []string{`{"processing_info": {"state": "failed", "error": {"message": "invalid media"}}}`},
+			wantErr:     true,
+			wantAppends: 1,
+		},
+	}
+
+	for _, tc := range tests {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			var mu sync.Mutex
+			appends := 0
+			statusCalls := 0
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				mu.Lock()
+				defer mu.Unlock()
+
+				if r.Method == "GET" {
+					if r.URL.Query().Get("command") != "STATUS" {
+						t.Errorf("unexpected GET command: %s", r.URL.Query().Get("command"))
+					}
+					_, _ = io.WriteString(w, tc.status[statusCalls])
+					statusCalls++
+					return
+				}
+
+				if strings.HasPrefix(r.Header.Get("Content-Type"), "multipart/form-data") {
+					if err := r.ParseMultipartForm(mediaChunkSize * 2); err != nil {
+						t.Errorf("failed to parse multipart form: %v", err)
+					}
+					if r.FormValue("command") != "APPEND" || r.FormValue("media_id") != "123" {
+						t.Errorf("unexpected APPEND form: %v", r.MultipartForm.Value)
+					}
+					appends++
+					w.WriteHeader(http.StatusNoContent)
+					return
+				}
+
+				if err := r.ParseForm(); err != nil {
+					t.Errorf("failed to parse form: %v", err)
+				}
+				switch r.FormValue("command") {
+				case "INIT":
+					if r.FormValue("media_category") != "tweet_image" {
+						t.Errorf("media_category = %s, want tweet_image", r.FormValue("media_category"))
+					}
+					_, _ = io.WriteString(w, `{"media_id_string": "123"}`)
+				case "FINALIZE":
+					_, _ = io.WriteString(w, tc.finalize)
+				default:
+					t.Errorf("unexpected command: %s", r.FormValue("command"))
+				}
+			}))
+			defer server.Close()
+
+			mediaID, err := newTestClient(server).UploadMedia(make([]byte, tc.size), "image/jpeg")
+			if (err != nil) != tc.wantErr {
+				t.Fatalf("UploadMedia() error = %v, wantErr %v", err, tc.wantErr)
+			}
+			if !tc.wantErr && mediaID != "123" {
+				t.Errorf("UploadMedia() = %s, want 123", mediaID)
+			}
+			if appends != tc.wantAppends {
+				t.Errorf("APPEND called %d times, want %d", appends, tc.wantAppends)
+			}
+		})
+	}
+}
+
+func TestUploadMediaFromURL(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name        string
+		path        string
+		wantErr     bool
+		wantUploads int
+	}{
+		{name: "正常系: ダウンロードした画像をアップロードする", path: "/image.jpg", wantUploads: 3},
+		{name: "異常系: ダウンロードに失敗した場合はアップロードしない", path: "/missing.jpg", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			var mu sync.Mutex
+			uploads := 0
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				switch r.URL.Path {
+				case "/image.jpg":
+					w.Header().Set("Content-Type", "image/jpeg")
+					_, _ = io.WriteString(w, "jpeg")
+				case "/1.1/media/upload.json":
+					mu.Lock()
+					uploads++
+					mu.Unlock()
+					_, _ = io.WriteString(w, `{"media_id_string": "123"}`)
+				default:
+					http.NotFound(w, r)
+				}
+			}))
+			defer server.Close()
+
+			id, err := newTestClient(server).UploadMediaFromURL(server.URL + tt.path)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("UploadMediaFromURL() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && id != "123" {
+				t.Errorf("UploadMediaFromURL() = %q, want 123", id)
+			}
+			// INIT, APPEND and FINALIZE
+			if uploads != tt.wantUploads {
+				t.Errorf("upload requests = %d, want %d", uploads, tt.wantUploads)
+			}
+		})
+	}
+}
+
+func TestPostWithMedia(t *testing.T) {
+	t.Parallel()
+
+	var got Tweet
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if err := json.NewDecoder(r.Body).Decode(&got); err != nil {
+			t.Errorf("failed to parse tweet: %v", err)
+		}
+		w.WriteHeader(http.StatusCreated)
+		_, _ = io.WriteString(w, `{"data": {"id": "1", "text": "hello"}}`)
+	}))
+	defer server.Close()
+
+	tweet := Tweet{Text: "hello", Media: &TweetMedia{MediaIDs: []string{"123"}}}
+	id, err := newTestClient(server).Post(tweet)
+	if err != nil {
+		t.Fatalf("Post() error = %v", err)
+	}
+
+	if id != "1" {
+		t.Errorf("Post() = %s, want 1", id)
+	}
+	if got.Media == nil || len(got.Media.MediaIDs) != 1 || got.Media.MediaIDs[0] != "123" {
+		t.Errorf("posted tweet = %+v, want media_ids [123]", got)
+	}
+}
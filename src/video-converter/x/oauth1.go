@@ -0,0 +1,58 @@
+package x
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+
+	"thiroyoshi.com/video-converter/secret"
+)
+
+// Name of the secret that stores the OAuth 1.0a credentials
+const oauth1CredentialsSecretName = "x-oauth1-credentials"
+
+// ErrOAuth1NotConfigured はOAuth 1.0aの認証情報が設定されていないことを表します。
+var ErrOAuth1NotConfigured = errors.New("X OAuth1 credentials not configured")
+
+// OAuth1Credentials はOAuth 1.0aのコンシューマーキーとアクセストークンです。
+type OAuth1Credentials struct {
+	APIKey            string `json:"api_key"`
+	APISecretKey      string `json:"api_secret_key"`
+	AccessToken       string `json:"access_token"`
+	AccessTokenSecret string `json:"access_token_secret"`
+}
+
+func (c OAuth1Credentials) complete() bool {
+	return c.APIKey != "" && c.APISecretKey != "" && c.AccessToken != "" && c.AccessTokenSecret != ""
+}
+
+// LoadOAuth1Credentials は X_API_KEY、X_API_SECRET_KEY、X_ACCESS_TOKEN、X_ACCESS_TOKEN_SECRET から認証情報を読み込みます。
+// 環境変数がそろっていない場合は、provider のシークレット x-oauth1-credentials（JSON）から読み込みます。
+// どちらにもない場合は ErrOAuth1NotConfigured を返します。
+func LoadOAuth1Credentials(provider secret.Provider) (OAuth1Credentials, error) {
+	credentials := OAuth1Credentials{
+		APIKey:            os.Getenv("X_API_KEY"),
+		APISecretKey:      os.Getenv("X_API_SECRET_KEY"),
+		AccessToken:       os.Getenv("X_ACCESS_TOKEN"),
+		AccessTokenSecret: os.Getenv("X_ACCESS_TOKEN_SECRET"),
+	}
+	if credentials.complete() {
+		return credentials, nil
+	}
+
+	data, err := provider.Get(oauth1CredentialsSecretName)
+	if errors.Is(err, secret.ErrNotFound) {
+		return OAuth1Credentials{}, ErrOAuth1NotConfigured
+	}
+	if err != nil {
+		return OAuth1Credentials{}, fmt.Errorf("failed to load X OAuth1 credentials: %w", err)
+	}
+	if err := json.Unmarshal(data, &credentials); err != nil {
+		return OAuth1Credentials{}, fmt.Errorf("failed to parse X OAuth1 credentials: %w", err)
+	}
+	if !credentials.complete() {
+		return OAuth1Credentials{}, fmt.Errorf("%w: %s is missing a key", ErrOAuth1NotConfigured, oauth1CredentialsSecretName)
+	}
+	return credentials, nil
+}
@@ -0,0 +1,57 @@
+package x
+
+import (
+	"errors"
+	"testing"
+
+	"thiroyoshi.com/video-converter/secret"
+)
+
+func TestLoadOAuth1Credentials(t *testing.T) {
+	want := OAuth1Credentials{APIKey: "key", APISecretKey: "key-secret", AccessToken: "token", AccessTokenSecret: "token-secret"}
+
+	tests := []struct {
+		name    string
+		env     map[string]string
+		stored  string
+		wantErr error
+	}{
+		{
+			name: "正常系: 環境変数から読み込む",
+			env:  map[string]string{"X_API_KEY": "key", "X_API_SECRET_KEY": "key-secret", "X_ACCESS_TOKEN": "token", "X_ACCESS_TOKEN_SECRET": "token-secret"},
+		},
+		{
+			name:   "正常系: シークレットから読み込む",
+			stored: `{"api_key":"key","api_secret_key":"key-secret","access_token":"token","access_token_secret":"token-secret"}`,
+		},
+		{name: "異常系: 設定されていない", wantErr: ErrOAuth1NotConfigured},
+		{name: "異常系: シークレットの項目が足りない", stored: `{"api_key":"key"}`, wantErr: ErrOAuth1NotConfigured},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for _, key := range []string{"X_API_KEY", "X_API_SECRET_KEY", "X_ACCESS_TOKEN", "X_ACCESS_TOKEN_SECRET"} {
+				t.Setenv(key, tt.env[key])
+			}
+			provider := secret.NewFile(t.TempDir())
+			if tt.stored != "" {
+				if err := provider.Put(oauth1CredentialsSecretName, []byte(tt.stored)); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			got, err := LoadOAuth1Credentials(provider)
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Errorf("LoadOAuth1Credentials() error = %v, want %v", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("LoadOAuth1Credentials() error = %v", err)
+			}
+			if got != want {
+				t.Errorf("LoadOAuth1Credentials() = %+v, want %+v", got, want)
+			}
+		})
+	}
+}
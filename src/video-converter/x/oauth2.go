@@ -0,0 +1,348 @@
+package x
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"thiroyoshi.com/video-converter/secret"
+)
+
+const (
+	oauth2AuthorizeEndpoint = "https://x.com/i/oauth2/authorize"
+	oauth2TokenEndpoint     = "https://api.x.com/2/oauth2/token"
+	// OAuth 1.0a uses upload.twitter.com, OAuth 2.0 user context uses the v2 endpoint
+	mediaUploadV2Endpoint = "https://api.x.com/2/media/upload"
+	// Name of the secret that stores the OAuth 2.0 token
+	oauth2TokenSecretName = "x-oauth2-token"
+	// DefaultRedirectURL は認可コードを受け取るローカルサーバーのURLです。X Developer Portalに登録しておきます。
+	DefaultRedirectURL = "http://127.0.0.1:8976/callback"
+	// Refresh the access token this long before it expires
+	tokenExpiryMargin = time.Minute
+	// How many times the stored token is read again when the refresh token was used by another process
+	tokenReloadAttempts = 3
+)
+
+// tokenReloadInterval is the wait for another process to save the token it refreshed. Shortened in tests.
+var tokenReloadInterval = 2 * time.Second
+
+// OAuth2Scopes は認可を求めるスコープです。offline.accessでリフレッシュトークンを受け取ります。
+var OAuth2Scopes = []string{"tweet.read", "tweet.write", "users.read", "follows.read", "follows.write", "media.write", "offline.access"}
+
+// OAuth2Config はOAuth 2.0 Authorization Code with PKCEの設定です。
+type OAuth2Config struct {
+	ClientID     string
+	ClientSecret string
+	RedirectURL  string
+	Scopes       []string
+
+	authorizeEndpoint string
+	tokenEndpoint     string
+	httpClient        *http.Client
+}
+
+// NewOAuth2Config はOAuth 2.0の設定を作成します。ClientSecretはConfidential Clientの場合のみ指定します。
+func NewOAuth2Config(clientID, clientSecret, redirectURL string) *OAuth2Config {
+	if redirectURL == "" {
+		redirectURL = DefaultRedirectURL
+	}
+	return &OAuth2Config{
+		ClientID:          clientID,
+		ClientSecret:      clientSecret,
+		RedirectURL:       redirectURL,
+		Scopes:            OAuth2Scopes,
+		authorizeEndpoint: oauth2AuthorizeEndpoint,
+		tokenEndpoint:     oauth2TokenEndpoint,
+		httpClient:        &http.Client{},
+	}
+}
+
+// OAuth2ConfigFromEnv は X_OAUTH2_CLIENT_ID、X_OAUTH2_CLIENT_SECRET、X_OAUTH2_REDIRECT_URL から設定を読み込みます。
+// クライアントIDが設定されていない場合はfalseを返します。
+func OAuth2ConfigFromEnv() (*OAuth2Config, bool) {
+	clientID := os.Getenv("X_OAUTH2_CLIENT_ID")
+	if clientID == "" {
+		return nil, false
+	}
+	return NewOAuth2Config(clientID, os.Getenv("X_OAUTH2_CLIENT_SECRET"), os.Getenv("X_OAUTH2_REDIRECT_URL")), true
+}
+
+// Token はOAuth 2.0のトークンです。
+type Token struct {
+	AccessToken  string    `json:"access_token"`
+	RefreshToken string    `json:"refresh_token"`
+	TokenType    string    `json:"token_type"`
+	Scope        string    `json:"scope"`
+	Expiry       time.Time `json:"expiry"`
+}
+
+// Expired はトークンが期限切れ、または期限切れ間近かを返します。
+func (t *Token) Expired(now time.Time) bool {
+	return !t.Expiry.IsZero() && now.Add(tokenExpiryMargin).After(t.Expiry)
+}
+
+// NewPKCE はPKCEのcode_verifierとS256のcode_challengeを作成します。
+func NewPKCE() (verifier, challenge string, err error) {
+	verifier, err = randomString(32)
+	if err != nil {
+		return "", "", err
+	}
+	sum := sha256.Sum256([]byte(verifier))
+	return verifier, base64.RawURLEncoding.EncodeToString(sum[:]), nil
+}
+
+// NewState はCSRF対策のstateを作成します。
+func NewState() (string, error) {
+	return randomString(16)
+}
+
+func randomString(n int) (string, error) {
+	b := make([]byte, n)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+// AuthCodeURL はブラウザで開く認可URLを返します。
+func (c *OAuth2Config) AuthCodeURL(state, challenge string) string {
+	params := url.Values{
+		"response_type":         {"code"},
+		"client_id":             {c.ClientID},
+		"redirect_uri":          {c.RedirectURL},
+		"scope":                 {strings.Join(c.Scopes, " ")},
+		"state":                 {state},
+		"code_challenge":        {challenge},
+		"code_challenge_method": {"S256"},
+	}
+	return c.authorizeEndpoint + "?" + params.Encode()
+}
+
+// Exchange は認可コードをトークンに交換します。
+func (c *OAuth2Config) Exchange(code, verifier string) (*Token, error) {
+	return c.requestToken(url.Values{
+		"grant_type":    {"authorization_code"},
+		"code":          {code},
+		"redirect_uri":  {c.RedirectURL},
+		"code_verifier": {verifier},
+	})
+}
+
+// Refresh はリフレッシュトークンで新しいトークンを取得します。
+// Xのリフレッシュトークンは一度しか使えないため、返されたトークンは必ず保存し直します。
+func (c *OAuth2Config) Refresh(refreshToken string) (*Token, error) {
+	return c.requestToken(url.Values{
+		"grant_type":    {"refresh_token"},
+		"refresh_token": {refreshToken},
+	})
+}
+
+func (c *OAuth2Config) requestToken(params url.Values) (*Token, error) {
+	params.Set("client_id", c.ClientID)
+
+	req, err := http.NewRequest("POST", c.tokenEndpoint, strings.NewReader(params.Encode()))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	if c.ClientSecret != "" {
+		req.SetBasicAuth(c.ClientID, c.ClientSecret)
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to request token: %w", err)
+	}
+	defer func() {
+		if err := resp.Body.Close(); err != nil {
+			slog.Error("Failed to close response body", "error", err)
+		}
+	}()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		slog.Error("X OAuth2 token error response", "status", resp.Status, "body", string(body))
+		tokenErr := &TokenError{StatusCode: resp.StatusCode}
+		_ = json.Unmarshal(body, tokenErr)
+		return nil, tokenErr
+	}
+
+	var result struct {
+		AccessToken  string `json:"access_token"`
+		RefreshToken string `json:"refresh_token"`
+		TokenType    string `json:"token_type"`
+		Scope        string `json:"scope"`
+		ExpiresIn    int    `json:"expires_in"`
+	}
+	if err := json.Unmarshal(body, &result); err != nil {
+		return nil, fmt.Errorf("failed to parse token response: %w", err)
+	}
+
+	token := &Token{
+		AccessToken:  result.AccessToken,
+		RefreshToken: result.RefreshToken,
+		TokenType:    result.TokenType,
+		Scope:        result.Scope,
+	}
+	if result.ExpiresIn > 0 {
+		token.Expiry = time.Now().Add(time.Duration(result.ExpiresIn) * time.Second)
+	}
+	return token, nil
+}
+
+// TokenError はトークンエンドポイントのエラーです。
+type TokenError struct {
+	StatusCode  int
+	Code        string `json:"error"`
+	Description string `json:"error_description"`
+}
+
+func (e *TokenError) Error() string {
+	return fmt.Sprintf("X OAuth2 token endpoint returned unexpected status code: %d %s %s", e.StatusCode, e.Code, e.Description)
+}
+
+// RejectedGrant はリフレッシュトークンが使用済みなどで受け付けられなかったかを返します。
+// Xは使用済みのトークンに invalid_grant ではなく invalid_request を返すことがあります。
+func (e *TokenError) RejectedGrant() bool {
+	return e.StatusCode == http.StatusBadRequest && (e.Code == "invalid_grant" || e.Code == "invalid_request")
+}
+
+// TokenStore はOAuth 2.0のトークンをシークレットとして保存します。
+type TokenStore struct {
+	provider secret.Provider
+	name     string
+}
+
+// NewTokenStore はトークンの保存先を作成します。
+func NewTokenStore(provider secret.Provider) *TokenStore {
+	return &TokenStore{provider: provider, name: oauth2TokenSecretName}
+}
+
+// Load は保存されたトークンを読み込みます。
+func (s *TokenStore) Load() (*Token, error) {
+	data, err := s.provider.Get(s.name)
+	if err != nil {
+		return nil, err
+	}
+	var token Token
+	if err := json.Unmarshal(data, &token); err != nil {
+		return nil, fmt.Errorf("failed to parse stored token: %w", err)
+	}
+	return &token, nil
+}
+
+// Save はトークンを保存します。
+func (s *TokenStore) Save(token *Token) error {
+	data, err := json.Marshal(token)
+	if err != nil {
+		return err
+	}
+	return s.provider.Put(s.name, data)
+}
+
+// oauth2Transport はBearerトークンを付けてリクエストを送り、期限切れのトークンを更新して保存します。
+type oauth2Transport struct {
+	config *OAuth2Config
+	store  *TokenStore
+	base   http.RoundTripper
+
+	mu    sync.Mutex
+	token *Token
+}
+
+func (t *oauth2Transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	token, err := t.validToken()
+	if err != nil {
+		return nil, err
+	}
+
+	// RoundTripper must not modify the original request
+	req = req.Clone(req.Context())
+	req.Header.Set("Authorization", "Bearer "+token.AccessToken)
+
+	base := t.base
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	return base.RoundTrip(req)
+}
+
+func (t *oauth2Transport) validToken() (*Token, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if t.token == nil {
+		token, err := t.store.Load()
+		if err != nil {
+			return nil, fmt.Errorf("failed to load X OAuth2 token: %w", err)
+		}
+		t.token = token
+	}
+	if !t.token.Expired(time.Now()) {
+		return t.token, nil
+	}
+	if t.token.RefreshToken == "" {
+		return nil, errors.New("X OAuth2 token expired and no refresh token is available")
+	}
+
+	token, err := t.config.Refresh(t.token.RefreshToken)
+	var tokenErr *TokenError
+	if errors.As(err, &tokenErr) && tokenErr.RejectedGrant() {
+		// The functions sharing the token refresh on their own. When another one used the refresh token first,
+		// the token it saved is the only valid one, so wait for it and use it instead
+		token, err = t.reloadRefreshed()
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to refresh X OAuth2 token: %w", err)
+	}
+	if token == t.token {
+		// The token saved by another process is already stored
+		return token, nil
+	}
+	if err := t.store.Save(token); err != nil {
+		// The old refresh token is already invalid, so the new one must not be lost silently
+		slog.Error("Failed to save refreshed X OAuth2 token", "error", err)
+		return nil, fmt.Errorf("failed to save refreshed X OAuth2 token: %w", err)
+	}
+	slog.Info("X OAuth2 token refreshed", "expiry", token.Expiry)
+
+	t.token = token
+	return token, nil
+}
+
+// reloadRefreshed は他の処理が更新して保存したトークンを読み込み直します。
+// 保存されたトークンも期限切れの場合は、そのリフレッシュトークンで一度だけ更新します。
+func (t *oauth2Transport) reloadRefreshed() (*Token, error) {
+	for attempt := 0; ; attempt++ {
+		stored, err := t.store.Load()
+		if err != nil {
+			return nil, fmt.Errorf("failed to reload X OAuth2 token: %w", err)
+		}
+		if stored.RefreshToken != t.token.RefreshToken {
+			slog.Warn("X OAuth2 token was refreshed by another process, using the stored token")
+			if !stored.Expired(time.Now()) {
+				t.token = stored
+				return stored, nil
+			}
+			return t.config.Refresh(stored.RefreshToken)
+		}
+		if attempt == tokenReloadAttempts-1 {
+			return nil, errors.New("X OAuth2 refresh token was rejected and no newer token was saved; run x-auth again")
+		}
+		time.Sleep(tokenReloadInterval)
+	}
+}
@@ -0,0 +1,300 @@
+package x
+
+import (
+	"crypto/sha256"
+	"encoding/base64"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"thiroyoshi.com/video-converter/secret"
+)
+
+func newTestOAuth2Config(server *httptest.Server) *OAuth2Config {
+	config := NewOAuth2Config("client-id", "", "")
+	config.tokenEndpoint = server.URL + "/2/oauth2/token"
+	config.httpClient = server.Client()
+	return config
+}
+
+func TestNewPKCE(t *testing.T) {
+	t.Parallel()
+
+	verifier, challenge, err := NewPKCE()
+	if err != nil {
+		t.Fatalf("NewPKCE() error = %v", err)
+	}
+	if len(verifier) < 43 || len(verifier) > 128 {
+		t.Errorf("verifier length = %d, want 43-128", len(verifier))
+	}
+	sum := sha256.Sum256([]byte(verifier))
+	if want := base64.RawURLEncoding.EncodeToString(sum[:]); challenge != want {
+		t.Errorf("challenge = %q, want %q", challenge, want)
+	}
+}
+
+func TestAuthCodeURL(t *testing.T) {
+	t.Parallel()
+
+	config := NewOAuth2Config("client-id", "", "")
+	u, err := url.Parse(config.AuthCodeURL("state-1", "challenge-1"))
+	if err != nil {
+		t.Fatalf("url.Parse() error = %v", err)
+	}
+
+	q := u.Query()
+	want := map[string]string{
+		"response_type":         "code",
+		"client_id":             "client-id",
+		"redirect_uri":          DefaultRedirectURL,
+		"state":                 "state-1",
+		"code_challenge":        "challenge-1",
+		"code_challenge_method": "S256",
+	}
+	for key, value := range want {
+		if q.Get(key) != value {
+			t.Errorf("%s = %q, want %q", key, q.Get(key), value)
+		}
+	}
+	if !strings.Contains(q.Get("scope"), "offline.access") {
+		t.Errorf("scope = %q, want offline.access", q.Get("scope"))
+	}
+}
+
+func TestExchangeAndRefresh(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name         string
+		clientSecret string
+		call         func(c *OAuth2Config) (*Token, error)
+		wantForm     map[string]string
+		status       int
+		wantErr      bool
+	}{
+		{
+			name: "正常系: 認可コードを交換する",
+			call: func(c *OAuth2Config) (*Token, error) { return c.Exchange("code-1", "verifier-1") },
+			wantForm: map[string]string{
+				"grant_type":    "authorization_code",
+				"code":          "code-1",
+				"code_verifier": "verifier-1",
+				"client_id":     "client-id",
+			},
+			status: http.StatusOK,
+		},
+		{
+			name:         "正常系: Confidential ClientはBasic認証でリフレッシュする",
+			clientSecret: "client-secret",
+			call:         func(c *OAuth2Config) (*Token, error) { return c.Refresh("refresh-1") },
+			wantForm: map[string]string{
+				"grant_type":    "refresh_token",
+				"refresh_token": "refresh-1",
+			},
+			status: http.StatusOK,
+		},
+		{
+			name:    "異常系: トークンエンドポイントがエラーを返す",
+			call:    func(c *OAuth2Config) (*Token, error) { return c.Refresh("refresh-1") },
+			status:  http.StatusBadRequest,
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if err := r.ParseForm(); err != nil {
+					t.Errorf("ParseForm() error = %v", err)
+				}
+				for key, value := range tt.wantForm {
+					if r.PostForm.Get(key) != value {
+						t.Errorf("%s = %q, want %q", key, r.PostForm.Get(key), value)
+					}
+				}
+				user, pass, ok := r.BasicAuth()
+				if tt.clientSecret != "" && (!ok || user != "client-id" || pass != tt.clientSecret) {
+					t.Errorf("BasicAuth() = %q, %q, %v", user, pass, ok)
+				}
+				w.WriteHeader(tt.status)
+				_, _ = w.Write([]byte(`{"token_type":"bearer","access_token":"access-2","refresh_token":"refresh-2","expires_in":7200,"scope":"tweet.write offline.access"}`))
+			}))
+			defer server.Close()
+
+			config := newTestOAuth2Config(server)
+			config.ClientSecret = tt.clientSecret
+
+			token, err := tt.call(config)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			if token.AccessToken != "access-2" || token.RefreshToken != "refresh-2" {
+				t.Errorf("token = %+v", token)
+			}
+			if token.Expiry.Before(time.Now().Add(time.Hour)) {
+				t.Errorf("Expiry = %v, want about 2 hours later", token.Expiry)
+			}
+		})
+	}
+}
+
+func TestOAuth2ClientRefreshesAndRotatesToken(t *testing.T) {
+	t.Parallel()
+
+	var mu sync.Mutex
+	refreshCount := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/2/oauth2/token":
+			if err := r.ParseForm(); err != nil {
+				t.Errorf("ParseForm() error = %v", err)
+			}
+			mu.Lock()
+			refreshCount++
+			n := refreshCount
+			mu.Unlock()
+			if got := r.PostForm.Get("refresh_token"); got != "refresh-1" {
+				t.Errorf("refresh_token = %q, want refresh-1", got)
+			}
+			_, _ = fmt.Fprintf(w, `{"access_token":"access-%d","refresh_token":"refresh-%d","expires_in":7200}`, n+1, n+1)
+		case "/2/tweets":
+			if got := r.Header.Get("Authorization"); got != "Bearer access-2" {
+				t.Errorf("Authorization = %q, want Bearer access-2", got)
+			}
+			w.WriteHeader(http.StatusCreated)
+			_, _ = w.Write([]byte(`{"data":{"id":"1"}}`))
+		default:
+			t.Errorf("unexpected path: %s", r.URL.Path)
+		}
+	}))
+	defer server.Close()
+
+	store := NewTokenStore(secret.NewFile(t.TempDir()))
+	expired := &Token{AccessToken: "access-1", RefreshToken: "refresh-1", Expiry: time.Now().Add(-time.Minute)}
+	if err := store.Save(expired); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+
+	client := NewOAuth2Client(newTestOAuth2Config(server), store)
+	client.tweetEndpoint = server.URL + "/2/tweets"
+	client.httpClient.Transport.(*oauth2Transport).base = server.Client().Transport
+
+	for range 2 {
+		if _, err := client.Post(Tweet{Text: "hello"}); err != nil {
+			t.Fatalf("Post() error = %v", err)
+		}
+	}
+
+	if refreshCount != 1 {
+		t.Errorf("refresh count = %d, want 1", refreshCount)
+	}
+	saved, err := store.Load()
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if saved.RefreshToken != "refresh-2" {
+		t.Errorf("saved refresh token = %q, want refresh-2", saved.RefreshToken)
+	}
+}
+
+func TestOAuth2ClientUsesTokenRefreshedByAnotherProcess(t *testing.T) {
+	store := NewTokenStore(secret.NewFile(t.TempDir()))
+	original := tokenReloadInterval
+	tokenReloadInterval = time.Millisecond
+	t.Cleanup(func() { tokenReloadInterval = original })
+
+	tests := []struct {
+		name string
+		// saved is the token saved by the other process when it used the refresh token
+		saved       *Token
+		wantRefresh []string
+		wantAuth    string
+		wantErr     bool
+	}{
+		{
+			name:        "正常系: 他の処理が保存したトークンを使う",
+			saved:       &Token{AccessToken: "access-other", RefreshToken: "refresh-other", Expiry: time.Now().Add(time.Hour)},
+			wantRefresh: []string{"refresh-1"},
+			wantAuth:    "Bearer access-other",
+		},
+		{
+			name:        "正常系: 保存されたトークンも期限切れなら更新する",
+			saved:       &Token{AccessToken: "access-other", RefreshToken: "refresh-other", Expiry: time.Now().Add(-time.Minute)},
+			wantRefresh: []string{"refresh-1", "refresh-other"},
+			wantAuth:    "Bearer access-new",
+		},
+		{
+			name:        "異常系: 新しいトークンが保存されない",
+			wantRefresh: []string{"refresh-1"},
+			wantErr:     true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			expired := &Token{AccessToken: "access-1", RefreshToken: "refresh-1", Expiry: time.Now().Add(-time.Minute)}
+			if err := store.Save(expired); err != nil {
+				t.Fatalf("Save() error = %v", err)
+			}
+
+			var refreshed []string
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				switch r.URL.Path {
+				case "/2/oauth2/token":
+					if err := r.ParseForm(); err != nil {
+						t.Errorf("ParseForm() error = %v", err)
+					}
+					refreshToken := r.PostForm.Get("refresh_token")
+					refreshed = append(refreshed, refreshToken)
+					if refreshToken == "refresh-1" {
+						// Another process used the refresh token first
+						if tt.saved != nil {
+							if err := store.Save(tt.saved); err != nil {
+								t.Errorf("Save() error = %v", err)
+							}
+						}
+						w.WriteHeader(http.StatusBadRequest)
+						_, _ = w.Write([]byte(`{"error":"invalid_request","error_description":"Value passed for the token was invalid."}`))
+						return
+					}
+					_, _ = w.Write([]byte(`{"access_token":"access-new","refresh_token":"refresh-new","expires_in":7200}`))
+				case "/2/tweets":
+					if got := r.Header.Get("Authorization"); got != tt.wantAuth {
+						t.Errorf("Authorization = %q, want %q", got, tt.wantAuth)
+					}
+					w.WriteHeader(http.StatusCreated)
+					_, _ = w.Write([]byte(`{"data":{"id":"1"}}`))
+				default:
+					t.Errorf("unexpected path: %s", r.URL.Path)
+				}
+			}))
+			defer server.Close()
+
+			client := NewOAuth2Client(newTestOAuth2Config(server), store)
+			client.tweetEndpoint = server.URL + "/2/tweets"
+			client.httpClient.Transport.(*oauth2Transport).base = server.Client().Transport
+
+			_, err := client.Post(Tweet{Text: "hello"})
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Post() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if strings.Join(refreshed, ",") != strings.Join(tt.wantRefresh, ",") {
+				t.Errorf("refreshed with %v, want %v", refreshed, tt.wantRefresh)
+			}
+			if tt.wantAuth == "Bearer access-new" {
+				if saved, err := store.Load(); err != nil || saved.RefreshToken != "refresh-new" {
+					t.Errorf("saved token = %+v, %v, want refresh-new", saved, err)
+				}
+			}
+		})
+	}
+}
@@ -1,80 +1,71 @@
-//nolint:unused
 package x
 
 import (
-	"bytes"
-	"encoding/json"
-	"io"
+	"fmt"
 	"log/slog"
-	"net/http"
-
-	"github.com/dghubble/oauth1"
-)
-
-const (
-	// Twitter/X API credentials
-	apiKey             = "vR8oo1pAQFgeYKlfxIPSrgRq6"
-	apiSecretKey       = "fyS3Nm8tEsSQOKK9Ez77TQn7Fi2A3HSO7ZdkDAArshXCSxNXT0"
-	accessToken        = "1449548285354516482-BxphqsVkM9LQUjHzIVpHnJ2DqcGQTw"
-	accessTokenSecret  = "1fj79P9ttUavCvjH7iZGVITuTgbqx5VqgrEznLPJTsVvU"
-	twitterAPIEndpoint = "https://api.twitter.com/2/tweets"
 )
 
-// Tweet はXのツイートデータを表す構造体です。ツイートの本文とその他のメタデータを含みます。
-type Tweet struct {
-	Text string `json:"text"`
+// PostToX はXにメッセージを投稿し、作成されたツイートのIDを返します
+func PostToX(message string) (string, error) {
+	client, err := NewClient()
+	if err != nil {
+		return "", err
+	}
+	return client.Post(Tweet{Text: message})
 }
 
-func postX(message string) error {
-	// APIキーとアクセストークンを設定
-
-	// OAuth1 認証設定
-	config := oauth1.NewConfig(apiKey, apiSecretKey)
-	token := oauth1.NewToken(accessToken, accessTokenSecret)
-	httpClient := config.Client(oauth1.NoContext, token)
-
-	// APIエンドポイントURL
-	endpoint := twitterAPIEndpoint
-
-	tweet := Tweet{Text: message}
-
-	// Marshal the Tweet struct to JSON
-	jsonData, err := json.Marshal(tweet)
+// PostToXWithImage は画像を添付してXにメッセージを投稿し、作成されたツイートのIDを返します。
+// 画像のアップロードに失敗した場合は、画像なしで投稿します。
+func PostToXWithImage(message, imageURL string) (string, error) {
+	client, err := NewClient()
 	if err != nil {
-		slog.Error("Failed to marshal tweet data", "error", err)
-		return err
+		return "", err
 	}
+	return postWithImage(client, Tweet{Text: message}, imageURL)
+}
 
-	// POSTリクエストを作成
-	req, err := http.NewRequest("POST", endpoint, bytes.NewBuffer(jsonData))
+// PostThread は最初のメッセージに画像を添付し、以降のメッセージを直前のツイートへの返信としてスレッドで投稿します。
+// 途中で失敗した場合は、それまでに作成したツイートのIDとエラーを返します。
+func PostThread(messages []string, imageURL string) ([]string, error) {
+	client, err := NewClient()
 	if err != nil {
-		slog.Error("Failed to create request", "error", err)
-		return err
+		return nil, err
 	}
+	return postThread(client, messages, imageURL)
+}
 
-	req.Header.Set("Content-Type", "application/json")
+func postThread(client *Client, messages []string, imageURL string) ([]string, error) {
+	if len(messages) == 0 {
+		return nil, fmt.Errorf("no messages to post")
+	}
 
-	// リクエストを送信
-	resp, err := httpClient.Do(req)
+	id, err := postWithImage(client, Tweet{Text: messages[0]}, imageURL)
 	if err != nil {
-		slog.Error("Failed to send request", "error", err)
-		return err
+		return nil, err
 	}
-	defer func() {
-		if err := resp.Body.Close(); err != nil {
-			slog.Error("Failed to close response body", "error", err)
-		}
-	}()
+	ids := []string{id}
 
-	// レスポンスを読み取る
-	body, err := io.ReadAll(resp.Body)
-	if err != nil {
-		slog.Error("Failed to read response", "error", err)
-		return err
+	for i, message := range messages[1:] {
+		id, err = client.Post(Tweet{Text: message, Reply: &TweetReply{InReplyToTweetID: id}})
+		if err != nil {
+			return ids, fmt.Errorf("failed to post thread reply %d: %w", i+1, err)
+		}
+		ids = append(ids, id)
 	}
 
-	// 結果を表示
-	slog.Info("X API post response", "status", resp.Status, "body", string(body))
+	slog.Info("Thread posted to X", "tweet_ids", ids)
+	return ids, nil
+}
+
+func postWithImage(client *Client, tweet Tweet, imageURL string) (string, error) {
+	if imageURL != "" {
+		mediaID, err := client.UploadMediaFromURL(imageURL)
+		if err != nil {
+			slog.Warn("Failed to upload image, posting without media", "image_url", imageURL, "error", err)
+		} else {
+			tweet.Media = &TweetMedia{MediaIDs: []string{mediaID}}
+		}
+	}
 
-	return nil
+	return client.Post(tweet)
 }
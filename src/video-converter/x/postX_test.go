@@ -0,0 +1,45 @@
+package x
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+)
+
+func TestPostThread(t *testing.T) {
+	t.Parallel()
+
+	var replies []string
+	count := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var tweet Tweet
+		if err := json.NewDecoder(r.Body).Decode(&tweet); err != nil {
+			t.Errorf("failed to parse tweet: %v", err)
+		}
+		reply := ""
+		if tweet.Reply != nil {
+			reply = tweet.Reply.InReplyToTweetID
+		}
+		replies = append(replies, reply)
+
+		count++
+		w.WriteHeader(http.StatusCreated)
+		_, _ = fmt.Fprintf(w, `{"data": {"id": "%d", "text": %q}}`, count, tweet.Text)
+	}))
+	defer server.Close()
+
+	ids, err := postThread(newTestClient(server), []string{"announce", "topic 1", "topic 2"}, "")
+	if err != nil {
+		t.Fatalf("postThread() error = %v", err)
+	}
+
+	if want := []string{"1", "2", "3"}; !reflect.DeepEqual(ids, want) {
+		t.Errorf("postThread() = %v, want %v", ids, want)
+	}
+	if want := []string{"", "1", "2"}; !reflect.DeepEqual(replies, want) {
+		t.Errorf("in_reply_to_tweet_id = %v, want %v", replies, want)
+	}
+}
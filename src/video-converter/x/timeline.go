@@ -0,0 +1,124 @@
+package x
+
+import (
+	"fmt"
+	"log/slog"
+	"net/url"
+	"os"
+	"time"
+)
+
+const (
+	// X API maximum results per request
+	userTweetsMaxResults = "100"
+	// Upper bound of pages to follow, so a wrong time range cannot exhaust the rate limit
+	userTweetsMaxPages = 10
+)
+
+// PublicMetrics はツイートの公開指標です。
+type PublicMetrics struct {
+	RetweetCount    int `json:"retweet_count"`
+	ReplyCount      int `json:"reply_count"`
+	LikeCount       int `json:"like_count"`
+	QuoteCount      int `json:"quote_count"`
+	BookmarkCount   int `json:"bookmark_count"`
+	ImpressionCount int `json:"impression_count"`
+}
+
+// Engagement はいいね・リポスト・返信・引用の合計です。
+func (m PublicMetrics) Engagement() int {
+	return m.LikeCount + m.RetweetCount + m.ReplyCount + m.QuoteCount
+}
+
+// ReferencedTweet は返信・引用・リポスト先のツイートです。Typeは "replied_to"、"quoted"、"retweeted" のいずれかです。
+type ReferencedTweet struct {
+	Type string `json:"type"`
+	ID   string `json:"id"`
+}
+
+// TimelineTweet はユーザーのタイムラインから取得したツイートです。
+type TimelineTweet struct {
+	ID               string            `json:"id"`
+	Text             string            `json:"text"`
+	CreatedAt        time.Time         `json:"created_at"`
+	PublicMetrics    PublicMetrics     `json:"public_metrics"`
+	ReferencedTweets []ReferencedTweet `json:"referenced_tweets"`
+}
+
+// Kind はツイートの種類を "post"、"reply"、"quote"、"retweet" のいずれかで返します。
+func (t TimelineTweet) Kind() string {
+	for _, ref := range t.ReferencedTweets {
+		switch ref.Type {
+		case "retweeted":
+			return "retweet"
+		case "replied_to":
+			return "reply"
+		case "quoted":
+			return "quote"
+		}
+	}
+	return "post"
+}
+
+// URL はツイートのURLです。
+func (t TimelineTweet) URL() string {
+	return "https://x.com/i/web/status/" + t.ID
+}
+
+// Me は認証しているユーザーのIDを返します。
+func (c *Client) Me() (string, error) {
+	user, err := c.Profile()
+	if err != nil {
+		return "", err
+	}
+	return user.ID, nil
+}
+
+// UserTweets はユーザーが start から end までに投稿したツイートを、ページをたどってすべて取得します。
+func (c *Client) UserTweets(userID string, start, end time.Time) ([]TimelineTweet, error) {
+	params := url.Values{}
+	params.Set("max_results", userTweetsMaxResults)
+	params.Set("start_time", start.UTC().Format(time.RFC3339))
+	params.Set("end_time", end.UTC().Format(time.RFC3339))
+	params.Set("tweet.fields", "public_metrics,created_at,referenced_tweets")
+
+	var tweets []TimelineTweet
+	for page := 0; page < userTweetsMaxPages; page++ {
+		var result struct {
+			Data []TimelineTweet `json:"data"`
+			Meta struct {
+				ResultCount int    `json:"result_count"`
+				NextToken   string `json:"next_token"`
+			} `json:"meta"`
+		}
+		if err := c.getJSON(c.usersEndpoint+"/"+userID+"/tweets", params, &result); err != nil {
+			return nil, fmt.Errorf("failed to get user tweets: %w", err)
+		}
+		tweets = append(tweets, result.Data...)
+
+		if result.Meta.NextToken == "" {
+			return tweets, nil
+		}
+		params.Set("pagination_token", result.Meta.NextToken)
+	}
+
+	slog.Warn("User tweets truncated", "user_id", userID, "pages", userTweetsMaxPages, "tweets", len(tweets))
+	return tweets, nil
+}
+
+// CollectTweets は自分のアカウントが start から end までに投稿したツイートを取得します。
+// ユーザーIDは X_USER_ID が設定されていればそれを使い、なければ認証しているユーザーを使います。
+func CollectTweets(start, end time.Time) ([]TimelineTweet, error) {
+	client, err := NewClient()
+	if err != nil {
+		return nil, err
+	}
+
+	userID := os.Getenv("X_USER_ID")
+	if userID == "" {
+		if userID, err = client.Me(); err != nil {
+			return nil, err
+		}
+	}
+	return client.UserTweets(userID, start, end)
+}
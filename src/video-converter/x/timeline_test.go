@@ -0,0 +1,96 @@
+package x
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestUserTweets(t *testing.T) {
+	t.Parallel()
+
+	start := time.Date(2025, 4, 14, 0, 0, 0, 0, time.UTC)
+	end := start.AddDate(0, 0, 7)
+
+	pages := map[string]string{
+		"": `{"data":[
+			{"id":"1","text":"動画をアップしました","created_at":"2025-04-18T10:00:00.000Z","public_metrics":{"like_count":10,"retweet_count":2,"reply_count":1,"quote_count":0,"impression_count":500}},
+			{"id":"2","text":"RT @FortniteJP: 新スキン","created_at":"2025-04-18T09:00:00.000Z","referenced_tweets":[{"type":"retweeted","id":"9"}]}
+		],"meta":{"result_count":2,"next_token":"page2"}}`,
+		"page2": `{"data":[
+			{"id":"3","text":"@friend ナイス！","created_at":"2025-04-15T09:00:00.000Z","referenced_tweets":[{"type":"replied_to","id":"8"}],"public_metrics":{"like_count":1}}
+		],"meta":{"result_count":1}}`,
+	}
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/2/users/123/tweets" {
+			t.Errorf("path = %s", r.URL.Path)
+		}
+		q := r.URL.Query()
+		if q.Get("tweet.fields") != "public_metrics,created_at,referenced_tweets" {
+			t.Errorf("tweet.fields = %q", q.Get("tweet.fields"))
+		}
+		if q.Get("start_time") != "2025-04-14T00:00:00Z" || q.Get("end_time") != "2025-04-21T00:00:00Z" {
+			t.Errorf("start_time = %q, end_time = %q", q.Get("start_time"), q.Get("end_time"))
+		}
+		_, _ = w.Write([]byte(pages[q.Get("pagination_token")]))
+	}))
+	defer server.Close()
+
+	tweets, err := newTestClient(server).UserTweets("123", start, end)
+	if err != nil {
+		t.Fatalf("UserTweets() error = %v", err)
+	}
+	if len(tweets) != 3 {
+		t.Fatalf("len(tweets) = %d, want 3", len(tweets))
+	}
+
+	wantKinds := []string{"post", "retweet", "reply"}
+	for i, tweet := range tweets {
+		if tweet.Kind() != wantKinds[i] {
+			t.Errorf("tweets[%d].Kind() = %q, want %q", i, tweet.Kind(), wantKinds[i])
+		}
+	}
+	if tweets[0].PublicMetrics.ImpressionCount != 500 || !tweets[0].CreatedAt.Equal(time.Date(2025, 4, 18, 10, 0, 0, 0, time.UTC)) {
+		t.Errorf("tweets[0] = %+v", tweets[0])
+	}
+}
+
+func TestMe(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		status  int
+		body    string
+		want    string
+		wantErr bool
+	}{
+		{name: "正常系: ユーザーIDを返す", status: http.StatusOK, body: `{"data":{"id":"123","username":"GABA_FORTNITE"}}`, want: "123"},
+		{name: "異常系: 認証エラー", status: http.StatusUnauthorized, body: `{"title":"Unauthorized"}`, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if r.URL.Path != "/2/users/me" {
+					t.Errorf("path = %s", r.URL.Path)
+				}
+				w.WriteHeader(tt.status)
+				_, _ = w.Write([]byte(tt.body))
+			}))
+			defer server.Close()
+
+			got, err := newTestClient(server).Me()
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Me() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("Me() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,139 @@
+package x
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/url"
+	"strconv"
+	"time"
+)
+
+const (
+	// X API maximum results per request for follow lists
+	followListMaxResults = "1000"
+	// Upper bound of pages to follow for follow lists
+	followListMaxPages = 50
+	// Longest wait for a rate limit reset while paging
+	maxRateLimitWait = 16 * time.Minute
+	// Epoch of tweet IDs (Snowflake) in milliseconds
+	snowflakeEpoch = 1288834974657
+)
+
+// sleep is replaced in tests
+var sleep = time.Sleep
+
+// User はXのユーザーです。
+type User struct {
+	ID                string      `json:"id"`
+	Username          string      `json:"username"`
+	Name              string      `json:"name"`
+	Description       string      `json:"description"`
+	Verified          bool        `json:"verified"`
+	VerifiedType      string      `json:"verified_type"`
+	MostRecentTweetID string      `json:"most_recent_tweet_id"`
+	PublicMetrics     UserMetrics `json:"public_metrics"`
+}
+
+// UserMetrics はユーザーの公開指標です。
+type UserMetrics struct {
+	FollowersCount int `json:"followers_count"`
+	FollowingCount int `json:"following_count"`
+	TweetCount     int `json:"tweet_count"`
+	ListedCount    int `json:"listed_count"`
+}
+
+// IsVerified はブルーバッジまたは金バッジなどの認証済みアカウントかを返します。
+func (u User) IsVerified() bool {
+	return u.Verified || (u.VerifiedType != "" && u.VerifiedType != "none")
+}
+
+// LastTweetAt は最新のツイートの投稿日時を返します。ツイートIDのSnowflakeから求めるため追加のリクエストは不要です。
+// ツイートがない場合はゼロ値を返します。
+func (u User) LastTweetAt() time.Time {
+	id, err := strconv.ParseInt(u.MostRecentTweetID, 10, 64)
+	if err != nil {
+		return time.Time{}
+	}
+	return time.UnixMilli((id >> 22) + snowflakeEpoch)
+}
+
+// Profile は認証しているユーザーの情報を公開指標付きで取得します。
+func (c *Client) Profile() (*User, error) {
+	params := url.Values{"user.fields": {"description,verified,verified_type,public_metrics"}}
+	var result struct {
+		Data User `json:"data"`
+	}
+	if err := c.getJSON(c.usersEndpoint+"/me", params, &result); err != nil {
+		return nil, fmt.Errorf("failed to get authenticated user: %w", err)
+	}
+	return &result.Data, nil
+}
+
+// Following はユーザーがフォローしているアカウントをすべて取得します。
+func (c *Client) Following(userID string) ([]User, error) {
+	return c.followList(userID, "following")
+}
+
+// Followers はユーザーのフォロワーをすべて取得します。
+func (c *Client) Followers(userID string) ([]User, error) {
+	return c.followList(userID, "followers")
+}
+
+// followList はページをたどってフォロー・フォロワー一覧を取得します。レート制限に達した場合は解除まで待ちます。
+func (c *Client) followList(userID, kind string) ([]User, error) {
+	params := url.Values{}
+	params.Set("max_results", followListMaxResults)
+	params.Set("user.fields", "description,verified,verified_type,most_recent_tweet_id,public_metrics")
+
+	var users []User
+	for page := 0; page < followListMaxPages; {
+		var result struct {
+			Data []User `json:"data"`
+			Meta struct {
+				NextToken string `json:"next_token"`
+			} `json:"meta"`
+		}
+		err := c.getJSON(c.usersEndpoint+"/"+userID+"/"+kind, params, &result)
+		var rateErr *RateLimitError
+		if errors.As(err, &rateErr) {
+			wait := time.Until(rateErr.Reset)
+			if wait > maxRateLimitWait {
+				return nil, err
+			}
+			slog.Info("Waiting for X API rate limit reset", "kind", kind, "wait", wait)
+			sleep(wait)
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to get %s: %w", kind, err)
+		}
+		users = append(users, result.Data...)
+		page++
+
+		if result.Meta.NextToken == "" {
+			return users, nil
+		}
+		params.Set("pagination_token", result.Meta.NextToken)
+	}
+
+	slog.Warn("Follow list truncated", "kind", kind, "pages", followListMaxPages, "users", len(users))
+	return users, nil
+}
+
+// Follow はsourceUserIDのアカウントでtargetUserIDをフォローします。
+func (c *Client) Follow(sourceUserID, targetUserID string) error {
+	payload := map[string]string{"target_user_id": targetUserID}
+	if err := c.doJSON("POST", c.usersEndpoint+"/"+sourceUserID+"/following", payload, nil); err != nil {
+		return fmt.Errorf("failed to follow %s: %w", targetUserID, err)
+	}
+	return nil
+}
+
+// Unfollow はsourceUserIDのアカウントでtargetUserIDのフォローを解除します。
+func (c *Client) Unfollow(sourceUserID, targetUserID string) error {
+	if err := c.doJSON("DELETE", c.usersEndpoint+"/"+sourceUserID+"/following/"+targetUserID, nil, nil); err != nil {
+		return fmt.Errorf("failed to unfollow %s: %w", targetUserID, err)
+	}
+	return nil
+}
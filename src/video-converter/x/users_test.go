@@ -0,0 +1,112 @@
+package x
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestFollowListPagesAndWaitsForRateLimit(t *testing.T) {
+	var slept []time.Duration
+	sleep = func(d time.Duration) { slept = append(slept, d) }
+	defer func() { sleep = time.Sleep }()
+
+	var mu sync.Mutex
+	calls := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/2/users/123/followers" {
+			t.Errorf("path = %s", r.URL.Path)
+		}
+		mu.Lock()
+		calls++
+		n := calls
+		mu.Unlock()
+
+		switch n {
+		case 1:
+			_, _ = w.Write([]byte(`{"data":[{"id":"1","username":"a","verified_type":"blue"}],"meta":{"next_token":"p2"}}`))
+		case 2:
+			w.Header().Set("x-rate-limit-reset", strconv.FormatInt(time.Now().Add(time.Minute).Unix(), 10))
+			w.WriteHeader(http.StatusTooManyRequests)
+		default:
+			if r.URL.Query().Get("pagination_token") != "p2" {
+				t.Errorf("pagination_token = %q, want p2", r.URL.Query().Get("pagination_token"))
+			}
+			_, _ = w.Write([]byte(`{"data":[{"id":"2","username":"b"}],"meta":{}}`))
+		}
+	}))
+	defer server.Close()
+
+	users, err := newTestClient(server).Followers("123")
+	if err != nil {
+		t.Fatalf("Followers() error = %v", err)
+	}
+	if len(users) != 2 || users[0].Username != "a" || users[1].Username != "b" {
+		t.Errorf("users = %+v", users)
+	}
+	if !users[0].IsVerified() || users[1].IsVerified() {
+		t.Errorf("IsVerified() = %v, %v, want true, false", users[0].IsVerified(), users[1].IsVerified())
+	}
+	if len(slept) != 1 || slept[0] <= 0 || slept[0] > time.Minute {
+		t.Errorf("slept = %v, want one wait up to a minute", slept)
+	}
+}
+
+func TestFollowAndUnfollow(t *testing.T) {
+	t.Parallel()
+
+	var got []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got = append(got, r.Method+" "+r.URL.Path)
+		if r.Method == "POST" {
+			var payload map[string]string
+			if err := json.NewDecoder(r.Body).Decode(&payload); err != nil || payload["target_user_id"] != "456" {
+				t.Errorf("payload = %v, err = %v", payload, err)
+			}
+			_, _ = w.Write([]byte(`{"data":{"following":true}}`))
+			return
+		}
+		_, _ = w.Write([]byte(`{"data":{"following":false}}`))
+	}))
+	defer server.Close()
+
+	client := newTestClient(server)
+	if err := client.Follow("123", "456"); err != nil {
+		t.Fatalf("Follow() error = %v", err)
+	}
+	if err := client.Unfollow("123", "456"); err != nil {
+		t.Fatalf("Unfollow() error = %v", err)
+	}
+
+	want := []string{"POST /2/users/123/following", "DELETE /2/users/123/following/456"}
+	if len(got) != 2 || got[0] != want[0] || got[1] != want[1] {
+		t.Errorf("requests = %v, want %v", got, want)
+	}
+}
+
+func TestLastTweetAt(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name string
+		id   string
+		want time.Time
+	}{
+		{name: "正常系: ツイートIDから投稿日時を求める", id: "1913095775930007825", want: time.Date(2025, 4, 18, 5, 2, 31, 0, time.UTC)},
+		{name: "正常系: ツイートがない", id: "", want: time.Time{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			got := User{MostRecentTweetID: tt.id}.LastTweetAt()
+			if !got.Truncate(time.Second).Equal(tt.want) {
+				t.Errorf("LastTweetAt() = %v, want %v", got.UTC(), tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,38 @@
+package youtube
+
+import (
+	"errors"
+	"net/url"
+)
+
+// ChannelStatistics はチャンネルの統計情報です。
+type ChannelStatistics struct {
+	ChannelID       string
+	SubscriberCount int64 `json:"subscriberCount,string"`
+	ViewCount       int64 `json:"viewCount,string"`
+	VideoCount      int64 `json:"videoCount,string"`
+	// HiddenSubscriberCount が true の場合、SubscriberCount は公開されていません
+	HiddenSubscriberCount bool `json:"hiddenSubscriberCount"`
+}
+
+// MyChannelStatistics は認証しているチャンネルの統計情報を channels?part=statistics&mine=true で取得します。
+func (c *Client) MyChannelStatistics() (*ChannelStatistics, error) {
+	params := url.Values{"part": {"statistics"}, "mine": {"true"}}
+
+	var result struct {
+		Items []struct {
+			ID         string            `json:"id"`
+			Statistics ChannelStatistics `json:"statistics"`
+		} `json:"items"`
+	}
+	if err := c.call("GET", "channels", params, nil, &result); err != nil {
+		return nil, err
+	}
+	if len(result.Items) == 0 {
+		return nil, errors.New("no channel found for the authenticated user")
+	}
+
+	stats := result.Items[0].Statistics
+	stats.ChannelID = result.Items[0].ID
+	return &stats, nil
+}
@@ -0,0 +1,207 @@
+// Package youtube はYouTube Data APIのクライアントです。OAuthのリフレッシュトークンでアクセストークンを取得します。
+package youtube
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	tokenEndpoint     = "https://oauth2.googleapis.com/token"
+	apiEndpoint       = "https://www.googleapis.com/youtube/v3"
+	analyticsEndpoint = "https://youtubeanalytics.googleapis.com/v2"
+)
+
+// ErrNotConfigured はYouTubeの認証情報が設定されていないことを表します。
+var ErrNotConfigured = errors.New("youtube credentials are not configured")
+
+// Credentials はOAuthクライアントとリフレッシュトークンです。
+type Credentials struct {
+	ClientID     string
+	ClientSecret string
+	RefreshToken string
+}
+
+// CredentialsFromEnv は YOUTUBE_CLIENT_ID、YOUTUBE_CLIENT_SECRET、YOUTUBE_REFRESH_TOKEN から認証情報を読み込みます。
+func CredentialsFromEnv() (Credentials, error) {
+	creds := Credentials{
+		ClientID:     os.Getenv("YOUTUBE_CLIENT_ID"),
+		ClientSecret: os.Getenv("YOUTUBE_CLIENT_SECRET"),
+		RefreshToken: os.Getenv("YOUTUBE_REFRESH_TOKEN"),
+	}
+	if creds.ClientID == "" || creds.ClientSecret == "" || creds.RefreshToken == "" {
+		return Credentials{}, ErrNotConfigured
+	}
+	return creds, nil
+}
+
+// Client はYouTube Data APIのクライアントです。
+type Client struct {
+	httpClient        *http.Client
+	apiEndpoint       string
+	analyticsEndpoint string
+	tokenEndpoint     string
+	creds             Credentials
+
+	mu          sync.Mutex
+	accessToken string
+	expiry      time.Time
+}
+
+// NewClient はクライアントを作成します。
+func NewClient(creds Credentials) *Client {
+	return &Client{
+		httpClient:        &http.Client{Timeout: 30 * time.Second},
+		apiEndpoint:       apiEndpoint,
+		analyticsEndpoint: analyticsEndpoint,
+		tokenEndpoint:     tokenEndpoint,
+		creds:             creds,
+	}
+}
+
+// NewClientFromEnv は環境変数の認証情報でクライアントを作成します。
+func NewClientFromEnv() (*Client, error) {
+	creds, err := CredentialsFromEnv()
+	if err != nil {
+		return nil, err
+	}
+	return NewClient(creds), nil
+}
+
+// token は有効なアクセストークンを返します。期限が切れていればリフレッシュします。
+func (c *Client) token() (string, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.accessToken != "" && time.Now().Add(time.Minute).Before(c.expiry) {
+		return c.accessToken, nil
+	}
+
+	params := url.Values{
+		"client_id":     {c.creds.ClientID},
+		"client_secret": {c.creds.ClientSecret},
+		"refresh_token": {c.creds.RefreshToken},
+		"grant_type":    {"refresh_token"},
+	}
+	req, err := http.NewRequest("POST", c.tokenEndpoint, strings.NewReader(params.Encode()))
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	var result struct {
+		AccessToken string `json:"access_token"`
+		ExpiresIn   int    `json:"expires_in"`
+	}
+	if err := c.send(req, &result); err != nil {
+		return "", fmt.Errorf("failed to refresh youtube access token: %w", err)
+	}
+	c.accessToken = result.AccessToken
+	c.expiry = time.Now().Add(time.Duration(result.ExpiresIn) * time.Second)
+	return c.accessToken, nil
+}
+
+// call はData APIを呼び出します。payloadがnilでなければJSONで送ります。
+func (c *Client) call(method, resource string, params url.Values, payload, out any) error {
+	return c.request(method, c.apiEndpoint+"/"+resource, params, payload, out)
+}
+
+func (c *Client) request(method, endpoint string, params url.Values, payload, out any) error {
+	token, err := c.token()
+	if err != nil {
+		return err
+	}
+
+	var body io.Reader
+	if payload != nil {
+		data, err := json.Marshal(payload)
+		if err != nil {
+			return err
+		}
+		body = bytes.NewReader(data)
+	}
+
+	if len(params) > 0 {
+		endpoint += "?" + params.Encode()
+	}
+	req, err := http.NewRequest(method, endpoint, body)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Authorization", "Bearer "+token)
+	if payload != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	return c.send(req, out)
+}
+
+func (c *Client) send(req *http.Request, out any) error {
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err := resp.Body.Close(); err != nil {
+			slog.Error("Failed to close response body", "error", err)
+		}
+	}()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		slog.Error("YouTube API error response", "url", req.URL.Path, "status", resp.Status, "body", string(body))
+		return newAPIError(req.URL.Path, resp.StatusCode, body)
+	}
+	if out == nil || len(body) == 0 {
+		return nil
+	}
+	return json.Unmarshal(body, out)
+}
+
+// APIError はAPIがエラーを返したことを表します。
+type APIError struct {
+	Path       string
+	StatusCode int
+	// Reason はエラーの理由です（quotaExceeded など）。レスポンスに含まれない場合は空です
+	Reason string
+}
+
+func (e *APIError) Error() string {
+	if e.Reason != "" {
+		return fmt.Sprintf("%s returned unexpected status code: %d (%s)", e.Path, e.StatusCode, e.Reason)
+	}
+	return fmt.Sprintf("%s returned unexpected status code: %d", e.Path, e.StatusCode)
+}
+
+func newAPIError(path string, statusCode int, body []byte) *APIError {
+	var result struct {
+		Error struct {
+			Errors []struct {
+				Reason string `json:"reason"`
+			} `json:"errors"`
+		} `json:"error"`
+	}
+	apiErr := &APIError{Path: path, StatusCode: statusCode}
+	if json.Unmarshal(body, &result) == nil && len(result.Error.Errors) > 0 {
+		apiErr.Reason = result.Error.Errors[0].Reason
+	}
+	return apiErr
+}
+
+// IsQuotaExceeded はエラーがAPIの1日のクォータを使い切ったことによるものかを返します。
+func IsQuotaExceeded(err error) bool {
+	var apiErr *APIError
+	return errors.As(err, &apiErr) && (apiErr.Reason == "quotaExceeded" || apiErr.Reason == "dailyLimitExceeded")
+}
@@ -0,0 +1,104 @@
+package youtube
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+)
+
+// newTestClient はトークンエンドポイントとAPIをserverに向けたクライアントを返します。
+func newTestClient(server *httptest.Server) *Client {
+	client := NewClient(Credentials{ClientID: "id", ClientSecret: "secret", RefreshToken: "refresh"})
+	client.httpClient = server.Client()
+	client.apiEndpoint = server.URL + "/youtube/v3"
+	client.analyticsEndpoint = server.URL + "/v2"
+	client.tokenEndpoint = server.URL + "/token"
+	return client
+}
+
+// tokenHandler はアクセストークンを返すハンドラーを登録し、呼ばれた回数を数えます。
+func tokenHandler(t *testing.T, mux *http.ServeMux) *int {
+	var mu sync.Mutex
+	count := 0
+	mux.HandleFunc("/token", func(w http.ResponseWriter, r *http.Request) {
+		if err := r.ParseForm(); err != nil || r.PostForm.Get("refresh_token") != "refresh" {
+			t.Errorf("refresh_token = %q, err = %v", r.PostForm.Get("refresh_token"), err)
+		}
+		mu.Lock()
+		count++
+		mu.Unlock()
+		_, _ = w.Write([]byte(`{"access_token":"access","expires_in":3600}`))
+	})
+	return &count
+}
+
+func TestMyChannelStatistics(t *testing.T) {
+	t.Parallel()
+
+	mux := http.NewServeMux()
+	tokenCount := tokenHandler(t, mux)
+	mux.HandleFunc("/youtube/v3/channels", func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer access" {
+			t.Errorf("Authorization = %q", r.Header.Get("Authorization"))
+		}
+		if r.URL.Query().Get("part") != "statistics" || r.URL.Query().Get("mine") != "true" {
+			t.Errorf("query = %s", r.URL.RawQuery)
+		}
+		_, _ = w.Write([]byte(`{"items":[{"id":"UC123","statistics":{"viewCount":"12345","subscriberCount":"678","hiddenSubscriberCount":false,"videoCount":"90"}}]}`))
+	})
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	client := newTestClient(server)
+	for range 2 {
+		stats, err := client.MyChannelStatistics()
+		if err != nil {
+			t.Fatalf("MyChannelStatistics() error = %v", err)
+		}
+		if stats.ChannelID != "UC123" || stats.ViewCount != 12345 || stats.SubscriberCount != 678 || stats.VideoCount != 90 {
+			t.Errorf("stats = %+v", stats)
+		}
+	}
+	// アクセストークンは期限まで使い回す
+	if *tokenCount != 1 {
+		t.Errorf("token requests = %d, want 1", *tokenCount)
+	}
+}
+
+func TestIsQuotaExceeded(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name   string
+		status int
+		body   string
+		want   bool
+	}{
+		{name: "正常系: クォータ超過", status: http.StatusForbidden, body: `{"error":{"code":403,"errors":[{"reason":"quotaExceeded"}]}}`, want: true},
+		{name: "正常系: 権限不足", status: http.StatusForbidden, body: `{"error":{"code":403,"errors":[{"reason":"forbidden"}]}}`, want: false},
+		{name: "正常系: JSONでないエラー", status: http.StatusInternalServerError, body: `oops`, want: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			mux := http.NewServeMux()
+			tokenHandler(t, mux)
+			mux.HandleFunc("/youtube/v3/channels", func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(tt.status)
+				_, _ = w.Write([]byte(tt.body))
+			})
+			server := httptest.NewServer(mux)
+			defer server.Close()
+
+			_, err := newTestClient(server).MyChannelStatistics()
+			if err == nil {
+				t.Fatal("MyChannelStatistics() should fail")
+			}
+			if got := IsQuotaExceeded(err); got != tt.want {
+				t.Errorf("IsQuotaExceeded(%v) = %v, want %v", err, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,135 @@
+package youtube
+
+import (
+	"net/url"
+	"strings"
+	"time"
+)
+
+// ModerationStatus はコメントの公開状態です。
+type ModerationStatus string
+
+const (
+	ModerationPublished     ModerationStatus = "published"
+	ModerationHeldForReview ModerationStatus = "heldForReview"
+	ModerationRejected      ModerationStatus = "rejected"
+)
+
+// Maximum number of comment threads per page allowed by commentThreads.list
+const commentThreadsPageSize = "100"
+
+// Comment はトップレベルのコメントです。
+type Comment struct {
+	ID              string
+	VideoID         string
+	AuthorName      string
+	AuthorChannelID string
+	Text            string
+	PublishedAt     time.Time
+	LikeCount       int
+	ReplyCount      int
+}
+
+// URL はコメントを表示するURLを返します。
+func (c Comment) URL() string {
+	return "https://www.youtube.com/watch?v=" + c.VideoID + "&lc=" + c.ID
+}
+
+type commentThread struct {
+	ID      string `json:"id"`
+	Snippet struct {
+		VideoID         string `json:"videoId"`
+		TotalReplyCount int    `json:"totalReplyCount"`
+		TopLevelComment struct {
+			ID      string `json:"id"`
+			Snippet struct {
+				AuthorDisplayName string `json:"authorDisplayName"`
+				AuthorChannelID   struct {
+					Value string `json:"value"`
+				} `json:"authorChannelId"`
+				TextOriginal string    `json:"textOriginal"`
+				PublishedAt  time.Time `json:"publishedAt"`
+				LikeCount    int       `json:"likeCount"`
+			} `json:"snippet"`
+		} `json:"topLevelComment"`
+	} `json:"snippet"`
+}
+
+func (t commentThread) comment() Comment {
+	top := t.Snippet.TopLevelComment
+	return Comment{
+		ID:              top.ID,
+		VideoID:         t.Snippet.VideoID,
+		AuthorName:      top.Snippet.AuthorDisplayName,
+		AuthorChannelID: top.Snippet.AuthorChannelID.Value,
+		Text:            top.Snippet.TextOriginal,
+		PublishedAt:     top.Snippet.PublishedAt,
+		LikeCount:       top.Snippet.LikeCount,
+		ReplyCount:      t.Snippet.TotalReplyCount,
+	}
+}
+
+// CommentThreads はチャンネルの動画に付いたコメントのうち、sinceより後に投稿されたものを新しい順に返します。
+// commentThreads?allThreadsRelatedToChannelId を新しい順に取得し、since以前のコメントに達したらページングを止めます。
+func (c *Client) CommentThreads(channelID string, since time.Time) ([]Comment, error) {
+	params := url.Values{
+		"part":                         {"snippet"},
+		"allThreadsRelatedToChannelId": {channelID},
+		"maxResults":                   {commentThreadsPageSize},
+		"order":                        {"time"},
+		"textFormat":                   {"plainText"},
+	}
+
+	var comments []Comment
+	for {
+		var result struct {
+			Items         []commentThread `json:"items"`
+			NextPageToken string          `json:"nextPageToken"`
+		}
+		if err := c.call("GET", "commentThreads", params, nil, &result); err != nil {
+			return nil, err
+		}
+		for _, item := range result.Items {
+			comment := item.comment()
+			if !comment.PublishedAt.After(since) {
+				return comments, nil
+			}
+			comments = append(comments, comment)
+		}
+		if result.NextPageToken == "" {
+			return comments, nil
+		}
+		params.Set("pageToken", result.NextPageToken)
+	}
+}
+
+// SetModerationStatus はコメントの公開状態を comments/setModerationStatus で変更します。
+// banAuthor は status が rejected の場合だけ有効で、投稿者のコメントを今後も自動で拒否します。
+func (c *Client) SetModerationStatus(ids []string, status ModerationStatus, banAuthor bool) error {
+	params := url.Values{
+		"id":               {strings.Join(ids, ",")},
+		"moderationStatus": {string(status)},
+	}
+	if banAuthor && status == ModerationRejected {
+		params.Set("banAuthor", "true")
+	}
+	return c.call("POST", "comments/setModerationStatus", params, nil, nil)
+}
+
+// Reply はコメントに返信し、返信のIDを返します。
+func (c *Client) Reply(parentID, text string) (string, error) {
+	payload := map[string]any{
+		"snippet": map[string]string{
+			"parentId":     parentID,
+			"textOriginal": text,
+		},
+	}
+
+	var result struct {
+		ID string `json:"id"`
+	}
+	if err := c.call("POST", "comments", url.Values{"part": {"snippet"}}, payload, &result); err != nil {
+		return "", err
+	}
+	return result.ID, nil
+}
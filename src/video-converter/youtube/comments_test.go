@@ -0,0 +1,124 @@
+package youtube
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestCommentThreads(t *testing.T) {
+	t.Parallel()
+
+	pages := map[string]string{
+		"": `{"items":[
+			{"id":"t3","snippet":{"videoId":"v1","totalReplyCount":1,"topLevelComment":{"id":"c3","snippet":{"authorDisplayName":"A","authorChannelId":{"value":"UCa"},"textOriginal":"最高！","publishedAt":"2025-05-03T10:00:00Z","likeCount":2}}}},
+			{"id":"t2","snippet":{"videoId":"v2","topLevelComment":{"id":"c2","snippet":{"authorDisplayName":"B","textOriginal":"すごい","publishedAt":"2025-05-02T10:00:00Z"}}}}
+		],"nextPageToken":"p2"}`,
+		"p2": `{"items":[
+			{"id":"t1","snippet":{"videoId":"v1","topLevelComment":{"id":"c1","snippet":{"authorDisplayName":"C","textOriginal":"old","publishedAt":"2025-05-01T10:00:00Z"}}}},
+			{"id":"t0","snippet":{"videoId":"v1","topLevelComment":{"id":"c0","snippet":{"authorDisplayName":"D","textOriginal":"older","publishedAt":"2025-04-30T10:00:00Z"}}}}
+		],"nextPageToken":"p3"}`,
+	}
+
+	mux := http.NewServeMux()
+	tokenHandler(t, mux)
+	mux.HandleFunc("/youtube/v3/commentThreads", func(w http.ResponseWriter, r *http.Request) {
+		query := r.URL.Query()
+		if query.Get("allThreadsRelatedToChannelId") != "UC123" || query.Get("order") != "time" {
+			t.Errorf("query = %s", r.URL.RawQuery)
+		}
+		page, ok := pages[query.Get("pageToken")]
+		if !ok {
+			t.Errorf("unexpected page %q", query.Get("pageToken"))
+		}
+		_, _ = w.Write([]byte(page))
+	})
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	since := time.Date(2025, 5, 1, 10, 0, 0, 0, time.UTC)
+	comments, err := newTestClient(server).CommentThreads("UC123", since)
+	if err != nil {
+		t.Fatalf("CommentThreads() error = %v", err)
+	}
+	// since以前のコメントに達したら次のページは取得しない
+	if len(comments) != 2 || comments[0].ID != "c3" || comments[1].ID != "c2" {
+		t.Fatalf("comments = %+v", comments)
+	}
+	got := comments[0]
+	if got.VideoID != "v1" || got.AuthorName != "A" || got.AuthorChannelID != "UCa" || got.Text != "最高！" || got.LikeCount != 2 || got.ReplyCount != 1 {
+		t.Errorf("comment = %+v", got)
+	}
+	if got.URL() != "https://www.youtube.com/watch?v=v1&lc=c3" {
+		t.Errorf("URL() = %q", got.URL())
+	}
+}
+
+func TestSetModerationStatus(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name      string
+		status    ModerationStatus
+		banAuthor bool
+		wantBan   string
+	}{
+		{name: "正常系: 保留", status: ModerationHeldForReview, banAuthor: true, wantBan: ""},
+		{name: "正常系: 拒否して投稿者をブロック", status: ModerationRejected, banAuthor: true, wantBan: "true"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			mux := http.NewServeMux()
+			tokenHandler(t, mux)
+			mux.HandleFunc("/youtube/v3/comments/setModerationStatus", func(w http.ResponseWriter, r *http.Request) {
+				query := r.URL.Query()
+				if r.Method != "POST" || query.Get("id") != "c1,c2" || query.Get("moderationStatus") != string(tt.status) || query.Get("banAuthor") != tt.wantBan {
+					t.Errorf("request = %s %s", r.Method, r.URL.RawQuery)
+				}
+				w.WriteHeader(http.StatusNoContent)
+			})
+			server := httptest.NewServer(mux)
+			defer server.Close()
+
+			if err := newTestClient(server).SetModerationStatus([]string{"c1", "c2"}, tt.status, tt.banAuthor); err != nil {
+				t.Errorf("SetModerationStatus() error = %v", err)
+			}
+		})
+	}
+}
+
+func TestReply(t *testing.T) {
+	t.Parallel()
+
+	mux := http.NewServeMux()
+	tokenHandler(t, mux)
+	mux.HandleFunc("/youtube/v3/comments", func(w http.ResponseWriter, r *http.Request) {
+		var payload struct {
+			Snippet struct {
+				ParentID     string `json:"parentId"`
+				TextOriginal string `json:"textOriginal"`
+			} `json:"snippet"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+			t.Errorf("failed to parse payload: %v", err)
+		}
+		if r.URL.Query().Get("part") != "snippet" || payload.Snippet.ParentID != "c1" || payload.Snippet.TextOriginal != "ありがとう！" {
+			t.Errorf("request = %s %+v", r.URL.RawQuery, payload)
+		}
+		_, _ = w.Write([]byte(`{"id":"c1.r1"}`))
+	})
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	id, err := newTestClient(server).Reply("c1", "ありがとう！")
+	if err != nil {
+		t.Fatalf("Reply() error = %v", err)
+	}
+	if id != "c1.r1" {
+		t.Errorf("Reply() = %q, want c1.r1", id)
+	}
+}
@@ -0,0 +1,89 @@
+package youtube
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// VideoSnippet は動画のタイトル・説明・タグ・カテゴリです。
+// videos.update は snippet を丸ごと置き換えるため、取得したときの他の項目も保持して更新時に送ります。
+type VideoSnippet struct {
+	ID          string
+	Title       string
+	Description string
+	Tags        []string
+	CategoryID  string
+	PublishedAt time.Time
+
+	raw map[string]json.RawMessage
+}
+
+type snippetFields struct {
+	Title       string    `json:"title"`
+	Description string    `json:"description"`
+	Tags        []string  `json:"tags"`
+	CategoryID  string    `json:"categoryId"`
+	PublishedAt time.Time `json:"publishedAt"`
+}
+
+// VideoSnippets は動画の snippet を videos?part=snippet で取得します。削除された動画は含めません。
+func (c *Client) VideoSnippets(ids []string) ([]VideoSnippet, error) {
+	var snippets []VideoSnippet
+	for start := 0; start < len(ids); start += maxPageSize {
+		batch := ids[start:min(start+maxPageSize, len(ids))]
+		params := url.Values{"part": {"snippet"}, "id": {strings.Join(batch, ",")}, "maxResults": {"50"}}
+
+		var result struct {
+			Items []struct {
+				ID      string          `json:"id"`
+				Snippet json.RawMessage `json:"snippet"`
+			} `json:"items"`
+		}
+		if err := c.call("GET", "videos", params, nil, &result); err != nil {
+			return nil, err
+		}
+		for _, item := range result.Items {
+			var fields snippetFields
+			if err := json.Unmarshal(item.Snippet, &fields); err != nil {
+				return nil, fmt.Errorf("invalid snippet of video %s: %w", item.ID, err)
+			}
+			var raw map[string]json.RawMessage
+			if err := json.Unmarshal(item.Snippet, &raw); err != nil {
+				return nil, fmt.Errorf("invalid snippet of video %s: %w", item.ID, err)
+			}
+			snippets = append(snippets, VideoSnippet{
+				ID:          item.ID,
+				Title:       fields.Title,
+				Description: fields.Description,
+				Tags:        fields.Tags,
+				CategoryID:  fields.CategoryID,
+				PublishedAt: fields.PublishedAt,
+				raw:         raw,
+			})
+		}
+	}
+	return snippets, nil
+}
+
+// UpdateVideoSnippet は動画の snippet を videos?part=snippet で更新します。
+// タイトル・説明・タグ・カテゴリ以外の項目は VideoSnippets で取得したときの値を送ります。
+func (c *Client) UpdateVideoSnippet(snippet VideoSnippet) error {
+	raw := make(map[string]any, len(snippet.raw)+4)
+	for key, value := range snippet.raw {
+		raw[key] = value
+	}
+	// Read-only fields are ignored by videos.update, but they are dropped to keep the request small
+	for _, key := range []string{"publishedAt", "channelId", "channelTitle", "thumbnails", "liveBroadcastContent", "localized"} {
+		delete(raw, key)
+	}
+	raw["title"] = snippet.Title
+	raw["description"] = snippet.Description
+	raw["tags"] = snippet.Tags
+	raw["categoryId"] = snippet.CategoryID
+
+	payload := map[string]any{"id": snippet.ID, "snippet": raw}
+	return c.call("PUT", "videos", url.Values{"part": {"snippet"}}, payload, nil)
+}
@@ -0,0 +1,251 @@
+package youtube
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Maximum number of IDs or items per request allowed by videos.list, playlists.list and playlistItems.list
+const maxPageSize = 50
+
+// PlaylistVideo は再生リストに含まれる動画です。
+type PlaylistVideo struct {
+	// ItemID は再生リストのアイテムのIDで、削除や並べ替えに使います
+	ItemID      string
+	VideoID     string
+	Title       string
+	PublishedAt time.Time
+	Position    int
+}
+
+// Video は動画とその統計情報です。
+type Video struct {
+	ID           string
+	Title        string
+	PublishedAt  time.Time
+	Duration     time.Duration
+	ViewCount    int64
+	LikeCount    int64
+	CommentCount int64
+}
+
+// PlaylistItems は再生リストのすべての動画を playlistItems?part=snippet,contentDetails で再生リストの順に取得します。
+// 非公開や削除済みで公開日時が分からない動画は含めません。
+func (c *Client) PlaylistItems(playlistID string) ([]PlaylistVideo, error) {
+	params := url.Values{
+		"part":       {"snippet,contentDetails"},
+		"playlistId": {playlistID},
+		"maxResults": {"50"},
+	}
+
+	var videos []PlaylistVideo
+	for {
+		var result struct {
+			Items []struct {
+				ID      string `json:"id"`
+				Snippet struct {
+					Title    string `json:"title"`
+					Position int    `json:"position"`
+				} `json:"snippet"`
+				ContentDetails struct {
+					VideoID          string    `json:"videoId"`
+					VideoPublishedAt time.Time `json:"videoPublishedAt"`
+				} `json:"contentDetails"`
+			} `json:"items"`
+			NextPageToken string `json:"nextPageToken"`
+		}
+		if err := c.call("GET", "playlistItems", params, nil, &result); err != nil {
+			return nil, err
+		}
+		for _, item := range result.Items {
+			if item.ContentDetails.VideoPublishedAt.IsZero() {
+				continue
+			}
+			videos = append(videos, PlaylistVideo{
+				ItemID:      item.ID,
+				VideoID:     item.ContentDetails.VideoID,
+				Title:       item.Snippet.Title,
+				PublishedAt: item.ContentDetails.VideoPublishedAt,
+				Position:    item.Snippet.Position,
+			})
+		}
+		if result.NextPageToken == "" {
+			return videos, nil
+		}
+		params.Set("pageToken", result.NextPageToken)
+	}
+}
+
+// PlaylistTitles は再生リストのIDごとのタイトルを返します。
+func (c *Client) PlaylistTitles(ids []string) (map[string]string, error) {
+	titles := make(map[string]string, len(ids))
+	for start := 0; start < len(ids); start += maxPageSize {
+		batch := ids[start:min(start+maxPageSize, len(ids))]
+		params := url.Values{"part": {"snippet"}, "id": {strings.Join(batch, ",")}, "maxResults": {"50"}}
+
+		var result struct {
+			Items []struct {
+				ID      string `json:"id"`
+				Snippet struct {
+					Title string `json:"title"`
+				} `json:"snippet"`
+			} `json:"items"`
+		}
+		if err := c.call("GET", "playlists", params, nil, &result); err != nil {
+			return nil, err
+		}
+		for _, item := range result.Items {
+			titles[item.ID] = item.Snippet.Title
+		}
+	}
+	return titles, nil
+}
+
+// Videos は動画の統計情報と長さを videos?part=snippet,statistics,contentDetails で取得します。削除された動画は含めません。
+func (c *Client) Videos(ids []string) ([]Video, error) {
+	var videos []Video
+	for start := 0; start < len(ids); start += maxPageSize {
+		batch := ids[start:min(start+maxPageSize, len(ids))]
+		params := url.Values{"part": {"snippet,statistics,contentDetails"}, "id": {strings.Join(batch, ",")}, "maxResults": {"50"}}
+
+		var result struct {
+			Items []struct {
+				ID      string `json:"id"`
+				Snippet struct {
+					Title       string    `json:"title"`
+					PublishedAt time.Time `json:"publishedAt"`
+				} `json:"snippet"`
+				Statistics struct {
+					ViewCount    int64 `json:"viewCount,string"`
+					LikeCount    int64 `json:"likeCount,string"`
+					CommentCount int64 `json:"commentCount,string"`
+				} `json:"statistics"`
+				ContentDetails struct {
+					Duration string `json:"duration"`
+				} `json:"contentDetails"`
+			} `json:"items"`
+		}
+		if err := c.call("GET", "videos", params, nil, &result); err != nil {
+			return nil, err
+		}
+		for _, item := range result.Items {
+			duration, err := parseDuration(item.ContentDetails.Duration)
+			if err != nil {
+				return nil, fmt.Errorf("video %s: %w", item.ID, err)
+			}
+			videos = append(videos, Video{
+				ID:           item.ID,
+				Title:        item.Snippet.Title,
+				PublishedAt:  item.Snippet.PublishedAt,
+				Duration:     duration,
+				ViewCount:    item.Statistics.ViewCount,
+				LikeCount:    item.Statistics.LikeCount,
+				CommentCount: item.Statistics.CommentCount,
+			})
+		}
+	}
+	return videos, nil
+}
+
+// durationPattern matches ISO 8601 durations used by the Data API, such as "PT1H2M3S" or "P1DT2H"
+var durationPattern = regexp.MustCompile(`^P(?:(\d+)D)?(?:T(?:(\d+)H)?(?:(\d+)M)?(?:(\d+)S)?)?$`)
+
+// parseDuration はISO 8601形式の動画の長さを変換します。ライブ配信中などで空の場合は0を返します。
+func parseDuration(s string) (time.Duration, error) {
+	if s == "" {
+		return 0, nil
+	}
+	match := durationPattern.FindStringSubmatch(s)
+	if match == nil {
+		return 0, fmt.Errorf("invalid duration: %q", s)
+	}
+	units := []time.Duration{24 * time.Hour, time.Hour, time.Minute, time.Second}
+	var d time.Duration
+	for i, unit := range units {
+		if match[i+1] == "" {
+			continue
+		}
+		n, err := strconv.Atoi(match[i+1])
+		if err != nil {
+			return 0, err
+		}
+		d += time.Duration(n) * unit
+	}
+	return d, nil
+}
+
+// UploadsPlaylistID は認証しているチャンネルのアップロード動画の再生リストのIDを返します。
+func (c *Client) UploadsPlaylistID() (string, error) {
+	params := url.Values{"part": {"contentDetails"}, "mine": {"true"}}
+
+	var result struct {
+		Items []struct {
+			ContentDetails struct {
+				RelatedPlaylists struct {
+					Uploads string `json:"uploads"`
+				} `json:"relatedPlaylists"`
+			} `json:"contentDetails"`
+		} `json:"items"`
+	}
+	if err := c.call("GET", "channels", params, nil, &result); err != nil {
+		return "", err
+	}
+	if len(result.Items) == 0 || result.Items[0].ContentDetails.RelatedPlaylists.Uploads == "" {
+		return "", errors.New("no uploads playlist found for the authenticated user")
+	}
+	return result.Items[0].ContentDetails.RelatedPlaylists.Uploads, nil
+}
+
+func playlistItemPayload(playlistID, videoID string, position *int) map[string]any {
+	snippet := map[string]any{
+		"playlistId": playlistID,
+		"resourceId": map[string]string{"kind": "youtube#video", "videoId": videoID},
+	}
+	if position != nil {
+		snippet["position"] = *position
+	}
+	return map[string]any{"snippet": snippet}
+}
+
+// PlaylistContains は動画が再生リストに含まれているかを返します。
+func (c *Client) PlaylistContains(playlistID, videoID string) (bool, error) {
+	params := url.Values{"part": {"id"}, "playlistId": {playlistID}, "videoId": {videoID}}
+	var result struct {
+		Items []struct {
+			ID string `json:"id"`
+		} `json:"items"`
+	}
+	if err := c.call("GET", "playlistItems", params, nil, &result); err != nil {
+		return false, err
+	}
+	return len(result.Items) > 0, nil
+}
+
+// InsertPlaylistItem は動画を再生リストに追加し、アイテムのIDを返します。positionがnilの場合は末尾に追加します。
+func (c *Client) InsertPlaylistItem(playlistID, videoID string, position *int) (string, error) {
+	var result struct {
+		ID string `json:"id"`
+	}
+	payload := playlistItemPayload(playlistID, videoID, position)
+	if err := c.call("POST", "playlistItems", url.Values{"part": {"snippet"}}, payload, &result); err != nil {
+		return "", err
+	}
+	return result.ID, nil
+}
+
+// DeletePlaylistItem は再生リストのアイテムを削除します。
+func (c *Client) DeletePlaylistItem(itemID string) error {
+	return c.call("DELETE", "playlistItems", url.Values{"id": {itemID}}, nil, nil)
+}
+
+// MovePlaylistItem は再生リストのアイテムをpositionに移動します。再生リストが手動の並び順になっている必要があります。
+func (c *Client) MovePlaylistItem(itemID, playlistID, videoID string, position int) error {
+	payload := playlistItemPayload(playlistID, videoID, &position)
+	payload["id"] = itemID
+	return c.call("PUT", "playlistItems", url.Values{"part": {"snippet"}}, payload, nil)
+}
@@ -0,0 +1,252 @@
+package youtube
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestPlaylistItems(t *testing.T) {
+	t.Parallel()
+
+	mux := http.NewServeMux()
+	tokenHandler(t, mux)
+	mux.HandleFunc("/youtube/v3/playlistItems", func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("playlistId") != "PL1" {
+			t.Errorf("query = %s", r.URL.RawQuery)
+		}
+		switch r.URL.Query().Get("pageToken") {
+		case "":
+			_, _ = w.Write([]byte(`{"items":[
+				{"snippet":{"title":"v1"},"contentDetails":{"videoId":"v1","videoPublishedAt":"2025-05-01T12:00:00Z"}},
+				{"snippet":{"title":"Private video"},"contentDetails":{"videoId":"v2"}}
+			],"nextPageToken":"p2"}`))
+		case "p2":
+			_, _ = w.Write([]byte(`{"items":[{"snippet":{"title":"v3"},"contentDetails":{"videoId":"v3","videoPublishedAt":"2025-05-02T12:00:00Z"}}]}`))
+		}
+	})
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	videos, err := newTestClient(server).PlaylistItems("PL1")
+	if err != nil {
+		t.Fatalf("PlaylistItems() error = %v", err)
+	}
+	// 公開日時のない非公開動画は含めない
+	if len(videos) != 2 || videos[0].VideoID != "v1" || videos[1].VideoID != "v3" || !videos[1].PublishedAt.Equal(time.Date(2025, 5, 2, 12, 0, 0, 0, time.UTC)) {
+		t.Errorf("videos = %+v", videos)
+	}
+}
+
+func TestVideos(t *testing.T) {
+	t.Parallel()
+
+	ids := make([]string, 60)
+	for i := range ids {
+		ids[i] = fmt.Sprintf("v%d", i)
+	}
+
+	mux := http.NewServeMux()
+	tokenHandler(t, mux)
+	requests := 0
+	mux.HandleFunc("/youtube/v3/videos", func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		var items []string
+		for _, id := range strings.Split(r.URL.Query().Get("id"), ",") {
+			items = append(items, fmt.Sprintf(`{"id":%q,"snippet":{"title":"t","publishedAt":"2025-05-01T12:00:00Z"},"statistics":{"viewCount":"100","likeCount":"10","commentCount":"2"},"contentDetails":{"duration":"PT1M5S"}}`, id))
+		}
+		_, _ = w.Write([]byte(`{"items":[` + strings.Join(items, ",") + `]}`))
+	})
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	videos, err := newTestClient(server).Videos(ids)
+	if err != nil {
+		t.Fatalf("Videos() error = %v", err)
+	}
+	// 50件ずつ取得する
+	if requests != 2 || len(videos) != 60 {
+		t.Fatalf("requests = %d, videos = %d", requests, len(videos))
+	}
+	if v := videos[59]; v.ID != "v59" || v.ViewCount != 100 || v.LikeCount != 10 || v.CommentCount != 2 || v.Duration != 65*time.Second {
+		t.Errorf("video = %+v", v)
+	}
+}
+
+func TestVideoWatchTime(t *testing.T) {
+	t.Parallel()
+
+	mux := http.NewServeMux()
+	tokenHandler(t, mux)
+	mux.HandleFunc("/v2/reports", func(w http.ResponseWriter, r *http.Request) {
+		query := r.URL.Query()
+		want := map[string]string{
+			"ids":        "channel==MINE",
+			"startDate":  "2025-05-01",
+			"endDate":    "2025-05-10",
+			"metrics":    "estimatedMinutesWatched,averageViewDuration",
+			"dimensions": "video",
+			"sort":       "-estimatedMinutesWatched",
+			"filters":    "video==v1,v2",
+			"maxResults": "200",
+		}
+		for key, value := range want {
+			if got := query.Get(key); got != value {
+				t.Errorf("query %s = %q, want %q", key, got, value)
+			}
+		}
+		_, _ = w.Write([]byte(`{"columnHeaders":[{"name":"video"},{"name":"estimatedMinutesWatched"},{"name":"averageViewDuration"}],"rows":[["v1",120,95]]}`))
+	})
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	start := time.Date(2025, 5, 1, 0, 0, 0, 0, time.UTC)
+	got, err := newTestClient(server).VideoWatchTime([]string{"v1", "v2"}, start, start.AddDate(0, 0, 9))
+	if err != nil {
+		t.Fatalf("VideoWatchTime() error = %v", err)
+	}
+	if len(got) != 1 || got["v1"] != (WatchTime{MinutesWatched: 120, AverageViewDurationSeconds: 95}) {
+		t.Errorf("VideoWatchTime() = %+v", got)
+	}
+}
+
+func TestParseDuration(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		in      string
+		want    time.Duration
+		wantErr bool
+	}{
+		{name: "正常系: 時分秒", in: "PT1H2M3S", want: time.Hour + 2*time.Minute + 3*time.Second},
+		{name: "正常系: 秒だけ", in: "PT45S", want: 45 * time.Second},
+		{name: "正常系: 日を含む", in: "P1DT2H", want: 26 * time.Hour},
+		{name: "正常系: 空", in: "", want: 0},
+		{name: "異常系: 不正な形式", in: "1:02:03", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			got, err := parseDuration(tt.in)
+			if (err != nil) != tt.wantErr || got != tt.want {
+				t.Errorf("parseDuration(%q) = %v, %v, want %v, wantErr %v", tt.in, got, err, tt.want, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestPlaylistItemWrites(t *testing.T) {
+	t.Parallel()
+
+	var mu sync.Mutex
+	var calls []string
+	mux := http.NewServeMux()
+	tokenHandler(t, mux)
+	mux.HandleFunc("/youtube/v3/playlistItems", func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		mu.Lock()
+		calls = append(calls, r.Method+" "+r.URL.RawQuery+" "+string(body))
+		mu.Unlock()
+		if r.Method == "POST" {
+			_, _ = w.Write([]byte(`{"id":"item1"}`))
+		}
+	})
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	client := newTestClient(server)
+	id, err := client.InsertPlaylistItem("PL1", "v1", nil)
+	if err != nil || id != "item1" {
+		t.Fatalf("InsertPlaylistItem() = %q, %v", id, err)
+	}
+	if err := client.MovePlaylistItem("item1", "PL1", "v1", 0); err != nil {
+		t.Fatalf("MovePlaylistItem() error = %v", err)
+	}
+	if err := client.DeletePlaylistItem("item1"); err != nil {
+		t.Fatalf("DeletePlaylistItem() error = %v", err)
+	}
+
+	want := []string{
+		`POST part=snippet {"snippet":{"playlistId":"PL1","resourceId":{"kind":"youtube#video","videoId":"v1"}}}`,
+		`PUT part=snippet {"id":"item1","snippet":{"playlistId":"PL1","position":0,"resourceId":{"kind":"youtube#video","videoId":"v1"}}}`,
+		`DELETE id=item1 `,
+	}
+	if strings.Join(calls, "\n") != strings.Join(want, "\n") {
+		t.Errorf("calls =\n%s\nwant\n%s", strings.Join(calls, "\n"), strings.Join(want, "\n"))
+	}
+}
+
+func TestPlaylistContains(t *testing.T) {
+	t.Parallel()
+
+	mux := http.NewServeMux()
+	tokenHandler(t, mux)
+	mux.HandleFunc("/youtube/v3/playlistItems", func(w http.ResponseWriter, r *http.Request) {
+		query := r.URL.Query()
+		if query.Get("part") != "id" || query.Get("playlistId") != "PL1" {
+			t.Errorf("query = %s", r.URL.RawQuery)
+		}
+		if query.Get("videoId") == "v1" {
+			_, _ = w.Write([]byte(`{"items":[{"id":"item1"}]}`))
+			return
+		}
+		_, _ = w.Write([]byte(`{"items":[]}`))
+	})
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	client := newTestClient(server)
+	for videoID, want := range map[string]bool{"v1": true, "v2": false} {
+		got, err := client.PlaylistContains("PL1", videoID)
+		if err != nil || got != want {
+			t.Errorf("PlaylistContains(%q) = %v, %v, want %v", videoID, got, err, want)
+		}
+	}
+}
+
+func TestVideoSnippets(t *testing.T) {
+	t.Parallel()
+
+	var updated string
+	mux := http.NewServeMux()
+	tokenHandler(t, mux)
+	mux.HandleFunc("/youtube/v3/videos", func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case "GET":
+			_, _ = w.Write([]byte(`{"items":[{"id":"v1","snippet":{"publishedAt":"2025-05-01T12:00:00Z","channelId":"UC1","title":"t","description":"old","tags":["a"],"categoryId":"20","defaultLanguage":"ja","thumbnails":{}}}]}`))
+		case "PUT":
+			body, _ := io.ReadAll(r.Body)
+			updated = string(body)
+		}
+	})
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	client := newTestClient(server)
+	snippets, err := client.VideoSnippets([]string{"v1"})
+	if err != nil {
+		t.Fatalf("VideoSnippets() error = %v", err)
+	}
+	if len(snippets) != 1 || snippets[0].Description != "old" || snippets[0].Tags[0] != "a" || snippets[0].CategoryID != "20" {
+		t.Fatalf("snippets = %+v", snippets)
+	}
+
+	snippet := snippets[0]
+	snippet.Description = "new"
+	snippet.Tags = []string{"b"}
+	if err := client.UpdateVideoSnippet(snippet); err != nil {
+		t.Fatalf("UpdateVideoSnippet() error = %v", err)
+	}
+	// 取得した項目は保持し、読み取り専用の項目は送らない
+	want := `{"id":"v1","snippet":{"categoryId":"20","defaultLanguage":"ja","description":"new","tags":["b"],"title":"t"}}`
+	if updated != want {
+		t.Errorf("request = %s, want %s", updated, want)
+	}
+}